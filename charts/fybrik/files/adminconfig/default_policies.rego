@@ -30,6 +30,13 @@ config[{"capability": "delete", "decision": decision}] {
     decision := {"policy": policy, "deploy": "True"}
 }
 
+# stream requested by the user
+config[{"capability": "stream", "decision": decision}] {
+    input.request.usage == "stream"
+    policy := {"ID": "stream-request", "description":"Stream (continuous ingest) capability is requested by the user", "version": "0.1"}
+    decision := {"policy": policy, "deploy": "True"}
+}
+
 # do not deploy copy in scenarios different from read or copy
 config[{"capability": "copy", "decision": decision}] {
     input.request.usage != "read"
@@ -58,3 +65,10 @@ config[{"capability": "delete", "decision": decision}] {
     policy := {"ID": "delete-disabled", "description":"Delete capability is not requested", "version": "0.1"}
     decision := {"policy": policy, "deploy": "False"}
 }
+
+# do not deploy stream in other scenarios
+config[{"capability": "stream", "decision": decision}] {
+    input.request.usage != "stream"
+    policy := {"ID": "stream-disabled", "description":"Stream capability is not requested", "version": "0.1"}
+    decision := {"policy": policy, "deploy": "False"}
+}
@@ -148,6 +148,14 @@ Relevant only when .Values.chartsPersistentVolumeClaim is set.
 {{- printf "/opt/fybrik" }}
 {{- end }}
 
+{{/*
+auditMountPath returns the mount path of the persistent volume that holds the audit files.
+Relevant only when .Values.manager.audit.persistentVolumeClaim is set.
+*/}}
+{{- define "fybrik.auditMountPath" }}
+{{- printf "/opt/fybrik-audit" }}
+{{- end }}
+
 {{/*
 Print Data directory.
 */}}
@@ -0,0 +1,233 @@
+// Copyright 2023 IBM Corp.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	fappv1 "fybrik.io/fybrik/manager/apis/app/v1beta1"
+)
+
+const (
+	defaultApplicationNamespace = "default"
+	// padding between the columns of printed tables
+	tablePadding = 2
+)
+
+// appCmd groups the commands for FybrikApplication introspection
+var appCmd = &cobra.Command{
+	Use:   "app",
+	Short: "Inspect FybrikApplications",
+}
+
+var appStatusCmd = &cobra.Command{
+	Use:   "status <name>",
+	Short: "Show the per-asset conditions, endpoints and provisioned storage of a FybrikApplication",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		application := &fappv1.FybrikApplication{}
+		if err := getResource(args[0], namespaceOrDefault(defaultApplicationNamespace), application); err != nil {
+			return err
+		}
+		return printAppStatus(cmd.OutOrStdout(), application)
+	},
+}
+
+var appExplainCmd = &cobra.Command{
+	Use:   "explain <name>",
+	Short: "Show the data path, modules and clusters chosen for each asset of a FybrikApplication",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		application := &fappv1.FybrikApplication{}
+		if err := getResource(args[0], namespaceOrDefault(defaultApplicationNamespace), application); err != nil {
+			return err
+		}
+		var plotter *fappv1.Plotter
+		if ref := application.Status.Generated; ref != nil {
+			plotter = &fappv1.Plotter{}
+			if err := getResource(ref.Name, ref.Namespace, plotter); err != nil {
+				return err
+			}
+		}
+		printAppExplain(cmd.OutOrStdout(), application, plotter)
+		return nil
+	},
+}
+
+func init() {
+	appCmd.AddCommand(appStatusCmd, appExplainCmd)
+	rootCmd.AddCommand(appCmd)
+}
+
+// printAppStatus writes the status of the application: its readiness, the conditions of each asset,
+// the endpoints from which the assets are served and the storage provisioned for them
+func printAppStatus(w io.Writer, application *fappv1.FybrikApplication) error {
+	status := &application.Status
+	fmt.Fprintf(w, "Application: %s/%s\n", application.Namespace, application.Name)
+	fmt.Fprintf(w, "Ready: %t\n", status.Ready)
+	if status.ValidApplication != "" {
+		fmt.Fprintf(w, "Valid: %s\n", status.ValidApplication)
+	}
+	if status.ErrorMessage != "" {
+		fmt.Fprintf(w, "Error: %s\n", status.ErrorMessage)
+	}
+	if status.Generated != nil {
+		fmt.Fprintf(w, "Plotter: %s/%s\n", status.Generated.Namespace, status.Generated.Name)
+	}
+	assetIDs := sortedKeys(status.AssetStates)
+
+	fmt.Fprintln(w, "\nAssets:")
+	tw := tabwriter.NewWriter(w, 0, 0, tablePadding, ' ', 0)
+	fmt.Fprintln(tw, "ASSET\tREADY\tDENY\tERROR\tMESSAGE")
+	for _, assetID := range assetIDs {
+		conditions := map[fappv1.ConditionType]fappv1.Condition{}
+		messages := []string{}
+		for _, condition := range status.AssetStates[assetID].Conditions {
+			conditions[condition.Type] = condition
+			if condition.Message != "" {
+				messages = append(messages, string(condition.Type)+": "+condition.Message)
+			}
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", assetID, conditions[fappv1.ReadyCondition].Status,
+			conditions[fappv1.DenyCondition].Status, conditions[fappv1.ErrorCondition].Status, strings.Join(messages, "; "))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(w, "\nEndpoints:")
+	for _, assetID := range assetIDs {
+		endpoint := status.AssetStates[assetID].Endpoint
+		if endpoint.Name == "" {
+			continue
+		}
+		properties, err := json.Marshal(endpoint.AdditionalProperties.Items)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "  %s: %s %s\n", assetID, endpoint.Name, properties)
+	}
+
+	fmt.Fprintln(w, "\nProvisioned storage:")
+	for _, assetID := range sortedKeys(status.ProvisionedStorage) {
+		storage := status.ProvisionedStorage[assetID]
+		location := ""
+		if storage.Details != nil {
+			location = string(storage.Details.Connection.Name)
+		}
+		if storage.ResourceMetadata != nil && storage.ResourceMetadata.Geography != "" {
+			location += " in " + storage.ResourceMetadata.Geography
+		}
+		fmt.Fprintf(w, "  %s: %s, secret %s/%s, persistent: %t\n", assetID, location,
+			storage.SecretRef.Namespace, storage.SecretRef.Name, storage.Persistent)
+	}
+	return nil
+}
+
+// printAppExplain writes, for each asset of the application, the steps of the data path chosen for it:
+// the modules, their capabilities, the clusters they run on and the governance actions they apply.
+// Assets for which no data path has been generated are explained by their conditions.
+func printAppExplain(w io.Writer, application *fappv1.FybrikApplication, plotter *fappv1.Plotter) {
+	for i := range application.Spec.Data {
+		assetID := application.Spec.Data[i].DataSetID
+		fmt.Fprintf(w, "Asset %s (%s)\n", assetID, application.Spec.Data[i].Flow)
+		flow := findFlow(plotter, assetID)
+		if flow == nil {
+			fmt.Fprintln(w, "  No data path has been generated")
+			for _, condition := range application.Status.AssetStates[assetID].Conditions {
+				if condition.Message != "" {
+					fmt.Fprintf(w, "  %s: %s\n", condition.Type, condition.Message)
+				}
+			}
+		} else {
+			for i := range flow.SubFlows {
+				subFlow := &flow.SubFlows[i]
+				fmt.Fprintf(w, "  %s sub-flow, triggered by %s:\n", subFlow.FlowType, describeTriggers(subFlow))
+				for _, steps := range subFlow.Steps {
+					for stepInd := range steps {
+						fmt.Fprintf(w, "    %d. %s\n", stepInd+1, describeStep(plotter, &steps[stepInd]))
+					}
+				}
+			}
+		}
+		alternatives := application.Status.AssetStates[assetID].Alternatives
+		if len(alternatives) > 0 {
+			fmt.Fprintln(w, "  Alternative data paths:")
+			for _, alternative := range alternatives {
+				steps := []string{}
+				for _, step := range alternative.Steps {
+					steps = append(steps, fmt.Sprintf("%s (%s) on %s", step.Module, step.Capability, step.Cluster))
+				}
+				score := ""
+				if alternative.Score != "" {
+					score = ", score " + alternative.Score
+				}
+				fmt.Fprintf(w, "    #%d%s: %s\n", alternative.Rank, score, strings.Join(steps, " -> "))
+			}
+		}
+	}
+}
+
+// findFlow returns the plotter flow of the given asset, or nil if there is none
+func findFlow(plotter *fappv1.Plotter, assetID string) *fappv1.Flow {
+	if plotter == nil {
+		return nil
+	}
+	for i := range plotter.Spec.Flows {
+		if plotter.Spec.Flows[i].AssetID == assetID {
+			return &plotter.Spec.Flows[i]
+		}
+	}
+	return nil
+}
+
+// describeStep returns the modules executing a step, the cluster on which they run and the actions they apply
+func describeStep(plotter *fappv1.Plotter, step *fappv1.DataFlowStep) string {
+	modules := []string{}
+	for _, module := range plotter.Spec.Templates[step.Template].Modules {
+		modules = append(modules, fmt.Sprintf("%s (%s)", module.Name, module.Capability))
+	}
+	description := fmt.Sprintf("%s on cluster %s", strings.Join(modules, ", "), step.Cluster)
+	if step.Parameters != nil && len(step.Parameters.Actions) > 0 {
+		actions := []string{}
+		for _, action := range step.Parameters.Actions {
+			actions = append(actions, string(action.Name))
+		}
+		description += ", actions: " + strings.Join(actions, ", ")
+	}
+	return description
+}
+
+// describeTriggers lists the triggers of a sub-flow, together with the schedule of a timer trigger
+func describeTriggers(subFlow *fappv1.SubFlow) string {
+	if subFlow.Schedule != "" {
+		return joinTriggers(subFlow.Triggers) + " (schedule " + subFlow.Schedule + ")"
+	}
+	return joinTriggers(subFlow.Triggers)
+}
+
+func joinTriggers(triggers []fappv1.SubFlowTrigger) string {
+	res := make([]string, len(triggers))
+	for i, trigger := range triggers {
+		res[i] = string(trigger)
+	}
+	return strings.Join(res, ", ")
+}
+
+// sortedKeys returns the keys of a map in lexicographic order
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
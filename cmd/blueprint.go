@@ -0,0 +1,80 @@
+// Copyright 2023 IBM Corp.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	fappv1 "fybrik.io/fybrik/manager/apis/app/v1beta1"
+	managerUtils "fybrik.io/fybrik/manager/controllers/utils"
+	"fybrik.io/fybrik/pkg/environment"
+)
+
+// blueprintCmd groups the commands for Blueprint introspection
+var blueprintCmd = &cobra.Command{
+	Use:   "blueprint",
+	Short: "Inspect Blueprints",
+}
+
+var blueprintReleasesCmd = &cobra.Command{
+	Use:   "releases <name>",
+	Short: "Show the Helm releases of a Blueprint and their readiness",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		blueprint := &fappv1.Blueprint{}
+		if err := getResource(args[0], namespaceOrDefault(environment.GetInternalCRsNamespace()), blueprint); err != nil {
+			return err
+		}
+		return printBlueprintReleases(cmd.OutOrStdout(), blueprint)
+	},
+}
+
+func init() {
+	blueprintCmd.AddCommand(blueprintReleasesCmd)
+	rootCmd.AddCommand(blueprintCmd)
+}
+
+// printBlueprintReleases writes the Helm release of each module instance in the blueprint,
+// together with the module readiness and the blueprint generation the release belongs to.
+// Releases that are no longer part of the blueprint spec (and are about to be uninstalled) are listed last.
+func printBlueprintReleases(w io.Writer, blueprint *fappv1.Blueprint) error {
+	status := &blueprint.Status
+	fmt.Fprintf(w, "Blueprint: %s/%s (cluster %s)\n", blueprint.Namespace, blueprint.Name, blueprint.Spec.Cluster)
+	fmt.Fprintf(w, "Ready: %t\n", status.ObservedState.Ready)
+	if status.ObservedState.Error != "" {
+		fmt.Fprintf(w, "Error: %s\n", status.ObservedState.Error)
+	}
+	fmt.Fprintln(w)
+
+	uuid := managerUtils.GetFybrikApplicationUUIDfromAnnotations(blueprint.GetAnnotations())
+	appName := managerUtils.GetApplicationNameFromLabels(blueprint.Labels)
+	tw := tabwriter.NewWriter(w, 0, 0, tablePadding, ' ', 0)
+	fmt.Fprintln(tw, "RELEASE\tINSTANCE\tCHART\tREADY\tGENERATION\tERROR")
+	listed := map[string]bool{}
+	for _, instanceName := range sortedKeys(blueprint.Spec.Modules) {
+		releaseName := managerUtils.GetReleaseName(appName, uuid, instanceName)
+		listed[releaseName] = true
+		state := status.ModulesState[instanceName]
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%t\t%s\t%s\n", releaseName, instanceName, blueprint.Spec.Modules[instanceName].Chart.Name,
+			state.Ready, releaseGeneration(status, releaseName), state.Error)
+	}
+	for _, releaseName := range sortedKeys(status.Releases) {
+		if !listed[releaseName] {
+			fmt.Fprintf(tw, "%s\t-\t-\tfalse\t%s\t\n", releaseName, releaseGeneration(status, releaseName))
+		}
+	}
+	return tw.Flush()
+}
+
+// releaseGeneration returns the blueprint generation containing the release, or "-" if the release is not installed
+func releaseGeneration(status *fappv1.BlueprintStatus, releaseName string) string {
+	if generation, found := status.Releases[releaseName]; found {
+		return fmt.Sprint(generation)
+	}
+	return "-"
+}
@@ -0,0 +1,63 @@
+// Copyright 2023 IBM Corp.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"context"
+
+	"emperror.dev/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/config"
+
+	fappv1 "fybrik.io/fybrik/manager/apis/app/v1beta1"
+)
+
+var (
+	kubeconfig string
+	namespace  string
+)
+
+// newClient creates a client for fybrik resources using the kubeconfig flag if set,
+// or the default kubeconfig resolution rules otherwise
+func newClient() (client.Client, error) {
+	var restConfig *rest.Config
+	var err error
+	if kubeconfig != "" {
+		restConfig, err = clientcmd.BuildConfigFromFlags("", kubeconfig)
+	} else {
+		restConfig, err = config.GetConfig()
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "could not load the kubeconfig")
+	}
+	scheme := runtime.NewScheme()
+	if err = fappv1.AddToScheme(scheme); err != nil {
+		return nil, err
+	}
+	return client.New(restConfig, client.Options{Scheme: scheme})
+}
+
+// getResource fetches the named resource from the given namespace
+func getResource(name, ns string, obj client.Object) error {
+	cl, err := newClient()
+	if err != nil {
+		return err
+	}
+	if err = cl.Get(context.Background(), types.NamespacedName{Namespace: ns, Name: name}, obj); err != nil {
+		return errors.Wrapf(err, "could not get %s/%s", ns, name)
+	}
+	return nil
+}
+
+// namespaceOrDefault returns the namespace flag if set, or the given default namespace otherwise
+func namespaceOrDefault(defaultNamespace string) string {
+	if namespace != "" {
+		return namespace
+	}
+	return defaultNamespace
+}
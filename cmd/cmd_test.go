@@ -0,0 +1,272 @@
+// Copyright 2023 IBM Corp.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	fappv1 "fybrik.io/fybrik/manager/apis/app/v1beta1"
+	"fybrik.io/fybrik/manager/controllers/app"
+	managerUtils "fybrik.io/fybrik/manager/controllers/utils"
+	"fybrik.io/fybrik/pkg/model/taxonomy"
+	"fybrik.io/fybrik/pkg/serde"
+)
+
+const (
+	assetID   = "s3/allow-dataset"
+	deniedID  = "s3/deny-dataset"
+	denyMsg   = "governance policies forbid access to the data"
+	cluster   = "thegreendragon"
+	plotterNS = "fybrik-system"
+)
+
+func testApplication() *fappv1.FybrikApplication {
+	return &fappv1.FybrikApplication{
+		ObjectMeta: metav1.ObjectMeta{Name: "notebook", Namespace: "default"},
+		Spec: fappv1.FybrikApplicationSpec{
+			Data: []fappv1.DataContext{
+				{DataSetID: assetID, Flow: taxonomy.ReadFlow},
+				{DataSetID: deniedID, Flow: taxonomy.ReadFlow},
+			},
+		},
+		Status: fappv1.FybrikApplicationStatus{
+			Generated: &fappv1.ResourceReference{Name: "notebook-default", Namespace: plotterNS},
+			AssetStates: map[string]fappv1.AssetState{
+				assetID: {
+					Conditions: []fappv1.Condition{
+						{Type: fappv1.ReadyCondition, Status: corev1.ConditionTrue},
+						{Type: fappv1.DenyCondition, Status: corev1.ConditionFalse},
+						{Type: fappv1.ErrorCondition, Status: corev1.ConditionFalse},
+					},
+					Endpoint: taxonomy.Connection{
+						Name: "fybrik-arrow-flight",
+						AdditionalProperties: serde.Properties{Items: map[string]interface{}{
+							"fybrik-arrow-flight": map[string]interface{}{"hostname": "read-path.notebook"},
+						}},
+					},
+				},
+				deniedID: {
+					Conditions: []fappv1.Condition{
+						{Type: fappv1.ReadyCondition, Status: corev1.ConditionFalse},
+						{Type: fappv1.DenyCondition, Status: corev1.ConditionTrue, Message: denyMsg},
+						{Type: fappv1.ErrorCondition, Status: corev1.ConditionFalse},
+					},
+				},
+			},
+			ProvisionedStorage: map[string]fappv1.DatasetDetails{
+				assetID: {
+					SecretRef: taxonomy.SecretRef{Name: "credentials-theshire", Namespace: plotterNS},
+					Details:   &fappv1.DataStore{Connection: taxonomy.Connection{Name: "s3"}},
+				},
+			},
+		},
+	}
+}
+
+func testPlotter() *fappv1.Plotter {
+	return &fappv1.Plotter{
+		ObjectMeta: metav1.ObjectMeta{Name: "notebook-default", Namespace: plotterNS},
+		Spec: fappv1.PlotterSpec{
+			Templates: map[string]fappv1.Template{
+				"copy": {Modules: []fappv1.ModuleInfo{{Name: "implicit-copy", Capability: "copy"}}},
+				"read": {Modules: []fappv1.ModuleInfo{{Name: "arrow-flight-module", Capability: "read"}}},
+			},
+			Flows: []fappv1.Flow{{
+				Name:     "notebook-read",
+				FlowType: taxonomy.ReadFlow,
+				AssetID:  assetID,
+				SubFlows: []fappv1.SubFlow{
+					{
+						Name:     "notebook-copy",
+						FlowType: taxonomy.CopyFlow,
+						Triggers: []fappv1.SubFlowTrigger{fappv1.InitTrigger},
+						Steps: [][]fappv1.DataFlowStep{{{
+							Name:       "step-copy",
+							Cluster:    cluster,
+							Template:   "copy",
+							Parameters: &fappv1.StepParameters{Actions: []taxonomy.Action{{Name: "RedactAction"}}},
+						}}},
+					},
+					{
+						Name:     "notebook-read",
+						FlowType: taxonomy.ReadFlow,
+						Triggers: []fappv1.SubFlowTrigger{fappv1.WorkloadTrigger},
+						Steps:    [][]fappv1.DataFlowStep{{{Name: "step-read", Cluster: cluster, Template: "read"}}},
+					},
+				},
+			}},
+		},
+		Status: fappv1.PlotterStatus{
+			Flows: map[string]fappv1.FlowStatus{"notebook-read": {ObservedState: fappv1.ObservedState{Ready: true}}},
+		},
+	}
+}
+
+func TestPrintAppStatus(t *testing.T) {
+	t.Parallel()
+	var out bytes.Buffer
+	if err := printAppStatus(&out, testApplication()); err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		"Application: default/notebook",
+		"Plotter: fybrik-system/notebook-default",
+		"Deny: " + denyMsg,
+		`s3/allow-dataset: fybrik-arrow-flight {"fybrik-arrow-flight":{"hostname":"read-path.notebook"}}`,
+		"s3/allow-dataset: s3, secret fybrik-system/credentials-theshire, persistent: false",
+	}
+	for _, str := range expected {
+		if !strings.Contains(out.String(), str) {
+			t.Errorf("Expecting %q in the output:\n%s", str, out.String())
+		}
+	}
+}
+
+func TestPrintAppExplain(t *testing.T) {
+	t.Parallel()
+	var out bytes.Buffer
+	printAppExplain(&out, testApplication(), testPlotter())
+	expected := []string{
+		"Asset s3/allow-dataset (read)",
+		"  copy sub-flow, triggered by init:\n    1. implicit-copy (copy) on cluster thegreendragon, actions: RedactAction",
+		"  read sub-flow, triggered by workload:\n    1. arrow-flight-module (read) on cluster thegreendragon",
+		"Asset s3/deny-dataset (read)\n  No data path has been generated\n  Deny: " + denyMsg,
+	}
+	for _, str := range expected {
+		if !strings.Contains(out.String(), str) {
+			t.Errorf("Expecting %q in the output:\n%s", str, out.String())
+		}
+	}
+}
+
+func TestPlotterTree(t *testing.T) {
+	t.Parallel()
+	var out bytes.Buffer
+	printTree(&out, plotterTree(testPlotter()))
+	expected := `Plotter fybrik-system/notebook-default
+└── flow notebook-read [read] asset s3/allow-dataset (ready)
+    ├── sub-flow notebook-copy [copy] triggers: init
+    │   └── step step-copy: implicit-copy (copy) on cluster thegreendragon, actions: RedactAction
+    └── sub-flow notebook-read [read] triggers: workload
+        └── step step-read: arrow-flight-module (read) on cluster thegreendragon
+`
+	if out.String() != expected {
+		t.Errorf("Unexpected tree:\n%s\nexpected:\n%s", out.String(), expected)
+	}
+}
+
+func TestPrintBlueprintReleases(t *testing.T) {
+	t.Parallel()
+	blueprint := &fappv1.Blueprint{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "notebook-default",
+			Namespace:   plotterNS,
+			Labels:      map[string]string{managerUtils.ApplicationNameLabel: "notebook"},
+			Annotations: map[string]string{managerUtils.FybrikAppUUID: "1234"},
+		},
+		Spec: fappv1.BlueprintSpec{
+			Cluster: cluster,
+			Modules: map[string]fappv1.BlueprintModule{
+				"arrow-flight-module": {Name: "arrow-flight-module", Chart: fappv1.ChartSpec{Name: "ghcr.io/fybrik/arrow-flight"}},
+			},
+		},
+		Status: fappv1.BlueprintStatus{
+			ModulesState: map[string]fappv1.ObservedState{"arrow-flight-module": {Ready: true}},
+			Releases:     map[string]int64{"notebook1234-arrow-flight-module": 2, "notebook1234-old-module": 1},
+		},
+	}
+	var out bytes.Buffer
+	if err := printBlueprintReleases(&out, blueprint); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 6 {
+		t.Fatalf("Expecting 6 lines in the output:\n%s", out.String())
+	}
+	if fields := strings.Fields(lines[4]); strings.Join(fields, " ") !=
+		"notebook1234-arrow-flight-module arrow-flight-module ghcr.io/fybrik/arrow-flight true 2" {
+		t.Errorf("Unexpected release line: %s", lines[4])
+	}
+	if fields := strings.Fields(lines[5]); strings.Join(fields, " ") != "notebook1234-old-module - - false 1" {
+		t.Errorf("Unexpected release line: %s", lines[5])
+	}
+}
+
+func TestLoadOfflineInput(t *testing.T) {
+	t.Parallel()
+	sampleDir := "../samples/offline-solve/"
+	offlineEnv, application, err := loadOfflineInput(&solveOptions{
+		modules:         sampleDir + "modules.yaml",
+		clusters:        sampleDir + "clusters.yaml",
+		storageAccounts: sampleDir + "storage-accounts.yaml",
+		assets:          sampleDir + "assets.yaml",
+		application:     sampleDir + "application.yaml",
+		adminConfig:     "../samples/adminconfig",
+		optimize:        true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(offlineEnv.Modules) != 2 || offlineEnv.Modules[1].Name != "arrow-flight-module" {
+		t.Errorf("Unexpected modules: %v", offlineEnv.Modules)
+	}
+	if len(offlineEnv.StorageAccounts) != 2 || offlineEnv.StorageAccounts[1].Spec.Geography != "neverland" {
+		t.Errorf("Unexpected storage accounts: %v", offlineEnv.StorageAccounts)
+	}
+	if _, found := offlineEnv.StorageAccounts[0].Spec.AdditionalProperties.Items["s3"]; !found {
+		t.Errorf("Expecting s3 properties of the storage account: %v", offlineEnv.StorageAccounts[0].Spec)
+	}
+	if len(offlineEnv.Clusters) != 2 || offlineEnv.Clusters[1].Metadata.Region != "neverland" {
+		t.Errorf("Unexpected clusters: %v", offlineEnv.Clusters)
+	}
+	if len(offlineEnv.Assets) != 2 || offlineEnv.Assets[0].Decisions[0].Actions[0].Name != "RedactAction" {
+		t.Errorf("Unexpected assets: %v", offlineEnv.Assets)
+	}
+	if offlineEnv.ConfigEvaluator == nil || len(offlineEnv.Infrastructure.Attributes) == 0 {
+		t.Error("Expecting the admin config policies and infrastructure attributes to be loaded")
+	}
+	if len(application.Spec.Data) != 2 {
+		t.Errorf("Unexpected application: %v", application.Spec)
+	}
+}
+
+func TestPrintOfflineSolutions(t *testing.T) {
+	t.Parallel()
+	solutions := []app.OfflineSolution{
+		{
+			AssetID: assetID,
+			DataPaths: []fappv1.DataPathAlternative{{
+				Rank:  1,
+				Score: "360",
+				Goals: []fappv1.OptimizationGoalScore{{Attribute: "distance", Directive: "min", Weight: "0.8", Value: "0"}},
+				Steps: []fappv1.DataPathStep{
+					{Module: "implicit-copy", Capability: "copy", Cluster: cluster, StorageAccount: "theshire-object-store",
+						Actions: []taxonomy.ActionName{"RedactAction"}},
+					{Module: "arrow-flight-module", Capability: "read", Cluster: cluster},
+				},
+			}},
+			Model: "solve minimize score;",
+		},
+		{AssetID: deniedID, Error: denyMsg},
+	}
+	var out bytes.Buffer
+	printOfflineSolutions(&out, solutions, true)
+	expected := `Asset s3/allow-dataset
+  Data path #1, score: 360
+    goal: min distance, weight 0.8, value 0
+    1. implicit-copy (copy) on cluster thegreendragon, storage account theshire-object-store, actions: RedactAction
+    2. arrow-flight-module (read) on cluster thegreendragon
+  FlatZinc model:
+solve minimize score;
+Asset s3/deny-dataset
+  No data path: ` + denyMsg + "\n"
+	if out.String() != expected {
+		t.Errorf("Unexpected output:\n%s\nexpected:\n%s", out.String(), expected)
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright 2023 IBM Corp.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+
+	fappv1 "fybrik.io/fybrik/manager/apis/app/v1beta1"
+	"fybrik.io/fybrik/pkg/environment"
+)
+
+// plotterCmd groups the commands for Plotter introspection
+var plotterCmd = &cobra.Command{
+	Use:   "plotter",
+	Short: "Inspect Plotters",
+}
+
+var plotterTreeCmd = &cobra.Command{
+	Use:   "tree <name>",
+	Short: "Show the flows, sub-flows and steps of a Plotter as a tree",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		plotter := &fappv1.Plotter{}
+		if err := getResource(args[0], namespaceOrDefault(environment.GetInternalCRsNamespace()), plotter); err != nil {
+			return err
+		}
+		printTree(cmd.OutOrStdout(), plotterTree(plotter))
+		return nil
+	},
+}
+
+func init() {
+	plotterCmd.AddCommand(plotterTreeCmd)
+	rootCmd.AddCommand(plotterCmd)
+}
+
+// treeNode is a labeled node of a printed tree
+type treeNode struct {
+	label    string
+	children []*treeNode
+}
+
+func (n *treeNode) add(label string) *treeNode {
+	child := &treeNode{label: label}
+	n.children = append(n.children, child)
+	return child
+}
+
+// plotterTree constructs a tree of the plotter flows, their sub-flows and the steps of each sub-flow.
+// Parallel branches of a sub-flow are shown as separate nodes when there is more than one.
+func plotterTree(plotter *fappv1.Plotter) *treeNode {
+	root := &treeNode{label: fmt.Sprintf("Plotter %s/%s%s", plotter.Namespace, plotter.Name,
+		stateSuffix(plotter.Status.ObservedState))}
+	for _, flow := range plotter.Spec.Flows {
+		flowNode := root.add(fmt.Sprintf("flow %s [%s] asset %s%s", flow.Name, flow.FlowType, flow.AssetID,
+			stateSuffix(plotter.Status.Flows[flow.Name].ObservedState)))
+		for i := range flow.SubFlows {
+			subFlow := &flow.SubFlows[i]
+			subFlowNode := flowNode.add(fmt.Sprintf("sub-flow %s [%s] triggers: %s", subFlow.Name, subFlow.FlowType,
+				describeTriggers(subFlow)))
+			for branchInd, steps := range subFlow.Steps {
+				parent := subFlowNode
+				if len(subFlow.Steps) > 1 {
+					parent = subFlowNode.add(fmt.Sprintf("branch %d", branchInd+1))
+				}
+				for stepInd := range steps {
+					parent.add(fmt.Sprintf("step %s: %s", steps[stepInd].Name, describeStep(plotter, &steps[stepInd])))
+				}
+			}
+		}
+	}
+	return root
+}
+
+// stateSuffix returns a short description of an observed state to append to a tree label
+func stateSuffix(state fappv1.ObservedState) string {
+	if state.Error != "" {
+		return " (error: " + state.Error + ")"
+	}
+	if state.Ready {
+		return " (ready)"
+	}
+	return ""
+}
+
+// printTree writes the tree with its root on the first line and each node below its parent
+func printTree(w io.Writer, root *treeNode) {
+	fmt.Fprintln(w, root.label)
+	printChildren(w, root, "")
+}
+
+func printChildren(w io.Writer, node *treeNode, prefix string) {
+	for i, child := range node.children {
+		connector, indent := "├── ", "│   "
+		if i == len(node.children)-1 {
+			connector, indent = "└── ", "    "
+		}
+		fmt.Fprintln(w, prefix+connector+child.label)
+		printChildren(w, child, prefix+indent)
+	}
+}
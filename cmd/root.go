@@ -30,6 +30,8 @@ func init() {
 
 	// An optional configuration file to override default flags (available to subcommands)
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.fybrik.yaml)")
+	rootCmd.PersistentFlags().StringVar(&kubeconfig, "kubeconfig", "", "path to the kubeconfig file (default is $KUBECONFIG or $HOME/.kube/config)")
+	rootCmd.PersistentFlags().StringVarP(&namespace, "namespace", "n", "", "namespace of the resource")
 }
 
 // initConfig reads in config file and ENV variables if set.
@@ -0,0 +1,224 @@
+// Copyright 2023 IBM Corp.
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"emperror.dev/errors"
+	"github.com/rs/zerolog"
+	"github.com/spf13/cobra"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/yaml"
+
+	fappv1 "fybrik.io/fybrik/manager/apis/app/v1beta1"
+	fappv2 "fybrik.io/fybrik/manager/apis/app/v1beta2"
+	"fybrik.io/fybrik/manager/controllers/app"
+	"fybrik.io/fybrik/pkg/adminconfig"
+	"fybrik.io/fybrik/pkg/environment"
+	"fybrik.io/fybrik/pkg/infrastructure"
+)
+
+// input files and options of the solve command
+type solveOptions struct {
+	modules         string
+	clusters        string
+	storageAccounts string
+	assets          string
+	application     string
+	adminConfig     string
+	dataPaths       int
+	optimize        bool
+	printModel      bool
+}
+
+var solveOpts = solveOptions{}
+
+var solveCmd = &cobra.Command{
+	Use:   "solve",
+	Short: "Select the data paths of a FybrikApplication offline, using modules, clusters, storage accounts and assets from files",
+	Long: `Select the data paths of a FybrikApplication without a Kubernetes cluster.
+The modules, clusters, storage accounts, assets and governance decisions are read from YAML files,
+the admin config policies and the infrastructure attributes from the admin config directory.
+For each asset, the chosen data path is printed along with its score and the FlatZinc model solved by the optimizer.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		offlineEnv, application, err := loadOfflineInput(&solveOpts)
+		if err != nil {
+			return err
+		}
+		solutions, err := app.SolveOffline(offlineEnv, application, solveOpts.dataPaths)
+		if err != nil {
+			return err
+		}
+		printOfflineSolutions(cmd.OutOrStdout(), solutions, solveOpts.printModel)
+		return nil
+	},
+}
+
+func init() {
+	flags := solveCmd.Flags()
+	flags.StringVar(&solveOpts.application, "application", "", "FybrikApplication file")
+	flags.StringVar(&solveOpts.modules, "modules", "", "file of FybrikModules, separated by '---'")
+	flags.StringVar(&solveOpts.clusters, "clusters", "", "file with a list of clusters, their names and metadata")
+	flags.StringVar(&solveOpts.storageAccounts, "storage-accounts", "", "file of FybrikStorageAccounts, separated by '---'")
+	flags.StringVar(&solveOpts.assets, "assets", "", "file with a list of assets, their catalog metadata and governance decisions")
+	flags.StringVar(&solveOpts.adminConfig, "adminconfig", "",
+		"directory of the admin config policies and the infrastructure attributes (default is $DATA_DIR/adminconfig)")
+	flags.IntVar(&solveOpts.dataPaths, "data-paths", 1, "number of data paths to print for each asset, from best to worst")
+	flags.BoolVar(&solveOpts.optimize, "optimize", true, "use the optimizer for selecting data paths")
+	flags.BoolVar(&solveOpts.printModel, "print-model", true, "print the FlatZinc model solved by the optimizer")
+	for _, flag := range []string{"application", "modules", "clusters"} {
+		cobra.CheckErr(solveCmd.MarkFlagRequired(flag))
+	}
+	rootCmd.AddCommand(solveCmd)
+}
+
+// loadOfflineInput reads the files given in the options and prepares the environment for solving the application offline
+func loadOfflineInput(opts *solveOptions) (*app.OfflineEnvironment, *fappv1.FybrikApplication, error) {
+	// the manager logs are printed only if requested explicitly
+	if _, found := os.LookupEnv(environment.LoggingVerbosityKey); !found {
+		if err := os.Setenv(environment.LoggingVerbosityKey, strconv.Itoa(int(zerolog.Disabled))); err != nil {
+			return nil, nil, err
+		}
+	}
+	if err := os.Setenv(environment.UseCSPKey, strconv.FormatBool(opts.optimize)); err != nil {
+		return nil, nil, err
+	}
+	if opts.adminConfig != "" {
+		// the directory is expected to end with a separator
+		dir := filepath.Clean(opts.adminConfig) + string(filepath.Separator)
+		adminconfig.RegoPolicyDirectory = dir
+		infrastructure.RegoPolicyDirectory = dir
+	}
+	offlineEnv := &app.OfflineEnvironment{}
+	var err error
+	if offlineEnv.ConfigEvaluator, err = adminconfig.NewRegoPolicyEvaluator(); err != nil {
+		return nil, nil, errors.Wrap(err, "could not compile the admin config policies")
+	}
+	if offlineEnv.Infrastructure, err = infrastructure.NewAttributeManager(); err != nil {
+		return nil, nil, errors.Wrap(err, "could not read the infrastructure attributes")
+	}
+	if err = readYAMLDocuments(opts.modules, func(doc []byte) error {
+		module := fappv1.FybrikModule{}
+		err := yaml.Unmarshal(doc, &module)
+		offlineEnv.Modules = append(offlineEnv.Modules, module)
+		return err
+	}); err != nil {
+		return nil, nil, err
+	}
+	if err = readYAMLDocuments(opts.storageAccounts, func(doc []byte) error {
+		account := fappv2.FybrikStorageAccount{}
+		err := account.DecodeYaml(doc)
+		offlineEnv.StorageAccounts = append(offlineEnv.StorageAccounts, account)
+		return err
+	}); err != nil {
+		return nil, nil, err
+	}
+	if err = readYAMLFile(opts.clusters, &offlineEnv.Clusters); err != nil {
+		return nil, nil, err
+	}
+	if err = readYAMLFile(opts.assets, &offlineEnv.Assets); err != nil {
+		return nil, nil, err
+	}
+	application := &fappv1.FybrikApplication{}
+	if err = readYAMLFile(opts.application, application); err != nil {
+		return nil, nil, err
+	}
+	return offlineEnv, application, nil
+}
+
+// readYAMLFile decodes a YAML file into the given object, an empty file name is ignored
+func readYAMLFile(fileName string, obj interface{}) error {
+	if fileName == "" {
+		return nil
+	}
+	content, err := os.ReadFile(fileName)
+	if err != nil {
+		return err
+	}
+	return errors.Wrapf(yaml.Unmarshal(content, obj), "could not parse %s", fileName)
+}
+
+// readYAMLDocuments calls decode for each non-empty document of a multi-document YAML file,
+// an empty file name is ignored
+func readYAMLDocuments(fileName string, decode func([]byte) error) error {
+	if fileName == "" {
+		return nil
+	}
+	file, err := os.Open(fileName)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	reader := utilyaml.NewYAMLReader(bufio.NewReader(file))
+	for {
+		doc, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return errors.Wrapf(err, "could not read %s", fileName)
+		}
+		// skip documents with comments only
+		if content, err := yaml.YAMLToJSON(doc); err == nil && string(content) == "null" {
+			continue
+		}
+		if err = decode(doc); err != nil {
+			return errors.Wrapf(err, "could not parse %s", fileName)
+		}
+	}
+}
+
+// printOfflineSolutions writes the data paths found for each asset with their scores,
+// followed by the FlatZinc model from which the best data path was selected
+func printOfflineSolutions(w io.Writer, solutions []app.OfflineSolution, printModel bool) {
+	for i := range solutions {
+		solution := &solutions[i]
+		fmt.Fprintf(w, "Asset %s\n", solution.AssetID)
+		if solution.Error != "" {
+			fmt.Fprintf(w, "  No data path: %s\n", strings.ReplaceAll(solution.Error, "\n", "\n  "))
+			continue
+		}
+		for _, dataPath := range solution.DataPaths {
+			score := "none"
+			if dataPath.Score != "" {
+				score = dataPath.Score
+			}
+			fmt.Fprintf(w, "  Data path #%d, score: %s\n", dataPath.Rank, score)
+			for _, goal := range dataPath.Goals {
+				fmt.Fprintf(w, "    goal: %s %s, weight %s, value %s\n", goal.Directive, goal.Attribute, goal.Weight, goal.Value)
+			}
+			for stepInd := range dataPath.Steps {
+				fmt.Fprintf(w, "    %d. %s\n", stepInd+1, describeDataPathStep(&dataPath.Steps[stepInd]))
+			}
+		}
+		if printModel && solution.Model != "" {
+			fmt.Fprintf(w, "  FlatZinc model:\n%s\n", solution.Model)
+		}
+	}
+}
+
+// describeDataPathStep returns the module and capability of a data path step, its cluster,
+// the storage account it writes to and the actions it applies
+func describeDataPathStep(step *fappv1.DataPathStep) string {
+	description := fmt.Sprintf("%s (%s) on cluster %s", step.Module, step.Capability, step.Cluster)
+	if step.StorageAccount != "" {
+		description += ", storage account " + step.StorageAccount
+	}
+	if len(step.Actions) > 0 {
+		actions := []string{}
+		for _, action := range step.Actions {
+			actions = append(actions, string(action))
+		}
+		description += ", actions: " + strings.Join(actions, ", ")
+	}
+	return description
+}
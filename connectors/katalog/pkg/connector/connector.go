@@ -5,6 +5,7 @@ package connector
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"strings"
@@ -20,12 +21,15 @@ import (
 	"fybrik.io/fybrik/connectors/katalog/pkg/apis/katalog/v1alpha1"
 	"fybrik.io/fybrik/pkg/logging"
 	"fybrik.io/fybrik/pkg/model/datacatalog"
+	"fybrik.io/fybrik/pkg/model/taxonomy"
 	"fybrik.io/fybrik/pkg/utils"
 	"fybrik.io/fybrik/pkg/vault"
 )
 
 const (
 	FybrikAssetPrefix = "fybrik-"
+	// LineageAnnotation holds the lineage of assets created by Fybrik, in JSON format
+	LineageAnnotation = "katalog.fybrik.io/lineage"
 )
 
 type Handler struct {
@@ -128,7 +132,17 @@ func (r *Handler) createAsset(c *gin.Context) {
 			Details:   request.Details,
 		},
 	}
+	if request.Lineage != nil {
+		lineage, err := json.Marshal(request.Lineage)
+		if err != nil {
+			r.Log.Info().Msg(err.Error())
+			r.reportError(c, http.StatusBadRequest, "Invalid lineage in request.")
+			return
+		}
+		asset.Annotations = map[string]string{LineageAnnotation: string(lineage)}
+	}
 
+	LabelAsset(asset)
 	logging.LogStructure("Fybrik Asset to be created in Katalog:", asset, &r.Log, zerolog.DebugLevel, false, false)
 
 	err = r.client.Create(context.Background(), asset)
@@ -228,6 +242,7 @@ func (r *Handler) updateAsset(c *gin.Context) {
 	asset.Spec.Metadata.Owner = request.Owner
 	asset.Spec.Metadata.Tags = request.Tags
 	asset.Spec.Metadata.Columns = request.Columns
+	LabelAsset(asset)
 
 	if err := r.client.Patch(context.Background(), asset, patch); err != nil {
 		r.Log.Info().Msg(err.Error())
@@ -242,3 +257,63 @@ func (r *Handler) updateAsset(c *gin.Context) {
 
 	c.JSON(http.StatusOK, &response)
 }
+
+// Enables listing of the katalog assets that match the given filters.
+// The catalog ID is the namespace of the assets. Assets of all namespaces are listed if it is not specified.
+// The filters are selected by the labels of the assets, and the page size and continue token are passed to
+// the API server. Filters that can not be expressed as labels are matched by the listed assets, hence a page may
+// hold less assets than requested.
+func (r *Handler) listAssets(c *gin.Context) {
+	// Parse request
+	var request datacatalog.ListAssetsRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		r.Log.Info().Msg(err.Error())
+		r.reportError(c, http.StatusBadRequest, "Error during ShouldBindJSON in listAssets")
+		return
+	}
+	logging.LogStructure("ListAssetsRequest received:", request, &r.Log, zerolog.DebugLevel, false, false)
+
+	// The filters are matched by the connector rather than by label selectors, so that assets without the filter
+	// labels, e.g., assets created with kubectl, are listed too. Pages of the API server are listed until the response
+	// is full; each page is limited to the number of missing assets, so that its continue token resumes after the
+	// last returned asset.
+	response := &datacatalog.ListAssetsResponse{Assets: []datacatalog.AssetInfo{}}
+	token := request.Continue
+	for {
+		assetList := &v1alpha1.AssetList{}
+		listOptions := &kclient.ListOptions{Namespace: request.CatalogID, Continue: token}
+		if request.Limit > 0 {
+			listOptions.Limit = int64(int(request.Limit) - len(response.Assets))
+		}
+		if err := r.client.List(context.Background(), assetList, listOptions); err != nil {
+			switch {
+			case errors.IsForbidden(err):
+				r.reportError(c, http.StatusForbidden, err.Error())
+			case errors.IsResourceExpired(err) || errors.IsBadRequest(err):
+				r.reportError(c, http.StatusBadRequest, err.Error())
+			default:
+				r.reportError(c, http.StatusInternalServerError, err.Error())
+			}
+			return
+		}
+		for i := range assetList.Items {
+			asset := &assetList.Items[i]
+			info := datacatalog.AssetInfo{
+				AssetID:          taxonomy.AssetID(asset.Namespace + "/" + asset.Name),
+				ResourceMetadata: asset.Spec.Metadata,
+				Details:          asset.Spec.Details,
+			}
+			if request.Matches(&info) {
+				response.Assets = append(response.Assets, info)
+			}
+		}
+		token = assetList.Continue
+		if token == "" || (request.Limit > 0 && len(response.Assets) == int(request.Limit)) {
+			break
+		}
+	}
+	response.Continue = token
+	r.Log.Info().Msgf("Sending response from Katalog Connector with %d assets", len(response.Assets))
+
+	c.JSON(http.StatusOK, response)
+}
@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"sort"
 	"strings"
 	"testing"
 
@@ -21,6 +22,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/validation"
+	kclient "sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
@@ -184,6 +186,12 @@ func TestCreateAsset(t *testing.T) {
 					DataFormat: tt.format,
 				},
 				Credentials: "/v1/kubernetes-secrets/dummy-creds?namespace=dummy-namespace2",
+				Lineage: &datacatalog.AssetLineage{
+					Source:      taxonomy.AssetID("fybrik-notebook-sample/" + tt.sourceAssetName),
+					Application: "fybrik-notebook-sample/my-notebook-write",
+					Steps:       []datacatalog.LineageStep{{Name: "copy", Module: "arrow-flight-module", Capability: "copy"}},
+					Policies:    []string{"redact PII columns"},
+				},
 			}
 
 			// Create a fake client to mock API calls.
@@ -226,6 +234,9 @@ func TestCreateAsset(t *testing.T) {
 				return
 			}
 			g.Expect(&createAssetReq.ResourceMetadata).To(BeEquivalentTo(&asset.Spec.Metadata))
+			lineage := &datacatalog.AssetLineage{}
+			g.Expect(json.Unmarshal([]byte(asset.Annotations[LineageAnnotation]), lineage)).To(Succeed())
+			g.Expect(lineage).To(BeEquivalentTo(createAssetReq.Lineage))
 
 			// just for logging - start
 			b, err := json.Marshal(asset)
@@ -502,3 +513,164 @@ func TestCreateAndUpdateAsset(t *testing.T) {
 		// just for logging - end
 	})
 }
+
+// pagingClient pages the listed assets by the limit and continue token of the list options, ordered by their namespace
+// and name as the API server does, and counts the list requests
+type pagingClient struct {
+	kclient.Client
+	lists int
+}
+
+func (c *pagingClient) List(ctx context.Context, list kclient.ObjectList, opts ...kclient.ListOption) error {
+	listOptions := &kclient.ListOptions{}
+	listOptions.ApplyOptions(opts)
+	c.lists++
+	limit, token := listOptions.Limit, listOptions.Continue
+	listOptions.Limit, listOptions.Continue = 0, ""
+	if err := c.Client.List(ctx, list, listOptions); err != nil {
+		return err
+	}
+	assetList := list.(*v1alpha1.AssetList)
+	sort.Slice(assetList.Items, func(i, j int) bool {
+		return assetList.Items[i].Namespace+"/"+assetList.Items[i].Name < assetList.Items[j].Namespace+"/"+assetList.Items[j].Name
+	})
+	items := []v1alpha1.Asset{}
+	for i := range assetList.Items {
+		key := assetList.Items[i].Namespace + "/" + assetList.Items[i].Name
+		if token != "" && key <= token {
+			continue
+		}
+		if limit > 0 && len(items) == int(limit) {
+			assetList.Continue = items[len(items)-1].Namespace + "/" + items[len(items)-1].Name
+			break
+		}
+		items = append(items, assetList.Items[i])
+	}
+	assetList.Items = items
+	return nil
+}
+
+func TestListAssets(t *testing.T) {
+	t.Parallel()
+	g := NewGomegaWithT(t)
+	logf.SetLogger(zap.New(zap.UseDevMode(true)))
+
+	// Create fake Assets in two namespaces
+	newAsset := func(namespace, name, geography string, finance bool) *v1alpha1.Asset {
+		asset := &v1alpha1.Asset{
+			ObjectMeta: v1.ObjectMeta{Namespace: namespace, Name: name},
+			Spec: v1alpha1.AssetSpec{
+				SecretRef: v1alpha1.SecretRef{Name: "creds-" + name},
+				Details:   datacatalog.ResourceDetails{Connection: taxonomy.Connection{Name: "s3"}, DataFormat: "csv"},
+				Metadata: datacatalog.ResourceMetadata{
+					Name:      name,
+					Owner:     "Alice",
+					Geography: geography,
+					Tags: &taxonomy.Tags{Properties: serde.Properties{Items: map[string]interface{}{
+						"finance": finance,
+					}}},
+				},
+			},
+		}
+		LabelAsset(asset)
+		return asset
+	}
+	schema := runtime.NewScheme()
+	_ = v1alpha1.AddToScheme(schema)
+	// the owner can not be a label value, hence it is matched by the listed assets
+	otherOwner := newAsset("demo", "budget", "theshire", true)
+	otherOwner.Spec.Metadata.Owner = "Bob Smith"
+	LabelAsset(otherOwner)
+	// an asset created with kubectl has no filter labels
+	unlabeled := newAsset("demo", "loans", "theshire", true)
+	unlabeled.Labels = nil
+	client := &pagingClient{Client: fake.NewClientBuilder().WithScheme(schema).WithObjects(
+		unlabeled,
+		newAsset("demo", "transactions", "theshire", true),
+		newAsset("demo", "accounts", "theshire", true),
+		newAsset("demo", "weather", "theshire", false),
+		newAsset("demo", "payments", "neverland", true),
+		newAsset("other", "loans", "theshire", true),
+		otherOwner,
+	).Build()}
+	handler := NewHandler(client)
+
+	listAssets := func(request *datacatalog.ListAssetsRequest) *datacatalog.ListAssetsResponse {
+		w := httptest.NewRecorder()
+		gin.SetMode(gin.TestMode)
+		c, _ := gin.CreateTestContext(w)
+		requestBytes, err := json.Marshal(request)
+		g.Expect(err).To(BeNil())
+		c.Request = httptest.NewRequest(http.MethodPost, "http://localhost/", bytes.NewBuffer(requestBytes))
+		handler.listAssets(c)
+		g.Expect(w.Code).To(Equal(http.StatusOK))
+		response := &datacatalog.ListAssetsResponse{}
+		g.Expect(json.Unmarshal(w.Body.Bytes(), response)).To(Succeed())
+		return response
+	}
+	assetIDs := func(response *datacatalog.ListAssetsResponse) []taxonomy.AssetID {
+		ids := []taxonomy.AssetID{}
+		for i := range response.Assets {
+			ids = append(ids, response.Assets[i].AssetID)
+		}
+		return ids
+	}
+
+	// all assets are listed if no filter is given
+	response := listAssets(&datacatalog.ListAssetsRequest{})
+	g.Expect(assetIDs(response)).To(HaveLen(7))
+	g.Expect(response.Continue).To(BeEmpty())
+	g.Expect(otherOwner.Labels).NotTo(HaveKey(OwnerLabel))
+
+	// filter by catalog, tags, geography and connection, one asset per page
+	request := &datacatalog.ListAssetsRequest{
+		CatalogID: "demo",
+		Geography: "theshire",
+		Tags: &taxonomy.Tags{Properties: serde.Properties{Items: map[string]interface{}{
+			"finance": true,
+		}}},
+		ConnectionType: "s3",
+		Limit:          1,
+	}
+	response = listAssets(request)
+	g.Expect(assetIDs(response)).To(Equal([]taxonomy.AssetID{"demo/accounts"}))
+	g.Expect(response.Assets[0].ResourceMetadata.Owner).To(Equal("Alice"))
+	g.Expect(response.Continue).NotTo(BeEmpty())
+	request.Continue = response.Continue
+	response = listAssets(request)
+	g.Expect(assetIDs(response)).To(Equal([]taxonomy.AssetID{"demo/budget"}))
+	request.Continue = response.Continue
+	response = listAssets(request)
+	g.Expect(assetIDs(response)).To(Equal([]taxonomy.AssetID{"demo/loans"}))
+	request.Continue = response.Continue
+	response = listAssets(request)
+	g.Expect(assetIDs(response)).To(Equal([]taxonomy.AssetID{"demo/transactions"}))
+	// more assets may match, until a page is listed to the end
+	request.Continue = response.Continue
+	response = listAssets(request)
+	g.Expect(response.Assets).To(BeEmpty())
+	g.Expect(response.Continue).To(BeEmpty())
+
+	// pages are full although assets that do not match are listed in between
+	request.Limit, request.Continue = 2, ""
+	lists := client.lists
+	response = listAssets(request)
+	g.Expect(assetIDs(response)).To(Equal([]taxonomy.AssetID{"demo/accounts", "demo/budget"}))
+	request.Continue = response.Continue
+	response = listAssets(request)
+	g.Expect(assetIDs(response)).To(Equal([]taxonomy.AssetID{"demo/loans", "demo/transactions"}))
+	g.Expect(client.lists - lists).To(BeNumerically(">", 2))
+
+	// owners that are not label values are matched too
+	response = listAssets(&datacatalog.ListAssetsRequest{Owner: "Bob Smith"})
+	g.Expect(assetIDs(response)).To(Equal([]taxonomy.AssetID{"demo/budget"}))
+	// no asset matches a different owner
+	response = listAssets(&datacatalog.ListAssetsRequest{Owner: "Bob"})
+	g.Expect(response.Assets).To(BeEmpty())
+
+	// the labels follow the changes of the metadata
+	otherOwner.Spec.Metadata.Geography = "neverland"
+	otherOwner.Spec.Metadata.Tags = nil
+	LabelAsset(otherOwner)
+	g.Expect(otherOwner.Labels).To(Equal(map[string]string{GeographyLabel: "neverland", ConnectionTypeLabel: "s3"}))
+}
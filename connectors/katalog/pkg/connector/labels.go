@@ -0,0 +1,74 @@
+// Copyright 2023 IBM Corp.
+// SPDX-License-Identifier: Apache-2.0
+
+package connector
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"fybrik.io/fybrik/connectors/katalog/pkg/apis/katalog/v1alpha1"
+	"fybrik.io/fybrik/pkg/model/taxonomy"
+)
+
+// Labels of Asset resources by which the assets can be selected, e.g., with kubectl
+const (
+	OwnerLabel          = "katalog.fybrik.io/owner"
+	GeographyLabel      = "katalog.fybrik.io/geography"
+	ConnectionTypeLabel = "katalog.fybrik.io/connection-type"
+	// TagLabelPrefix is followed by the name of a tag
+	TagLabelPrefix = "tag.katalog.fybrik.io/"
+)
+
+// LabelAsset sets the labels by which the asset can be selected from its metadata and connection type.
+// Values that can not be label values, e.g., an owner with spaces, are not labeled.
+func LabelAsset(asset *v1alpha1.Asset) {
+	for key := range asset.Labels {
+		if isFilterLabel(key) {
+			delete(asset.Labels, key)
+		}
+	}
+	filterLabels := assetFilterLabels(asset.Spec.Metadata.Owner, asset.Spec.Metadata.Geography,
+		asset.Spec.Details.Connection.Name, asset.Spec.Metadata.Tags)
+	if len(filterLabels) == 0 {
+		return
+	}
+	if asset.Labels == nil {
+		asset.Labels = map[string]string{}
+	}
+	for key, value := range filterLabels {
+		asset.Labels[key] = value
+	}
+}
+
+// assetFilterLabels returns the labels of the given metadata, skipping the values that can not be label values
+func assetFilterLabels(owner, geography string, connectionType taxonomy.ConnectionType, tags *taxonomy.Tags) map[string]string {
+	filterLabels := map[string]string{}
+	setLabel := func(key string, value interface{}) {
+		var text string
+		switch value.(type) {
+		case string, bool, float64, int, int64:
+			text = fmt.Sprint(value)
+		default:
+			return
+		}
+		if text != "" && len(validation.IsQualifiedName(key)) == 0 && len(validation.IsValidLabelValue(text)) == 0 {
+			filterLabels[key] = text
+		}
+	}
+	setLabel(OwnerLabel, owner)
+	setLabel(GeographyLabel, geography)
+	setLabel(ConnectionTypeLabel, string(connectionType))
+	if tags != nil {
+		for tag, value := range tags.Items {
+			setLabel(TagLabelPrefix+tag, value)
+		}
+	}
+	return filterLabels
+}
+
+func isFilterLabel(key string) bool {
+	return key == OwnerLabel || key == GeographyLabel || key == ConnectionTypeLabel || strings.HasPrefix(key, TagLabelPrefix)
+}
@@ -0,0 +1,76 @@
+// Copyright 2023 IBM Corp.
+// SPDX-License-Identifier: Apache-2.0
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"fybrik.io/fybrik/pkg/model/taxonomy"
+)
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Namespaced
+// +kubebuilder:printcolumn:name="Description",type=string,JSONPath=`.spec.description`
+// GovernancePolicy defines governance actions required for operations on assets
+type GovernancePolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// +required
+	Spec GovernancePolicySpec `json:"spec"`
+}
+
+type GovernancePolicySpec struct {
+	// Description of the policy, reported along with the policy ID in policy decisions
+	// +optional
+	Description string `json:"description,omitempty"`
+	// Conditions on the requests to which the policy applies
+	// +optional
+	Match PolicyMatch `json:"match,omitempty"`
+	// Governance actions required by the policy. A Deny action forbids the operation.
+	// A policy without actions allows the operation.
+	// +optional
+	Actions []PolicyAction `json:"actions,omitempty"`
+}
+
+// PolicyMatch defines the requests to which a policy applies. All the conditions must hold.
+// Empty conditions match all requests.
+type PolicyMatch struct {
+	// Requested operations: read, write or delete
+	// +optional
+	Flows []taxonomy.DataFlow `json:"flows,omitempty"`
+	// Requirements on the tags of the asset, e.g., residency In [Turkey]
+	// +optional
+	AssetTags []metav1.LabelSelectorRequirement `json:"assetTags,omitempty"`
+	// Requirements on the properties of the application (its appInfo), e.g., intent In [Fraud Detection]
+	// +optional
+	AppInfo []metav1.LabelSelectorRequirement `json:"appInfo,omitempty"`
+	// Requirements on the locations of the request. Supported keys are geography (of the asset),
+	// processingLocation (of the operation) and destination (to which the data is written or sent).
+	// +optional
+	Locations []metav1.LabelSelectorRequirement `json:"locations,omitempty"`
+}
+
+// PolicyAction is a governance action required by a policy
+type PolicyAction struct {
+	// Action name and properties
+	Action taxonomy.Action `json:"action"`
+	// Tag of the asset columns to which the action applies.
+	// If set, the names of the asset columns with this tag are added to the columns property of the action.
+	// The action is not required if the asset has no such columns.
+	// +optional
+	ColumnTag string `json:"columnTag,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// GovernancePolicyList contains a list of GovernancePolicy resources
+type GovernancePolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []GovernancePolicy `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&GovernancePolicy{}, &GovernancePolicyList{})
+}
@@ -0,0 +1,94 @@
+// Copyright 2023 IBM Corp.
+// SPDX-License-Identifier: Apache-2.0
+
+package connector
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+	kclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"fybrik.io/fybrik/connectors/kubepolicy/pkg/apis/kubepolicy/v1alpha1"
+	"fybrik.io/fybrik/pkg/logging"
+	"fybrik.io/fybrik/pkg/model/policymanager"
+)
+
+type Handler struct {
+	client kclient.Client
+	// namespace of the GovernancePolicy resources
+	namespace string
+	// deny operations that no policy matches
+	denyByDefault bool
+	Log           zerolog.Logger
+}
+
+func NewHandler(client kclient.Client, namespace string, denyByDefault bool) *Handler {
+	handler := &Handler{
+		client:        client,
+		namespace:     namespace,
+		denyByDefault: denyByDefault,
+		Log:           logging.LogInit(logging.CONNECTOR, "kubepolicy-connector"),
+	}
+	return handler
+}
+
+// getPoliciesDecisions evaluates the GovernancePolicy resources against the request
+func (r *Handler) getPoliciesDecisions(c *gin.Context) {
+	// Parse request
+	var request policymanager.GetPolicyDecisionsRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		r.reportError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	logging.LogStructure("GetPoliciesDecisions object received:", request, &r.Log, zerolog.DebugLevel, false, false)
+
+	policies := &v1alpha1.GovernancePolicyList{}
+	if err := r.client.List(context.Background(), policies, kclient.InNamespace(r.namespace)); err != nil {
+		r.reportError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response := policymanager.GetPolicyDecisionsResponse{
+		Result: Evaluate(policies.Items, &request, r.denyByDefault),
+	}
+	logging.LogStructure("GetPoliciesDecisions response:", response, &r.Log, zerolog.DebugLevel, false, false)
+
+	c.JSON(http.StatusOK, &response)
+}
+
+// getPoliciesDecisionsBatch evaluates the GovernancePolicy resources against each request of the batch
+func (r *Handler) getPoliciesDecisionsBatch(c *gin.Context) {
+	// Parse request
+	var request policymanager.GetPolicyDecisionsBatchRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		r.reportError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	logging.LogStructure("GetPoliciesDecisionsBatch object received:", request, &r.Log, zerolog.DebugLevel, false, false)
+
+	policies := &v1alpha1.GovernancePolicyList{}
+	if err := r.client.List(context.Background(), policies, kclient.InNamespace(r.namespace)); err != nil {
+		r.reportError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response := policymanager.GetPolicyDecisionsBatchResponse{
+		Responses: make([]policymanager.GetPolicyDecisionsResponse, 0, len(request.Requests)),
+	}
+	for i := range request.Requests {
+		response.Responses = append(response.Responses, policymanager.GetPolicyDecisionsResponse{
+			Result: Evaluate(policies.Items, &request.Requests[i], r.denyByDefault),
+		})
+	}
+	logging.LogStructure("GetPoliciesDecisionsBatch response:", response, &r.Log, zerolog.DebugLevel, false, false)
+
+	c.JSON(http.StatusOK, &response)
+}
+
+func (r *Handler) reportError(c *gin.Context, httpCode int, errorMessage string) {
+	r.Log.Warn().CallerSkipFrame(1).Msg(errorMessage)
+	c.JSON(httpCode, gin.H{"error": errorMessage})
+}
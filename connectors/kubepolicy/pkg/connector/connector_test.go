@@ -0,0 +1,212 @@
+// Copyright 2023 IBM Corp.
+// SPDX-License-Identifier: Apache-2.0
+
+package connector
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"fybrik.io/fybrik/connectors/kubepolicy/pkg/apis/kubepolicy/v1alpha1"
+	"fybrik.io/fybrik/pkg/model/datacatalog"
+	"fybrik.io/fybrik/pkg/model/policymanager"
+	"fybrik.io/fybrik/pkg/model/taxonomy"
+	"fybrik.io/fybrik/pkg/serde"
+)
+
+const policyNamespace = "fybrik-system"
+
+func testPolicies() []v1alpha1.GovernancePolicy {
+	return []v1alpha1.GovernancePolicy{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "redact-pii", Namespace: policyNamespace},
+			Spec: v1alpha1.GovernancePolicySpec{
+				Description: "Redact PII columns of finance data read for fraud detection",
+				Match: v1alpha1.PolicyMatch{
+					Flows: []taxonomy.DataFlow{taxonomy.ReadFlow},
+					AssetTags: []metav1.LabelSelectorRequirement{
+						{Key: "finance", Operator: metav1.LabelSelectorOpIn, Values: []string{"true"}},
+					},
+					AppInfo: []metav1.LabelSelectorRequirement{
+						{Key: "intent", Operator: metav1.LabelSelectorOpIn, Values: []string{"Fraud Detection"}},
+					},
+				},
+				Actions: []v1alpha1.PolicyAction{
+					{Action: taxonomy.Action{Name: "RedactAction"}, ColumnTag: "PII"},
+					{Action: taxonomy.Action{Name: "RemoveAction"}, ColumnTag: "SPI"},
+				},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "residency", Namespace: policyNamespace},
+			Spec: v1alpha1.GovernancePolicySpec{
+				Match: v1alpha1.PolicyMatch{
+					Flows: []taxonomy.DataFlow{taxonomy.WriteFlow},
+					Locations: []metav1.LabelSelectorRequirement{
+						{Key: DestinationKey, Operator: metav1.LabelSelectorOpNotIn, Values: []string{"theshire"}},
+					},
+				},
+				Actions: []v1alpha1.PolicyAction{{Action: taxonomy.Action{Name: DenyAction}}},
+			},
+		},
+	}
+}
+
+func testRequest(flow taxonomy.DataFlow, intent, destination string) *policymanager.GetPolicyDecisionsRequest {
+	return &policymanager.GetPolicyDecisionsRequest{
+		Context: taxonomy.PolicyManagerRequestContext{Properties: serde.Properties{Items: map[string]interface{}{
+			"intent": intent,
+		}}},
+		Action: policymanager.RequestAction{ActionType: flow, Destination: destination},
+		Resource: policymanager.Resource{
+			ID: "fybrik-notebook-sample/paysim-csv",
+			Metadata: &datacatalog.ResourceMetadata{
+				Geography: "theshire",
+				Tags: &taxonomy.Tags{Properties: serde.Properties{Items: map[string]interface{}{
+					"finance": true,
+				}}},
+				Columns: []datacatalog.ResourceColumn{
+					{Name: "nameOrig", Tags: &taxonomy.Tags{Properties: serde.Properties{Items: map[string]interface{}{
+						"PII": true,
+					}}}},
+					{Name: "nameDest", Tags: &taxonomy.Tags{Properties: serde.Properties{Items: map[string]interface{}{
+						"PII": true,
+					}}}},
+					{Name: "amount"},
+				},
+			},
+		},
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	t.Parallel()
+	g := NewGomegaWithT(t)
+	policies := testPolicies()
+
+	// column actions apply to the columns with the tag only
+	result := Evaluate(policies, testRequest(taxonomy.ReadFlow, "Fraud Detection", ""), false)
+	g.Expect(result).To(HaveLen(1))
+	g.Expect(result[0].Policy).To(Equal("fybrik-system/redact-pii: Redact PII columns of finance data read for fraud detection"))
+	g.Expect(result[0].Action.Name).To(Equal(taxonomy.ActionName("RedactAction")))
+	g.Expect(result[0].Action.AdditionalProperties.Items[ColumnsProperty]).To(ConsistOf("nameOrig", "nameDest"))
+	g.Expect(policies[0].Spec.Actions[0].Action.AdditionalProperties.Items).To(BeEmpty())
+
+	// the policy does not apply for other intents
+	g.Expect(Evaluate(policies, testRequest(taxonomy.ReadFlow, "Marketing", ""), false)).To(BeEmpty())
+
+	// writing outside theshire is denied
+	result = Evaluate(policies, testRequest(taxonomy.WriteFlow, "Fraud Detection", "neverland"), false)
+	g.Expect(result).To(HaveLen(1))
+	g.Expect(result[0].Policy).To(Equal("fybrik-system/residency"))
+	g.Expect(result[0].Action.Name).To(Equal(taxonomy.ActionName(DenyAction)))
+	g.Expect(Evaluate(policies, testRequest(taxonomy.WriteFlow, "Fraud Detection", "theshire"), false)).To(BeEmpty())
+
+	// operations matching no policy are denied by default if requested
+	result = Evaluate(policies, testRequest(taxonomy.DeleteFlow, "Fraud Detection", ""), true)
+	g.Expect(result).To(HaveLen(1))
+	g.Expect(result[0].Action.Name).To(Equal(taxonomy.ActionName(DenyAction)))
+}
+
+func TestMatchRequirements(t *testing.T) {
+	t.Parallel()
+	g := NewGomegaWithT(t)
+	properties := map[string]interface{}{"role": "Data Scientist", "level": 3}
+
+	g.Expect(matchRequirements(nil, properties)).To(BeTrue())
+	g.Expect(matchRequirements([]metav1.LabelSelectorRequirement{
+		{Key: "role", Operator: metav1.LabelSelectorOpExists},
+		{Key: "level", Operator: metav1.LabelSelectorOpIn, Values: []string{"2", "3"}},
+		{Key: "team", Operator: metav1.LabelSelectorOpDoesNotExist},
+		{Key: "team", Operator: metav1.LabelSelectorOpNotIn, Values: []string{"fraud"}},
+	}, properties)).To(BeTrue())
+	g.Expect(matchRequirements([]metav1.LabelSelectorRequirement{
+		{Key: "role", Operator: metav1.LabelSelectorOpNotIn, Values: []string{"Data Scientist"}},
+	}, properties)).To(BeFalse())
+	g.Expect(matchRequirements([]metav1.LabelSelectorRequirement{
+		{Key: "team", Operator: metav1.LabelSelectorOpIn, Values: []string{"fraud"}},
+	}, properties)).To(BeFalse())
+	g.Expect(matchRequirements([]metav1.LabelSelectorRequirement{
+		{Key: "role", Operator: "Equals", Values: []string{"Data Scientist"}},
+	}, properties)).To(BeFalse())
+}
+
+func TestGetPoliciesDecisions(t *testing.T) {
+	t.Parallel()
+	g := NewGomegaWithT(t)
+
+	policies := testPolicies()
+	// policies in other namespaces are ignored
+	otherPolicy := policies[1].DeepCopy()
+	otherPolicy.Namespace = "default"
+	otherPolicy.Spec.Match = v1alpha1.PolicyMatch{}
+	schema := runtime.NewScheme()
+	g.Expect(v1alpha1.AddToScheme(schema)).To(Succeed())
+	client := fake.NewClientBuilder().WithScheme(schema).WithObjects(&policies[0], &policies[1], otherPolicy).Build()
+	handler := NewHandler(client, policyNamespace, false)
+
+	requestBytes, err := json.Marshal(testRequest(taxonomy.ReadFlow, "Fraud Detection", ""))
+	g.Expect(err).To(BeNil())
+	w := httptest.NewRecorder()
+	gin.SetMode(gin.TestMode)
+	router := NewRouter(handler)
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/getPoliciesDecisions", bytes.NewBuffer(requestBytes)))
+
+	g.Expect(w.Code).To(Equal(http.StatusOK))
+	response := &policymanager.GetPolicyDecisionsResponse{}
+	g.Expect(json.Unmarshal(w.Body.Bytes(), response)).To(Succeed())
+	g.Expect(response.Result).To(HaveLen(1))
+	g.Expect(response.Result[0].Action.Name).To(Equal(taxonomy.ActionName("RedactAction")))
+	g.Expect(response.Result[0].Action.AdditionalProperties.Items[ColumnsProperty]).To(ConsistOf("nameOrig", "nameDest"))
+
+	// invalid requests are rejected
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/getPoliciesDecisions", bytes.NewBufferString("{")))
+	g.Expect(w.Code).To(Equal(http.StatusBadRequest))
+}
+
+func TestGetPoliciesDecisionsBatch(t *testing.T) {
+	t.Parallel()
+	g := NewGomegaWithT(t)
+
+	policies := testPolicies()
+	schema := runtime.NewScheme()
+	g.Expect(v1alpha1.AddToScheme(schema)).To(Succeed())
+	client := fake.NewClientBuilder().WithScheme(schema).WithObjects(&policies[0], &policies[1]).Build()
+	handler := NewHandler(client, policyNamespace, false)
+
+	batch := policymanager.GetPolicyDecisionsBatchRequest{Requests: []policymanager.GetPolicyDecisionsRequest{
+		*testRequest(taxonomy.ReadFlow, "Fraud Detection", ""),
+		*testRequest(taxonomy.WriteFlow, "Fraud Detection", "theshire"),
+	}}
+	requestBytes, err := json.Marshal(&batch)
+	g.Expect(err).To(BeNil())
+	w := httptest.NewRecorder()
+	gin.SetMode(gin.TestMode)
+	router := NewRouter(handler)
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/getPoliciesDecisionsBatch", bytes.NewBuffer(requestBytes)))
+
+	g.Expect(w.Code).To(Equal(http.StatusOK))
+	response := &policymanager.GetPolicyDecisionsBatchResponse{}
+	g.Expect(json.Unmarshal(w.Body.Bytes(), response)).To(Succeed())
+	// responses are ordered as the requests, and equal to the decisions on each request
+	g.Expect(response.Responses).To(HaveLen(len(batch.Requests)))
+	for i := range batch.Requests {
+		expected := Evaluate(policies, &batch.Requests[i], false)
+		g.Expect(response.Responses[i].Result).To(HaveLen(len(expected)))
+		for j := range expected {
+			g.Expect(response.Responses[i].Result[j].Policy).To(Equal(expected[j].Policy))
+			g.Expect(response.Responses[i].Result[j].Action.Name).To(Equal(expected[j].Action.Name))
+		}
+	}
+	g.Expect(response.Responses[0].Result).To(HaveLen(1))
+}
@@ -0,0 +1,169 @@
+// Copyright 2023 IBM Corp.
+// SPDX-License-Identifier: Apache-2.0
+
+package connector
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"fybrik.io/fybrik/connectors/kubepolicy/pkg/apis/kubepolicy/v1alpha1"
+	"fybrik.io/fybrik/pkg/model/policymanager"
+	"fybrik.io/fybrik/pkg/model/taxonomy"
+)
+
+const (
+	// DenyAction forbids the requested operation
+	DenyAction = "Deny"
+	// ColumnsProperty is the action property listing the columns to which a column action applies
+	ColumnsProperty = "columns"
+
+	// keys of the location requirements
+	GeographyKey          = "geography"
+	ProcessingLocationKey = "processingLocation"
+	DestinationKey        = "destination"
+)
+
+// Evaluate returns the governance actions of the policies matching the request.
+// Policies without actions allow the operation. If no policy matches the request,
+// the operation is denied when denyByDefault is set, and allowed otherwise.
+func Evaluate(policies []v1alpha1.GovernancePolicy, request *policymanager.GetPolicyDecisionsRequest,
+	denyByDefault bool) []policymanager.ResultItem {
+	result := []policymanager.ResultItem{}
+	matched := false
+	for i := range policies {
+		policy := &policies[i]
+		if !matchPolicy(&policy.Spec.Match, request) {
+			continue
+		}
+		matched = true
+		for j := range policy.Spec.Actions {
+			action, required := resolveAction(&policy.Spec.Actions[j], request)
+			if required {
+				result = append(result, policymanager.ResultItem{Policy: policyID(policy), Action: action})
+			}
+		}
+	}
+	if !matched && denyByDefault {
+		result = append(result, policymanager.ResultItem{
+			Policy: "no governance policy allows the operation",
+			Action: taxonomy.Action{Name: DenyAction},
+		})
+	}
+	return result
+}
+
+// policyID identifies the policy in the decisions, along with its description if there is one
+func policyID(policy *v1alpha1.GovernancePolicy) string {
+	id := policy.Namespace + "/" + policy.Name
+	if policy.Spec.Description != "" {
+		id += ": " + policy.Spec.Description
+	}
+	return id
+}
+
+// resolveAction returns the action to be taken for the request and whether it is required.
+// Column actions apply to the asset columns with the policy column tag, and are not required if there are none.
+func resolveAction(policyAction *v1alpha1.PolicyAction, request *policymanager.GetPolicyDecisionsRequest) (taxonomy.Action, bool) {
+	action := *policyAction.Action.DeepCopy()
+	if policyAction.ColumnTag == "" {
+		return action, true
+	}
+	columns := []string{}
+	if request.Resource.Metadata != nil {
+		for _, column := range request.Resource.Metadata.Columns {
+			if column.Tags != nil && hasTag(column.Tags.Items, policyAction.ColumnTag) {
+				columns = append(columns, column.Name)
+			}
+		}
+	}
+	if len(columns) == 0 {
+		return action, false
+	}
+	if action.AdditionalProperties.Items == nil {
+		action.AdditionalProperties.Items = map[string]interface{}{}
+	}
+	action.AdditionalProperties.Items[ColumnsProperty] = columns
+	return action, true
+}
+
+// matchPolicy checks whether all the conditions of a policy hold for the request
+func matchPolicy(match *v1alpha1.PolicyMatch, request *policymanager.GetPolicyDecisionsRequest) bool {
+	if len(match.Flows) > 0 && !containsFlow(match.Flows, request.Action.ActionType) {
+		return false
+	}
+	assetTags := map[string]interface{}{}
+	locations := map[string]interface{}{}
+	if metadata := request.Resource.Metadata; metadata != nil {
+		if metadata.Tags != nil {
+			assetTags = metadata.Tags.Items
+		}
+		if metadata.Geography != "" {
+			locations[GeographyKey] = metadata.Geography
+		}
+	}
+	if request.Action.ProcessingLocation != "" {
+		locations[ProcessingLocationKey] = string(request.Action.ProcessingLocation)
+	}
+	if request.Action.Destination != "" {
+		locations[DestinationKey] = request.Action.Destination
+	}
+	return matchRequirements(match.AssetTags, assetTags) &&
+		matchRequirements(match.AppInfo, request.Context.Items) &&
+		matchRequirements(match.Locations, locations)
+}
+
+func containsFlow(flows []taxonomy.DataFlow, flow taxonomy.DataFlow) bool {
+	for _, f := range flows {
+		if f == flow {
+			return true
+		}
+	}
+	return false
+}
+
+// matchRequirements checks whether all the requirements hold for the given properties.
+// Property values are compared with the requirement values by their string representation.
+func matchRequirements(requirements []metav1.LabelSelectorRequirement, properties map[string]interface{}) bool {
+	for _, requirement := range requirements {
+		value, exists := properties[requirement.Key]
+		switch requirement.Operator {
+		case metav1.LabelSelectorOpExists:
+			if !exists {
+				return false
+			}
+		case metav1.LabelSelectorOpDoesNotExist:
+			if exists {
+				return false
+			}
+		case metav1.LabelSelectorOpIn:
+			if !exists || !containsValue(requirement.Values, value) {
+				return false
+			}
+		case metav1.LabelSelectorOpNotIn:
+			if exists && containsValue(requirement.Values, value) {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func containsValue(values []string, value interface{}) bool {
+	str := fmt.Sprint(value)
+	for _, v := range values {
+		if v == str {
+			return true
+		}
+	}
+	return false
+}
+
+// hasTag checks whether a tag is set, a tag with a false value is considered unset
+func hasTag(tags map[string]interface{}, tag string) bool {
+	value, exists := tags[tag]
+	return exists && value != false
+}
@@ -0,0 +1,14 @@
+// Copyright 2023 IBM Corp.
+// SPDX-License-Identifier: Apache-2.0
+
+package connector
+
+import "github.com/gin-gonic/gin"
+
+// NewRouter returns a new router.
+func NewRouter(handler *Handler) *gin.Engine {
+	router := gin.Default()
+	router.POST("/getPoliciesDecisions", handler.getPoliciesDecisions)
+	router.POST("/getPoliciesDecisionsBatch", handler.getPoliciesDecisionsBatch)
+	return router
+}
@@ -11,6 +11,7 @@ import (
 	"net/http"
 	"strings"
 
+	"emperror.dev/errors"
 	"github.com/gin-gonic/gin"
 	"github.com/hashicorp/go-retryablehttp"
 	"github.com/rs/zerolog"
@@ -61,47 +62,78 @@ func (r *ConnectorController) GetPoliciesDecisions(c *gin.Context) {
 		return
 	}
 	logging.LogStructure("GetPoliciesDecisions object received:", request, &r.Log, zerolog.DebugLevel, false, false)
+	response, httpCode, err := r.queryOPA(&request)
+	if err != nil {
+		r.reportError(c, httpCode, err.Error())
+		return
+	}
+	r.Log.Info().Msg(
+		"Sending response from opa connector with created asset ID: " + string(request.Resource.ID))
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetPoliciesDecisionsBatch queries OPA for each request of the batch, and returns the responses in the order of the requests
+func (r *ConnectorController) GetPoliciesDecisionsBatch(c *gin.Context) {
+	// Parse request
+	var request policymanager.GetPolicyDecisionsBatchRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		r.reportError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	logging.LogStructure("GetPoliciesDecisionsBatch object received:", request, &r.Log, zerolog.DebugLevel, false, false)
+	response := policymanager.GetPolicyDecisionsBatchResponse{
+		Responses: make([]policymanager.GetPolicyDecisionsResponse, 0, len(request.Requests)),
+	}
+	for i := range request.Requests {
+		decisions, httpCode, err := r.queryOPA(&request.Requests[i])
+		if err != nil {
+			r.reportError(c, httpCode, err.Error())
+			return
+		}
+		response.Responses = append(response.Responses, *decisions)
+	}
+	r.Log.Info().Msgf("Sending response from opa connector with %d policy decisions", len(response.Responses))
+
+	c.JSON(http.StatusOK, response)
+}
+
+// queryOPA sends a request to OPA and returns its response, or an error with the HTTP status code to report
+func (r *ConnectorController) queryOPA(request *policymanager.GetPolicyDecisionsRequest) (
+	*policymanager.GetPolicyDecisionsResponse, int, error) {
 	// Add "input" hierarchy
-	inputStruct := map[string]interface{}{"input": &request}
+	inputStruct := map[string]interface{}{"input": request}
 	// Marshal request as JSON
 	requestBody, err := json.Marshal(&inputStruct)
 	if err != nil {
-		r.reportError(c, http.StatusInternalServerError, err.Error())
-		return
+		return nil, http.StatusInternalServerError, err
 	}
 	// Send request to OPA
 	endpoint := fmt.Sprintf("%s/%s", strings.TrimRight(r.OpaServerURL, "/"), strings.TrimLeft(policyEndpoint, "/"))
 	responseFromOPA, err := r.OpaClient.Post(endpoint, "application/json", bytes.NewBuffer(requestBody))
 	if err != nil {
-		r.reportError(c, http.StatusInternalServerError, err.Error())
-		return
+		return nil, http.StatusInternalServerError, err
 	}
 
 	// Read response from OPA
 	defer responseFromOPA.Body.Close()
 	responseFromOPABody, err := io.ReadAll(responseFromOPA.Body)
 	if err != nil {
-		r.reportError(c, http.StatusInternalServerError, err.Error())
-		return
+		return nil, http.StatusInternalServerError, err
 	}
 
 	// Handle errors from OPA
 	if responseFromOPA.StatusCode != http.StatusOK {
 		// TODO: better error handling for OPA errors
-		r.reportError(c, responseFromOPA.StatusCode, string(responseFromOPABody))
-		return
+		return nil, responseFromOPA.StatusCode, errors.New(string(responseFromOPABody))
 	}
 
 	// Unmarshal as GetPolicyDecisionsResponse for the sake of validation
 	var response policymanager.GetPolicyDecisionsResponse
 	if err := json.Unmarshal(responseFromOPABody, &response); err != nil {
-		r.reportError(c, http.StatusInternalServerError, err.Error())
-		return
+		return nil, http.StatusInternalServerError, err
 	}
-	r.Log.Info().Msg(
-		"Sending response from opa connector with created asset ID: " + string(request.Resource.ID))
-
-	c.JSON(http.StatusOK, response)
+	return &response, http.StatusOK, nil
 }
 
 func (r *ConnectorController) reportError(c *gin.Context, httpCode int, errorMessage string) {
@@ -7,6 +7,7 @@ import (
 	netv1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	"fybrik.io/fybrik/pkg/model/datacatalog"
 	"fybrik.io/fybrik/pkg/model/taxonomy"
 )
 
@@ -25,6 +26,10 @@ type AssetContext struct {
 	// +optional
 	Transformations []taxonomy.Action `json:"transformations,omitempty"`
 
+	// Columns of the asset, including their types and nested fields, if known to the data catalog
+	// +optional
+	Columns []datacatalog.ResourceColumn `json:"columns,omitempty"`
+
 	// Capability of the module
 	// +required
 	Capability taxonomy.Capability `json:"capability"`
@@ -110,6 +115,16 @@ type BlueprintModule struct {
 	// Network specifies the module communication with a workload or other modules
 	// +optional
 	Network ModuleNetwork `json:"network,omitempty"`
+
+	// Continuous indicates that the module processes the data continuously, e.g., ingests a stream,
+	// rather than completing once. The readiness of such a module is monitored as long as it runs.
+	// +optional
+	Continuous bool `json:"continuous,omitempty"`
+
+	// Scheduled indicates that the module runs upon scheduled times, e.g., makes periodic copies.
+	// Its release is installed anew whenever it changes, so that a new job is run rather than the completed one upgraded.
+	// +optional
+	Scheduled bool `json:"scheduled,omitempty"`
 }
 
 // BlueprintSpec defines the desired state of Blueprint, which defines the components of the workload's data path
@@ -133,6 +148,12 @@ type BlueprintSpec struct {
 	// ApplicationContext is a context of the origin FybrikApplication (labels, properties, etc.)
 	// +optional
 	Application *ApplicationDetails `json:"application,omitempty"`
+
+	// RolloutStrategy indicates how changes of the blueprint are applied to the module releases.
+	// With the Sequential strategy only the releases that changed are upgraded, and a failed upgrade is rolled back.
+	// With the BlueGreen strategy the releases that are not in the blueprint any more are uninstalled once the others are ready.
+	// +optional
+	RolloutStrategy RolloutStrategy `json:"rolloutStrategy,omitempty"`
 }
 
 // BlueprintStatus defines the observed state of Blueprint
@@ -158,6 +179,16 @@ type BlueprintStatus struct {
 	// At the end of reconcile, each release should be mapped to the latest blueprint version or be uninstalled.
 	// +optional
 	Releases map[string]int64 `json:"releases,omitempty"`
+
+	// PreviousRevisions map the releases upgraded to the observed generation to their Helm revisions before the upgrade.
+	// A release is rolled back to its previous revision if the upgrade fails.
+	// +optional
+	PreviousRevisions map[string]int `json:"previousRevisions,omitempty"`
+
+	// RolledBack map the releases that have been rolled back to their previous revisions to the reasons of the rollbacks.
+	// It is cleared when the blueprint changes.
+	// +optional
+	RolledBack map[string]string `json:"rolledBack,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -33,6 +33,30 @@ type FlowRequirements struct {
 	// Relevant when writing new asset.
 	// +optional
 	ResourceMetadata *datacatalog.ResourceMetadata `json:"metadata,omitempty"`
+
+	// Schedule of periodic copies in Cron format, e.g., "0 2 * * *" for a daily copy at 2am UTC.
+	// Relevant for copy flows. A new copy is made in new storage upon each scheduled time,
+	// and the workload is served from the latest copy.
+	// +optional
+	Schedule string `json:"schedule,omitempty"`
+
+	// Retention of the previous copies made according to the schedule.
+	// If not set, a previous copy is deleted once a new copy has completed.
+	// +optional
+	Retention *CopyRetention `json:"retention,omitempty"`
+}
+
+// CopyRetention determines which of the previous copies of a scheduled copy flow are kept.
+// A previous copy is deleted if it exceeds either of the limits.
+type CopyRetention struct {
+	// Count is the number of copies to keep, including the latest copy
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Count int32 `json:"count,omitempty"`
+
+	// TTL is the time to keep a previous copy since it has been made, e.g., "168h"
+	// +optional
+	TTL *metav1.Duration `json:"ttl,omitempty"`
 }
 
 // DataRequirements structure contains a list of requirements (interface, need to catalog the dataset, etc.)
@@ -90,6 +114,11 @@ type FybrikApplicationSpec struct {
 	// and the protocol used to access it and the format expected.
 	// +required
 	Data []DataContext `json:"data"`
+
+	// RolloutStrategy indicates how changes of the application are rolled out to the clusters.
+	// The default is set when installing Fybrik.
+	// +optional
+	RolloutStrategy RolloutStrategy `json:"rolloutStrategy,omitempty"`
 }
 
 // ResourceReference contains resource identifier(name, namespace, kind)
@@ -125,6 +154,20 @@ type DatasetDetails struct {
 	// Persistent storage (not to be removed after FybrikApplication is deleted)
 	// +optional
 	Persistent bool `json:"persistent,omitempty"`
+
+	// CreationTime is the time the storage has been provisioned
+	// +optional
+	CreationTime *metav1.Time `json:"creationTime,omitempty"`
+
+	// ExpirationTime is the time temporary storage expires according to the TTL of its storage account.
+	// Expired storage is replaced by a new copy in new storage.
+	// +optional
+	ExpirationTime *metav1.Time `json:"expirationTime,omitempty"`
+
+	// ScheduledCopyOf is set in previous copies of a scheduled copy flow to the asset that has been copied.
+	// The latest copy is kept under the asset identifier, previous copies are kept until their retention expires.
+	// +optional
+	ScheduledCopyOf string `json:"scheduledCopyOf,omitempty"`
 }
 
 // AssetState defines the observed state of an asset
@@ -140,6 +183,62 @@ type AssetState struct {
 	// Endpoint provides the endpoint spec from which the asset will be served to the application
 	// +optional
 	Endpoint taxonomy.Connection `json:"endpoint,omitempty"`
+
+	// DecisionIDs lists the IDs of the policy manager decisions the data flow of the asset is based on
+	// +optional
+	DecisionIDs []string `json:"decisionIDs,omitempty"`
+
+	// Alternatives lists the best data paths found for the asset, from best to worst.
+	// Only recorded when the manager is configured to report alternative data paths.
+	// +optional
+	Alternatives []DataPathAlternative `json:"alternatives,omitempty"`
+}
+
+// DataPathAlternative describes a data path for an asset and its score with respect to the optimization strategy
+type DataPathAlternative struct {
+	// Rank of the data path among the alternatives (starting with 1)
+	Rank int `json:"rank"`
+
+	// Score of the data path, the weighted sum of the optimization goals (the smaller the better).
+	// Empty if no optimization goals are set.
+	// +optional
+	Score string `json:"score,omitempty"`
+
+	// Goals provides the value of each optimization goal in this data path
+	// +optional
+	Goals []OptimizationGoalScore `json:"goals,omitempty"`
+
+	// Steps of the data path, from the data source to the workload
+	Steps []DataPathStep `json:"steps"`
+}
+
+// OptimizationGoalScore is the value of an optimization goal in a data path
+type OptimizationGoalScore struct {
+	// Attribute name
+	Attribute string `json:"attribute"`
+	// Optimization directive: min or max
+	Directive string `json:"directive"`
+	// Weight of the goal
+	// +optional
+	Weight string `json:"weight,omitempty"`
+	// Value of the (normalized) attribute summed over the data path
+	Value string `json:"value"`
+}
+
+// DataPathStep is a module capability deployed on a data path
+type DataPathStep struct {
+	// Module name
+	Module string `json:"module"`
+	// Capability of the module
+	Capability taxonomy.Capability `json:"capability"`
+	// Cluster where the module is deployed
+	Cluster string `json:"cluster"`
+	// Storage account used by the module
+	// +optional
+	StorageAccount string `json:"storageAccount,omitempty"`
+	// Governance actions applied by the module
+	// +optional
+	Actions []taxonomy.ActionName `json:"actions,omitempty"`
 }
 
 // FybrikApplicationStatus defines the observed state of FybrikApplication.
@@ -179,6 +278,61 @@ type FybrikApplicationStatus struct {
 	// ProvisionedStorage has the information required to register the dataset once the owned plotter resource is ready
 	// +optional
 	ProvisionedStorage map[string]DatasetDetails `json:"provisionedStorage,omitempty"`
+
+	// LineageGenerations maps a dataset (identified by AssetID) to the generation of the generated resource whose lineage
+	// of the dataset has been emitted. Unlike AssetStates, it is kept when the application is planned again.
+	// +optional
+	LineageGenerations map[string]int64 `json:"lineageGenerations,omitempty"`
+
+	// GovernanceDigest is a digest of the governance decisions, asset metadata and infrastructure attributes
+	// the generated resource is based on. It is used to detect whether a re-evaluation changes the plan.
+	// +optional
+	GovernanceDigest string `json:"governanceDigest,omitempty"`
+
+	// Reevaluation describes the last re-evaluation of the governance decisions that has changed the plan
+	// or that has failed, without a change of the FybrikApplication spec
+	// +optional
+	Reevaluation *Reevaluation `json:"reevaluation,omitempty"`
+
+	// Failover describes the last time that the application has been planned again because clusters that it has been
+	// deployed on have failed
+	// +optional
+	Failover *Failover `json:"failover,omitempty"`
+}
+
+// Reevaluation describes why and when the governance decisions of a FybrikApplication have been re-evaluated
+type Reevaluation struct {
+	// Reason for the re-evaluation, e.g., a change of an asset or of the configuration policies
+	// +required
+	Reason string `json:"reason"`
+
+	// Time of the re-evaluation
+	// +required
+	Time metav1.Time `json:"time"`
+
+	// Error describes why the governance decisions could not be evaluated. The re-evaluation is retried,
+	// and the current plan is kept unless the re-evaluations fail for longer than the configured timeout.
+	// +optional
+	Error string `json:"error,omitempty"`
+
+	// FailingSince is the time of the first of the re-evaluations that have failed in a row
+	// +optional
+	FailingSince *metav1.Time `json:"failingSince,omitempty"`
+}
+
+// Failover describes why and when a FybrikApplication has been planned again without the clusters that have failed
+type Failover struct {
+	// Clusters that have failed and are not used by the new plan
+	// +required
+	Clusters []string `json:"clusters"`
+
+	// Reason for the failover, e.g., how long the clusters have been unreachable
+	// +required
+	Reason string `json:"reason"`
+
+	// Time of the failover
+	// +required
+	Time metav1.Time `json:"time"`
 }
 
 // FybrikApplication provides information about the application whose data is being operated on,
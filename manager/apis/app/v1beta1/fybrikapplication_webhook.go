@@ -14,6 +14,8 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	"fybrik.io/fybrik/pkg/environment"
+	"fybrik.io/fybrik/pkg/model/taxonomy"
+	"fybrik.io/fybrik/pkg/schedule"
 	"fybrik.io/fybrik/pkg/validate"
 )
 
@@ -59,6 +61,7 @@ func (r *FybrikApplication) ValidateFybrikApplication(taxonomyFile string) error
 	if err != nil {
 		return err
 	}
+	allErrs = append(allErrs, r.validateSchedules()...)
 
 	// Return any error
 	if len(allErrs) == 0 {
@@ -69,3 +72,27 @@ func (r *FybrikApplication) ValidateFybrikApplication(taxonomyFile string) error
 		schema.GroupKind{Group: "app.fybrik.io", Kind: "FybrikApplication"},
 		r.Name, allErrs)
 }
+
+// validateSchedules checks that schedules are set for copy flows only and are valid Cron schedules
+func (r *FybrikApplication) validateSchedules() []*field.Error {
+	var allErrs []*field.Error
+	for i := range r.Spec.Data {
+		flowParams := &r.Spec.Data[i].Requirements.FlowParams
+		path := field.NewPath("spec", "data").Index(i).Child("requirements", "flowParams")
+		if flowParams.Schedule == "" {
+			if flowParams.Retention != nil {
+				allErrs = append(allErrs, field.Invalid(path.Child("retention"), flowParams.Retention,
+					"retention is relevant for scheduled copies only"))
+			}
+			continue
+		}
+		if r.Spec.Data[i].Flow != taxonomy.CopyFlow {
+			allErrs = append(allErrs, field.Invalid(path.Child("schedule"), flowParams.Schedule,
+				"a schedule can be set for copy flows only"))
+		}
+		if _, err := schedule.Parse(flowParams.Schedule); err != nil {
+			allErrs = append(allErrs, field.Invalid(path.Child("schedule"), flowParams.Schedule, err.Error()))
+		}
+	}
+	return allErrs
+}
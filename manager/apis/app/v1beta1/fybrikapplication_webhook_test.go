@@ -97,3 +97,26 @@ func TestInvalidInterfaceWithEnhancedTaxonomy(t *testing.T) {
 	validateErr := (*fybrikApp).ValidateFybrikApplication(taxonomyFile)
 	assert.NotNil(t, validateErr, "Invalid interface error should be found")
 }
+
+func TestInvalidSchedules(t *testing.T) {
+	t.Parallel()
+
+	filename := "../../../testdata/unittests/fybrikapplication-scheduleErrors.yaml"
+	buf, err := os.ReadFile(filename)
+	if err != nil {
+		fmt.Printf("err: %v\n", err)
+		return
+	}
+
+	fybrikApp := &FybrikApplication{}
+	err = yaml.Unmarshal(buf, fybrikApp)
+	if err != nil {
+		fmt.Printf("err: %v\n", err)
+		return
+	}
+
+	assert.Len(t, fybrikApp.validateSchedules(), 3, "Invalid schedule errors should be found")
+	taxonomyFile := "../../../testdata/unittests/sampletaxonomy/fybrik_application.json"
+	validateErr := (*fybrikApp).ValidateFybrikApplication(taxonomyFile)
+	assert.NotNil(t, validateErr, "Invalid schedule error should be found")
+}
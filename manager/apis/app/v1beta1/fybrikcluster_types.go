@@ -0,0 +1,81 @@
+// Copyright 2023 IBM Corp.
+// SPDX-License-Identifier: Apache-2.0
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// FybrikClusterSpec defines the attributes of a cluster that Fybrik takes into account when planning data paths
+type FybrikClusterSpec struct {
+	// Region of the cluster
+	// +required
+	Region string `json:"region"`
+	// Zone of the cluster
+	// +optional
+	Zone string `json:"zone,omitempty"`
+	// VaultAuthPath is the path of the Vault Kubernetes authentication method of the cluster
+	// +optional
+	VaultAuthPath string `json:"vaultAuthPath,omitempty"`
+	// Labels classify the cluster, e.g. by a compliance zone.
+	// They can be used in restrictions on clusters as metadata.labels.<name>
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+	// Attributes of the cluster, e.g. its cost or its free GPU capacity.
+	// They can be used in restrictions on clusters as metadata.attributes.<name>
+	// +optional
+	Attributes map[string]string `json:"attributes,omitempty"`
+}
+
+// FybrikClusterStatus defines the observed state of FybrikCluster
+type FybrikClusterStatus struct {
+	// Reachable is true if the cluster has been found by the cluster manager at the last heartbeat
+	// +optional
+	Reachable bool `json:"reachable"`
+	// LastHeartbeatTime is the last time that the cluster has been found reachable
+	// +optional
+	LastHeartbeatTime *metav1.Time `json:"lastHeartbeatTime,omitempty"`
+	// UnreachableSince is the first heartbeat at which the cluster has been found unreachable since it was last reachable.
+	// The cluster is not used for new plans while it is unreachable.
+	// +optional
+	UnreachableSince *metav1.Time `json:"unreachableSince,omitempty"`
+	// FybrikVersion is the version of Fybrik that is deployed in the cluster
+	// +optional
+	FybrikVersion string `json:"fybrikVersion,omitempty"`
+}
+
+// FybrikCluster describes a cluster that Fybrik can deploy modules to. The name of the resource is the name of the cluster.
+// FybrikClusters are registered automatically for the clusters found by the cluster manager, and their spec can be edited
+// by the administrator. The spec takes precedence over the metadata that the cluster manager reports.
+// +kubebuilder:object:root=true
+// +kubebuilder:storageversion
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:printcolumn:name="Region",type=string,JSONPath=`.spec.region`
+// +kubebuilder:printcolumn:name="Zone",type=string,JSONPath=`.spec.zone`
+// +kubebuilder:printcolumn:name="Reachable",type=boolean,JSONPath=`.status.reachable`
+// +kubebuilder:printcolumn:name="Version",type=string,JSONPath=`.status.fybrikVersion`
+// +kubebuilder:printcolumn:name="Heartbeat",type=date,JSONPath=`.status.lastHeartbeatTime`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+type FybrikCluster struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// +required
+	Spec   FybrikClusterSpec   `json:"spec"`
+	Status FybrikClusterStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// FybrikClusterList contains a list of FybrikCluster
+type FybrikClusterList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []FybrikCluster `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&FybrikCluster{}, &FybrikClusterList{})
+}
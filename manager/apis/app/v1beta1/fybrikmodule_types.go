@@ -75,6 +75,9 @@ type Plugin struct {
 	DataFormat string `json:"dataFormat"`
 }
 
+// StreamCapability is the capability of modules that continuously ingest a stream, e.g., a Kafka topic, into storage
+const StreamCapability taxonomy.Capability = "stream"
+
 // Capability declares what this module knows how to do and the types of data it knows how to handle
 type ModuleCapability struct {
 
@@ -111,6 +114,28 @@ type ModuleSupportedAction struct {
 	// Unique name of an action supported by the module
 	// +required
 	Name taxonomy.ActionName `json:"name"`
+
+	// Scopes of the action supported by the module: dataset, column (e.g., masking of a list of columns)
+	// or row (filtering of rows with a predicate expression). All scopes are supported if none is specified.
+	// +optional
+	Scopes []taxonomy.ActionScope `json:"scopes,omitempty"`
+}
+
+// Supports returns true if the module can apply the given action
+func (a *ModuleSupportedAction) Supports(action *taxonomy.Action) bool {
+	if a.Name != action.Name {
+		return false
+	}
+	if len(a.Scopes) == 0 {
+		return true
+	}
+	scope := action.Scope()
+	for _, supported := range a.Scopes {
+		if supported == scope {
+			return true
+		}
+	}
+	return false
 }
 
 // ResourceStatusIndicator is used to determine the status of an orchestrated resource
@@ -132,6 +157,14 @@ type ResourceStatusIndicator struct {
 	// ErrorMessage specifies the resource field to check for an error, e.g. status.errorMsg
 	// +optional
 	ErrorMessage string `json:"errorMessage,omitempty"`
+
+	// LagField specifies the resource field that holds the lag of a continuous resource, e.g. status.consumerLag
+	// +optional
+	LagField string `json:"lagField,omitempty"`
+
+	// MaxLag is the maximal lag of a ready resource. A resource with a larger lag is not ready until it catches up.
+	// +optional
+	MaxLag int64 `json:"maxLag,omitempty"`
 }
 
 // FybrikModuleSpec contains the info common to all modules,
@@ -57,6 +57,7 @@ func (r *FybrikModule) ValidateFybrikModule(taxonomyFile string) error {
 	if err != nil {
 		return err
 	}
+	allErrs = append(allErrs, r.validateStreamCapabilities()...)
 
 	// Return any error
 	if len(allErrs) == 0 {
@@ -67,3 +68,26 @@ func (r *FybrikModule) ValidateFybrikModule(taxonomyFile string) error {
 		schema.GroupKind{Group: "app.fybrik.io", Kind: "FybrikModule"},
 		r.Name, allErrs)
 }
+
+// validateStreamCapabilities checks that stream capabilities declare both the stream they read and the storage they write
+func (r *FybrikModule) validateStreamCapabilities() []*field.Error {
+	var allErrs []*field.Error
+	capabilitiesPath := field.NewPath("spec", "capabilities")
+	for i := range r.Spec.Capabilities {
+		capability := &r.Spec.Capabilities[i]
+		if capability.Capability != StreamCapability {
+			continue
+		}
+		interfacesPath := capabilitiesPath.Index(i).Child("supportedInterfaces")
+		if len(capability.SupportedInterfaces) == 0 {
+			allErrs = append(allErrs, field.Required(interfacesPath, "a stream capability must declare its source and sink"))
+		}
+		for j := range capability.SupportedInterfaces {
+			inOut := &capability.SupportedInterfaces[j]
+			if inOut.Source == nil || inOut.Sink == nil {
+				allErrs = append(allErrs, field.Required(interfacesPath.Index(j), "a stream capability requires both a source and a sink"))
+			}
+		}
+	}
+	return allErrs
+}
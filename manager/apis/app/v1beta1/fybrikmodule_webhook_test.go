@@ -52,3 +52,23 @@ func TestInvalidModuleWithTaxonomy(t *testing.T) {
 	validateErr := fybrikModule.ValidateFybrikModule(taxonomyFile)
 	assert.NotNil(t, validateErr, "Invalid actions error should be found")
 }
+
+func TestInvalidStreamModule(t *testing.T) {
+	t.Parallel()
+
+	filename := "../../../testdata/unittests/fybrikmodule-streamErrors.yaml"
+	buf, err := os.ReadFile(filename)
+	if err != nil {
+		fmt.Printf("err: %v\n", err)
+		return
+	}
+	fybrikModule := &FybrikModule{}
+	err = yaml.Unmarshal(buf, fybrikModule)
+	if err != nil {
+		fmt.Printf("err: %v\n", err)
+		return
+	}
+	taxonomyFile := "../../../testdata/unittests/sampletaxonomy/fybrik_module.json"
+	validateErr := fybrikModule.ValidateFybrikModule(taxonomyFile)
+	assert.NotNil(t, validateErr, "A stream capability without a sink should be rejected")
+}
@@ -20,6 +20,10 @@ type AssetDetails struct {
 
 	// +required
 	DataStore DataStore `json:"assetDetails"`
+
+	// Columns of the asset, including their types and nested fields, if known to the data catalog
+	// +optional
+	Columns []datacatalog.ResourceColumn `json:"columns,omitempty"`
 }
 
 // StepArgument describes a step: it could be assetID
@@ -71,7 +75,7 @@ type DataFlowStep struct {
 }
 
 // SubFlowTrigger indicates the trigger for this subflow
-// +kubebuilder:validation:Enum=workload;init;timer
+// +kubebuilder:validation:Enum=workload;init;timer;continuous
 type SubFlowTrigger string
 
 // TODO: These will come from the taxonomy in the future.
@@ -82,8 +86,11 @@ const (
 	// Workload flow trigger
 	WorkloadTrigger SubFlowTrigger = "workload"
 
-	// Timer flow trigger
+	// Timer flow trigger, the subflow runs upon the times set by its schedule, e.g., to make periodic copies
 	TimerTrigger SubFlowTrigger = "timer"
+
+	// Continuous flow trigger, the subflow runs as long as the application exists, e.g., to ingest a stream
+	ContinuousTrigger SubFlowTrigger = "continuous"
 )
 
 // Subflows is a list of data flows which are originated from the same data asset
@@ -102,6 +109,10 @@ type SubFlow struct {
 	// +required
 	Triggers []SubFlowTrigger `json:"triggers"`
 
+	// Schedule of a subflow with the timer trigger in Cron format
+	// +optional
+	Schedule string `json:"schedule,omitempty"`
+
 	// Steps defines a series of sequential/parallel data flow steps
 	// The first dimension represents parallel data flows. The second sequential components
 	// within the same parallel data flow.
@@ -126,6 +137,10 @@ type Flow struct {
 
 	// +required
 	SubFlows []SubFlow `json:"subFlows"`
+
+	// Policies lists the IDs of the governance policies whose decisions apply to the flow
+	// +optional
+	Policies []string `json:"policies,omitempty"`
 }
 
 // ModuleInfo is a copy of FybrikModule Custom Resource.  It contains information
@@ -189,6 +204,72 @@ type FlowStatus struct {
 	SubFlows map[string]ObservedState `json:"subFlows"`
 }
 
+// RolloutStrategy indicates how a change of a plotter is rolled out to the clusters
+// +kubebuilder:validation:Enum=AllAtOnce;Sequential;BlueGreen
+type RolloutStrategy string
+
+const (
+	// AllAtOnce updates the blueprints of all the clusters together, and upgrades all the module releases
+	AllAtOnce RolloutStrategy = "AllAtOnce"
+
+	// Sequential updates the blueprint of one cluster at a time, and proceeds to the next cluster once the blueprint is ready.
+	// Only the module releases that changed are upgraded.
+	// If the upgraded releases fail, they are rolled back to their previous Helm revisions and the updated clusters are
+	// rolled back to their previous blueprints.
+	Sequential RolloutStrategy = "Sequential"
+
+	// BlueGreen deploys the module releases of a new generation of the application side by side with the current ones,
+	// under new release names. The endpoints of the application are switched to the new releases once all of them are
+	// ready, and the current releases are uninstalled then.
+	BlueGreen RolloutStrategy = "BlueGreen"
+)
+
+// RolloutPhase indicates the progress of a rollout
+type RolloutPhase string
+
+const (
+	// RolloutProgressing means that blueprints are being updated
+	RolloutProgressing RolloutPhase = "Progressing"
+
+	// RolloutCompleted means that the blueprints of all the clusters have been updated
+	RolloutCompleted RolloutPhase = "Completed"
+
+	// RolloutRollingBack means that the updated clusters are being rolled back to their previous blueprints
+	RolloutRollingBack RolloutPhase = "RollingBack"
+
+	// RolloutRolledBack means that the updated clusters have been rolled back to their previous blueprints
+	RolloutRolledBack RolloutPhase = "RolledBack"
+)
+
+// Rollout holds the progress of a sequential rollout of a plotter generation to the clusters
+type Rollout struct {
+	// Generation of the plotter that is rolled out
+	// +required
+	Generation int64 `json:"generation"`
+
+	// Phase of the rollout
+	// +required
+	Phase RolloutPhase `json:"phase"`
+
+	// UpdatedClusters are the clusters whose blueprints have been updated, in the order of the updates
+	// +optional
+	UpdatedClusters []string `json:"updatedClusters,omitempty"`
+
+	// AddedClusters are the clusters whose blueprints have been created by the rollout.
+	// Their blueprints are deleted if the rollout fails.
+	// +optional
+	AddedClusters []string `json:"addedClusters,omitempty"`
+
+	// PreviousBlueprints are the blueprint specs of the updated clusters before the updates, by the cluster names.
+	// They are restored if the rollout fails.
+	// +optional
+	PreviousBlueprints map[string]BlueprintSpec `json:"previousBlueprints,omitempty"`
+
+	// Message explains why the rollout has been rolled back
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
 // PlotterSpec defines the desired state of Plotter, which is applied in a multi-clustered environment.
 // Plotter declares what needs to be installed and where (as blueprints running on remote clusters)
 // which provides the Data Scientist's application with secure and governed access to the data requested in the
@@ -219,6 +300,16 @@ type PlotterSpec struct {
 	// The key is the template name
 	// +required
 	Templates map[string]Template `json:"templates"`
+
+	// RolloutStrategy indicates how changes of the plotter are rolled out to the clusters.
+	// The default is AllAtOnce.
+	// +optional
+	RolloutStrategy RolloutStrategy `json:"rolloutStrategy,omitempty"`
+
+	// ReleaseSuffix is appended to the names of the module releases.
+	// The BlueGreen strategy changes it with every generation of the application.
+	// +optional
+	ReleaseSuffix string `json:"releaseSuffix,omitempty"`
 }
 
 // PlotterStatus defines the observed state of Plotter
@@ -248,6 +339,10 @@ type PlotterStatus struct {
 	// +optional
 	Blueprints map[string]MetaBlueprint `json:"blueprints,omitempty"`
 
+	// Rollout holds the progress of the last sequential rollout
+	// +optional
+	Rollout *Rollout `json:"rollout,omitempty"`
+
 	// Conditions represent the possible error and failure conditions
 	// +optional
 	Conditions []Condition `json:"conditions,omitempty"`
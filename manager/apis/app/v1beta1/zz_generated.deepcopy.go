@@ -11,6 +11,7 @@ import (
 	"fybrik.io/fybrik/pkg/model/datacatalog"
 	"fybrik.io/fybrik/pkg/model/taxonomy"
 	"k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -68,6 +69,13 @@ func (in *AssetContext) DeepCopyInto(out *AssetContext) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Columns != nil {
+		in, out := &in.Columns, &out.Columns
+		*out = make([]datacatalog.ResourceColumn, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AssetContext.
@@ -84,6 +92,13 @@ func (in *AssetContext) DeepCopy() *AssetContext {
 func (in *AssetDetails) DeepCopyInto(out *AssetDetails) {
 	*out = *in
 	in.DataStore.DeepCopyInto(&out.DataStore)
+	if in.Columns != nil {
+		in, out := &in.Columns, &out.Columns
+		*out = make([]datacatalog.ResourceColumn, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AssetDetails.
@@ -105,6 +120,18 @@ func (in *AssetState) DeepCopyInto(out *AssetState) {
 		copy(*out, *in)
 	}
 	in.Endpoint.DeepCopyInto(&out.Endpoint)
+	if in.DecisionIDs != nil {
+		in, out := &in.DecisionIDs, &out.DecisionIDs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Alternatives != nil {
+		in, out := &in.Alternatives, &out.Alternatives
+		*out = make([]DataPathAlternative, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AssetState.
@@ -244,6 +271,20 @@ func (in *BlueprintStatus) DeepCopyInto(out *BlueprintStatus) {
 			(*out)[key] = val
 		}
 	}
+	if in.PreviousRevisions != nil {
+		in, out := &in.PreviousRevisions, &out.PreviousRevisions
+		*out = make(map[string]int, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.RolledBack != nil {
+		in, out := &in.RolledBack, &out.RolledBack
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BlueprintStatus.
@@ -293,6 +334,26 @@ func (in *Condition) DeepCopy() *Condition {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CopyRetention) DeepCopyInto(out *CopyRetention) {
+	*out = *in
+	if in.TTL != nil {
+		in, out := &in.TTL, &out.TTL
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CopyRetention.
+func (in *CopyRetention) DeepCopy() *CopyRetention {
+	if in == nil {
+		return nil
+	}
+	out := new(CopyRetention)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DataContext) DeepCopyInto(out *DataContext) {
 	*out = *in
@@ -329,6 +390,53 @@ func (in *DataFlowStep) DeepCopy() *DataFlowStep {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataPathAlternative) DeepCopyInto(out *DataPathAlternative) {
+	*out = *in
+	if in.Goals != nil {
+		in, out := &in.Goals, &out.Goals
+		*out = make([]OptimizationGoalScore, len(*in))
+		copy(*out, *in)
+	}
+	if in.Steps != nil {
+		in, out := &in.Steps, &out.Steps
+		*out = make([]DataPathStep, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataPathAlternative.
+func (in *DataPathAlternative) DeepCopy() *DataPathAlternative {
+	if in == nil {
+		return nil
+	}
+	out := new(DataPathAlternative)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataPathStep) DeepCopyInto(out *DataPathStep) {
+	*out = *in
+	if in.Actions != nil {
+		in, out := &in.Actions, &out.Actions
+		*out = make([]taxonomy.ActionName, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataPathStep.
+func (in *DataPathStep) DeepCopy() *DataPathStep {
+	if in == nil {
+		return nil
+	}
+	out := new(DataPathStep)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DataRequirements) DeepCopyInto(out *DataRequirements) {
 	*out = *in
@@ -387,6 +495,14 @@ func (in *DatasetDetails) DeepCopyInto(out *DatasetDetails) {
 		*out = new(datacatalog.ResourceMetadata)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.CreationTime != nil {
+		in, out := &in.CreationTime, &out.CreationTime
+		*out = (*in).DeepCopy()
+	}
+	if in.ExpirationTime != nil {
+		in, out := &in.ExpirationTime, &out.ExpirationTime
+		*out = (*in).DeepCopy()
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatasetDetails.
@@ -414,6 +530,27 @@ func (in *Dependency) DeepCopy() *Dependency {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Failover) DeepCopyInto(out *Failover) {
+	*out = *in
+	if in.Clusters != nil {
+		in, out := &in.Clusters, &out.Clusters
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.Time.DeepCopyInto(&out.Time)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Failover.
+func (in *Failover) DeepCopy() *Failover {
+	if in == nil {
+		return nil
+	}
+	out := new(Failover)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Flow) DeepCopyInto(out *Flow) {
 	*out = *in
@@ -424,6 +561,11 @@ func (in *Flow) DeepCopyInto(out *Flow) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Policies != nil {
+		in, out := &in.Policies, &out.Policies
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Flow.
@@ -444,6 +586,11 @@ func (in *FlowRequirements) DeepCopyInto(out *FlowRequirements) {
 		*out = new(datacatalog.ResourceMetadata)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Retention != nil {
+		in, out := &in.Retention, &out.Retention
+		*out = new(CopyRetention)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FlowRequirements.
@@ -584,6 +731,23 @@ func (in *FybrikApplicationStatus) DeepCopyInto(out *FybrikApplicationStatus) {
 			(*out)[key] = *val.DeepCopy()
 		}
 	}
+	if in.LineageGenerations != nil {
+		in, out := &in.LineageGenerations, &out.LineageGenerations
+		*out = make(map[string]int64, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Reevaluation != nil {
+		in, out := &in.Reevaluation, &out.Reevaluation
+		*out = new(Reevaluation)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Failover != nil {
+		in, out := &in.Failover, &out.Failover
+		*out = new(Failover)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FybrikApplicationStatus.
@@ -596,6 +760,117 @@ func (in *FybrikApplicationStatus) DeepCopy() *FybrikApplicationStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FybrikCluster) DeepCopyInto(out *FybrikCluster) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FybrikCluster.
+func (in *FybrikCluster) DeepCopy() *FybrikCluster {
+	if in == nil {
+		return nil
+	}
+	out := new(FybrikCluster)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *FybrikCluster) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FybrikClusterList) DeepCopyInto(out *FybrikClusterList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]FybrikCluster, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FybrikClusterList.
+func (in *FybrikClusterList) DeepCopy() *FybrikClusterList {
+	if in == nil {
+		return nil
+	}
+	out := new(FybrikClusterList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *FybrikClusterList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FybrikClusterSpec) DeepCopyInto(out *FybrikClusterSpec) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Attributes != nil {
+		in, out := &in.Attributes, &out.Attributes
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FybrikClusterSpec.
+func (in *FybrikClusterSpec) DeepCopy() *FybrikClusterSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(FybrikClusterSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FybrikClusterStatus) DeepCopyInto(out *FybrikClusterStatus) {
+	*out = *in
+	if in.LastHeartbeatTime != nil {
+		in, out := &in.LastHeartbeatTime, &out.LastHeartbeatTime
+		*out = (*in).DeepCopy()
+	}
+	if in.UnreachableSince != nil {
+		in, out := &in.UnreachableSince, &out.UnreachableSince
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FybrikClusterStatus.
+func (in *FybrikClusterStatus) DeepCopy() *FybrikClusterStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(FybrikClusterStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *FybrikModule) DeepCopyInto(out *FybrikModule) {
 	*out = *in
@@ -858,7 +1133,9 @@ func (in *ModuleCapability) DeepCopyInto(out *ModuleCapability) {
 	if in.Actions != nil {
 		in, out := &in.Actions, &out.Actions
 		*out = make([]ModuleSupportedAction, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 	if in.Plugins != nil {
 		in, out := &in.Plugins, &out.Plugins
@@ -980,6 +1257,11 @@ func (in *ModuleNetwork) DeepCopy() *ModuleNetwork {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ModuleSupportedAction) DeepCopyInto(out *ModuleSupportedAction) {
 	*out = *in
+	if in.Scopes != nil {
+		in, out := &in.Scopes, &out.Scopes
+		*out = make([]taxonomy.ActionScope, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModuleSupportedAction.
@@ -1007,6 +1289,21 @@ func (in *ObservedState) DeepCopy() *ObservedState {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OptimizationGoalScore) DeepCopyInto(out *OptimizationGoalScore) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OptimizationGoalScore.
+func (in *OptimizationGoalScore) DeepCopy() *OptimizationGoalScore {
+	if in == nil {
+		return nil
+	}
+	out := new(OptimizationGoalScore)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Plotter) DeepCopyInto(out *Plotter) {
 	*out = *in
@@ -1129,6 +1426,11 @@ func (in *PlotterStatus) DeepCopyInto(out *PlotterStatus) {
 			(*out)[key] = *val.DeepCopy()
 		}
 	}
+	if in.Rollout != nil {
+		in, out := &in.Rollout, &out.Rollout
+		*out = new(Rollout)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make([]Condition, len(*in))
@@ -1165,6 +1467,26 @@ func (in *Plugin) DeepCopy() *Plugin {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Reevaluation) DeepCopyInto(out *Reevaluation) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+	if in.FailingSince != nil {
+		in, out := &in.FailingSince, &out.FailingSince
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Reevaluation.
+func (in *Reevaluation) DeepCopy() *Reevaluation {
+	if in == nil {
+		return nil
+	}
+	out := new(Reevaluation)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ResourceReference) DeepCopyInto(out *ResourceReference) {
 	*out = *in
@@ -1195,6 +1517,38 @@ func (in *ResourceStatusIndicator) DeepCopy() *ResourceStatusIndicator {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Rollout) DeepCopyInto(out *Rollout) {
+	*out = *in
+	if in.UpdatedClusters != nil {
+		in, out := &in.UpdatedClusters, &out.UpdatedClusters
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AddedClusters != nil {
+		in, out := &in.AddedClusters, &out.AddedClusters
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.PreviousBlueprints != nil {
+		in, out := &in.PreviousBlueprints, &out.PreviousBlueprints
+		*out = make(map[string]BlueprintSpec, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Rollout.
+func (in *Rollout) DeepCopy() *Rollout {
+	if in == nil {
+		return nil
+	}
+	out := new(Rollout)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Selector) DeepCopyInto(out *Selector) {
 	*out = *in
@@ -5,7 +5,10 @@ package v1beta2
 
 import (
 	"encoding/json"
+	"fmt"
+	"time"
 
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"fybrik.io/fybrik/pkg/model/taxonomy"
@@ -16,6 +19,8 @@ const typeKey = "type"
 const idKey = "id"
 const geographyKey = "geography"
 const secretRefKey = "secretRef"
+const quotaKey = "quota"
+const ttlKey = "ttl"
 
 // FybrikStorageAccountSpec defines the desired state of FybrikStorageAccount
 // +kubebuilder:pruning:PreserveUnknownFields
@@ -32,18 +37,67 @@ type FybrikStorageAccountSpec struct {
 	// +required
 	// Storage geography
 	Geography taxonomy.ProcessingLocation `json:"geography"`
+	// Maximal amount of storage that Fybrik may allocate in the account, computed from the storage estimates
+	// of the datasets. No limit is enforced if the quota is not set.
+	// +optional
+	Quota *resource.Quantity `json:"quota,omitempty"`
+	// TTL of temporary storage allocated in the account, e.g., "24h". Temporary storage holds copies that are not
+	// registered in a catalog. Once it expires, a new copy is made in new storage and the expired storage is deleted.
+	// Temporary storage does not expire if the TTL is not set.
+	// +optional
+	TTL *metav1.Duration `json:"ttl,omitempty"`
 	// Additional storage properties, specific to the storage type
 	AdditionalProperties serde.Properties `json:"-"`
 }
 
+// StorageAllocation is storage allocated by Fybrik in a storage account for a dataset of a FybrikApplication
+type StorageAllocation struct {
+	// Application the storage is allocated for, in the format <namespace>/<name>
+	// +required
+	Application string `json:"application"`
+	// Dataset the storage is allocated for
+	// +required
+	DatasetID string `json:"datasetID"`
+	// Storage estimate of the dataset as provided in the application requirements
+	// +optional
+	StorageEstimate resource.Quantity `json:"storageEstimate,omitempty"`
+}
+
+// OrphanedStorage is storage allocated by Fybrik in a storage account that is not used by any FybrikApplication
+type OrphanedStorage struct {
+	// Name of the storage, e.g., a bucket or a topic
+	// +required
+	Name string `json:"name"`
+	// Application the storage has been allocated for, in the format <namespace>/<name>
+	// +optional
+	Application string `json:"application,omitempty"`
+	// Time the storage has been created, if known
+	// +optional
+	CreationTime *metav1.Time `json:"creationTime,omitempty"`
+	// Time the storage has been first found orphaned
+	// +required
+	DetectionTime metav1.Time `json:"detectionTime"`
+}
+
 // FybrikStorageAccountStatus defines the observed state of FybrikStorageAccount
 type FybrikStorageAccountStatus struct {
+	// Storage allocated in the account for datasets of FybrikApplications
+	// +optional
+	Allocations []StorageAllocation `json:"allocations,omitempty"`
+	// Sum of the storage estimates of the allocations
+	// +optional
+	EstimatedUsage resource.Quantity `json:"estimatedUsage,omitempty"`
+	// Storage allocated by Fybrik in the account that is not used by any FybrikApplication,
+	// as found by the storage garbage collection
+	// +optional
+	Orphans []OrphanedStorage `json:"orphans,omitempty"`
 }
 
 // FybrikStorageAccount is a storage account Fybrik uses to dynamically allocate space
 // for datasets whose creation or copy it orchestrates.
 // +kubebuilder:object:root=true
 // +kubebuilder:storageversion
+// +kubebuilder:subresource:status
 type FybrikStorageAccount struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
@@ -73,6 +127,12 @@ func (o FybrikStorageAccountSpec) MarshalJSON() ([]byte, error) {
 		typeKey:      o.Type,
 		geographyKey: o.Geography,
 	}
+	if o.Quota != nil {
+		toSerialize[quotaKey] = o.Quota
+	}
+	if o.TTL != nil {
+		toSerialize[ttlKey] = o.TTL
+	}
 	for key, value := range o.AdditionalProperties.Items {
 		toSerialize[key] = value
 	}
@@ -95,6 +155,18 @@ func (o *FybrikStorageAccountSpec) UnmarshalJSON(bytes []byte) (err error) {
 			o.Geography = taxonomy.ProcessingLocation(val.(string))
 			delete(items, geographyKey)
 		}
+		if val, ok := items[quotaKey]; ok {
+			if o.Quota, err = parseQuantity(val); err != nil {
+				return err
+			}
+			delete(items, quotaKey)
+		}
+		if val, ok := items[ttlKey]; ok {
+			if o.TTL, err = parseDuration(val); err != nil {
+				return err
+			}
+			delete(items, ttlKey)
+		}
 		if len(items) == 0 {
 			items = nil
 		}
@@ -102,3 +174,29 @@ func (o *FybrikStorageAccountSpec) UnmarshalJSON(bytes []byte) (err error) {
 	}
 	return err
 }
+
+// parseQuantity parses a quantity given either as a string, e.g., "100Gi", or as a number of bytes
+func parseQuantity(val interface{}) (*resource.Quantity, error) {
+	bytes, err := json.Marshal(val)
+	if err != nil {
+		return nil, err
+	}
+	quantity := &resource.Quantity{}
+	if err := json.Unmarshal(bytes, quantity); err != nil {
+		return nil, err
+	}
+	return quantity, nil
+}
+
+// parseDuration parses a duration given as a string, e.g., "24h"
+func parseDuration(val interface{}) (*metav1.Duration, error) {
+	str, ok := val.(string)
+	if !ok {
+		return nil, fmt.Errorf("invalid duration %v", val)
+	}
+	duration, err := time.ParseDuration(str)
+	if err != nil {
+		return nil, err
+	}
+	return &metav1.Duration{Duration: duration}, nil
+}
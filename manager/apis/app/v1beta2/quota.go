@@ -0,0 +1,79 @@
+// Copyright 2023 IBM Corp.
+// SPDX-License-Identifier: Apache-2.0
+
+package v1beta2
+
+import (
+	"github.com/c2h5oh/datasize"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// HasCapacity returns true if a dataset of the given estimated size can be allocated in the storage account
+// without exceeding its quota. Accounts without a quota have unlimited capacity.
+func (a *FybrikStorageAccount) HasCapacity(estimate datasize.ByteSize) bool {
+	if a.Spec.Quota == nil {
+		return true
+	}
+	usage := a.Status.EstimatedUsage.DeepCopy()
+	usage.Add(estimateQuantity(estimate))
+	return usage.Cmp(*a.Spec.Quota) <= 0
+}
+
+// Allocate records the storage allocated for a dataset of the given application,
+// replacing the previous allocation of the dataset, if any
+func (s *FybrikStorageAccountStatus) Allocate(application, datasetID string, estimate datasize.ByteSize) {
+	s.Release(application, datasetID)
+	s.Allocations = append(s.Allocations, StorageAllocation{
+		Application:     application,
+		DatasetID:       datasetID,
+		StorageEstimate: estimateQuantity(estimate),
+	})
+	s.updateUsage()
+}
+
+// Release removes the allocation of a dataset of the given application,
+// or all the allocations of the application if the dataset is empty.
+// Returns true if an allocation has been removed.
+func (s *FybrikStorageAccountStatus) Release(application, datasetID string) bool {
+	allocations := []StorageAllocation{}
+	for _, allocation := range s.Allocations {
+		if allocation.Application != application || (datasetID != "" && allocation.DatasetID != datasetID) {
+			allocations = append(allocations, allocation)
+		}
+	}
+	if len(allocations) == len(s.Allocations) {
+		return false
+	}
+	if len(allocations) == 0 {
+		allocations = nil
+	}
+	s.Allocations = allocations
+	s.updateUsage()
+	return true
+}
+
+// Rename moves the allocation of a dataset of the given application to another dataset identifier,
+// e.g., to keep the allocation of a retained copy apart from the allocation of the next copy.
+// Returns true if an allocation has been moved.
+func (s *FybrikStorageAccountStatus) Rename(application, datasetID, newDatasetID string) bool {
+	renamed := false
+	for i := range s.Allocations {
+		if s.Allocations[i].Application == application && s.Allocations[i].DatasetID == datasetID {
+			s.Allocations[i].DatasetID = newDatasetID
+			renamed = true
+		}
+	}
+	return renamed
+}
+
+func (s *FybrikStorageAccountStatus) updateUsage() {
+	usage := resource.NewQuantity(0, resource.BinarySI)
+	for _, allocation := range s.Allocations {
+		usage.Add(allocation.StorageEstimate)
+	}
+	s.EstimatedUsage = *usage
+}
+
+func estimateQuantity(estimate datasize.ByteSize) resource.Quantity {
+	return *resource.NewQuantity(int64(estimate.Bytes()), resource.BinarySI)
+}
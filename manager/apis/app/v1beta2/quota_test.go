@@ -0,0 +1,89 @@
+// Copyright 2023 IBM Corp.
+// SPDX-License-Identifier: Apache-2.0
+
+package v1beta2
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/c2h5oh/datasize"
+	"github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestStorageAccountQuota(t *testing.T) {
+	t.Parallel()
+	g := gomega.NewGomegaWithT(t)
+
+	account := &FybrikStorageAccount{}
+	g.Expect(account.HasCapacity(datasize.TB)).To(gomega.BeTrue(), "an account without a quota has unlimited capacity")
+
+	quota := resource.MustParse("10Gi")
+	account.Spec.Quota = &quota
+	account.Status.Allocate("default/notebook", "s3/finance", 4*datasize.GB)
+	account.Status.Allocate("default/notebook", "s3/transactions", 4*datasize.GB)
+	g.Expect(account.Status.EstimatedUsage.Value()).To(gomega.BeEquivalentTo(8 * datasize.GB))
+	g.Expect(account.HasCapacity(2 * datasize.GB)).To(gomega.BeTrue())
+	g.Expect(account.HasCapacity(3 * datasize.GB)).To(gomega.BeFalse())
+
+	// a new allocation of a dataset replaces the previous one
+	account.Status.Allocate("default/notebook", "s3/finance", datasize.GB)
+	g.Expect(account.Status.Allocations).To(gomega.HaveLen(2))
+	g.Expect(account.Status.EstimatedUsage.Value()).To(gomega.BeEquivalentTo(5 * datasize.GB))
+
+	// a renamed allocation is kept when the dataset is allocated again
+	g.Expect(account.Status.Rename("default/notebook", "s3/finance", "s3/finance@20230101T000000Z")).To(gomega.BeTrue())
+	account.Status.Allocate("default/notebook", "s3/finance", datasize.GB)
+	g.Expect(account.Status.EstimatedUsage.Value()).To(gomega.BeEquivalentTo(6 * datasize.GB))
+	g.Expect(account.Status.Release("default/notebook", "s3/finance@20230101T000000Z")).To(gomega.BeTrue())
+
+	g.Expect(account.Status.Release("default/other", "")).To(gomega.BeFalse())
+	g.Expect(account.Status.Release("default/notebook", "s3/finance")).To(gomega.BeTrue())
+	g.Expect(account.Status.Allocations).To(gomega.HaveLen(1))
+	g.Expect(account.Status.Release("default/notebook", "")).To(gomega.BeTrue())
+	g.Expect(account.Status.Allocations).To(gomega.BeNil())
+	g.Expect(account.Status.EstimatedUsage.IsZero()).To(gomega.BeTrue())
+}
+
+func TestStorageAccountQuotaSerialization(t *testing.T) {
+	t.Parallel()
+	g := gomega.NewGomegaWithT(t)
+
+	for _, quota := range []string{`"100Gi"`, `107374182400`} {
+		spec := &FybrikStorageAccountSpec{}
+		g.Expect(json.Unmarshal([]byte(`{"id": "theshire", "secretRef": "credentials", "type": "s3", "geography": "theshire", `+
+			`"quota": `+quota+`, "s3": {"endpoint": "http://s3.theshire"}}`), spec)).To(gomega.Succeed())
+		g.Expect(spec.Quota).NotTo(gomega.BeNil())
+		g.Expect(spec.Quota.Value()).To(gomega.BeEquivalentTo(100 * datasize.GB))
+		g.Expect(spec.AdditionalProperties.Items).NotTo(gomega.HaveKey(quotaKey))
+
+		bytes, err := json.Marshal(spec)
+		g.Expect(err).NotTo(gomega.HaveOccurred())
+		decoded := &FybrikStorageAccountSpec{}
+		g.Expect(json.Unmarshal(bytes, decoded)).To(gomega.Succeed())
+		g.Expect(decoded.Quota.Cmp(*spec.Quota)).To(gomega.Equal(0))
+	}
+}
+
+func TestStorageAccountTTLSerialization(t *testing.T) {
+	t.Parallel()
+	g := gomega.NewGomegaWithT(t)
+
+	spec := &FybrikStorageAccountSpec{}
+	g.Expect(json.Unmarshal([]byte(`{"id": "theshire", "secretRef": "credentials", "type": "s3", "geography": "theshire", `+
+		`"ttl": "24h", "s3": {"endpoint": "http://s3.theshire"}}`), spec)).To(gomega.Succeed())
+	g.Expect(spec.TTL).NotTo(gomega.BeNil())
+	g.Expect(spec.TTL.Duration).To(gomega.Equal(24 * time.Hour))
+	g.Expect(spec.AdditionalProperties.Items).NotTo(gomega.HaveKey(ttlKey))
+
+	bytes, err := json.Marshal(spec)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	decoded := &FybrikStorageAccountSpec{}
+	g.Expect(json.Unmarshal(bytes, decoded)).To(gomega.Succeed())
+	g.Expect(decoded.TTL).To(gomega.Equal(spec.TTL))
+
+	g.Expect(json.Unmarshal([]byte(`{"id": "theshire", "secretRef": "credentials", "type": "s3", "ttl": "one day"}`), &FybrikStorageAccountSpec{})).
+		NotTo(gomega.Succeed())
+}
@@ -8,6 +8,7 @@
 package v1beta2
 
 import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -17,7 +18,7 @@ func (in *FybrikStorageAccount) DeepCopyInto(out *FybrikStorageAccount) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FybrikStorageAccount.
@@ -73,6 +74,16 @@ func (in *FybrikStorageAccountList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *FybrikStorageAccountSpec) DeepCopyInto(out *FybrikStorageAccountSpec) {
 	*out = *in
+	if in.Quota != nil {
+		in, out := &in.Quota, &out.Quota
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.TTL != nil {
+		in, out := &in.TTL, &out.TTL
+		*out = new(v1.Duration)
+		**out = **in
+	}
 	in.AdditionalProperties.DeepCopyInto(&out.AdditionalProperties)
 }
 
@@ -89,6 +100,21 @@ func (in *FybrikStorageAccountSpec) DeepCopy() *FybrikStorageAccountSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *FybrikStorageAccountStatus) DeepCopyInto(out *FybrikStorageAccountStatus) {
 	*out = *in
+	if in.Allocations != nil {
+		in, out := &in.Allocations, &out.Allocations
+		*out = make([]StorageAllocation, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	out.EstimatedUsage = in.EstimatedUsage.DeepCopy()
+	if in.Orphans != nil {
+		in, out := &in.Orphans, &out.Orphans
+		*out = make([]OrphanedStorage, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FybrikStorageAccountStatus.
@@ -100,3 +126,39 @@ func (in *FybrikStorageAccountStatus) DeepCopy() *FybrikStorageAccountStatus {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OrphanedStorage) DeepCopyInto(out *OrphanedStorage) {
+	*out = *in
+	if in.CreationTime != nil {
+		in, out := &in.CreationTime, &out.CreationTime
+		*out = (*in).DeepCopy()
+	}
+	in.DetectionTime.DeepCopyInto(&out.DetectionTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OrphanedStorage.
+func (in *OrphanedStorage) DeepCopy() *OrphanedStorage {
+	if in == nil {
+		return nil
+	}
+	out := new(OrphanedStorage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StorageAllocation) DeepCopyInto(out *StorageAllocation) {
+	*out = *in
+	out.StorageEstimate = in.StorageEstimate.DeepCopy()
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StorageAllocation.
+func (in *StorageAllocation) DeepCopy() *StorageAllocation {
+	if in == nil {
+		return nil
+	}
+	out := new(StorageAllocation)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -0,0 +1,93 @@
+// Copyright 2023 IBM Corp.
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/rs/zerolog"
+
+	fappv1 "fybrik.io/fybrik/manager/apis/app/v1beta1"
+	"fybrik.io/fybrik/pkg/datapath"
+	"fybrik.io/fybrik/pkg/environment"
+	"fybrik.io/fybrik/pkg/logging"
+	"fybrik.io/fybrik/pkg/model/taxonomy"
+	"fybrik.io/fybrik/pkg/optimizer"
+)
+
+// record the best data paths found for each asset in the asset state, if requested by the manager configuration
+func recordAlternatives(appContext ApplicationContext, env *datapath.Environment, requirements []datapath.DataInfo) {
+	numAlternatives, err := environment.GetDataPathAlternatives()
+	if err != nil || numAlternatives == 0 {
+		return
+	}
+	for i := range requirements {
+		dataSetID := requirements[i].Context.DataSetID
+		solutions, err := findAlternatives(env, &requirements[i], numAlternatives, appContext.Log)
+		if err != nil {
+			appContext.Log.Warn().Err(err).Str(logging.DATASETID, dataSetID).Msg("Could not find alternative data paths")
+			continue
+		}
+		assetState := appContext.Application.Status.AssetStates[dataSetID]
+		assetState.Alternatives = alternativesToStatus(solutions)
+		appContext.Application.Status.AssetStates[dataSetID] = assetState
+	}
+}
+
+// find up to k data paths for a dataset, from best to worst
+func findAlternatives(env *datapath.Environment, dataset *datapath.DataInfo, k int,
+	log *zerolog.Logger) ([]datapath.ScoredSolution, error) {
+	if environment.UseCSP() {
+		return optimizer.NewOptimizer(env, dataset, optimizer.NewSolverFromEnv(), log).SolveTopK(k)
+	}
+	// without the optimizer, there are no scores; data paths are ordered as in PathBuilder.solve()
+	pathBuilder := PathBuilder{Log: log, Env: env, Asset: dataset}
+	solutions := pathBuilder.FindPaths()
+	if len(solutions) > k {
+		solutions = solutions[:k]
+	}
+	scoredSolutions := make([]datapath.ScoredSolution, len(solutions))
+	for i := range solutions {
+		scoredSolutions[i] = datapath.ScoredSolution{Solution: solutions[i], Score: math.NaN()}
+	}
+	return scoredSolutions, nil
+}
+
+// translate scored solutions into data path alternatives reported in the asset state
+func alternativesToStatus(solutions []datapath.ScoredSolution) []fappv1.DataPathAlternative {
+	alternatives := []fappv1.DataPathAlternative{}
+	for i := range solutions {
+		alternative := fappv1.DataPathAlternative{Rank: i + 1, Score: formatScore(solutions[i].Score), Steps: []fappv1.DataPathStep{}}
+		for _, goal := range solutions[i].Goals {
+			alternative.Goals = append(alternative.Goals, fappv1.OptimizationGoalScore{
+				Attribute: goal.Goal.Attribute,
+				Directive: string(goal.Goal.Directive),
+				Weight:    goal.Goal.Weight,
+				Value:     formatScore(goal.Value),
+			})
+		}
+		for _, edge := range solutions[i].DataPath {
+			step := fappv1.DataPathStep{
+				Module:         edge.Module.Name,
+				Capability:     edge.Module.Spec.Capabilities[edge.CapabilityIndex].Capability,
+				Cluster:        edge.Cluster,
+				StorageAccount: edge.StorageAccount.ID,
+			}
+			for _, action := range edge.Actions {
+				step.Actions = append(step.Actions, taxonomy.ActionName(action.Name))
+			}
+			alternative.Steps = append(alternative.Steps, step)
+		}
+		alternatives = append(alternatives, alternative)
+	}
+	return alternatives
+}
+
+func formatScore(score float64) string {
+	if math.IsNaN(score) {
+		return ""
+	}
+	return strconv.FormatFloat(score, 'f', -1, 64) //nolint:revive // Ignore magic number 64
+}
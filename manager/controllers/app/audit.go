@@ -0,0 +1,133 @@
+// Copyright 2023 IBM Corp.
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	fappv1 "fybrik.io/fybrik/manager/apis/app/v1beta1"
+	fappv2 "fybrik.io/fybrik/manager/apis/app/v1beta2"
+	"fybrik.io/fybrik/pkg/adminconfig"
+	"fybrik.io/fybrik/pkg/audit"
+	"fybrik.io/fybrik/pkg/datapath"
+	"fybrik.io/fybrik/pkg/model/policymanager"
+	"fybrik.io/fybrik/pkg/model/taxonomy"
+)
+
+// newAuditRecord returns an audit record of the given type for a dataset of the application
+func newAuditRecord(appContext ApplicationContext, recordType audit.RecordType, datasetID string) *audit.Record {
+	application := audit.Application{
+		Namespace: appContext.Application.Namespace,
+		Name:      appContext.Application.Name,
+		UUID:      appContext.UUID,
+	}
+	return &audit.Record{Type: recordType, Application: application, DatasetID: datasetID}
+}
+
+// auditGovernanceDecision records a decision of the policy manager
+func auditGovernanceDecision(appContext ApplicationContext, request *policymanager.GetPolicyDecisionsRequest,
+	decision *PolicyDecision) {
+	if appContext.Audit == nil {
+		return
+	}
+	record := newAuditRecord(appContext, audit.GovernanceDecisionRecord, string(request.Resource.ID))
+	record.GovernanceDecision = &audit.GovernanceDecision{
+		Request:    request,
+		DecisionID: decision.DecisionID,
+		Allowed:    decision.Err == nil,
+		Actions:    decision.Actions,
+		Policies:   decision.Policies,
+		Message:    decision.Message,
+	}
+	if decision.Err != nil {
+		record.GovernanceDecision.Error = decision.Err.Error()
+	}
+	appContext.Audit.Record(record)
+}
+
+// auditConfigurationDecision records the evaluation of the configuration policies for a dataset
+func auditConfigurationDecision(appContext ApplicationContext, datasetID string, output *adminconfig.EvaluatorOutput) {
+	if appContext.Audit == nil {
+		return
+	}
+	record := newAuditRecord(appContext, audit.ConfigurationDecisionRecord, datasetID)
+	record.ConfigurationDecision = &audit.ConfigurationDecision{
+		Valid:       output.Valid,
+		PolicySetID: output.PolicySetID,
+		Decisions:   output.ConfigDecisions,
+		Policies:    output.Policies,
+	}
+	appContext.Audit.Record(record)
+}
+
+// auditDataPath records the data path selected for a dataset
+func auditDataPath(appContext ApplicationContext, item *datapath.DataInfo, selection *datapath.Solution) {
+	if appContext.Audit == nil {
+		return
+	}
+	dataPath := &audit.DataPath{Steps: []audit.DataPathStep{}, Policies: item.Policies}
+	for _, policy := range item.Configuration.Policies {
+		dataPath.ConfigurationPolicies = append(dataPath.ConfigurationPolicies, policy.ID)
+	}
+	for _, edge := range selection.DataPath {
+		step := audit.DataPathStep{
+			Module:         edge.Module.Name,
+			Capability:     edge.Module.Spec.Capabilities[edge.CapabilityIndex].Capability,
+			Cluster:        edge.Cluster,
+			StorageAccount: edge.StorageAccount.ID,
+			Actions:        edge.Actions,
+		}
+		if edge.Source != nil {
+			step.Source = edge.Source.Connection
+		}
+		if edge.Sink != nil {
+			step.Sink = edge.Sink.Connection
+		}
+		dataPath.Steps = append(dataPath.Steps, step)
+	}
+	record := newAuditRecord(appContext, audit.DataPathRecord, item.Context.DataSetID)
+	record.DataPath = dataPath
+	appContext.Audit.Record(record)
+}
+
+// auditStorageAllocation records the allocation of storage in an account for a dataset
+func (p *PlotterGenerator) auditStorageAllocation(datasetID string, account *fappv2.FybrikStorageAccountSpec,
+	connection *taxonomy.Connection, err error) {
+	if p.Audit == nil {
+		return
+	}
+	record := &audit.Record{
+		Type:        audit.StorageAllocationRecord,
+		Application: audit.Application{Namespace: p.Owner.Namespace, Name: p.Owner.Name, UUID: p.UUID},
+		DatasetID:   datasetID,
+		Storage: &audit.Storage{
+			Account:    account.ID,
+			Type:       account.Type,
+			Geography:  account.Geography,
+			Connection: connection,
+		},
+	}
+	if err != nil {
+		record.Storage.Error = err.Error()
+	}
+	p.Audit.Record(record)
+}
+
+// auditStorageDeletion records the deletion of storage provisioned for a dataset
+func auditStorageDeletion(appContext ApplicationContext, datasetID string, details *fappv1.DatasetDetails, err error) {
+	if appContext.Audit == nil {
+		return
+	}
+	record := newAuditRecord(appContext, audit.StorageDeletionRecord, datasetID)
+	record.Storage = &audit.Storage{Persistent: details.Persistent}
+	if details.Details != nil {
+		record.Storage.Type = details.Details.Connection.Name
+		record.Storage.Connection = details.Details.Connection.DeepCopy()
+	}
+	if details.ResourceMetadata != nil {
+		record.Storage.Geography = taxonomy.ProcessingLocation(details.ResourceMetadata.Geography)
+	}
+	if err != nil {
+		record.Storage.Error = err.Error()
+	}
+	appContext.Audit.Record(record)
+}
@@ -7,6 +7,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
 
 	"emperror.dev/errors"
@@ -294,7 +295,7 @@ func (r *BlueprintReconciler) updateModuleState(blueprint *fapp.Blueprint, insta
 	blueprint.Status.ModulesState[instanceName] = state
 }
 
-//nolint:gocyclo
+//nolint:funlen,gocyclo
 func (r *BlueprintReconciler) reconcile(ctx context.Context, cfg *action.Configuration, log *zerolog.Logger,
 	blueprint *fapp.Blueprint) (ctrl.Result, error) {
 	uuid := managerUtils.GetFybrikApplicationUUIDfromAnnotations(blueprint.GetAnnotations())
@@ -303,6 +304,12 @@ func (r *BlueprintReconciler) reconcile(ctx context.Context, cfg *action.Configu
 	// force-update if the blueprint spec is different
 	updateRequired := blueprint.Status.ObservedGeneration != blueprint.GetGeneration()
 	blueprint.Status.ObservedGeneration = blueprint.GetGeneration()
+	// a sequential rollout upgrades only the releases that changed, and rolls them back if the upgrade fails
+	sequential := blueprint.Spec.RolloutStrategy == fapp.Sequential
+	if updateRequired {
+		blueprint.Status.PreviousRevisions = nil
+		blueprint.Status.RolledBack = nil
+	}
 	// reset blueprint state
 	blueprint.Status.ObservedState.Ready = false
 	blueprint.Status.ObservedState.Error = ""
@@ -343,11 +350,27 @@ func (r *BlueprintReconciler) reconcile(ctx context.Context, cfg *action.Configu
 
 		// check the release status
 		rel, err := r.Helmer.Status(cfg, releaseName)
+		upgradeRequired := updateRequired
+		if updateRequired && sequential && err == nil && rel != nil && rel.Info.Status == release.StatusDeployed {
+			upgradeRequired = releaseChanged(rel, module.Chart, args)
+			// a scheduled run is installed anew, hence it has no revision to roll back to
+			if upgradeRequired && !module.Scheduled {
+				recordPreviousRevision(blueprint, releaseName, rel.Version)
+			}
+		}
 		// nonexistent release or a failed release - re-apply the chart
-		if updateRequired || err != nil || rel == nil || rel.Info.Status == release.StatusFailed {
+		if upgradeRequired || err != nil || rel == nil || rel.Info.Status == release.StatusFailed {
+			if module.Scheduled && err == nil && rel != nil && argumentsChanged(rel, args) {
+				// a scheduled run, e.g., of a periodic copy, is a new job rather than an upgrade of the completed one
+				log.Info().Str(logging.ACTION, logging.DELETE).Msg("Uninstalling release " + releaseName + " before a scheduled run")
+				if _, err = r.Helmer.Uninstall(cfg, releaseName); err != nil {
+					log.Error().Err(err).Str(logging.ACTION, logging.DELETE).Msg("Error uninstalling release " + releaseName)
+				}
+			}
 			// Process templates with arguments
 			chart := module.Chart
 			if _, err = r.applyChartResource(ctx, cfg, chart, &module.Network, args, blueprint, releaseName, log); err != nil {
+				r.rollbackRelease(cfg, blueprint, releaseName, err.Error(), log)
 				blueprint.Status.ObservedState.Error += errors.Wrap(err, "ChartDeploymentFailure: ").Error() + "\n"
 				r.updateModuleState(blueprint, instanceName, false, err.Error())
 			} else {
@@ -356,18 +379,26 @@ func (r *BlueprintReconciler) reconcile(ctx context.Context, cfg *action.Configu
 		} else if rel != nil && rel.Info.Status == release.StatusDeployed {
 			status, errMsg := r.checkReleaseStatus(rel, uuid)
 			if status == corev1.ConditionFalse {
+				r.rollbackRelease(cfg, blueprint, releaseName, errMsg, log)
 				blueprint.Status.ObservedState.Error += "ResourceAllocationFailure: " + errMsg + "\n"
 				r.updateModuleState(blueprint, instanceName, false, errMsg)
 			} else if status == corev1.ConditionTrue {
+				// the upgrade has succeeded, and is not rolled back upon later failures
+				delete(blueprint.Status.PreviousRevisions, releaseName)
 				r.updateModuleState(blueprint, instanceName, true, "")
 				numReady++
+			} else if module.Continuous {
+				// a continuous module may become not ready again, e.g. when it falls behind the stream
+				r.updateModuleState(blueprint, instanceName, false, "")
 			}
 		}
 		blueprint.Status.Releases[releaseName] = blueprint.Status.ObservedGeneration
 	}
 	// clean-up
+	// a blue/green rollout keeps the releases of the previous generations until the new releases are ready
+	blueGreen := blueprint.Spec.RolloutStrategy == fapp.BlueGreen
 	for release, version := range blueprint.Status.Releases {
-		if version != blueprint.Status.ObservedGeneration {
+		if version != blueprint.Status.ObservedGeneration && (!blueGreen || numReady == numReleases) {
 			_, err := r.Helmer.Uninstall(cfg, release)
 			if err != nil {
 				log.Error().Err(err).Str(logging.ACTION, logging.DELETE).Msg("Error uninstalling release " + release)
@@ -376,24 +407,98 @@ func (r *BlueprintReconciler) reconcile(ctx context.Context, cfg *action.Configu
 			}
 		}
 	}
+	// a rolled back blueprint keeps reporting the failure of its generation, which is not deployed
+	for _, reason := range blueprint.Status.RolledBack {
+		blueprint.Status.ObservedState.Error += "RolloutFailure: " + reason + "\n"
+	}
+	// if an error exists it is logged in LogEnvVariables and a default value is used
+	interval, _ := environment.GetResourcesPollingInterval()
+	// continuous modules never complete, their health is monitored as long as they run
+	continuous := hasContinuousModules(blueprint)
 	// check if all releases reached the ready state
-	if numReady == numReleases {
+	if numReady == numReleases && len(blueprint.Status.RolledBack) == 0 {
 		// all modules have been orchestrated successfully - the data is ready for use
 		blueprint.Status.ObservedState.Ready = true
 		log.Info().Msg("blueprint is ready")
+		if continuous {
+			return ctrl.Result{RequeueAfter: interval}, nil
+		}
 		return ctrl.Result{}, nil
 	}
 
 	// the status is unknown yet - continue polling
-	if blueprint.Status.ObservedState.Error == "" {
+	if blueprint.Status.ObservedState.Error == "" || continuous {
 		log.Trace().Msg("blueprint.Status.ObservedState is not ready, will try again")
-		// if an error exists it is logged in LogEnvVariables and a default value is used
-		interval, _ := environment.GetResourcesPollingInterval()
 		return ctrl.Result{RequeueAfter: interval}, nil
 	}
 	return ctrl.Result{}, nil
 }
 
+// argumentsChanged returns true if the release has been deployed with different asset arguments
+func argumentsChanged(rel *release.Release, args map[string]interface{}) bool {
+	return !equality.Semantic.DeepEqual(rel.Config["assets"], args["assets"])
+}
+
+// releaseChanged returns true if the release has been deployed with a different chart or different values
+func releaseChanged(rel *release.Release, chartSpec fapp.ChartSpec, args map[string]interface{}) bool {
+	if rel.Chart == nil || rel.Chart.Metadata == nil {
+		return true
+	}
+	// the chart reference is of the form [registry/]name[:version]
+	name, version := chartSpec.Name, ""
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		name, version = name[:i], name[i+1:]
+	}
+	name = name[strings.LastIndex(name, "/")+1:]
+	if rel.Chart.Metadata.Name != name || (version != "" && rel.Chart.Metadata.Version != version) {
+		return true
+	}
+	values := CopyMap(args)
+	for k, v := range chartSpec.Values {
+		SetMapField(values, k, v)
+	}
+	return !equality.Semantic.DeepEqual(rel.Config, values)
+}
+
+// recordPreviousRevision records the revision of a release before it is upgraded, to roll back to if the upgrade fails
+func recordPreviousRevision(blueprint *fapp.Blueprint, releaseName string, revision int) {
+	if blueprint.Status.PreviousRevisions == nil {
+		blueprint.Status.PreviousRevisions = map[string]int{}
+	}
+	blueprint.Status.PreviousRevisions[releaseName] = revision
+}
+
+// rollbackRelease rolls a release that has failed after an upgrade back to its revision before the upgrade.
+// Nothing is done if the previous revision of the release has not been recorded.
+func (r *BlueprintReconciler) rollbackRelease(cfg *action.Configuration, blueprint *fapp.Blueprint, releaseName, reason string,
+	log *zerolog.Logger) {
+	revision, found := blueprint.Status.PreviousRevisions[releaseName]
+	if !found {
+		return
+	}
+	if err := r.Helmer.Rollback(cfg, releaseName, revision); err != nil {
+		log.Error().Err(err).Str(logging.ACTION, logging.UPDATE).Msg("Error rolling back release " + releaseName)
+		return
+	}
+	delete(blueprint.Status.PreviousRevisions, releaseName)
+	if blueprint.Status.RolledBack == nil {
+		blueprint.Status.RolledBack = map[string]string{}
+	}
+	blueprint.Status.RolledBack[releaseName] = fmt.Sprintf("release %s has been rolled back to revision %d: %s",
+		releaseName, revision, strings.TrimSpace(reason))
+	log.Info().Str(logging.ACTION, logging.UPDATE).Msg(blueprint.Status.RolledBack[releaseName])
+}
+
+// hasContinuousModules returns true if the blueprint deploys modules that process the data continuously
+func hasContinuousModules(blueprint *fapp.Blueprint) bool {
+	for _, module := range blueprint.Spec.Modules {
+		if module.Continuous {
+			return true
+		}
+	}
+	return false
+}
+
 // NewBlueprintReconciler creates a new reconciler for Blueprint resources
 func NewBlueprintReconciler(mgr ctrl.Manager, name string, helmer helm.Interface) *BlueprintReconciler {
 	return &BlueprintReconciler{
@@ -479,6 +584,10 @@ func (r *BlueprintReconciler) checkResourceStatus(res *unstructured.Unstructured
 	}
 	// use expected values to compute the status
 	if r.matchesCondition(res, expected.SuccessCondition, uuid) {
+		// a continuous resource that falls behind is not ready until it catches up
+		if r.exceedsMaxLag(res, expected, uuid) {
+			return corev1.ConditionUnknown, ""
+		}
 		return corev1.ConditionTrue, ""
 	}
 	if r.matchesCondition(res, expected.FailureCondition, uuid) {
@@ -496,6 +605,31 @@ func getErrorMessage(res *unstructured.Unstructured, fieldPath string) string {
 	return labelsImpl.Get(fieldPath)
 }
 
+// exceedsMaxLag returns true if the lag reported by the resource is larger than the maximal lag of a ready resource.
+// The lag is ignored if the resource does not report it.
+func (r *BlueprintReconciler) exceedsMaxLag(res *unstructured.Unstructured, expected *fapp.ResourceStatusIndicator,
+	uuid string) bool {
+	if expected.LagField == "" {
+		return false
+	}
+	labelsImpl := managerUtils.UnstructuredAsLabels{Data: res}
+	if !labelsImpl.Has(expected.LagField) {
+		return false
+	}
+	lag, err := strconv.ParseInt(labelsImpl.Get(expected.LagField), 10, 64) //nolint:revive // Ignore magic numbers
+	if err != nil {
+		r.Log.Error().Err(err).Str(managerUtils.FybrikAppUUID, uuid).
+			Msg("lag field " + expected.LagField + " of " + res.GetKind() + " is not an integer")
+		return false
+	}
+	if lag > expected.MaxLag {
+		r.Log.Debug().Str(managerUtils.FybrikAppUUID, uuid).
+			Msgf("%s %s lags by %d, the maximal lag is %d", res.GetKind(), res.GetName(), lag, expected.MaxLag)
+		return true
+	}
+	return false
+}
+
 func (r *BlueprintReconciler) matchesCondition(res *unstructured.Unstructured, condition, uuid string) bool {
 	selector, err := labels.Parse(condition)
 	if err != nil {
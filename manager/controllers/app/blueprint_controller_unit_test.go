@@ -5,12 +5,18 @@ package app
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"testing"
 
 	"github.com/onsi/gomega"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/release"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
@@ -93,6 +99,140 @@ func TestBlueprintReconcile(t *testing.T) {
 		Should(gomega.HaveKeyWithValue("notebook1234-notebook-read-module", blueprint.Status.ObservedGeneration))
 }
 
+// failingUpgradeHelmer fails the upgrades of the releases, which are deployed at revision 1
+type failingUpgradeHelmer struct {
+	*helm.Fake
+	rolledBack map[string]int
+}
+
+func (h *failingUpgradeHelmer) Status(cfg *action.Configuration, releaseName string) (*release.Release, error) {
+	return &release.Release{Name: releaseName, Version: 1, Info: &release.Info{Status: release.StatusDeployed}}, nil
+}
+
+func (h *failingUpgradeHelmer) IsInstalled(cfg *action.Configuration, releaseName string) (bool, error) {
+	return true, nil
+}
+
+func (h *failingUpgradeHelmer) Upgrade(ctx context.Context, cfg *action.Configuration, chrt *chart.Chart, kubeNamespace,
+	releaseName string, vals map[string]interface{}) (*release.Release, error) {
+	return nil, errors.New("timed out waiting for the condition")
+}
+
+func (h *failingUpgradeHelmer) Rollback(cfg *action.Configuration, releaseName string, revision int) error {
+	h.rolledBack[releaseName] = revision
+	return nil
+}
+
+// This test checks that the releases of a blueprint with the sequential rollout strategy are rolled back to
+// their previous revisions when their upgrade fails, and that the blueprint keeps reporting the failure
+func TestBlueprintRollback(t *testing.T) {
+	t.Parallel()
+	g := gomega.NewGomegaWithT(t)
+	logf.SetLogger(zap.New(zap.UseDevMode(true)))
+
+	blueprint, err := readBlueprint("../../testdata/blueprint.yaml")
+	g.Expect(err).To(gomega.BeNil(), "Cannot read blueprint file for test")
+	blueprint.Spec.ModulesNamespace = environment.GetDefaultModulesNamespace()
+	blueprint.Spec.RolloutStrategy = fapp.Sequential
+	// the blueprint has been changed after its releases have been deployed
+	blueprint.Generation = 2
+	blueprint.Status.ObservedGeneration = 1
+
+	s := utils.NewScheme(g)
+	cl := fake.NewClientBuilder().WithScheme(s).WithObjects(blueprint).Build()
+	helmer := &failingUpgradeHelmer{Fake: helm.NewEmptyFake(), rolledBack: map[string]int{}}
+	r := &BlueprintReconciler{
+		Client: cl,
+		Name:   "BlueprintTestController",
+		Log:    logging.LogInit(logging.CONTROLLER, "test-blueprint-controller"),
+		Scheme: s,
+		Helmer: helmer,
+	}
+	req := reconcile.Request{NamespacedName: client.ObjectKeyFromObject(blueprint)}
+
+	_, err = r.Reconcile(context.Background(), req)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(helmer.rolledBack).To(gomega.Equal(map[string]int{
+		"notebook1234-notebook-copy-batch":  1,
+		"notebook1234-notebook-read-module": 1,
+	}))
+	g.Expect(cl.Get(context.Background(), req.NamespacedName, blueprint)).To(gomega.Succeed())
+	g.Expect(blueprint.Status.RolledBack).To(gomega.HaveLen(2))
+	g.Expect(blueprint.Status.PreviousRevisions).To(gomega.BeEmpty())
+
+	// the rolled back releases are deployed, but the blueprint does not become ready
+	_, err = r.Reconcile(context.Background(), req)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(cl.Get(context.Background(), req.NamespacedName, blueprint)).To(gomega.Succeed())
+	g.Expect(blueprint.Status.ObservedState.Ready).To(gomega.BeFalse())
+	g.Expect(blueprint.Status.ObservedState.Error).To(gomega.ContainSubstring("RolloutFailure"))
+	g.Expect(blueprint.Status.ObservedState.Error).To(gomega.ContainSubstring("has been rolled back to revision 1"))
+}
+
+// pendingHelmer reports the releases as pending until they are ready, and records the uninstalled releases
+type pendingHelmer struct {
+	*helm.Fake
+	ready       bool
+	uninstalled []string
+}
+
+func (h *pendingHelmer) Status(cfg *action.Configuration, releaseName string) (*release.Release, error) {
+	status := release.StatusPendingInstall
+	if h.ready {
+		status = release.StatusDeployed
+	}
+	return &release.Release{Name: releaseName, Version: 1, Info: &release.Info{Status: status}}, nil
+}
+
+func (h *pendingHelmer) Uninstall(cfg *action.Configuration, releaseName string) (*release.UninstallReleaseResponse, error) {
+	h.uninstalled = append(h.uninstalled, releaseName)
+	return &release.UninstallReleaseResponse{}, nil
+}
+
+// This test checks that the releases of a previous generation of a blueprint with the blue/green rollout strategy
+// are uninstalled only once the releases of the new generation are ready
+func TestBlueprintBlueGreen(t *testing.T) {
+	t.Parallel()
+	g := gomega.NewGomegaWithT(t)
+	logf.SetLogger(zap.New(zap.UseDevMode(true)))
+
+	blueprint, err := readBlueprint("../../testdata/blueprint.yaml")
+	g.Expect(err).To(gomega.BeNil(), "Cannot read blueprint file for test")
+	blueprint.Spec.ModulesNamespace = environment.GetDefaultModulesNamespace()
+	blueprint.Spec.RolloutStrategy = fapp.BlueGreen
+	// the releases of the new generation have been installed side by side with the releases of the previous one
+	blueprint.Generation = 2
+	blueprint.Status.ObservedGeneration = 2
+	blueprint.Status.Releases = map[string]int64{"notebook1234-notebook-read-module-g1": 1}
+
+	s := utils.NewScheme(g)
+	cl := fake.NewClientBuilder().WithScheme(s).WithObjects(blueprint).Build()
+	helmer := &pendingHelmer{Fake: helm.NewEmptyFake()}
+	r := &BlueprintReconciler{
+		Client: cl,
+		Name:   "BlueprintTestController",
+		Log:    logging.LogInit(logging.CONTROLLER, "test-blueprint-controller"),
+		Scheme: s,
+		Helmer: helmer,
+	}
+	req := reconcile.Request{NamespacedName: client.ObjectKeyFromObject(blueprint)}
+
+	_, err = r.Reconcile(context.Background(), req)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(cl.Get(context.Background(), req.NamespacedName, blueprint)).To(gomega.Succeed())
+	g.Expect(blueprint.Status.ObservedState.Ready).To(gomega.BeFalse())
+	g.Expect(helmer.uninstalled).To(gomega.BeEmpty())
+	g.Expect(blueprint.Status.Releases).To(gomega.HaveKey("notebook1234-notebook-read-module-g1"))
+
+	helmer.ready = true
+	_, err = r.Reconcile(context.Background(), req)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(cl.Get(context.Background(), req.NamespacedName, blueprint)).To(gomega.Succeed())
+	g.Expect(blueprint.Status.ObservedState.Ready).To(gomega.BeTrue())
+	g.Expect(helmer.uninstalled).To(gomega.Equal([]string{"notebook1234-notebook-read-module-g1"}))
+	g.Expect(blueprint.Status.Releases).To(gomega.HaveLen(2))
+}
+
 // This test checks that a short release name is not truncated
 func TestShortReleaseName(t *testing.T) {
 	t.Parallel()
@@ -167,3 +307,33 @@ func TestLongReleaseName(t *testing.T) {
 	g.Expect(relName2).To(gomega.HavePrefix(appName + uuid))
 	g.Expect(relName2).To(gomega.HaveLen(53))
 }
+
+// This test checks that a continuous resource is not ready while its lag exceeds the maximal lag
+func TestResourceLag(t *testing.T) {
+	t.Parallel()
+	g := gomega.NewGomegaWithT(t)
+	module := &fapp.FybrikModule{}
+	g.Expect(readObjectFromFile("../../testdata/unittests/stream-module.yaml", module)).NotTo(gomega.HaveOccurred())
+	module.Namespace = environment.GetAdminCRsNamespace()
+	cl := fake.NewClientBuilder().WithScheme(utils.NewScheme(g)).WithObjects(module).Build()
+	r := &BlueprintReconciler{Client: cl, Log: logging.LogInit(logging.CONTROLLER, "test-controller")}
+
+	transfer := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "motion.fybrik.io/v1alpha1",
+		"kind":       "StreamTransfer",
+		"metadata":   map[string]interface{}{"name": "transfer"},
+		"status":     map[string]interface{}{"status": "RUNNING", "lag": int64(10)},
+	}}
+	status, _ := r.checkResourceStatus(transfer)
+	g.Expect(status).To(gomega.Equal(corev1.ConditionTrue))
+
+	// the stream falls behind
+	g.Expect(unstructured.SetNestedField(transfer.Object, int64(5000), "status", "lag")).To(gomega.Succeed())
+	status, _ = r.checkResourceStatus(transfer)
+	g.Expect(status).To(gomega.Equal(corev1.ConditionUnknown))
+
+	// a failure is reported regardless of the lag
+	g.Expect(unstructured.SetNestedField(transfer.Object, "FAILED", "status", "status")).To(gomega.Succeed())
+	status, _ = r.checkResourceStatus(transfer)
+	g.Expect(status).To(gomega.Equal(corev1.ConditionFalse))
+}
@@ -46,6 +46,8 @@ func (r *PlotterReconciler) RefineInstances(instances []ModuleInstanceSpec) []Mo
 			instance.Module.Arguments.Assets = append(instance.Module.Arguments.Assets, instances[ind].Module.Arguments.Assets...)
 			// AssetID is used for step name generation
 			instance.Module.AssetIDs = append(instance.Module.AssetIDs, instances[ind].Module.AssetIDs...)
+			instance.Module.Continuous = instance.Module.Continuous || instances[ind].Module.Continuous
+			instance.Module.Scheduled = instance.Module.Scheduled || instances[ind].Module.Scheduled
 			instanceMap[key] = instance
 		}
 	}
@@ -87,6 +89,7 @@ func (r *PlotterReconciler) GenerateBlueprint(instances []ModuleInstanceSpec,
 		Cluster:          clusterName,
 		ModulesNamespace: plotter.Spec.ModulesNamespace,
 		Modules:          map[string]fapp.BlueprintModule{},
+		RolloutStrategy:  plotter.Spec.RolloutStrategy,
 		Application: &fapp.ApplicationDetails{
 			WorkloadSelector: plotter.Spec.Selector.WorkloadSelector,
 			Namespaces:       plotter.Spec.Selector.Namespaces,
@@ -100,7 +103,7 @@ func (r *PlotterReconciler) GenerateBlueprint(instances []ModuleInstanceSpec,
 		if len(instances[ind].Module.AssetIDs) > 0 {
 			assetID = instances[ind].Module.AssetIDs[0]
 		}
-		instanceName := mngrUtils.CreateStepName(instances[ind].Module.Name, assetID, instances[ind].Scope)
+		instanceName := mngrUtils.CreateStepName(instances[ind].Module.Name, assetID, instances[ind].Scope, plotter.Spec.ReleaseSuffix)
 		releaseName := mngrUtils.GetReleaseName(mngrUtils.GetApplicationNameFromLabels(plotter.Labels),
 			mngrUtils.GetFybrikApplicationUUIDfromAnnotations(plotter.Annotations), instanceName)
 		moduleKey := UniqueReleaseName(instances[ind].ClusterName, releaseName)
@@ -213,7 +216,7 @@ func createIngressMap(plotter *fapp.Plotter, moduleInstances []ModuleInstanceSpe
 		if len(inst.Module.AssetIDs) > 0 {
 			assetID = inst.Module.AssetIDs[0]
 		}
-		instanceName := mngrUtils.CreateStepName(inst.Module.Name, assetID, inst.Scope)
+		instanceName := mngrUtils.CreateStepName(inst.Module.Name, assetID, inst.Scope, plotter.Spec.ReleaseSuffix)
 		releaseName := mngrUtils.GetReleaseName(mngrUtils.GetApplicationNameFromLabels(plotter.Labels),
 			mngrUtils.GetFybrikApplicationUUIDfromAnnotations(plotter.Annotations), instanceName)
 		moduleKey := UniqueReleaseName(inst.ClusterName, releaseName)
@@ -17,11 +17,12 @@ import (
 // - assetID: DataSetID as it appears in fybrik-application
 // - catalogID: the destination catalog identifier
 // - info: connection and credential details
+// - assetLineage: the source, steps and policies of the data flow that produced the asset, if known
 // Returns:
 // - an error if happened
 // - the new asset identifier
 func (r *FybrikApplicationReconciler) RegisterAsset(assetID string, catalogID string,
-	info *fapp.DatasetDetails, input *fapp.FybrikApplication) (string, error) {
+	info *fapp.DatasetDetails, assetLineage *datacatalog.AssetLineage, input *fapp.FybrikApplication) (string, error) {
 	r.Log.Trace().Msg("RegisterAsset")
 	details := datacatalog.ResourceDetails{}
 	if info.Details != nil {
@@ -50,6 +51,7 @@ func (r *FybrikApplicationReconciler) RegisterAsset(assetID string, catalogID st
 		Credentials:          creds,
 		DestinationCatalogID: catalogID,
 		DestinationAssetID:   assetID,
+		Lineage:              assetLineage,
 	}
 	// credentialPath is constructed even if vault is not used for credential management
 	// in order to enable the connector to get the credentials directly from the secret
@@ -0,0 +1,195 @@
+// Copyright 2023 IBM Corp.
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	"emperror.dev/errors"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8suuid "k8s.io/apimachinery/pkg/util/uuid"
+	"sigs.k8s.io/yaml"
+
+	fappv1 "fybrik.io/fybrik/manager/apis/app/v1beta1"
+	"fybrik.io/fybrik/manager/controllers/utils"
+	"fybrik.io/fybrik/pkg/logging"
+)
+
+// DryRunPath is the manager endpoint for planning a FybrikApplication without deploying it
+const DryRunPath = "/dryrun"
+
+// maximal size of a FybrikApplication sent to the dry-run endpoint
+const maxDryRunRequestSize = 1 << 20
+
+// DryRunResult is the outcome of planning a FybrikApplication without deploying it
+type DryRunResult struct {
+	// Status of the application as it would be set by the reconciler, including errors and asset states
+	Status fappv1.FybrikApplicationStatus `json:"status"`
+	// Plotter that would be generated for the application
+	Plotter *fappv1.PlotterSpec `json:"plotter,omitempty"`
+	// Blueprints that would be deployed, mapped by cluster names
+	Blueprints map[string]fappv1.BlueprintSpec `json:"blueprints,omitempty"`
+}
+
+// DryRun plans the given FybrikApplication: it validates the application, collects the asset metadata,
+// governance actions and configuration decisions, selects the data paths and generates the plotter and blueprints.
+// No Plotter or Blueprint resources are created, no storage is allocated and the application resource is not updated.
+// Errors that would be reported in the application status are returned in the result status.
+func (r *FybrikApplicationReconciler) DryRun(application *fappv1.FybrikApplication) (*DryRunResult, error) {
+	if application.UID == "" {
+		// the application has not been created, generate an identifier for logging and resource names
+		application.UID = k8suuid.NewUUID()
+	}
+	uuid := utils.GetFybrikApplicationUUID(application)
+	log := r.Log.With().Str(FybrikApplicationKind, application.Namespace+"/"+application.Name).
+		Str(utils.FybrikAppUUID, uuid).Bool("dryRun", true).Logger()
+	applicationContext := ApplicationContext{Log: &log, Application: application, UUID: uuid}
+	// the status is computed from scratch
+	application.Status = fappv1.FybrikApplicationStatus{}
+	result := &DryRunResult{}
+
+	if err := application.ValidateFybrikApplication(ApplicationTaxonomy); err != nil {
+		application.Status.ErrorMessage = err.Error()
+		application.Status.ValidApplication = v1.ConditionFalse
+		result.Status = application.Status
+		return result, nil
+	}
+	application.Status.ValidApplication = v1.ConditionTrue
+	initStatus(application)
+	application.Status.ProvisionedStorage = make(map[string]fappv1.DatasetDetails)
+
+	env, requirements, messages, err := r.collectRequirements(applicationContext)
+	if err != nil {
+		return nil, err
+	}
+	if len(requirements) == 0 {
+		result.Status = application.Status
+		return result, nil
+	}
+	provisionedStorage, plotterSpec, err := r.buildSolution(applicationContext, env, requirements, true)
+	if err != nil && application.Status.ErrorMessage == "" {
+		application.Status.ErrorMessage = err.Error()
+	}
+	if err != nil || getErrorMessages(application) != "" {
+		result.Status = application.Status
+		return result, nil
+	}
+	// the provisioned storage map of the status is empty, thus no storage is released
+	if err := r.updateProvisionedStorageStatus(applicationContext, provisionedStorage); err != nil {
+		return nil, err
+	}
+	setVirtualEndpoints(application, plotterSpec.Flows)
+	for key, val := range messages {
+		application.Status.AssetStates[key].Conditions[ReadyConditionIndex].Message = val
+	}
+
+	ownerRef := &fappv1.ResourceReference{Name: application.Name, Namespace: application.Namespace}
+	resourceRef := r.ResourceInterface.CreateResourceReference(ownerRef)
+	plotter := &fappv1.Plotter{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        resourceRef.Name,
+			Namespace:   resourceRef.Namespace,
+			Labels:      application.Labels,
+			Annotations: map[string]string{utils.FybrikAppUUID: uuid},
+		},
+		Spec: *plotterSpec,
+	}
+	plotterReconciler := &PlotterReconciler{Log: r.Log}
+	result.Plotter = plotterSpec
+	result.Blueprints = plotterReconciler.getBlueprintsMap(plotter, env.Clusters)
+	result.Status = application.Status
+	return result, nil
+}
+
+// authorizeDryRun checks that the bearer token of a dry-run request belongs to a user that is allowed to create
+// FybrikApplications in the namespace of the application, since the result reveals the connections of the assets.
+// It returns the HTTP status code of the failure.
+func (r *FybrikApplicationReconciler) authorizeDryRun(ctx context.Context, req *http.Request, namespace string) (int, error) {
+	authorization := req.Header.Get("Authorization")
+	token := strings.TrimPrefix(authorization, "Bearer ")
+	if token == "" || token == authorization {
+		return http.StatusUnauthorized, errors.New("a bearer token is required")
+	}
+	tokenReview := &authenticationv1.TokenReview{Spec: authenticationv1.TokenReviewSpec{Token: token}}
+	if err := r.Create(ctx, tokenReview); err != nil {
+		return http.StatusInternalServerError, errors.Wrap(err, "could not review the token")
+	}
+	if !tokenReview.Status.Authenticated {
+		return http.StatusUnauthorized, errors.New("the token is not valid")
+	}
+	user := tokenReview.Status.User
+	extra := make(map[string]authorizationv1.ExtraValue, len(user.Extra))
+	for key, val := range user.Extra {
+		extra[key] = authorizationv1.ExtraValue(val)
+	}
+	accessReview := &authorizationv1.SubjectAccessReview{Spec: authorizationv1.SubjectAccessReviewSpec{
+		ResourceAttributes: &authorizationv1.ResourceAttributes{
+			Namespace: namespace,
+			Verb:      "create",
+			Group:     fappv1.GroupVersion.Group,
+			Resource:  "fybrikapplications",
+		},
+		User:   user.Username,
+		Groups: user.Groups,
+		UID:    user.UID,
+		Extra:  extra,
+	}}
+	if err := r.Create(ctx, accessReview); err != nil {
+		return http.StatusInternalServerError, errors.Wrap(err, "could not review the access")
+	}
+	if !accessReview.Status.Allowed {
+		return http.StatusForbidden, errors.Errorf("user %s can not create FybrikApplications in namespace %s",
+			user.Username, namespace)
+	}
+	return http.StatusOK, nil
+}
+
+// DryRunHandler returns an HTTP handler for planning FybrikApplications.
+// The handler receives a FybrikApplication in JSON or YAML format and responds with a DryRunResult in JSON format.
+// Requests are authenticated by their bearer tokens, and the user must be allowed to create FybrikApplications
+// in the namespace of the application.
+func (r *FybrikApplicationReconciler) DryRunHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodPost {
+			http.Error(w, "only POST requests are supported", http.StatusMethodNotAllowed)
+			return
+		}
+		body, err := io.ReadAll(http.MaxBytesReader(w, req.Body, maxDryRunRequestSize))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		application := &fappv1.FybrikApplication{}
+		if err = yaml.Unmarshal(body, application); err != nil {
+			http.Error(w, errors.Wrap(err, "could not parse the FybrikApplication").Error(), http.StatusBadRequest)
+			return
+		}
+		if application.Namespace == "" {
+			http.Error(w, "the namespace of the FybrikApplication must be set", http.StatusBadRequest)
+			return
+		}
+		if code, err := r.authorizeDryRun(req.Context(), req, application.Namespace); err != nil {
+			r.Log.Warn().Err(err).Str(logging.NAMESPACE, application.Namespace).Msg("Dry run request rejected")
+			http.Error(w, err.Error(), code)
+			return
+		}
+		result, err := r.DryRun(application)
+		if err != nil {
+			r.Log.Error().Err(err).Str(logging.ACTION, "DryRun").Msg("Dry run failed")
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err = json.NewEncoder(w).Encode(result); err != nil {
+			r.Log.Error().Err(err).Msg("Could not write the dry run result")
+		}
+	})
+}
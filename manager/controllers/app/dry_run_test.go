@@ -0,0 +1,148 @@
+// Copyright 2023 IBM Corp.
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/onsi/gomega"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	fappv1 "fybrik.io/fybrik/manager/apis/app/v1beta1"
+	fappv2 "fybrik.io/fybrik/manager/apis/app/v1beta2"
+	"fybrik.io/fybrik/manager/controllers/utils"
+	"fybrik.io/fybrik/pkg/environment"
+)
+
+// reviewingClient reviews the tokens and access of dry-run requests: the token "valid" belongs to a user
+// that is allowed to create FybrikApplications in the allowed namespace
+type reviewingClient struct {
+	client.Client
+	allowedNamespace string
+}
+
+func (c *reviewingClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	switch review := obj.(type) {
+	case *authenticationv1.TokenReview:
+		review.Status.Authenticated = review.Spec.Token == "valid"
+		review.Status.User = authenticationv1.UserInfo{Username: "data-scientist"}
+		return nil
+	case *authorizationv1.SubjectAccessReview:
+		attributes := review.Spec.ResourceAttributes
+		review.Status.Allowed = review.Spec.User == "data-scientist" && attributes.Namespace == c.allowedNamespace &&
+			attributes.Verb == "create" && attributes.Resource == "fybrikapplications"
+		return nil
+	}
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+func dryRunRequest(content []byte, token string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, DryRunPath, strings.NewReader(string(content)))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return req
+}
+
+// This test sends a read scenario requiring an implicit copy to the dry-run endpoint.
+// It checks that the plotter and blueprints are returned, while no plotter is created and no storage is allocated.
+func TestDryRun(t *testing.T) {
+	t.Parallel()
+	g := gomega.NewGomegaWithT(t)
+	adminCRsNamespace := environment.GetAdminCRsNamespace()
+	s := utils.NewScheme(g)
+	cl := fake.NewFakeClientWithScheme(s)
+
+	readModule := &fappv1.FybrikModule{}
+	copyModule := &fappv1.FybrikModule{}
+	g.Expect(readObjectFromFile("../../testdata/unittests/implicit-copy-batch-module-csv.yaml", copyModule)).NotTo(gomega.HaveOccurred())
+	copyModule.Namespace = adminCRsNamespace
+	g.Expect(readObjectFromFile("../../testdata/unittests/module-read-csv.yaml", readModule)).NotTo(gomega.HaveOccurred())
+	readModule.Namespace = adminCRsNamespace
+	g.Expect(cl.Create(context.Background(), copyModule)).NotTo(gomega.HaveOccurred())
+	g.Expect(cl.Create(context.Background(), readModule)).NotTo(gomega.HaveOccurred())
+	dummySecret := &corev1.Secret{}
+	g.Expect(readObjectFromFile("../../testdata/unittests/credentials-theshire.yaml", dummySecret)).NotTo(gomega.HaveOccurred())
+	dummySecret.Namespace = adminCRsNamespace
+	g.Expect(cl.Create(context.Background(), dummySecret)).NotTo(gomega.HaveOccurred())
+	account := &fappv2.FybrikStorageAccount{}
+	g.Expect(readStorageAccountData("../../testdata/unittests/account-theshire.yaml", account)).NotTo(gomega.HaveOccurred())
+	account.Namespace = adminCRsNamespace
+	g.Expect(cl.Create(context.Background(), account)).NotTo(gomega.HaveOccurred())
+
+	r := createTestFybrikApplicationController(&reviewingClient{Client: cl, allowedNamespace: "default"}, s)
+	g.Expect(r).NotTo(gomega.BeNil())
+	handler := r.DryRunHandler()
+
+	// only POST requests are served
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, DryRunPath, http.NoBody))
+	g.Expect(recorder.Code).To(gomega.Equal(http.StatusMethodNotAllowed))
+
+	content, err := os.ReadFile("../../testdata/unittests/fybrikcopyapp-csv.yaml")
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+
+	// requests must be authenticated, and the user must be allowed to create applications in the namespace
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, dryRunRequest(content, ""))
+	g.Expect(recorder.Code).To(gomega.Equal(http.StatusUnauthorized))
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, dryRunRequest(content, "expired"))
+	g.Expect(recorder.Code).To(gomega.Equal(http.StatusUnauthorized))
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, dryRunRequest([]byte(strings.Replace(string(content), "namespace: default",
+		"namespace: finance", 1)), "valid"))
+	g.Expect(recorder.Code).To(gomega.Equal(http.StatusForbidden))
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, dryRunRequest(content, "valid"))
+	g.Expect(recorder.Code).To(gomega.Equal(http.StatusOK))
+	result := &DryRunResult{}
+	g.Expect(json.Unmarshal(recorder.Body.Bytes(), result)).NotTo(gomega.HaveOccurred())
+
+	g.Expect(result.Status.ErrorMessage).To(gomega.BeEmpty())
+	g.Expect(result.Plotter).NotTo(gomega.BeNil())
+	g.Expect(result.Plotter.Assets).To(gomega.HaveKey("s3-csv/redact-dataset-copy"))
+	g.Expect(result.Plotter.Flows).To(gomega.HaveLen(1))
+	g.Expect(result.Plotter.Flows[0].SubFlows).To(gomega.HaveLen(2))
+	g.Expect(result.Blueprints).To(gomega.HaveKey("thegreendragon"))
+	g.Expect(result.Status.ProvisionedStorage).To(gomega.HaveKey("s3-csv/redact-dataset"))
+	g.Expect(result.Status.AssetStates["s3-csv/redact-dataset"].Endpoint.Name).NotTo(gomega.BeEmpty())
+
+	// no resources have been created
+	plotters := &fappv1.PlotterList{}
+	g.Expect(cl.List(context.Background(), plotters)).NotTo(gomega.HaveOccurred())
+	g.Expect(plotters.Items).To(gomega.BeEmpty())
+	applications := &fappv1.FybrikApplicationList{}
+	g.Expect(cl.List(context.Background(), applications)).NotTo(gomega.HaveOccurred())
+	g.Expect(applications.Items).To(gomega.BeEmpty())
+}
+
+// This test checks that errors of an invalid application are reported in the status of the dry-run result
+func TestDryRunInvalidApplication(t *testing.T) {
+	t.Parallel()
+	g := gomega.NewGomegaWithT(t)
+	s := utils.NewScheme(g)
+	r := createTestFybrikApplicationController(fake.NewFakeClientWithScheme(s), s)
+	g.Expect(r).NotTo(gomega.BeNil())
+	application := &fappv1.FybrikApplication{}
+	filename := "../../testdata/unittests/fybrikapplication-appInfoErrors.yaml"
+	g.Expect(readObjectFromFile(filename, application)).NotTo(gomega.HaveOccurred())
+	result, err := r.DryRun(application)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(result.Status).To(gomega.Equal(application.Status))
+	g.Expect(getErrorMessages(application)).NotTo(gomega.BeEmpty())
+	g.Expect(result.Plotter).To(gomega.BeNil())
+	g.Expect(result.Blueprints).To(gomega.BeNil())
+}
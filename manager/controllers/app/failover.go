@@ -0,0 +1,125 @@
+// Copyright 2023 IBM Corp.
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	fappv1 "fybrik.io/fybrik/manager/apis/app/v1beta1"
+	"fybrik.io/fybrik/pkg/logging"
+)
+
+// failoverRequests holds the FybrikApplications that should be planned again because clusters that they are deployed on
+// have failed, together with the reasons of the failures by the cluster names.
+// A request is kept until a new plan has been generated for the application.
+type failoverRequests struct {
+	mutex    sync.Mutex
+	clusters map[types.NamespacedName]map[string]string
+}
+
+func (q *failoverRequests) add(key types.NamespacedName, cluster, reason string) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	if q.clusters == nil {
+		q.clusters = make(map[types.NamespacedName]map[string]string)
+	}
+	if q.clusters[key] == nil {
+		q.clusters[key] = make(map[string]string)
+	}
+	q.clusters[key][cluster] = reason
+}
+
+// get returns the failed clusters of an application and the reasons of their failures
+func (q *failoverRequests) get(key types.NamespacedName) ([]string, string, bool) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	failed, found := q.clusters[key]
+	if !found {
+		return nil, "", false
+	}
+	clusters := make([]string, 0, len(failed))
+	for cluster := range failed {
+		clusters = append(clusters, cluster)
+	}
+	sort.Strings(clusters)
+	reasons := make([]string, 0, len(clusters))
+	for _, cluster := range clusters {
+		reasons = append(reasons, failed[cluster])
+	}
+	return clusters, strings.Join(reasons, Separator), true
+}
+
+func (q *failoverRequests) remove(key types.NamespacedName) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	delete(q.clusters, key)
+}
+
+// OnClusterFailure requests a new plan for the FybrikApplications whose plotters deploy blueprints on a failed cluster.
+// The failed cluster is not returned by the cluster manager, hence the new plans use the other clusters.
+func (r *FybrikApplicationReconciler) OnClusterFailure(cluster, reason string) {
+	applications := &fappv1.FybrikApplicationList{}
+	if err := r.List(context.Background(), applications); err != nil {
+		r.Log.Error().Err(err).Msg("Could not list FybrikApplications for failover")
+		return
+	}
+	for i := range applications.Items {
+		application := &applications.Items[i]
+		generated := application.Status.Generated
+		if generated == nil {
+			continue
+		}
+		plotter := &fappv1.Plotter{}
+		if err := r.Get(context.Background(), types.NamespacedName{Namespace: generated.Namespace, Name: generated.Name},
+			plotter); err != nil {
+			if client.IgnoreNotFound(err) != nil {
+				r.Log.Error().Err(err).Str(logging.PLOTTER, generated.Name).Msg("Could not get the plotter for failover")
+			}
+			continue
+		}
+		if !deploysOn(plotter, cluster) {
+			continue
+		}
+		r.failovers.add(client.ObjectKeyFromObject(application), cluster, reason)
+		r.enqueueReconcile(application)
+	}
+}
+
+// deploysOn returns true if the plotter spec deploys modules on the given cluster.
+// Blueprints that are left in the plotter status after a new plan has moved away from the cluster are not considered,
+// so that the application is not planned again.
+func deploysOn(plotter *fappv1.Plotter, cluster string) bool {
+	for _, flow := range plotter.Spec.Flows {
+		for _, subFlow := range flow.SubFlows {
+			for _, steps := range subFlow.Steps {
+				for _, step := range steps {
+					if step.Cluster == cluster {
+						return true
+					}
+				}
+			}
+		}
+	}
+	return false
+}
+
+// failoverRequired checks whether clusters that the application is deployed on have failed.
+// If so, the failover is recorded in the status, and the application should be planned again.
+func (r *FybrikApplicationReconciler) failoverRequired(applicationContext ApplicationContext) bool {
+	clusters, reason, requested := r.failovers.get(client.ObjectKeyFromObject(applicationContext.Application))
+	if !requested {
+		return false
+	}
+	applicationContext.Log.Info().Bool(logging.FORUSER, true).Bool(logging.AUDIT, true).
+		Msg("Clusters have failed, generating a new plan without them: " + reason)
+	applicationContext.Application.Status.Failover = &fappv1.Failover{Clusters: clusters, Reason: reason, Time: metav1.Now()}
+	return true
+}
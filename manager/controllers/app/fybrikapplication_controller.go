@@ -10,34 +10,43 @@ import (
 	"net/http"
 	"os"
 	"strings"
+	"time"
 
 	"emperror.dev/errors"
 	"github.com/rs/zerolog"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	ctrl "sigs.k8s.io/controller-runtime"
+	ctrlbuilder "sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	ctrlutil "sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 
+	katalogv1alpha1 "fybrik.io/fybrik/connectors/katalog/pkg/apis/katalog/v1alpha1"
 	fappv1 "fybrik.io/fybrik/manager/apis/app/v1beta1"
 	fappv2 "fybrik.io/fybrik/manager/apis/app/v1beta2"
 	"fybrik.io/fybrik/manager/controllers"
 	"fybrik.io/fybrik/manager/controllers/utils"
 	"fybrik.io/fybrik/pkg/adminconfig"
+	"fybrik.io/fybrik/pkg/audit"
 	dcclient "fybrik.io/fybrik/pkg/connectors/datacatalog/clients"
 	pmclient "fybrik.io/fybrik/pkg/connectors/policymanager/clients"
 	storage "fybrik.io/fybrik/pkg/connectors/storagemanager/clients"
 	"fybrik.io/fybrik/pkg/datapath"
 	"fybrik.io/fybrik/pkg/environment"
 	"fybrik.io/fybrik/pkg/infrastructure"
+	"fybrik.io/fybrik/pkg/lineage"
 	"fybrik.io/fybrik/pkg/logging"
 	"fybrik.io/fybrik/pkg/model/datacatalog"
 	"fybrik.io/fybrik/pkg/model/policymanager"
@@ -62,12 +71,26 @@ type FybrikApplicationReconciler struct {
 	StorageManager    storage.StorageManagerInterface
 	ConfigEvaluator   adminconfig.EvaluatorInterface
 	Infrastructure    *infrastructure.AttributeManager
+	Lineage           lineage.Emitter
+	Audit             *audit.Auditor
+	// ReevaluationTimeout is how long the governance decisions of an application can fail to be re-evaluated
+	// before its data paths are removed. The data paths are kept if it is 0.
+	ReevaluationTimeout time.Duration
+
+	// applications whose governance decisions should be re-evaluated
+	reevaluations reevaluationRequests
+	// events that trigger reconciles of the applications to re-evaluate
+	reevaluationEvents chan event.GenericEvent
+	// applications that should be planned again because clusters that they are deployed on have failed
+	failovers failoverRequests
 }
 
 type ApplicationContext struct {
 	Log         *zerolog.Logger
 	Application *fappv1.FybrikApplication
 	UUID        string
+	// Audit records the decisions of reconciles; it is nil for dry runs and offline plans, which are not audited
+	Audit *audit.Auditor
 }
 
 var ApplicationTaxonomy = environment.GetDataDir() + "/taxonomy/fybrik_application.json"
@@ -90,13 +113,14 @@ const (
 	InsufficientStorage         string = "no bucket was provisioned for implicit copy"
 	InvalidClusterConfiguration string = "cluster configuration does not support the requirements"
 	NoDeployedModules           string = "There are no deployed modules in the environment"
+	UnknownActionColumn         string = "governance action %s refers to column %s which is not in the schema of the asset"
 )
 
 // Reconcile reconciles FybrikApplication CRD
 // It receives FybrikApplication CRD and selects the appropriate modules that will run
 // The outcome is a Plotter containing multiple Blueprints that run on different clusters
 //
-//nolint:gocyclo
+//nolint:funlen,gocyclo
 func (r *FybrikApplicationReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	sublog := r.Log.With().Str(FybrikApplicationKind, req.NamespacedName.String()).Logger()
 
@@ -113,6 +137,8 @@ func (r *FybrikApplicationReconciler) Reconcile(ctx context.Context, req ctrl.Re
 	application := &fappv1.FybrikApplication{}
 	if err := r.Get(ctx, nsName, application); err != nil {
 		sublog.Warn().Msg("The reconciled object was not found")
+		r.reevaluations.take(nsName)
+		r.failovers.remove(nsName)
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 
@@ -121,7 +147,7 @@ func (r *FybrikApplicationReconciler) Reconcile(ctx context.Context, req ctrl.Re
 
 	// Log the fybrikapplication
 	logging.LogStructure(FybrikApplicationKind, application, &log, zerolog.TraceLevel, true, true)
-	applicationContext := ApplicationContext{Log: &log, Application: application, UUID: uuid}
+	applicationContext := ApplicationContext{Log: &log, Application: application, UUID: uuid, Audit: r.Audit}
 	if plotterUpdate && (application.Status.Generated == nil || application.Status.Generated.AppVersion != application.GetGeneration()) {
 		// plotter update has been received but it does not match the fybrik application status
 		// this can happen if the plotter has just been created, and the application status was not updated by the server
@@ -156,8 +182,11 @@ func (r *FybrikApplicationReconciler) Reconcile(ctx context.Context, req ctrl.Re
 	}
 
 	// check if reconcile is required
-	// reconcile is required if the spec has been changed, or the previous reconcile has failed to allocate a Plotter resource
+	// reconcile is required if the spec has been changed, the previous reconcile has failed to allocate a Plotter resource,
+	// clusters that the application is deployed on have failed, a re-evaluation has changed the governance decisions,
+	// or a scheduled copy is due
 	generationComplete := observedStatus.Generated != nil && (observedStatus.Generated.AppVersion == appVersion)
+	specChanged := (observedStatus.ObservedGeneration != appVersion) || !generationComplete
 	if plotterUpdate {
 		// check plotter status and update the application status accordingly
 		resourceStatus, err := r.ResourceInterface.GetResourceStatus(application.Status.Generated)
@@ -165,17 +194,22 @@ func (r *FybrikApplicationReconciler) Reconcile(ctx context.Context, req ctrl.Re
 			return ctrl.Result{}, err
 		}
 		r.checkReadiness(applicationContext, resourceStatus)
-	} else if (observedStatus.ObservedGeneration != appVersion) || !generationComplete {
-		// spec has been changed, or there was a failure to allocate a plotter
-		if result, err := r.reconcile(applicationContext); err != nil || result.Requeue || (result.RequeueAfter > 0) {
+	} else if failover, reevaluation := r.replanRequests(applicationContext, specChanged); specChanged || failover || reevaluation ||
+		r.scheduledCopiesDue(applicationContext) || r.temporaryStorageExpired(applicationContext) {
+		// spec has been changed, there was a failure to allocate a plotter, clusters have failed, the governance decisions
+		// have changed, a scheduled copy is due, or temporary storage has expired
+		if result, err := r.replan(applicationContext, reevaluation, failover); err != nil || result.Requeue || (result.RequeueAfter > 0) {
 			// another attempt will be done
 			// users should be informed in case of errors
 			// ignore an update error, a new reconcile will be made in any case
 			_ = utils.UpdateStatus(ctx, r.Client, application, observedStatus)
 			return result, err
 		}
+		// the new plan does not use the failed clusters
+		r.failovers.remove(nsName)
 		application.Status.ObservedGeneration = appVersion
 	}
+	r.pruneScheduledCopies(applicationContext)
 	application.Status.Ready = isReady(application)
 	log.Trace().Str(logging.ACTION, logging.UPDATE).Msg("Updating status for desired generation " + fmt.Sprint(application.GetGeneration()))
 	if err := utils.UpdateStatus(ctx, r.Client, application, observedStatus); err != nil {
@@ -192,13 +226,17 @@ func (r *FybrikApplicationReconciler) Reconcile(ctx context.Context, req ctrl.Re
 		// trigger a new reconcile
 		return ctrl.Result{Requeue: true}, nil
 	}
-	return ctrl.Result{}, nil
+	// reconcile again upon the next scheduled copy or expiration of storage
+	return ctrl.Result{RequeueAfter: nextScheduledEvent(application, time.Now())}, nil
 }
 
 func (r *FybrikApplicationReconciler) checkReadiness(applicationContext ApplicationContext, status fappv1.ObservedState) {
 	if applicationContext.Application.Status.AssetStates == nil {
 		initStatus(applicationContext.Application)
 	}
+	if status.Ready {
+		r.switchEndpoints(applicationContext)
+	}
 
 	// TODO(shlomitk1): receive status per asset and update accordingly
 	// Temporary fix: all assets that are not in Deny state are updated based on the received status
@@ -213,8 +251,11 @@ func (r *FybrikApplicationReconciler) checkReadiness(applicationContext Applicat
 			continue
 		}
 		if !status.Ready {
+			// a continuous flow, e.g. a stream ingestion, is not ready anymore while it falls behind
+			applicationContext.Application.Status.AssetStates[assetID].Conditions[ReadyConditionIndex].Status = v1.ConditionFalse
 			continue
 		}
+		records, plotterGeneration := r.newLineageRecords(applicationContext, assetID)
 
 		// register assets if the ready state has been received
 		if dataCtx.Requirements.FlowParams.Catalog != "" {
@@ -246,7 +287,7 @@ func (r *FybrikApplicationReconciler) checkReadiness(applicationContext Applicat
 			applicationContext.Application.Status.ProvisionedStorage[assetID] = provisioned
 			// register the asset
 			if newAssetID, err := r.RegisterAsset(assetID, dataCtx.Requirements.FlowParams.Catalog,
-				&provisioned, applicationContext.Application); err == nil {
+				&provisioned, registeredAssetLineage(records), applicationContext.Application); err == nil {
 				state := applicationContext.Application.Status.AssetStates[assetID]
 				state.CatalogedAsset = newAssetID
 				applicationContext.Application.Status.AssetStates[assetID] = state
@@ -257,6 +298,7 @@ func (r *FybrikApplicationReconciler) checkReadiness(applicationContext Applicat
 			}
 		}
 		setReadyCondition(applicationContext, assetID)
+		r.emitLineage(applicationContext, assetID, records, plotterGeneration)
 	}
 }
 
@@ -310,7 +352,7 @@ func (r *FybrikApplicationReconciler) deleteExternalResources(applicationContext
 	for datasetID, datasetDetails := range applicationContext.Application.Status.ProvisionedStorage {
 		var err error
 		if !datasetDetails.Persistent {
-			err = r.deleteTemporaryStorage(datasetDetails)
+			err = r.deleteTemporaryStorage(applicationContext, datasetID, datasetDetails)
 		}
 		if err != nil {
 			errMsgs = append(errMsgs, err.Error())
@@ -318,13 +360,21 @@ func (r *FybrikApplicationReconciler) deleteExternalResources(applicationContext
 			deletedKeys = append(deletedKeys, datasetID)
 		}
 	}
+	owner := client.ObjectKeyFromObject(applicationContext.Application).String()
 	for _, datasetID := range deletedKeys {
+		if err := releaseStorage(r.Client, owner, datasetID); err != nil {
+			return err
+		}
 		delete(applicationContext.Application.Status.ProvisionedStorage, datasetID)
 	}
 	if len(errMsgs) != 0 {
 		return errors.New(strings.Join(errMsgs, Separator))
 	}
-	// delete the generated resource
+	return r.deleteGeneratedResource(applicationContext)
+}
+
+// deleteGeneratedResource deletes the resource generated by the current plan of the application
+func (r *FybrikApplicationReconciler) deleteGeneratedResource(applicationContext ApplicationContext) error {
 	if applicationContext.Application.Status.Generated == nil {
 		return nil
 	}
@@ -335,6 +385,8 @@ func (r *FybrikApplicationReconciler) deleteExternalResources(applicationContext
 		return err
 	}
 	applicationContext.Application.Status.Generated = nil
+	// the generations of a new plotter start over
+	applicationContext.Application.Status.LineageGenerations = nil
 	return nil
 }
 
@@ -363,6 +415,58 @@ func setVirtualEndpoints(application *fappv1.FybrikApplication, flows []fappv1.F
 	}
 }
 
+// currentEndpoints returns the endpoints in the status of the fybrikapplication
+func currentEndpoints(application *fappv1.FybrikApplication) map[string]taxonomy.Connection {
+	endpoints := map[string]taxonomy.Connection{}
+	for assetID, state := range application.Status.AssetStates {
+		if state.Endpoint.Name != "" {
+			endpoints[assetID] = state.Endpoint
+		}
+	}
+	return endpoints
+}
+
+// keepEndpoints restores the endpoints that the workload uses, until the new data plane of a blue/green rollout is ready
+func keepEndpoints(application *fappv1.FybrikApplication, endpoints map[string]taxonomy.Connection) {
+	if rolloutStrategy(application) != fappv1.BlueGreen {
+		return
+	}
+	for assetID, endpoint := range endpoints {
+		if state, found := application.Status.AssetStates[assetID]; found && state.Endpoint.Name != "" {
+			state.Endpoint = endpoint
+			application.Status.AssetStates[assetID] = state
+		}
+	}
+}
+
+// switchEndpoints sets the endpoints of a blue/green rollout once the plotter generation is ready
+func (r *FybrikApplicationReconciler) switchEndpoints(applicationContext ApplicationContext) {
+	if rolloutStrategy(applicationContext.Application) != fappv1.BlueGreen {
+		return
+	}
+	if plotter := r.readyPlotter(applicationContext); plotter != nil {
+		setVirtualEndpoints(applicationContext.Application, plotter.Spec.Flows)
+	}
+}
+
+// readyPlotter returns the plotter of the application if its current generation is ready, or nil otherwise
+func (r *FybrikApplicationReconciler) readyPlotter(applicationContext ApplicationContext) *fappv1.Plotter {
+	application := applicationContext.Application
+	if application.Status.Generated == nil {
+		return nil
+	}
+	plotter := &fappv1.Plotter{}
+	key := types.NamespacedName{Namespace: application.Status.Generated.Namespace, Name: application.Status.Generated.Name}
+	if err := r.Get(context.Background(), key, plotter); err != nil {
+		applicationContext.Log.Warn().Err(err).Msg("Could not get the plotter")
+		return nil
+	}
+	if plotter.Status.ObservedGeneration != plotter.Generation || !plotter.Status.ObservedState.Ready {
+		return nil
+	}
+	return plotter
+}
+
 // reconcile receives either FybrikApplication CRD
 // or a status update from the generated resource
 func (r *FybrikApplicationReconciler) reconcile(applicationContext ApplicationContext) (ctrl.Result, error) {
@@ -371,46 +475,36 @@ func (r *FybrikApplicationReconciler) reconcile(applicationContext ApplicationCo
 
 	// Data User created or updated the FybrikApplication
 
+	// a blue/green rollout keeps the current endpoints until the new data plane is ready
+	endpoints := currentEndpoints(applicationContext.Application)
 	// clear status
 	initStatus(applicationContext.Application)
 	if applicationContext.Application.Status.ProvisionedStorage == nil {
 		applicationContext.Application.Status.ProvisionedStorage = make(map[string]fappv1.DatasetDetails)
 	}
 
-	// create a list of requirements for creating a data flow (actions, interface to app, data format) per a single data set
-	env, err := r.Environment()
+	env, requirements, messages, err := r.collectRequirements(applicationContext)
 	if err != nil {
 		return ctrl.Result{}, err
 	}
-	// workload cluster is common for all datasets in the given application
-	workloadCluster, err := r.GetWorkloadCluster(applicationContext, env)
+	digest, err := r.governanceDigest(applicationContext.Application, requirements)
 	if err != nil {
-		// fatal
-		applicationContext.Log.Info().Err(err).Bool(logging.FORUSER, true).Bool(logging.AUDIT, true).
-			Str(logging.ACTION, logging.CREATE).Msg("Could not determine in which cluster the workload runs")
 		return ctrl.Result{}, err
 	}
-	var requirements []datapath.DataInfo
-	// messages from the connectors
-	messages := map[string]string{}
-	for _, dataset := range applicationContext.Application.Spec.Data {
-		req := datapath.DataInfo{
-			Context:             dataset.DeepCopy(),
-			DataDetails:         &datacatalog.GetAssetResponse{},
-			StorageRequirements: make(map[taxonomy.ProcessingLocation][]taxonomy.Action),
-		}
-		if messages[req.Context.DataSetID], err = r.constructDataInfo(&req, applicationContext, workloadCluster, env); err != nil {
-			AnalyzeError(applicationContext, req.Context.DataSetID, err)
-			continue
-		}
-		requirements = append(requirements, req)
-	}
 	// check if can proceed
 	if len(requirements) == 0 {
+		if getErrorMessages(applicationContext.Application) != "" {
+			return ctrl.Result{}, nil
+		}
+		// access to all the datasets is denied - resources generated by a previous plan are removed
+		if err := r.deleteExternalResources(applicationContext); err != nil {
+			return ctrl.Result{}, err
+		}
+		governanceEvaluated(applicationContext.Application, digest)
 		return ctrl.Result{}, nil
 	}
 
-	provisionedStorage, plotterSpec, err := r.buildSolution(applicationContext, env, requirements)
+	provisionedStorage, plotterSpec, err := r.buildSolution(applicationContext, env, requirements, false)
 	if err != nil {
 		applicationContext.Log.Error().Err(err).Bool(logging.FORUSER, true).Bool(logging.AUDIT, true).Msg("Plotter construction failed")
 	}
@@ -423,6 +517,7 @@ func (r *FybrikApplicationReconciler) reconcile(applicationContext ApplicationCo
 		return ctrl.Result{}, err
 	}
 	setVirtualEndpoints(applicationContext.Application, plotterSpec.Flows)
+	keepEndpoints(applicationContext.Application, endpoints)
 	ownerRef := &fappv1.ResourceReference{
 		Name:       applicationContext.Application.Name,
 		Namespace:  applicationContext.Application.Namespace,
@@ -439,6 +534,7 @@ func (r *FybrikApplicationReconciler) reconcile(applicationContext ApplicationCo
 		return ctrl.Result{}, err
 	}
 	applicationContext.Application.Status.Generated = resourceRef
+	governanceEvaluated(applicationContext.Application, digest)
 	applicationContext.Log.Trace().Str(logging.ACTION, logging.CREATE).Msgf("Created %s successfully!", resourceRef.Kind)
 	// propagating connector messages to the status
 	for key, val := range messages {
@@ -447,6 +543,71 @@ func (r *FybrikApplicationReconciler) reconcile(applicationContext ApplicationCo
 	return ctrl.Result{}, nil
 }
 
+// collectRequirements creates a list of requirements for creating a data flow (actions, interface to app, data format)
+// per a single data set. Datasets whose requirements cannot be collected are marked with an error in the application status.
+// It also returns messages from the connectors per data set.
+func (r *FybrikApplicationReconciler) collectRequirements(applicationContext ApplicationContext) (*datapath.Environment,
+	[]datapath.DataInfo, map[string]string, error) {
+	env, err := r.Environment()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	// storage allocated for the application is replaced by the storage of its new data paths
+	owner := client.ObjectKeyFromObject(applicationContext.Application).String()
+	for _, account := range env.StorageAccounts {
+		account.Status.Release(owner, "")
+	}
+	// workload cluster is common for all datasets in the given application
+	workloadCluster, err := r.GetWorkloadCluster(applicationContext, env)
+	if err != nil {
+		// fatal
+		applicationContext.Log.Info().Err(err).Bool(logging.FORUSER, true).Bool(logging.AUDIT, true).
+			Str(logging.ACTION, logging.CREATE).Msg("Could not determine in which cluster the workload runs")
+		return nil, nil, nil, err
+	}
+	var assets []datapath.DataInfo
+	var evaluatorInputs []*adminconfig.EvaluatorInput
+	// messages from the connectors
+	messages := map[string]string{}
+	for _, dataset := range applicationContext.Application.Spec.Data {
+		req := datapath.DataInfo{
+			Context:             dataset.DeepCopy(),
+			DataDetails:         &datacatalog.GetAssetResponse{},
+			StorageRequirements: make(map[taxonomy.ProcessingLocation][]taxonomy.Action),
+			StoragePolicies:     make(map[taxonomy.ProcessingLocation][]string),
+			StorageDecisionIDs:  make(map[taxonomy.ProcessingLocation]string),
+		}
+		configEvaluatorInput, catalogMsg, err := r.constructDataInfo(&req, applicationContext, workloadCluster)
+		if err != nil {
+			messages[req.Context.DataSetID] = ""
+			AnalyzeError(applicationContext, req.Context.DataSetID, err)
+			continue
+		}
+		messages[req.Context.DataSetID] = catalogMsg
+		assets = append(assets, req)
+		evaluatorInputs = append(evaluatorInputs, configEvaluatorInput)
+	}
+	// the governance actions of the data flows of all the datasets are requested at once
+	decisions := r.lookupDataFlowDecisions(assets, evaluatorInputs, applicationContext)
+	var requirements []datapath.DataInfo
+	for i := range assets {
+		req := &assets[i]
+		governanceMsg, err := r.checkGovernanceActions(evaluatorInputs[i], req, &decisions[i], applicationContext, env)
+		if err == nil {
+			err = r.evaluateConfigPolicies(evaluatorInputs[i], req, applicationContext)
+		}
+		if err != nil {
+			messages[req.Context.DataSetID] = ""
+			AnalyzeError(applicationContext, req.Context.DataSetID, err)
+			continue
+		}
+		// info has been collected successfully - keep messages from the catalog and policy manager
+		messages[req.Context.DataSetID] = strings.TrimPrefix(messages[req.Context.DataSetID]+Separator+governanceMsg, Separator)
+		requirements = append(requirements, *req)
+	}
+	return env, requirements, messages, nil
+}
+
 func (r *FybrikApplicationReconciler) Environment() (*datapath.Environment, error) {
 	// get deployed modules
 	moduleMap, err := r.GetAllModules()
@@ -521,23 +682,20 @@ func (r *FybrikApplicationReconciler) ValidateAssetResponse(response interface{}
 		datasetID, allErrs)
 }
 
-// constructDataInfo collects the following information about the asset:
-// - asset metadata
-// - governance actions to be performed on the data
-// - potential governance actions in case of caching the asset in a specific location
-// - decisions after evaluating config policies
-// The function returns an error received in the process of communication with connectors or evaluating policies
-// It also returns messages from data catalog and/or policy manager
+// constructDataInfo collects the asset metadata, and prepares the input for evaluating the config policies on the asset.
+// The governance actions and the config policy decisions are collected later, once the metadata of all the assets is known.
+// The function returns an error received in the process of communication with the data catalog.
+// It also returns the message from the data catalog
 // to be propagated to the application status (relevant for the ready state of the asset)
 func (r *FybrikApplicationReconciler) constructDataInfo(req *datapath.DataInfo, appContext ApplicationContext,
-	workloadCluster multicluster.Cluster, env *datapath.Environment) (string, error) {
+	workloadCluster multicluster.Cluster) (*adminconfig.EvaluatorInput, string, error) {
 	// Call the DataCatalog service to get info about the dataset
 	input := appContext.Application
 	log := appContext.Log.With().Str(logging.DATASETID, req.Context.DataSetID).Logger()
 	var err error
-	// retrieve and propagate messages from catalog and policy manager
+	// retrieve and propagate the message from the catalog
 	// if there are no errors to construct the data plane
-	var catalogMsg, governanceMsg string
+	var catalogMsg string
 	if !req.Context.Requirements.FlowParams.IsNewDataSet {
 		var credentialPath string
 		if input.Spec.SecretRef != "" {
@@ -554,14 +712,14 @@ func (r *FybrikApplicationReconciler) constructDataInfo(req *datapath.DataInfo,
 		if response, err = r.DataCatalog.GetAssetInfo(&request, credentialPath); err != nil {
 			log.Error().Err(err).Msg("failed to receive the catalog connector response")
 			// return the error from the data catalog
-			return "", err
+			return nil, "", err
 		}
 
 		err = r.ValidateAssetResponse(response, DataCatalogGetAssetResponseTaxonomy, req.Context.DataSetID)
 		if err != nil {
 			log.Error().Err(err).Msg("failed to validate the catalog connector response")
 			// return the error from the schema validator
-			return "", err
+			return nil, "", err
 		}
 		logging.LogStructure("Catalog connector response", response, &log, zerolog.DebugLevel, false, false)
 		catalogMsg = response.Message
@@ -575,64 +733,121 @@ func (r *FybrikApplicationReconciler) constructDataInfo(req *datapath.DataInfo,
 	input.Spec.AppInfo.DeepCopyInto(&configEvaluatorInput.Workload.Properties)
 	configEvaluatorInput.Workload.Cluster = workloadCluster
 	configEvaluatorInput.Request = CreateDataRequest(input, req.Context, &req.DataDetails.ResourceMetadata)
+	return configEvaluatorInput, catalogMsg, nil
+}
 
-	// Governance actions
-	governanceMsg, err = r.checkGovernanceActions(configEvaluatorInput, req, appContext, env)
-	if err != nil {
-		// return the error received from the policy manager, or generated by Fybrik in case of Deny
-		// the error is extended with an additional message from the policy manager
-		// which may help to understand the reason for the denial
-		return "", err
-	}
+// evaluateConfigPolicies evaluates the config policies on the asset, and records their decisions in the requirements
+func (r *FybrikApplicationReconciler) evaluateConfigPolicies(configEvaluatorInput *adminconfig.EvaluatorInput,
+	req *datapath.DataInfo, appContext ApplicationContext) error {
 	configDecisions, err := r.ConfigEvaluator.Evaluate(configEvaluatorInput)
 	if err != nil {
-		appContext.Log.Error().Err(err).Msg("Error evaluating config policies")
+		appContext.Log.Error().Err(err).Str(logging.DATASETID, req.Context.DataSetID).Msg("Error evaluating config policies")
 		// return the error from the config policy evaluator
-		return "", err
+		return err
 	}
 	logging.LogStructure("Config Policy Decisions", configDecisions, appContext.Log, zerolog.DebugLevel, false, false)
+	auditConfigurationDecision(appContext, req.Context.DataSetID, &configDecisions)
 	req.WorkloadCluster = configEvaluatorInput.Workload.Cluster
 	req.Configuration = configDecisions
-	// info has been collected successfully - return messages from the catalog and policy manager
-	msg := strings.TrimPrefix(catalogMsg+Separator+governanceMsg, Separator)
-	return msg, nil
+	return nil
 }
 
-// checkGovernanceActions consults the policy manager to retrieve:
-// - the governance actions to be performed on the asset
-// - the potential governance actions to be performed in case of caching to a specific location
-// The latter is relevant only if caching to the chosen location takes place
-// The function returns a message delegated by the policy manager (when no error is received),
-// or the error, in which case the first return value is empty.
-func (r *FybrikApplicationReconciler) checkGovernanceActions(configEvaluatorInput *adminconfig.EvaluatorInput,
-	req *datapath.DataInfo, appContext ApplicationContext, env *datapath.Environment) (string, error) {
-	var err error
-	var msg string
+// dataFlowOperation returns the operation on the asset that requires a policy decision, or nil if no decision is required
+func dataFlowOperation(configEvaluatorInput *adminconfig.EvaluatorInput, req *datapath.DataInfo) *policymanager.RequestAction {
+	region := configEvaluatorInput.Workload.Cluster.Metadata.Region
 	switch configEvaluatorInput.Request.Usage {
 	case taxonomy.WriteFlow:
-		if !req.Context.Requirements.FlowParams.IsNewDataSet {
-			// update an existing dataset
-			// query the policy manager whether the operation is allowed
-			reqAction := policymanager.RequestAction{
-				ActionType:         configEvaluatorInput.Request.Usage,
-				Destination:        req.DataDetails.ResourceMetadata.Geography,
-				ProcessingLocation: taxonomy.ProcessingLocation(configEvaluatorInput.Workload.Cluster.Metadata.Region),
-			}
-			req.Actions, msg, err = LookupPolicyDecisions(req.Context.DataSetID, &req.DataDetails.ResourceMetadata,
-				r.PolicyManager, appContext, &reqAction)
+		if req.Context.Requirements.FlowParams.IsNewDataSet {
+			return nil
+		}
+		// update an existing dataset
+		// query the policy manager whether the operation is allowed
+		return &policymanager.RequestAction{
+			ActionType:         configEvaluatorInput.Request.Usage,
+			Destination:        req.DataDetails.ResourceMetadata.Geography,
+			ProcessingLocation: taxonomy.ProcessingLocation(region),
 		}
 	case taxonomy.ReadFlow, taxonomy.DeleteFlow:
-		reqAction := policymanager.RequestAction{
+		return &policymanager.RequestAction{
 			ActionType:         configEvaluatorInput.Request.Usage,
-			Destination:        configEvaluatorInput.Workload.Cluster.Metadata.Region,
-			ProcessingLocation: taxonomy.ProcessingLocation(configEvaluatorInput.Workload.Cluster.Metadata.Region),
+			Destination:        region,
+			ProcessingLocation: taxonomy.ProcessingLocation(region),
 		}
-		req.Actions, msg, err = LookupPolicyDecisions(req.Context.DataSetID, &req.DataDetails.ResourceMetadata,
-			r.PolicyManager, appContext, &reqAction)
 	}
+	return nil
+}
+
+// lookupDataFlowDecisions requests the governance actions of the data flows of all the assets from the policy manager
+// in a single call. The decisions are returned in the order of the assets.
+// Assets whose flow requires no decision, such as writing a new dataset, get an empty decision.
+// If the policy manager could not be called, its error is reported in the decisions of all the flows.
+func (r *FybrikApplicationReconciler) lookupDataFlowDecisions(assets []datapath.DataInfo,
+	configEvaluatorInputs []*adminconfig.EvaluatorInput, appContext ApplicationContext) []PolicyDecision {
+	decisions := make([]PolicyDecision, len(assets))
+	requests := []PolicyDecisionRequest{}
+	// index of the asset of each request
+	indices := []int{}
+	for i := range assets {
+		operation := dataFlowOperation(configEvaluatorInputs[i], &assets[i])
+		if operation == nil {
+			continue
+		}
+		requests = append(requests, PolicyDecisionRequest{
+			DatasetID:        assets[i].Context.DataSetID,
+			ResourceMetadata: &assets[i].DataDetails.ResourceMetadata,
+			Operation:        *operation,
+		})
+		indices = append(indices, i)
+	}
+	results, err := LookupPolicyDecisionsOfDatasets(requests, r.PolicyManager, appContext)
+	for j, i := range indices {
+		if err != nil {
+			decisions[i] = PolicyDecision{Err: err}
+		} else {
+			decisions[i] = results[j]
+		}
+	}
+	return decisions
+}
+
+// checkGovernanceActions applies the policy decision on the data flow of the asset and consults the policy manager to retrieve
+// the potential governance actions to be performed in case of caching to a specific location.
+// The latter is relevant only if caching to the chosen location takes place
+// The function returns a message delegated by the policy manager (when no error is received),
+// or the error, in which case the first return value is empty.
+func (r *FybrikApplicationReconciler) checkGovernanceActions(configEvaluatorInput *adminconfig.EvaluatorInput,
+	req *datapath.DataInfo, decision *PolicyDecision, appContext ApplicationContext, env *datapath.Environment) (string, error) {
+	req.Actions, req.Policies = decision.Actions, decision.Policies
+	req.DecisionIDs = appendPolicy(nil, decision.DecisionID)
+	msg, err := decision.Message, decision.Err
 	if err != nil {
 		return "", err
 	}
+	if err = validateActionColumns(req.Actions, &req.DataDetails.ResourceMetadata); err != nil {
+		return "", err
+	}
+	if err = r.lookupStorageRequirements(req, appContext, env); err != nil {
+		return "", err
+	}
+	accountRequired := (req.Context.Requirements.FlowParams.IsNewDataSet && configEvaluatorInput.Request.Usage == taxonomy.WriteFlow) ||
+		(configEvaluatorInput.Request.Usage == taxonomy.CopyFlow) || (configEvaluatorInput.Request.Usage == taxonomy.StreamFlow)
+	// no account is defined, return an error for write, copy and stream flows
+	if len(env.StorageAccounts) == 0 && accountRequired {
+		return "", errors.New(StorageAccountUndefined)
+	}
+	// write is denied to all accounts, return Deny for write, copy and stream flows
+	if len(req.StorageRequirements) == 0 && accountRequired {
+		return "", errors.New(WriteNotAllowed)
+	}
+	// no errors - return the message from the policy manager
+	return msg, nil
+}
+
+// lookupStorageRequirements consults the policy manager whether the asset may be written to each storage account,
+// and records the governance actions to be performed in case of writing to the allowed ones.
+// The decisions for all the accounts are requested at once.
+func (r *FybrikApplicationReconciler) lookupStorageRequirements(req *datapath.DataInfo, appContext ApplicationContext,
+	env *datapath.Environment) error {
 	var resMetadata *datacatalog.ResourceMetadata
 	// query the policy manager whether WRITE operation is allowed
 	if req.Context.Requirements.FlowParams.IsNewDataSet {
@@ -647,35 +862,33 @@ func (r *FybrikApplicationReconciler) checkGovernanceActions(configEvaluatorInpu
 		// Use the existing resource metadata if the asset is not new
 		resMetadata = &req.DataDetails.ResourceMetadata
 	}
+	// get governance actions to consider only if a copy will be made to the storage accounts
+	reqActions := []policymanager.RequestAction{}
 	for accountInd := range env.StorageAccounts {
 		geo := env.StorageAccounts[accountInd].Spec.Geography
-		reqAction := policymanager.RequestAction{
+		reqActions = append(reqActions, policymanager.RequestAction{
 			ActionType:         taxonomy.WriteFlow,
 			Destination:        string(geo),
 			ProcessingLocation: geo,
-		}
-		// get governance actions to consider only if a copy will be made to this destination
-		// messages from the policy manager are disregarded
-		actions, _, err := LookupPolicyDecisions(req.Context.DataSetID, resMetadata, r.PolicyManager, appContext, &reqAction)
-		if err == nil {
-			req.StorageRequirements[geo] = actions
-		} else if err.Error() != WriteNotAllowed {
-			// received an error from the connector
-			return "", err
-		}
+		})
 	}
-	accountRequired := (req.Context.Requirements.FlowParams.IsNewDataSet && configEvaluatorInput.Request.Usage == taxonomy.WriteFlow) ||
-		(configEvaluatorInput.Request.Usage == taxonomy.CopyFlow)
-	// no account is defined, return an error for write and copy flows
-	if len(env.StorageAccounts) == 0 && accountRequired {
-		return "", errors.New(StorageAccountUndefined)
+	decisions, err := LookupPolicyDecisionsBatch(req.Context.DataSetID, resMetadata, r.PolicyManager, appContext, reqActions)
+	if err != nil {
+		// received an error from the connector
+		return err
 	}
-	// write is denied to all accounts, return Deny for write and copy flows
-	if len(req.StorageRequirements) == 0 && accountRequired {
-		return "", errors.New(WriteNotAllowed)
+	for i := range decisions {
+		// messages from the policy manager are disregarded
+		if decisions[i].Err == nil {
+			req.StorageRequirements[reqActions[i].ProcessingLocation] = decisions[i].Actions
+			req.StoragePolicies[reqActions[i].ProcessingLocation] = decisions[i].Policies
+			req.StorageDecisionIDs[reqActions[i].ProcessingLocation] = decisions[i].DecisionID
+		} else if decisions[i].Err.Error() != WriteNotAllowed {
+			// received an invalid response from the connector
+			return decisions[i].Err
+		}
 	}
-	// no errors - return the message from the policy manager
-	return msg, nil
+	return nil
 }
 
 // GetWorkloadCluster returns a workload cluster
@@ -706,7 +919,7 @@ func (r *FybrikApplicationReconciler) GetWorkloadCluster(appContext ApplicationC
 func NewFybrikApplicationReconciler(mgr ctrl.Manager, name string,
 	policyManager pmclient.PolicyManager, catalog dcclient.DataCatalog, cm multicluster.ClusterLister,
 	storageManager storage.StorageManagerInterface, evaluator adminconfig.EvaluatorInterface,
-	attributeManager *infrastructure.AttributeManager) *FybrikApplicationReconciler {
+	attributeManager *infrastructure.AttributeManager, auditor *audit.Auditor) *FybrikApplicationReconciler {
 	log := logging.LogInit(logging.CONTROLLER, name)
 	return &FybrikApplicationReconciler{
 		Client:            mgr.GetClient(),
@@ -720,6 +933,10 @@ func NewFybrikApplicationReconciler(mgr ctrl.Manager, name string,
 		DataCatalog:       catalog,
 		ConfigEvaluator:   evaluator,
 		Infrastructure:    attributeManager,
+		Lineage:           lineage.NewEmitter(environment.GetOpenLineageURL(), &log),
+		Audit:             auditor,
+
+		ReevaluationTimeout: environment.GetGovernanceReevaluationTimeout(),
 	}
 }
 
@@ -750,12 +967,40 @@ func (r *FybrikApplicationReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	numReconciles := environment.GetEnvAsInt(controllers.ApplicationConcurrentReconcilesConfiguration,
 		controllers.DefaultApplicationConcurrentReconciles)
 
-	return ctrl.NewControllerManagedBy(mgr).
+	// governance decisions are re-evaluated periodically, upon changes of katalog assets and upon requests
+	reevaluationInterval, err := environment.GetGovernanceReevaluationInterval()
+	if err != nil {
+		return err
+	}
+	if reevaluationInterval > 0 {
+		if err := mgr.Add(r.periodicReevaluation(reevaluationInterval)); err != nil {
+			return err
+		}
+	}
+	// storage that is not used by any application is collected periodically
+	storageGCInterval, err := environment.GetStorageGCInterval()
+	if err != nil {
+		return err
+	}
+	if storageGCInterval > 0 {
+		if err := mgr.Add(r.newStorageCollector().periodicCollection(storageGCInterval)); err != nil {
+			return err
+		}
+	}
+	r.reevaluationEvents = make(chan event.GenericEvent, reevaluationEventsBuffer)
+	builder := ctrl.NewControllerManagedBy(mgr).
 		WithOptions(controller.Options{MaxConcurrentReconciles: numReconciles}).
 		For(&fappv1.FybrikApplication{}).
 		Watches(&source.Kind{
 			Type: &fappv1.Plotter{},
-		}, handler.EnqueueRequestsFromMapFunc(mapFn)).Complete(r)
+		}, handler.EnqueueRequestsFromMapFunc(mapFn)).
+		Watches(&source.Channel{Source: r.reevaluationEvents}, &handler.EnqueueRequestForObject{})
+	if strings.EqualFold(environment.GetCatalogProvider(), KatalogProvider) {
+		builder = builder.Watches(&source.Kind{
+			Type: &katalogv1alpha1.Asset{},
+		}, handler.EnqueueRequestsFromMapFunc(r.assetToApplications), ctrlbuilder.WithPredicates(predicate.GenerationChangedPredicate{}))
+	}
+	return builder.Complete(r)
 }
 
 // AnalyzeError analyzes whether the given error is fatal, or a retrial attempt can be made.
@@ -849,48 +1094,82 @@ func (r *FybrikApplicationReconciler) getStorageAccounts() ([]*fappv2.FybrikStor
 	return accounts, nil
 }
 
-func (r *FybrikApplicationReconciler) deleteTemporaryStorage(datasetDetails fappv1.DatasetDetails) error {
+func (r *FybrikApplicationReconciler) deleteTemporaryStorage(applicationContext ApplicationContext, datasetID string,
+	datasetDetails fappv1.DatasetDetails) error {
 	req := &storagemanager.DeleteStorageRequest{
 		Connection: datasetDetails.Details.Connection,
 		Secret:     datasetDetails.SecretRef,
 		Opts:       storagemanager.Options{},
 	}
-	return r.StorageManager.DeleteStorage(req)
+	err := r.StorageManager.DeleteStorage(req)
+	auditStorageDeletion(applicationContext, datasetID, &datasetDetails, err)
+	return err
 }
 
 func (r *FybrikApplicationReconciler) updateProvisionedStorageStatus(applicationContext ApplicationContext,
 	provisionedStorage map[string]NewAssetInfo) error {
 	// update allocated storage in the status
 	// clean irrelevant buckets
+	// previous copies of a scheduled asset are relevant as long as the asset is copied, they are removed by their retention
 	for datasetID, provisioned := range applicationContext.Application.Status.ProvisionedStorage {
-		if _, found := provisionedStorage[datasetID]; !found {
+		assetID := datasetID
+		if provisioned.ScheduledCopyOf != "" {
+			assetID = provisioned.ScheduledCopyOf
+		}
+		if _, found := provisionedStorage[assetID]; !found {
 			if !provisioned.Persistent {
-				if err := r.deleteTemporaryStorage(provisioned); err != nil {
+				if err := r.deleteTemporaryStorage(applicationContext, datasetID, provisioned); err != nil {
 					return err
 				}
 			}
+			if err := releaseStorage(r.Client, client.ObjectKeyFromObject(applicationContext.Application).String(), datasetID); err != nil {
+				return err
+			}
 			delete(applicationContext.Application.Status.ProvisionedStorage, datasetID)
 		}
 	}
 	// add or update new buckets
+	now := metav1.Now()
 	for datasetID, info := range provisionedStorage {
 		details := &fappv1.DataStore{}
 		if info.Details != nil {
 			details = info.Details.DeepCopy()
 		}
-
+		// a new copy of a scheduled asset does not replace the previous one until its retention expires,
+		// other temporary storage is deleted once it is replaced
+		if !retainPreviousCopy(applicationContext.Application, datasetID) {
+			r.deleteReplacedStorage(applicationContext, datasetID, details)
+		}
 		applicationContext.Application.Status.ProvisionedStorage[datasetID] = fappv1.DatasetDetails{
 			SecretRef:        taxonomy.SecretRef{Name: info.StorageAccount.SecretRef, Namespace: environment.GetAdminCRsNamespace()},
 			Details:          details,
 			ResourceMetadata: &datacatalog.ResourceMetadata{Geography: string(info.StorageAccount.Geography)},
 			Persistent:       info.Persistent,
+			CreationTime:     &now,
+			ExpirationTime:   storageExpiration(applicationContext.Application, datasetID, info.StorageAccount, now.Time),
 		}
 	}
 	return nil
 }
 
+// deleteReplacedStorage deletes the temporary storage provisioned for a dataset if it is replaced by new storage.
+// Storage that could not be deleted is left to the storage garbage collection.
+func (r *FybrikApplicationReconciler) deleteReplacedStorage(applicationContext ApplicationContext, datasetID string,
+	replacement *fappv1.DataStore) {
+	previous, found := applicationContext.Application.Status.ProvisionedStorage[datasetID]
+	if !found || previous.Persistent || previous.Details == nil ||
+		equality.Semantic.DeepEqual(previous.Details.Connection, replacement.Connection) {
+		return
+	}
+	if err := r.deleteTemporaryStorage(applicationContext, datasetID, previous); err != nil {
+		applicationContext.Log.Error().Err(err).Str(logging.DATASETID, datasetID).Msg("Could not delete replaced storage")
+	}
+}
+
+// buildSolution selects the data paths and generates the plotter spec.
+// If dryRun is set, no storage is allocated for the data paths.
 func (r *FybrikApplicationReconciler) buildSolution(applicationContext ApplicationContext, env *datapath.Environment,
-	requirements []datapath.DataInfo) (map[string]NewAssetInfo, *fappv1.PlotterSpec, error) {
+	requirements []datapath.DataInfo, dryRun bool) (map[string]NewAssetInfo, *fappv1.PlotterSpec, error) {
 	plotterGen := &PlotterGenerator{
 		Client:             r.Client,
 		Log:                applicationContext.Log,
@@ -898,6 +1177,9 @@ func (r *FybrikApplicationReconciler) buildSolution(applicationContext Applicati
 		UUID:               applicationContext.UUID,
 		StorageManager:     r.StorageManager,
 		ProvisionedStorage: make(map[string]NewAssetInfo),
+		DryRun:             dryRun,
+		Audit:              applicationContext.Audit,
+		RetainedCopies:     retainedCopies(applicationContext.Application),
 	}
 
 	plotterSpec := &fappv1.PlotterSpec{
@@ -907,16 +1189,25 @@ func (r *FybrikApplicationReconciler) buildSolution(applicationContext Applicati
 		Flows:            []fappv1.Flow{},
 		ModulesNamespace: environment.GetDefaultModulesNamespace(),
 		Templates:        map[string]fappv1.Template{},
+		RolloutStrategy:  rolloutStrategy(applicationContext.Application),
+		ReleaseSuffix:    releaseSuffix(applicationContext.Application),
 	}
 
 	paths, err := solve(env, requirements, applicationContext.Log)
 	if err != nil {
-		applicationContext.Application.Status.ErrorMessage = err.Error()
+		// explanations of a failure to construct the data path of a specific asset are reported in the asset state
+		var dataPathErr *datapath.DataPathError
+		if errors.As(err, &dataPathErr) {
+			setErrorCondition(applicationContext, dataPathErr.DataSetID, dataPathErr.Error())
+		} else {
+			applicationContext.Application.Status.ErrorMessage = err.Error()
+		}
 		return plotterGen.ProvisionedStorage, plotterSpec, nil
 	}
 	if len(paths) != len(requirements) {
 		return plotterGen.ProvisionedStorage, plotterSpec, errors.New("Wrong number of data paths")
 	}
+	recordAlternatives(applicationContext, env, requirements)
 
 	for ind := range requirements {
 		// If the flag IsNewDataSet is true then a new asset must be allocated
@@ -932,10 +1223,32 @@ func (r *FybrikApplicationReconciler) buildSolution(applicationContext Applicati
 			setErrorCondition(applicationContext, requirements[ind].Context.DataSetID, err.Error())
 			return plotterGen.ProvisionedStorage, plotterSpec, err
 		}
+		// the decision IDs change upon every request, hence they are kept in the status rather than in the plotter
+		state := applicationContext.Application.Status.AssetStates[requirements[ind].Context.DataSetID]
+		state.DecisionIDs = requirements[ind].DecisionIDs
+		applicationContext.Application.Status.AssetStates[requirements[ind].Context.DataSetID] = state
+		auditDataPath(applicationContext, &requirements[ind], &paths[ind])
 	}
 	return plotterGen.ProvisionedStorage, plotterSpec, nil
 }
 
+// rolloutStrategy returns how changes of the application are rolled out, or "" for the default strategy
+func rolloutStrategy(application *fappv1.FybrikApplication) fappv1.RolloutStrategy {
+	if application.Spec.RolloutStrategy != "" {
+		return application.Spec.RolloutStrategy
+	}
+	return fappv1.RolloutStrategy(environment.GetRolloutStrategy())
+}
+
+// releaseSuffix returns the suffix of the module release names of the application.
+// A blue/green rollout deploys every generation of the application side by side with the previous one.
+func releaseSuffix(application *fappv1.FybrikApplication) string {
+	if rolloutStrategy(application) != fappv1.BlueGreen {
+		return ""
+	}
+	return fmt.Sprintf("-g%d", application.Generation)
+}
+
 // validation of FybrikApplication
 func (r *FybrikApplicationReconciler) validateApp(ctx context.Context, applicationContext ApplicationContext) error {
 	observedStatus := applicationContext.Application.Status
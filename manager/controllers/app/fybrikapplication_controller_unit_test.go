@@ -5,19 +5,24 @@ package app
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"testing"
 	"time"
 
+	"github.com/c2h5oh/datasize"
 	"github.com/onsi/gomega"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
@@ -28,11 +33,18 @@ import (
 	"fybrik.io/fybrik/manager/controllers/mockup"
 	"fybrik.io/fybrik/manager/controllers/utils"
 	"fybrik.io/fybrik/pkg/adminconfig"
+	"fybrik.io/fybrik/pkg/audit"
+	pmclient "fybrik.io/fybrik/pkg/connectors/policymanager/clients"
 	storage "fybrik.io/fybrik/pkg/connectors/storagemanager/clients"
 	"fybrik.io/fybrik/pkg/environment"
 	"fybrik.io/fybrik/pkg/infrastructure"
+	"fybrik.io/fybrik/pkg/lineage"
 	"fybrik.io/fybrik/pkg/logging"
+	"fybrik.io/fybrik/pkg/model/policymanager"
+	"fybrik.io/fybrik/pkg/model/storagemanager"
 	"fybrik.io/fybrik/pkg/model/taxonomy"
+	"fybrik.io/fybrik/pkg/multicluster"
+	"fybrik.io/fybrik/pkg/serde"
 )
 
 // Read utility
@@ -583,6 +595,76 @@ func TestMultipleDatasets(t *testing.T) {
 	g.Expect(plotter.Spec.Flows[1].SubFlows).To(gomega.HaveLen(2))
 }
 
+// batchCountingPolicyManager records the operations of the batch requests
+type batchCountingPolicyManager struct {
+	mockup.MockPolicyManager
+	batches [][]taxonomy.DataFlow
+}
+
+func (m *batchCountingPolicyManager) GetPoliciesDecisionsBatch(in *policymanager.GetPolicyDecisionsBatchRequest,
+	creds string) (*policymanager.GetPolicyDecisionsBatchResponse, error) {
+	operations := []taxonomy.DataFlow{}
+	for i := range in.Requests {
+		operations = append(operations, in.Requests[i].Action.ActionType)
+	}
+	m.batches = append(m.batches, operations)
+	return m.MockPolicyManager.GetPoliciesDecisionsBatch(in, creds)
+}
+
+// This test checks that the read decisions of all the datasets of an application are requested in a single batch
+func TestReadDecisionsBatch(t *testing.T) {
+	t.Parallel()
+	g := gomega.NewGomegaWithT(t)
+	// Set the logger to development mode for verbose logs.
+	logf.SetLogger(zap.New(zap.UseDevMode(true)))
+
+	namespaced := types.NamespacedName{
+		Name:      "read-test",
+		Namespace: "default",
+	}
+	application := &fappv1.FybrikApplication{}
+	g.Expect(readObjectFromFile("../../testdata/unittests/data-usage.yaml", application)).NotTo(gomega.HaveOccurred())
+	application.Spec.Data = []fappv1.DataContext{
+		{
+			DataSetID:    "s3/deny-dataset",
+			Requirements: fappv1.DataRequirements{Interface: &taxonomy.Interface{Protocol: mockup.ArrowFlight}},
+		},
+		{
+			DataSetID:    "s3/allow-dataset",
+			Requirements: fappv1.DataRequirements{Interface: &taxonomy.Interface{Protocol: mockup.ArrowFlight}},
+		},
+	}
+	application.SetGeneration(1)
+	application.SetUID("32")
+	// Register operator types with the runtime scheme.
+	s := utils.NewScheme(g)
+	// Create a fake client to mock API calls.
+	cl := fake.NewFakeClientWithScheme(s, application)
+	readModule := &fappv1.FybrikModule{}
+	g.Expect(readObjectFromFile("../../testdata/unittests/module-read-parquet.yaml", readModule)).NotTo(gomega.HaveOccurred())
+	readModule.Namespace = environment.GetAdminCRsNamespace()
+	g.Expect(cl.Create(context.Background(), readModule)).NotTo(gomega.HaveOccurred(), "the read module could not be created")
+
+	r := createTestFybrikApplicationController(cl, s)
+	g.Expect(r).NotTo(gomega.BeNil())
+	policyManager := &batchCountingPolicyManager{}
+	r.PolicyManager = policyManager
+	_, err := r.Reconcile(context.Background(), reconcile.Request{NamespacedName: namespaced})
+	g.Expect(err).To(gomega.BeNil())
+
+	readBatches := [][]taxonomy.DataFlow{}
+	for _, batch := range policyManager.batches {
+		if batch[0] == taxonomy.ReadFlow {
+			readBatches = append(readBatches, batch)
+		}
+	}
+	g.Expect(readBatches).To(gomega.Equal([][]taxonomy.DataFlow{{taxonomy.ReadFlow, taxonomy.ReadFlow}}))
+	g.Expect(cl.Get(context.Background(), namespaced, application)).To(gomega.Succeed())
+	g.Expect(application.Status.AssetStates["s3/deny-dataset"].Conditions[DenyConditionIndex].Status).
+		To(gomega.BeIdenticalTo(corev1.ConditionTrue))
+	g.Expect(application.Status.Generated).ToNot(gomega.BeNil())
+}
+
 // Assumptions on response from connectors:
 // Datasets:
 // Db2 dataset
@@ -988,6 +1070,239 @@ func TestCopyData(t *testing.T) {
 	g.Expect(subflow.Steps[0][0].Parameters.Arguments[1].AssetID).To(gomega.Equal("s3-external/allow-theshire-copy"))
 }
 
+// This test checks periodic copies - a new copy is made in new storage upon each scheduled time,
+// and previous copies are deleted according to the retention.
+func TestScheduledCopy(t *testing.T) {
+	t.Parallel()
+	g := gomega.NewGomegaWithT(t)
+	// Set the logger to development mode for verbose logs.
+	logf.SetLogger(zap.New(zap.UseDevMode(true)))
+
+	assetName := "s3-external/allow-theshire"
+	namespaced := types.NamespacedName{
+		Name:      "scheduled-copy",
+		Namespace: "default",
+	}
+	adminCRsNamespace := environment.GetAdminCRsNamespace()
+	application := &fappv1.FybrikApplication{}
+	g.Expect(readObjectFromFile("../../testdata/unittests/ingest.yaml", application)).NotTo(gomega.HaveOccurred())
+	application.Name = namespaced.Name
+	application.Spec.Data[0].DataSetID = assetName
+	application.Spec.Data[0].Flow = taxonomy.CopyFlow
+	application.Spec.Data[0].Requirements.FlowParams.Catalog = ""
+	application.Spec.Data[0].Requirements.FlowParams.Schedule = "@hourly"
+	application.Spec.Data[0].Requirements.FlowParams.Retention = &fappv1.CopyRetention{Count: 2}
+	application.SetGeneration(1)
+	application.SetUID("18")
+	// Objects to track in the fake client.
+	objs := []runtime.Object{
+		application,
+	}
+
+	// Register operator types with the runtime scheme.
+	s := utils.NewScheme(g)
+
+	// Create a fake client to mock API calls.
+	cl := fake.NewFakeClientWithScheme(s, objs...)
+	copyModule := &fappv1.FybrikModule{}
+	g.Expect(readObjectFromFile("../../testdata/unittests/implicit-copy-batch-module-csv.yaml", copyModule)).NotTo(gomega.HaveOccurred())
+	copyModule.Namespace = adminCRsNamespace
+	g.Expect(cl.Create(context.TODO(), copyModule)).NotTo(gomega.HaveOccurred(), "the copy module could not be created")
+	// Create a storage account
+	secret := &corev1.Secret{}
+	g.Expect(readObjectFromFile("../../testdata/unittests/credentials-theshire.yaml", secret)).NotTo(gomega.HaveOccurred())
+	secret.Namespace = adminCRsNamespace
+	g.Expect(cl.Create(context.Background(), secret)).NotTo(gomega.HaveOccurred())
+	account := &fappv2.FybrikStorageAccount{}
+	g.Expect(readStorageAccountData("../../testdata/unittests/account-theshire.yaml", account)).NotTo(gomega.HaveOccurred())
+	account.Namespace = adminCRsNamespace
+	g.Expect(cl.Create(context.Background(), account)).NotTo(gomega.HaveOccurred())
+
+	// Create a FybrikApplicationReconciler object with the scheme and fake client.
+	r := createTestFybrikApplicationController(cl, s)
+	g.Expect(r).NotTo(gomega.BeNil())
+
+	req := reconcile.Request{
+		NamespacedName: namespaced,
+	}
+
+	result, err := r.Reconcile(context.Background(), req)
+	g.Expect(err).To(gomega.BeNil())
+	// a new reconcile is scheduled for the next copy
+	g.Expect(result.RequeueAfter).To(gomega.BeNumerically(">", 0))
+	g.Expect(result.RequeueAfter).To(gomega.BeNumerically("<=", time.Hour))
+
+	g.Expect(cl.Get(context.TODO(), req.NamespacedName, application)).To(gomega.Succeed())
+	g.Expect(application.Status.ProvisionedStorage).To(gomega.HaveKey(assetName), "No storage provisioned")
+	g.Expect(application.Status.ProvisionedStorage[assetName].CreationTime).NotTo(gomega.BeNil())
+
+	// the copy is triggered by a timer
+	g.Expect(application.Status.Generated).ToNot(gomega.BeNil())
+	plotterObjectKey := types.NamespacedName{
+		Namespace: application.Status.Generated.Namespace,
+		Name:      application.Status.Generated.Name,
+	}
+	plotter := &fappv1.Plotter{}
+	g.Expect(cl.Get(context.Background(), plotterObjectKey, plotter)).To(gomega.Succeed())
+	g.Expect(plotter.Spec.Flows).To(gomega.HaveLen(1))
+	g.Expect(plotter.Spec.Flows[0].SubFlows).To(gomega.HaveLen(1))
+	subflow := plotter.Spec.Flows[0].SubFlows[0]
+	g.Expect(subflow.FlowType).To(gomega.Equal(taxonomy.CopyFlow))
+	g.Expect(subflow.Triggers).To(gomega.ConsistOf(fappv1.TimerTrigger))
+	g.Expect(subflow.Schedule).To(gomega.Equal("@hourly"))
+
+	// ageCopies moves the creation time of the provisioned storage back by two hours or more,
+	// so that the copies made within the test are retained under different keys
+	aging := time.Hour
+	ageCopies := func() {
+		aging += time.Hour
+		g.Expect(cl.Get(context.TODO(), req.NamespacedName, application)).To(gomega.Succeed())
+		for key, details := range application.Status.ProvisionedStorage {
+			created := metav1.NewTime(details.CreationTime.Add(-aging))
+			details.CreationTime = &created
+			application.Status.ProvisionedStorage[key] = details
+		}
+		g.Expect(cl.Status().Update(context.Background(), application)).To(gomega.Succeed())
+	}
+	previousCopies := func() []string {
+		keys := []string{}
+		for key, details := range application.Status.ProvisionedStorage {
+			if details.ScheduledCopyOf == assetName {
+				keys = append(keys, key)
+			}
+		}
+		return keys
+	}
+
+	// completeCopy marks the plotter of the latest copy as ready
+	completeCopy := func() {
+		g.Expect(cl.Get(context.Background(), plotterObjectKey, plotter)).To(gomega.Succeed())
+		plotter.Status.ObservedGeneration = plotter.Generation
+		plotter.Status.ObservedState = fappv1.ObservedState{Ready: true}
+		g.Expect(cl.Update(context.Background(), plotter)).To(gomega.Succeed())
+		plotterReq := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: req.Namespace, Name: "plotter_" + req.Name}}
+		_, err := r.Reconcile(context.Background(), plotterReq)
+		g.Expect(err).To(gomega.BeNil())
+		g.Expect(cl.Get(context.TODO(), req.NamespacedName, application)).To(gomega.Succeed())
+	}
+	// allocations returns the datasets whose storage is reserved in the storage account
+	allocations := func() []string {
+		g.Expect(cl.Get(context.Background(), client.ObjectKeyFromObject(account), account)).To(gomega.Succeed())
+		datasets := []string{}
+		for _, allocation := range account.Status.Allocations {
+			datasets = append(datasets, allocation.DatasetID)
+		}
+		return datasets
+	}
+	completeCopy()
+
+	// a new copy is made once the scheduled time has passed, the previous copy is retained along with its reservation
+	ageCopies()
+	_, err = r.Reconcile(context.Background(), req)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(cl.Get(context.TODO(), req.NamespacedName, application)).To(gomega.Succeed())
+	g.Expect(application.Status.ProvisionedStorage).To(gomega.HaveKey(assetName))
+	g.Expect(application.Status.ProvisionedStorage[assetName].CreationTime.Time).To(
+		gomega.BeTemporally("~", time.Now(), time.Minute))
+	g.Expect(previousCopies()).To(gomega.HaveLen(1))
+	g.Expect(allocations()).To(gomega.ConsistOf(assetName, previousCopies()[0]))
+	completeCopy()
+
+	// upon the next copy, the oldest copy exceeds the retention count, but it is kept until the new copy completes
+	ageCopies()
+	_, err = r.Reconcile(context.Background(), req)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(cl.Get(context.TODO(), req.NamespacedName, application)).To(gomega.Succeed())
+	g.Expect(previousCopies()).To(gomega.HaveLen(2))
+	g.Expect(allocations()).To(gomega.HaveLen(3))
+	completeCopy()
+	g.Expect(previousCopies()).To(gomega.HaveLen(1))
+	g.Expect(application.Status.ProvisionedStorage).To(gomega.HaveLen(2))
+	g.Expect(allocations()).To(gomega.ConsistOf(assetName, previousCopies()[0]))
+}
+
+// This test checks the stream ingestion scenario - a Kafka topic is continuously ingested into the allocated storage.
+func TestStreamData(t *testing.T) {
+	t.Parallel()
+	g := gomega.NewGomegaWithT(t)
+	// Set the logger to development mode for verbose logs.
+	logf.SetLogger(zap.New(zap.UseDevMode(true)))
+
+	assetName := "kafka/allow-theshire"
+	namespaced := types.NamespacedName{
+		Name:      "ingest",
+		Namespace: "default",
+	}
+	adminCRsNamespace := environment.GetAdminCRsNamespace()
+	application := &fappv1.FybrikApplication{}
+	g.Expect(readObjectFromFile("../../testdata/unittests/ingest.yaml", application)).NotTo(gomega.HaveOccurred())
+	application.Spec.Data[0].DataSetID = assetName
+	application.Spec.Data[0].Flow = taxonomy.StreamFlow
+	application.SetGeneration(1)
+	application.SetUID("10")
+	// Objects to track in the fake client.
+	objs := []runtime.Object{
+		application,
+	}
+
+	// Register operator types with the runtime scheme.
+	s := utils.NewScheme(g)
+
+	// Create a fake client to mock API calls.
+	cl := fake.NewFakeClientWithScheme(s, objs...)
+	streamModule := &fappv1.FybrikModule{}
+	g.Expect(readObjectFromFile("../../testdata/unittests/stream-module.yaml", streamModule)).NotTo(gomega.HaveOccurred())
+	streamModule.Namespace = adminCRsNamespace
+	g.Expect(cl.Create(context.TODO(), streamModule)).NotTo(gomega.HaveOccurred(), "the stream module could not be created")
+	// Create a storage account
+	secret := &corev1.Secret{}
+	g.Expect(readObjectFromFile("../../testdata/unittests/credentials-theshire.yaml", secret)).NotTo(gomega.HaveOccurred())
+	secret.Namespace = adminCRsNamespace
+	g.Expect(cl.Create(context.Background(), secret)).NotTo(gomega.HaveOccurred())
+	account := &fappv2.FybrikStorageAccount{}
+	g.Expect(readStorageAccountData("../../testdata/unittests/account-theshire.yaml", account)).NotTo(gomega.HaveOccurred())
+	account.Namespace = adminCRsNamespace
+	g.Expect(cl.Create(context.Background(), account)).NotTo(gomega.HaveOccurred())
+
+	// Create a FybrikApplicationReconciler object with the scheme and fake client.
+	r := createTestFybrikApplicationController(cl, s)
+	g.Expect(r).NotTo(gomega.BeNil())
+
+	req := reconcile.Request{
+		NamespacedName: namespaced,
+	}
+
+	_, err := r.Reconcile(context.Background(), req)
+	g.Expect(err).To(gomega.BeNil())
+
+	err = cl.Get(context.TODO(), req.NamespacedName, application)
+	g.Expect(err).To(gomega.BeNil(), "Cannot fetch fybrikapplication")
+
+	// check provisioned storage
+	g.Expect(application.Status.ProvisionedStorage).To(gomega.HaveKey(assetName), "No storage provisioned")
+	// check plotter creation
+	g.Expect(application.Status.Generated).ToNot(gomega.BeNil())
+	plotterObjectKey := types.NamespacedName{
+		Namespace: application.Status.Generated.Namespace,
+		Name:      application.Status.Generated.Name,
+	}
+	plotter := &fappv1.Plotter{}
+	err = cl.Get(context.Background(), plotterObjectKey, plotter)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+
+	// There should be a single stream module that runs continuously
+	g.Expect(plotter.Spec.Flows).To(gomega.HaveLen(1))
+	g.Expect(plotter.Spec.Flows[0].FlowType).To(gomega.Equal(taxonomy.StreamFlow))
+	g.Expect(plotter.Spec.Flows[0].SubFlows).To(gomega.HaveLen(1))
+	subflow := plotter.Spec.Flows[0].SubFlows[0]
+	g.Expect(subflow.Triggers).To(gomega.ConsistOf(fappv1.ContinuousTrigger))
+	g.Expect(subflow.FlowType).To(gomega.Equal(taxonomy.StreamFlow))
+	g.Expect(subflow.Steps).To(gomega.HaveLen(1))
+	g.Expect(subflow.Steps[0]).To(gomega.HaveLen(1))
+	g.Expect(subflow.Steps[0][0].Parameters.Arguments[0].AssetID).To(gomega.Equal(assetName))
+	g.Expect(subflow.Steps[0][0].Parameters.Arguments[1].AssetID).To(gomega.Equal(assetName + "-copy"))
+}
+
 // This test checks the ingest scenario
 // A storage account has been defined for the geography where the dataset can not be written to according to governance policies.
 // An error is received.
@@ -1014,68 +1329,465 @@ func TestCopyDataNotAllowed(t *testing.T) {
 		application,
 	}
 
-	// Register operator types with the runtime scheme.
+	// Register operator types with the runtime scheme.
+	s := utils.NewScheme(g)
+
+	// Create a fake client to mock API calls.
+	cl := fake.NewFakeClientWithScheme(s, objs...)
+	copyModule := &fappv1.FybrikModule{}
+	g.Expect(readObjectFromFile("../../testdata/unittests/implicit-copy-batch-module-csv.yaml", copyModule)).NotTo(gomega.HaveOccurred())
+	copyModule.Namespace = adminCRsNamespace
+	g.Expect(cl.Create(context.TODO(), copyModule)).NotTo(gomega.HaveOccurred(), "the copy module could not be created")
+
+	// Create storage account
+	dummySecret := &corev1.Secret{}
+	g.Expect(readObjectFromFile("../../testdata/unittests/credentials-theshire.yaml", dummySecret)).NotTo(gomega.HaveOccurred())
+	dummySecret.Namespace = adminCRsNamespace
+	g.Expect(cl.Create(context.TODO(), dummySecret)).NotTo(gomega.HaveOccurred())
+	account := &fappv2.FybrikStorageAccount{}
+	g.Expect(readStorageAccountData("../../testdata/unittests/account-theshire.yaml", account)).NotTo(gomega.HaveOccurred())
+	account.Namespace = adminCRsNamespace
+	g.Expect(cl.Create(context.TODO(), account)).NotTo(gomega.HaveOccurred())
+
+	// Create a FybrikApplicationReconciler object with the scheme and fake client.
+	r := createTestFybrikApplicationController(cl, s)
+	g.Expect(r).NotTo(gomega.BeNil())
+
+	req := reconcile.Request{
+		NamespacedName: namespaced,
+	}
+
+	_, err := r.Reconcile(context.Background(), req)
+	g.Expect(err).To(gomega.BeNil())
+
+	err = cl.Get(context.TODO(), req.NamespacedName, application)
+	g.Expect(err).To(gomega.BeNil(), "Cannot fetch fybrikapplication")
+	// check provisioned storage
+	g.Expect(application.Status.ProvisionedStorage).To(gomega.BeEmpty())
+	// Expect Deny condition
+	cond := application.Status.AssetStates[assetName].Conditions[DenyConditionIndex]
+	g.Expect(cond.Status).To(gomega.BeIdenticalTo(corev1.ConditionTrue), "Deny condition is not set")
+	g.Expect(cond.Message).To(gomega.ContainSubstring(WriteNotAllowed))
+	g.Expect(application.Status.Ready).To(gomega.BeTrue())
+}
+
+// This test checks the ingest scenario
+// A storage account has been defined for the region where the dataset can not be written to according to restrictions on cost
+// An error is received.
+func TestStorageCost(t *testing.T) {
+	t.Parallel()
+	g := gomega.NewGomegaWithT(t)
+	// Set the logger to development mode for verbose logs.
+	logf.SetLogger(zap.New(zap.UseDevMode(true)))
+
+	assetName := "s3-external/allow-dataset"
+	namespaced := types.NamespacedName{
+		Name:      "ingest",
+		Namespace: "default",
+	}
+	adminCRsNamespace := environment.GetAdminCRsNamespace()
+	application := &fappv1.FybrikApplication{}
+	g.Expect(readObjectFromFile("../../testdata/unittests/ingest.yaml", application)).NotTo(gomega.HaveOccurred())
+	application.Spec.Data[0].DataSetID = assetName
+	application.SetGeneration(1)
+	application.SetUID("storage-cost")
+	// Objects to track in the fake client.
+	objs := []runtime.Object{
+		application,
+	}
+
+	// Register operator types with the runtime scheme.
+	s := utils.NewScheme(g)
+
+	// Create a fake client to mock API calls.
+	cl := fake.NewFakeClientWithScheme(s, objs...)
+	copyModule := &fappv1.FybrikModule{}
+	g.Expect(readObjectFromFile("../../testdata/unittests/implicit-copy-batch-module-csv.yaml", copyModule)).NotTo(gomega.HaveOccurred())
+	copyModule.Namespace = adminCRsNamespace
+	g.Expect(cl.Create(context.TODO(), copyModule)).NotTo(gomega.HaveOccurred(), "the copy module could not be created")
+
+	// Create storage account
+	dummySecret := &corev1.Secret{}
+	g.Expect(readObjectFromFile("../../testdata/unittests/credentials-neverland.yaml", dummySecret)).NotTo(gomega.HaveOccurred())
+	dummySecret.Namespace = adminCRsNamespace
+	g.Expect(cl.Create(context.TODO(), dummySecret)).NotTo(gomega.HaveOccurred())
+	account := &fappv2.FybrikStorageAccount{}
+	g.Expect(readStorageAccountData("../../testdata/unittests/account-neverland.yaml", account)).NotTo(gomega.HaveOccurred())
+	account.Namespace = adminCRsNamespace
+	g.Expect(cl.Create(context.TODO(), account)).NotTo(gomega.HaveOccurred())
+
+	// Create a FybrikApplicationReconciler object with the scheme and fake client.
+	r := createTestFybrikApplicationController(cl, s)
+	g.Expect(r).NotTo(gomega.BeNil())
+
+	req := reconcile.Request{
+		NamespacedName: namespaced,
+	}
+
+	_, err := r.Reconcile(context.Background(), req)
+	g.Expect(err).To(gomega.BeNil())
+
+	err = cl.Get(context.TODO(), req.NamespacedName, application)
+	g.Expect(err).To(gomega.BeNil(), "Cannot fetch fybrikapplication")
+	// check provisioned storage
+	g.Expect(application.Status.ProvisionedStorage).To(gomega.BeEmpty())
+	// check errors
+	g.Expect(getErrorMessages(application)).NotTo(gomega.BeEmpty())
+}
+
+// This test checks that storage allocated for a copy is recorded in the status of the storage account,
+// and released when the application is deleted
+func TestStorageQuota(t *testing.T) {
+	t.Parallel()
+	g := gomega.NewGomegaWithT(t)
+	// Set the logger to development mode for verbose logs.
+	logf.SetLogger(zap.New(zap.UseDevMode(true)))
+
+	assetName := "s3-external/allow-theshire"
+	namespaced := types.NamespacedName{
+		Name:      "ingest",
+		Namespace: "default",
+	}
+	adminCRsNamespace := environment.GetAdminCRsNamespace()
+	application := &fappv1.FybrikApplication{}
+	g.Expect(readObjectFromFile("../../testdata/unittests/ingest.yaml", application)).NotTo(gomega.HaveOccurred())
+	application.Spec.Data[0].DataSetID = assetName
+	application.Spec.Data[0].Flow = taxonomy.CopyFlow
+	application.Spec.Data[0].Requirements.FlowParams.StorageEstimate = 20 * datasize.GB
+	application.SetGeneration(1)
+	application.SetUID("storage-quota")
+	// Objects to track in the fake client.
+	objs := []runtime.Object{
+		application,
+	}
+
+	// Register operator types with the runtime scheme.
+	s := utils.NewScheme(g)
+
+	// Create a fake client to mock API calls.
+	cl := fake.NewFakeClientWithScheme(s, objs...)
+	copyModule := &fappv1.FybrikModule{}
+	g.Expect(readObjectFromFile("../../testdata/unittests/implicit-copy-batch-module-csv.yaml", copyModule)).NotTo(gomega.HaveOccurred())
+	copyModule.Namespace = adminCRsNamespace
+	g.Expect(cl.Create(context.TODO(), copyModule)).NotTo(gomega.HaveOccurred(), "the copy module could not be created")
+
+	// Create storage account with a quota
+	dummySecret := &corev1.Secret{}
+	g.Expect(readObjectFromFile("../../testdata/unittests/credentials-theshire.yaml", dummySecret)).NotTo(gomega.HaveOccurred())
+	dummySecret.Namespace = adminCRsNamespace
+	g.Expect(cl.Create(context.TODO(), dummySecret)).NotTo(gomega.HaveOccurred())
+	account := &fappv2.FybrikStorageAccount{}
+	g.Expect(readStorageAccountData("../../testdata/unittests/account-theshire.yaml", account)).NotTo(gomega.HaveOccurred())
+	account.Namespace = adminCRsNamespace
+	quota := resource.MustParse("50Gi")
+	account.Spec.Quota = &quota
+	g.Expect(cl.Create(context.TODO(), account)).NotTo(gomega.HaveOccurred())
+
+	// Create a FybrikApplicationReconciler object with the scheme and fake client.
+	r := createTestFybrikApplicationController(cl, s)
+	g.Expect(r).NotTo(gomega.BeNil())
+
+	req := reconcile.Request{
+		NamespacedName: namespaced,
+	}
+
+	_, err := r.Reconcile(context.Background(), req)
+	g.Expect(err).To(gomega.BeNil())
+
+	err = cl.Get(context.TODO(), req.NamespacedName, application)
+	g.Expect(err).To(gomega.BeNil(), "Cannot fetch fybrikapplication")
+	g.Expect(application.Status.ProvisionedStorage).To(gomega.HaveKey(assetName), "No storage provisioned")
+
+	// check the allocation in the account status
+	g.Expect(cl.Get(context.TODO(), client.ObjectKeyFromObject(account), account)).To(gomega.Succeed())
+	g.Expect(account.Status.Allocations).To(gomega.HaveLen(1))
+	g.Expect(account.Status.Allocations[0].Application).To(gomega.Equal("default/ingest"))
+	g.Expect(account.Status.Allocations[0].DatasetID).To(gomega.Equal(assetName))
+	g.Expect(account.Status.EstimatedUsage.Value()).To(gomega.BeEquivalentTo(20 * datasize.GB))
+
+	// the allocation is released together with the storage
+	applicationContext := ApplicationContext{Log: &r.Log, Application: application, UUID: string(application.UID)}
+	g.Expect(r.deleteExternalResources(applicationContext)).To(gomega.Succeed())
+	g.Expect(cl.Get(context.TODO(), client.ObjectKeyFromObject(account), account)).To(gomega.Succeed())
+	g.Expect(account.Status.Allocations).To(gomega.BeEmpty())
+	g.Expect(account.Status.EstimatedUsage.IsZero()).To(gomega.BeTrue())
+}
+
+// This test checks that a storage account is not selected if the storage estimate exceeds its quota
+func TestStorageQuotaExceeded(t *testing.T) {
+	t.Parallel()
+	g := gomega.NewGomegaWithT(t)
+	// Set the logger to development mode for verbose logs.
+	logf.SetLogger(zap.New(zap.UseDevMode(true)))
+
+	assetName := "s3-external/allow-theshire"
+	namespaced := types.NamespacedName{
+		Name:      "ingest",
+		Namespace: "default",
+	}
+	adminCRsNamespace := environment.GetAdminCRsNamespace()
+	application := &fappv1.FybrikApplication{}
+	g.Expect(readObjectFromFile("../../testdata/unittests/ingest.yaml", application)).NotTo(gomega.HaveOccurred())
+	application.Spec.Data[0].DataSetID = assetName
+	application.Spec.Data[0].Flow = taxonomy.CopyFlow
+	application.Spec.Data[0].Requirements.FlowParams.StorageEstimate = 20 * datasize.GB
+	application.SetGeneration(1)
+	application.SetUID("storage-quota-exceeded")
+	// Objects to track in the fake client.
+	objs := []runtime.Object{
+		application,
+	}
+
+	// Register operator types with the runtime scheme.
+	s := utils.NewScheme(g)
+
+	// Create a fake client to mock API calls.
+	cl := fake.NewFakeClientWithScheme(s, objs...)
+	copyModule := &fappv1.FybrikModule{}
+	g.Expect(readObjectFromFile("../../testdata/unittests/implicit-copy-batch-module-csv.yaml", copyModule)).NotTo(gomega.HaveOccurred())
+	copyModule.Namespace = adminCRsNamespace
+	g.Expect(cl.Create(context.TODO(), copyModule)).NotTo(gomega.HaveOccurred(), "the copy module could not be created")
+
+	// Create storage account, most of its quota is allocated by another application
+	dummySecret := &corev1.Secret{}
+	g.Expect(readObjectFromFile("../../testdata/unittests/credentials-theshire.yaml", dummySecret)).NotTo(gomega.HaveOccurred())
+	dummySecret.Namespace = adminCRsNamespace
+	g.Expect(cl.Create(context.TODO(), dummySecret)).NotTo(gomega.HaveOccurred())
+	account := &fappv2.FybrikStorageAccount{}
+	g.Expect(readStorageAccountData("../../testdata/unittests/account-theshire.yaml", account)).NotTo(gomega.HaveOccurred())
+	account.Namespace = adminCRsNamespace
+	quota := resource.MustParse("50Gi")
+	account.Spec.Quota = &quota
+	account.Status.Allocate("default/notebook", "s3/finance", 40*datasize.GB)
+	g.Expect(cl.Create(context.TODO(), account)).NotTo(gomega.HaveOccurred())
+
+	// Create a FybrikApplicationReconciler object with the scheme and fake client.
+	r := createTestFybrikApplicationController(cl, s)
+	g.Expect(r).NotTo(gomega.BeNil())
+
+	req := reconcile.Request{
+		NamespacedName: namespaced,
+	}
+
+	_, err := r.Reconcile(context.Background(), req)
+	g.Expect(err).To(gomega.BeNil())
+
+	err = cl.Get(context.TODO(), req.NamespacedName, application)
+	g.Expect(err).To(gomega.BeNil(), "Cannot fetch fybrikapplication")
+	// check provisioned storage
+	g.Expect(application.Status.ProvisionedStorage).To(gomega.BeEmpty())
+	// check errors
+	g.Expect(getErrorMessages(application)).To(gomega.ContainSubstring("quota"))
+	g.Expect(cl.Get(context.TODO(), client.ObjectKeyFromObject(account), account)).To(gomega.Succeed())
+	g.Expect(account.Status.Allocations).To(gomega.HaveLen(1))
+}
+
+// This test checks that the plotter state propagates into the fybrikapp state
+func TestPlotterUpdate(t *testing.T) {
+	t.Parallel()
+	g := gomega.NewGomegaWithT(t)
+	// Set the logger to development mode for verbose logs.
+	logf.SetLogger(zap.New(zap.UseDevMode(true)))
+
+	namespaced := types.NamespacedName{
+		Name:      "read-test",
+		Namespace: "default",
+	}
+	adminCRsNamespace := environment.GetAdminCRsNamespace()
+	application := &fappv1.FybrikApplication{}
+	g.Expect(readObjectFromFile("../../testdata/unittests/data-usage.yaml", application)).NotTo(gomega.HaveOccurred())
+	application.Spec.Data[0] = fappv1.DataContext{
+		DataSetID:    "s3/allow-dataset",
+		Requirements: fappv1.DataRequirements{Interface: &taxonomy.Interface{Protocol: mockup.ArrowFlight}},
+	}
+	application.SetGeneration(1)
+	application.SetUID("11")
+	// Objects to track in the fake client.
+	objs := []runtime.Object{
+		application,
+	}
+
+	// Register operator types with the runtime scheme.
+	s := utils.NewScheme(g)
+
+	// Create a fake client to mock API calls.
+	cl := fake.NewFakeClientWithScheme(s, objs...)
+
+	// Read module
+	readModule := &fappv1.FybrikModule{}
+	g.Expect(readObjectFromFile("../../testdata/unittests/module-read-parquet.yaml", readModule)).NotTo(gomega.HaveOccurred())
+	readModule.Namespace = adminCRsNamespace
+	g.Expect(cl.Create(context.Background(), readModule)).NotTo(gomega.HaveOccurred(), "the read module could not be created")
+
+	// Create a FybrikApplicationReconciler object with the scheme and fake client.
+	r := createTestFybrikApplicationController(cl, s)
+	g.Expect(r).NotTo(gomega.BeNil())
+
+	req := reconcile.Request{
+		NamespacedName: namespaced,
+	}
+
+	_, err := r.Reconcile(context.Background(), req)
+	g.Expect(err).To(gomega.BeNil())
+
+	err = cl.Get(context.Background(), req.NamespacedName, application)
+	g.Expect(err).To(gomega.BeNil(), "Cannot fetch fybrikapplication")
+	// check plotter creation
+	g.Expect(application.Status.Generated).ToNot(gomega.BeNil())
+	g.Expect(application.Status.Generated.AppVersion).To(gomega.Equal(application.Generation))
+	plotterObjectKey := types.NamespacedName{
+		Namespace: application.Status.Generated.Namespace,
+		Name:      application.Status.Generated.Name,
+	}
+	plotter := &fappv1.Plotter{}
+	err = cl.Get(context.Background(), plotterObjectKey, plotter)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	// mark the plotter as in error state
+	errorMsg := "failure to orchestrate modules"
+	plotter.Status.ObservedState.Error = errorMsg
+	g.Expect(cl.Update(context.Background(), plotter)).NotTo(gomega.HaveOccurred())
+
+	// the new reconcile should update the application state
+	newReq := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: req.Namespace, Name: "plotter_" + req.Name}}
+	_, err = r.Reconcile(context.Background(), newReq)
+	g.Expect(err).To(gomega.BeNil())
+	err = cl.Get(context.Background(), req.NamespacedName, application)
+	g.Expect(err).To(gomega.BeNil(), "Cannot fetch fybrikapplication")
+	g.Expect(getErrorMessages(application)).To(gomega.ContainSubstring(errorMsg))
+
+	// mark the plotter as ready
+	plotter.Status.ObservedState.Error = ""
+	plotter.Status.ObservedState.Ready = true
+	g.Expect(cl.Update(context.Background(), plotter)).NotTo(gomega.HaveOccurred())
+
+	// the new reconcile should update the application state
+	_, err = r.Reconcile(context.Background(), newReq)
+	g.Expect(err).To(gomega.BeNil())
+	err = cl.Get(context.Background(), req.NamespacedName, application)
+	g.Expect(err).To(gomega.BeNil(), "Cannot fetch fybrikapplication")
+	g.Expect(application.Status.Ready).To(gomega.BeTrue())
+}
+
+// This test checks that a blue/green rollout names the module releases of every generation of the application apart,
+// and that the endpoint of the application is switched to the new releases once the plotter is ready
+func TestBlueGreenEndpoints(t *testing.T) {
+	t.Parallel()
+	g := gomega.NewGomegaWithT(t)
+	// Set the logger to development mode for verbose logs.
+	logf.SetLogger(zap.New(zap.UseDevMode(true)))
+
+	namespaced := types.NamespacedName{
+		Name:      "read-test",
+		Namespace: "default",
+	}
+	adminCRsNamespace := environment.GetAdminCRsNamespace()
+	application := &fappv1.FybrikApplication{}
+	g.Expect(readObjectFromFile("../../testdata/unittests/data-usage.yaml", application)).NotTo(gomega.HaveOccurred())
+	application.Spec.Data[0] = fappv1.DataContext{
+		DataSetID:    "s3/allow-dataset",
+		Requirements: fappv1.DataRequirements{Interface: &taxonomy.Interface{Protocol: mockup.ArrowFlight}},
+	}
+	application.Spec.RolloutStrategy = fappv1.BlueGreen
+	application.SetGeneration(1)
+	application.SetUID("11")
+
 	s := utils.NewScheme(g)
-
-	// Create a fake client to mock API calls.
-	cl := fake.NewFakeClientWithScheme(s, objs...)
-	copyModule := &fappv1.FybrikModule{}
-	g.Expect(readObjectFromFile("../../testdata/unittests/implicit-copy-batch-module-csv.yaml", copyModule)).NotTo(gomega.HaveOccurred())
-	copyModule.Namespace = adminCRsNamespace
-	g.Expect(cl.Create(context.TODO(), copyModule)).NotTo(gomega.HaveOccurred(), "the copy module could not be created")
-
-	// Create storage account
-	dummySecret := &corev1.Secret{}
-	g.Expect(readObjectFromFile("../../testdata/unittests/credentials-theshire.yaml", dummySecret)).NotTo(gomega.HaveOccurred())
-	dummySecret.Namespace = adminCRsNamespace
-	g.Expect(cl.Create(context.TODO(), dummySecret)).NotTo(gomega.HaveOccurred())
-	account := &fappv2.FybrikStorageAccount{}
-	g.Expect(readStorageAccountData("../../testdata/unittests/account-theshire.yaml", account)).NotTo(gomega.HaveOccurred())
-	account.Namespace = adminCRsNamespace
-	g.Expect(cl.Create(context.TODO(), account)).NotTo(gomega.HaveOccurred())
-
-	// Create a FybrikApplicationReconciler object with the scheme and fake client.
+	cl := fake.NewFakeClientWithScheme(s, application)
+	readModule := &fappv1.FybrikModule{}
+	g.Expect(readObjectFromFile("../../testdata/unittests/module-read-parquet.yaml", readModule)).NotTo(gomega.HaveOccurred())
+	readModule.Namespace = adminCRsNamespace
+	g.Expect(cl.Create(context.Background(), readModule)).NotTo(gomega.HaveOccurred(), "the read module could not be created")
 	r := createTestFybrikApplicationController(cl, s)
 	g.Expect(r).NotTo(gomega.BeNil())
-
-	req := reconcile.Request{
-		NamespacedName: namespaced,
+	req := reconcile.Request{NamespacedName: namespaced}
+	plotterReq := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: req.Namespace, Name: "plotter_" + req.Name}}
+	endpointOf := func() string {
+		g.Expect(cl.Get(context.Background(), req.NamespacedName, application)).To(gomega.Succeed())
+		return fmt.Sprint(application.Status.AssetStates["s3/allow-dataset"].Endpoint.AdditionalProperties.Items)
 	}
 
 	_, err := r.Reconcile(context.Background(), req)
 	g.Expect(err).To(gomega.BeNil())
+	g.Expect(endpointOf()).To(gomega.ContainSubstring("-g1"))
+	plotter := &fappv1.Plotter{}
+	plotterKey := types.NamespacedName{Namespace: application.Status.Generated.Namespace, Name: application.Status.Generated.Name}
+	g.Expect(cl.Get(context.Background(), plotterKey, plotter)).To(gomega.Succeed())
+	g.Expect(plotter.Spec.RolloutStrategy).To(gomega.Equal(fappv1.BlueGreen))
+	g.Expect(plotter.Spec.ReleaseSuffix).To(gomega.Equal("-g1"))
 
-	err = cl.Get(context.TODO(), req.NamespacedName, application)
-	g.Expect(err).To(gomega.BeNil(), "Cannot fetch fybrikapplication")
-	// check provisioned storage
-	g.Expect(application.Status.ProvisionedStorage).To(gomega.BeEmpty())
-	// Expect Deny condition
-	cond := application.Status.AssetStates[assetName].Conditions[DenyConditionIndex]
-	g.Expect(cond.Status).To(gomega.BeIdenticalTo(corev1.ConditionTrue), "Deny condition is not set")
-	g.Expect(cond.Message).To(gomega.ContainSubstring(WriteNotAllowed))
+	// the new generation is deployed side by side, and the workload keeps using the current endpoint
+	application.Spec.AppInfo.Items["intent"] = "Marketing"
+	application.SetGeneration(2)
+	g.Expect(cl.Update(context.Background(), application)).To(gomega.Succeed())
+	_, err = r.Reconcile(context.Background(), req)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(endpointOf()).To(gomega.ContainSubstring("-g1"))
+	g.Expect(cl.Get(context.Background(), plotterKey, plotter)).To(gomega.Succeed())
+	g.Expect(plotter.Spec.ReleaseSuffix).To(gomega.Equal("-g2"))
+
+	// the endpoint is switched once the new generation is ready
+	plotter.Status.ObservedGeneration = plotter.Generation
+	plotter.Status.ObservedState.Ready = true
+	g.Expect(cl.Update(context.Background(), plotter)).To(gomega.Succeed())
+	_, err = r.Reconcile(context.Background(), plotterReq)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(endpointOf()).To(gomega.ContainSubstring("-g2"))
 	g.Expect(application.Status.Ready).To(gomega.BeTrue())
 }
 
-// This test checks the ingest scenario
-// A storage account has been defined for the region where the dataset can not be written to according to restrictions on cost
-// An error is received.
-func TestStorageCost(t *testing.T) {
+// revokingPolicyManager denies access to all the datasets
+type revokingPolicyManager struct {
+	mockup.MockPolicyManager
+}
+
+func (m *revokingPolicyManager) GetPoliciesDecisions(in *policymanager.GetPolicyDecisionsRequest,
+	creds string) (*policymanager.GetPolicyDecisionsResponse, error) {
+	denied := *in
+	denied.Resource.ID = "s3/deny-dataset"
+	return m.MockPolicyManager.GetPoliciesDecisions(&denied, creds)
+}
+
+func (m *revokingPolicyManager) GetPoliciesDecisionsBatch(in *policymanager.GetPolicyDecisionsBatchRequest,
+	creds string) (*policymanager.GetPolicyDecisionsBatchResponse, error) {
+	return pmclient.GetPoliciesDecisionsOneByOne(m, in, creds)
+}
+
+// failingPolicyManager can not be reached
+type failingPolicyManager struct {
+	mockup.MockPolicyManager
+}
+
+func (m *failingPolicyManager) GetPoliciesDecisions(in *policymanager.GetPolicyDecisionsRequest,
+	creds string) (*policymanager.GetPolicyDecisionsResponse, error) {
+	return nil, errors.New("policy manager is unreachable")
+}
+
+func (m *failingPolicyManager) GetPoliciesDecisionsBatch(in *policymanager.GetPolicyDecisionsBatchRequest,
+	creds string) (*policymanager.GetPolicyDecisionsBatchResponse, error) {
+	return nil, errors.New("policy manager is unreachable")
+}
+
+// This test checks that an application whose copy runs on a failed cluster is planned again on another cluster
+// in the same region, and that the failover is recorded in its status
+func TestClusterFailover(t *testing.T) {
 	t.Parallel()
 	g := gomega.NewGomegaWithT(t)
 	// Set the logger to development mode for verbose logs.
 	logf.SetLogger(zap.New(zap.UseDevMode(true)))
 
-	assetName := "s3-external/allow-dataset"
 	namespaced := types.NamespacedName{
-		Name:      "ingest",
+		Name:      "read-test",
 		Namespace: "default",
 	}
 	adminCRsNamespace := environment.GetAdminCRsNamespace()
 	application := &fappv1.FybrikApplication{}
-	g.Expect(readObjectFromFile("../../testdata/unittests/ingest.yaml", application)).NotTo(gomega.HaveOccurred())
-	application.Spec.Data[0].DataSetID = assetName
+	g.Expect(readObjectFromFile("../../testdata/unittests/data-usage.yaml", application)).NotTo(gomega.HaveOccurred())
+	application.Spec.Data[0] = fappv1.DataContext{
+		DataSetID:    "s3-external/redact-dataset",
+		Requirements: fappv1.DataRequirements{Interface: &taxonomy.Interface{Protocol: mockup.ArrowFlight}},
+	}
 	application.SetGeneration(1)
-	application.SetUID("storage-cost")
+	application.SetUID("31")
 	// Objects to track in the fake client.
 	objs := []runtime.Object{
 		application,
@@ -1086,20 +1798,25 @@ func TestStorageCost(t *testing.T) {
 
 	// Create a fake client to mock API calls.
 	cl := fake.NewFakeClientWithScheme(s, objs...)
+
+	// Read module
+	readModule := &fappv1.FybrikModule{}
+	g.Expect(readObjectFromFile("../../testdata/unittests/module-read-parquet.yaml", readModule)).NotTo(gomega.HaveOccurred())
+	readModule.Namespace = adminCRsNamespace
+	g.Expect(cl.Create(context.Background(), readModule)).NotTo(gomega.HaveOccurred(), "the read module could not be created")
 	copyModule := &fappv1.FybrikModule{}
-	g.Expect(readObjectFromFile("../../testdata/unittests/implicit-copy-batch-module-csv.yaml", copyModule)).NotTo(gomega.HaveOccurred())
+	g.Expect(readObjectFromFile("../../testdata/unittests/copy-csv-parquet.yaml", copyModule)).NotTo(gomega.HaveOccurred())
 	copyModule.Namespace = adminCRsNamespace
-	g.Expect(cl.Create(context.TODO(), copyModule)).NotTo(gomega.HaveOccurred(), "the copy module could not be created")
-
+	g.Expect(cl.Create(context.Background(), copyModule)).NotTo(gomega.HaveOccurred(), "the copy module could not be created")
 	// Create storage account
 	dummySecret := &corev1.Secret{}
-	g.Expect(readObjectFromFile("../../testdata/unittests/credentials-neverland.yaml", dummySecret)).NotTo(gomega.HaveOccurred())
+	g.Expect(readObjectFromFile("../../testdata/unittests/credentials-theshire.yaml", dummySecret)).NotTo(gomega.HaveOccurred())
 	dummySecret.Namespace = adminCRsNamespace
-	g.Expect(cl.Create(context.TODO(), dummySecret)).NotTo(gomega.HaveOccurred())
+	g.Expect(cl.Create(context.Background(), dummySecret)).NotTo(gomega.HaveOccurred())
 	account := &fappv2.FybrikStorageAccount{}
-	g.Expect(readStorageAccountData("../../testdata/unittests/account-neverland.yaml", account)).NotTo(gomega.HaveOccurred())
+	g.Expect(readStorageAccountData("../../testdata/unittests/account-theshire.yaml", account)).NotTo(gomega.HaveOccurred())
 	account.Namespace = adminCRsNamespace
-	g.Expect(cl.Create(context.TODO(), account)).NotTo(gomega.HaveOccurred())
+	g.Expect(cl.Create(context.Background(), account)).NotTo(gomega.HaveOccurred())
 
 	// Create a FybrikApplicationReconciler object with the scheme and fake client.
 	r := createTestFybrikApplicationController(cl, s)
@@ -1108,20 +1825,45 @@ func TestStorageCost(t *testing.T) {
 	req := reconcile.Request{
 		NamespacedName: namespaced,
 	}
-
 	_, err := r.Reconcile(context.Background(), req)
 	g.Expect(err).To(gomega.BeNil())
-
-	err = cl.Get(context.TODO(), req.NamespacedName, application)
-	g.Expect(err).To(gomega.BeNil(), "Cannot fetch fybrikapplication")
-	// check provisioned storage
-	g.Expect(application.Status.ProvisionedStorage).To(gomega.BeEmpty())
-	// check errors
-	g.Expect(getErrorMessages(application)).NotTo(gomega.BeEmpty())
+	g.Expect(cl.Get(context.Background(), req.NamespacedName, application)).To(gomega.Succeed())
+	g.Expect(application.Status.Generated).ToNot(gomega.BeNil())
+	plotterObjectKey := types.NamespacedName{
+		Namespace: application.Status.Generated.Namespace,
+		Name:      application.Status.Generated.Name,
+	}
+	plotter := &fappv1.Plotter{}
+	g.Expect(cl.Get(context.Background(), plotterObjectKey, plotter)).To(gomega.Succeed())
+	g.Expect(plotter.Spec.Flows[0].SubFlows[0].Steps[0][0].Cluster).To(gomega.Equal("neverland-cluster"))
+
+	// the failure of a cluster that the application is not deployed on is ignored
+	r.OnClusterFailure("mordor", "cluster mordor has been unreachable")
+	_, _, requested := r.failovers.get(namespaced)
+	g.Expect(requested).To(gomega.BeFalse())
+
+	// the failed cluster is no longer listed, and another cluster is available in its region
+	r.ClusterManager = &offlineClusterLister{clusters: []multicluster.Cluster{
+		{Name: "thegreendragon", Metadata: multicluster.ClusterMetadata{Region: "theshire", VaultAuthPath: mockup.VaultAuthPath}},
+		{Name: "neverland-backup", Metadata: multicluster.ClusterMetadata{Region: "neverland", VaultAuthPath: mockup.VaultAuthPath}},
+	}}
+	reason := "cluster neverland-cluster has been unreachable since 2023-03-01T12:00:00Z"
+	r.OnClusterFailure("neverland-cluster", reason)
+	_, err = r.Reconcile(context.Background(), req)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(cl.Get(context.Background(), req.NamespacedName, application)).To(gomega.Succeed())
+	g.Expect(application.Status.Failover).NotTo(gomega.BeNil())
+	g.Expect(application.Status.Failover.Clusters).To(gomega.Equal([]string{"neverland-cluster"}))
+	g.Expect(application.Status.Failover.Reason).To(gomega.Equal(reason))
+	g.Expect(cl.Get(context.Background(), plotterObjectKey, plotter)).To(gomega.Succeed())
+	g.Expect(plotter.Spec.Flows[0].SubFlows[0].Steps[0][0].Cluster).To(gomega.Equal("neverland-backup"))
+	_, _, requested = r.failovers.get(namespaced)
+	g.Expect(requested).To(gomega.BeFalse())
 }
 
-// This test checks that the plotter state propagates into the fybrikapp state
-func TestPlotterUpdate(t *testing.T) {
+// This test checks that a re-evaluation generates a new plan only if the governance decisions have changed,
+// and that revoked access removes the generated plotter
+func TestGovernanceReevaluation(t *testing.T) {
 	t.Parallel()
 	g := gomega.NewGomegaWithT(t)
 	// Set the logger to development mode for verbose logs.
@@ -1139,7 +1881,7 @@ func TestPlotterUpdate(t *testing.T) {
 		Requirements: fappv1.DataRequirements{Interface: &taxonomy.Interface{Protocol: mockup.ArrowFlight}},
 	}
 	application.SetGeneration(1)
-	application.SetUID("11")
+	application.SetUID("30")
 	// Objects to track in the fake client.
 	objs := []runtime.Object{
 		application,
@@ -1164,46 +1906,145 @@ func TestPlotterUpdate(t *testing.T) {
 	req := reconcile.Request{
 		NamespacedName: namespaced,
 	}
-
 	_, err := r.Reconcile(context.Background(), req)
 	g.Expect(err).To(gomega.BeNil())
-
-	err = cl.Get(context.Background(), req.NamespacedName, application)
-	g.Expect(err).To(gomega.BeNil(), "Cannot fetch fybrikapplication")
-	// check plotter creation
+	g.Expect(cl.Get(context.Background(), req.NamespacedName, application)).To(gomega.Succeed())
 	g.Expect(application.Status.Generated).ToNot(gomega.BeNil())
-	g.Expect(application.Status.Generated.AppVersion).To(gomega.Equal(application.Generation))
+	digest := application.Status.GovernanceDigest
+	g.Expect(digest).NotTo(gomega.BeEmpty())
 	plotterObjectKey := types.NamespacedName{
 		Namespace: application.Status.Generated.Namespace,
 		Name:      application.Status.Generated.Name,
 	}
 	plotter := &fappv1.Plotter{}
-	err = cl.Get(context.Background(), plotterObjectKey, plotter)
-	g.Expect(err).NotTo(gomega.HaveOccurred())
-	// mark the plotter as in error state
-	errorMsg := "failure to orchestrate modules"
-	plotter.Status.ObservedState.Error = errorMsg
-	g.Expect(cl.Update(context.Background(), plotter)).NotTo(gomega.HaveOccurred())
+	g.Expect(cl.Get(context.Background(), plotterObjectKey, plotter)).To(gomega.Succeed())
+	plotterVersion := plotter.ResourceVersion
 
-	// the new reconcile should update the application state
-	newReq := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: req.Namespace, Name: "plotter_" + req.Name}}
-	_, err = r.Reconcile(context.Background(), newReq)
+	// re-evaluation without changes of the governance decisions keeps the plan
+	r.RequestReevaluation(PeriodicReevaluation)
+	_, err = r.Reconcile(context.Background(), req)
 	g.Expect(err).To(gomega.BeNil())
-	err = cl.Get(context.Background(), req.NamespacedName, application)
-	g.Expect(err).To(gomega.BeNil(), "Cannot fetch fybrikapplication")
-	g.Expect(getErrorMessages(application)).To(gomega.ContainSubstring(errorMsg))
+	g.Expect(cl.Get(context.Background(), req.NamespacedName, application)).To(gomega.Succeed())
+	g.Expect(application.Status.Reevaluation).To(gomega.BeNil())
+	g.Expect(application.Status.GovernanceDigest).To(gomega.Equal(digest))
+	g.Expect(cl.Get(context.Background(), plotterObjectKey, plotter)).To(gomega.Succeed())
+	g.Expect(plotter.ResourceVersion).To(gomega.Equal(plotterVersion))
+
+	// a re-evaluation that fails keeps the plan, records the failure and is retried
+	policyManager := r.PolicyManager
+	r.PolicyManager = &failingPolicyManager{}
+	r.RequestReevaluation(PeriodicReevaluation)
+	result, err := r.Reconcile(context.Background(), req)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(result.Requeue).To(gomega.BeTrue())
+	g.Expect(cl.Get(context.Background(), req.NamespacedName, application)).To(gomega.Succeed())
+	g.Expect(application.Status.Reevaluation).NotTo(gomega.BeNil())
+	g.Expect(application.Status.Reevaluation.Reason).To(gomega.Equal(PeriodicReevaluation))
+	g.Expect(application.Status.Reevaluation.Error).To(gomega.ContainSubstring("unreachable"))
+	g.Expect(application.Status.GovernanceDigest).To(gomega.Equal(digest))
+	g.Expect(getErrorMessages(application)).NotTo(gomega.BeEmpty())
+	g.Expect(application.Status.Generated).NotTo(gomega.BeNil())
+	g.Expect(cl.Get(context.Background(), plotterObjectKey, plotter)).To(gomega.Succeed())
+	g.Expect(plotter.ResourceVersion).To(gomega.Equal(plotterVersion))
 
-	// mark the plotter as ready
-	plotter.Status.ObservedState.Error = ""
-	plotter.Status.ObservedState.Ready = true
-	g.Expect(cl.Update(context.Background(), plotter)).NotTo(gomega.HaveOccurred())
+	// the retried re-evaluation clears the failure once the policy manager is reachable again
+	r.PolicyManager = policyManager
+	_, err = r.Reconcile(context.Background(), req)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(cl.Get(context.Background(), req.NamespacedName, application)).To(gomega.Succeed())
+	g.Expect(application.Status.Reevaluation.Error).To(gomega.BeEmpty())
+	g.Expect(getErrorMessages(application)).To(gomega.BeEmpty())
+	g.Expect(application.Status.GovernanceDigest).To(gomega.Equal(digest))
+	g.Expect(application.Status.Generated).NotTo(gomega.BeNil())
 
-	// the new reconcile should update the application state
-	_, err = r.Reconcile(context.Background(), newReq)
+	// the data paths are removed once the re-evaluations have failed for longer than the timeout
+	r.ReevaluationTimeout = time.Minute
+	r.PolicyManager = &failingPolicyManager{}
+	r.RequestReevaluation(PeriodicReevaluation)
+	_, err = r.Reconcile(context.Background(), req)
 	g.Expect(err).To(gomega.BeNil())
-	err = cl.Get(context.Background(), req.NamespacedName, application)
-	g.Expect(err).To(gomega.BeNil(), "Cannot fetch fybrikapplication")
-	g.Expect(application.Status.Ready).To(gomega.BeTrue())
+	g.Expect(cl.Get(context.Background(), req.NamespacedName, application)).To(gomega.Succeed())
+	g.Expect(application.Status.Reevaluation.FailingSince).NotTo(gomega.BeNil())
+	g.Expect(application.Status.Generated).NotTo(gomega.BeNil())
+	failingSince := metav1.NewTime(application.Status.Reevaluation.FailingSince.Add(-2 * time.Minute))
+	application.Status.Reevaluation.FailingSince = &failingSince
+	g.Expect(cl.Status().Update(context.Background(), application)).To(gomega.Succeed())
+	_, err = r.Reconcile(context.Background(), req)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(cl.Get(context.Background(), req.NamespacedName, application)).To(gomega.Succeed())
+	g.Expect(application.Status.Reevaluation.FailingSince.Time).To(gomega.BeTemporally("==", failingSince.Time))
+	g.Expect(application.Status.Generated).To(gomega.BeNil())
+	g.Expect(cl.Get(context.Background(), plotterObjectKey, plotter)).To(gomega.Succeed())
+	g.Expect(plotter.DeletionTimestamp.IsZero()).To(gomega.BeFalse(), "the plotter has not been deleted")
+	g.Expect(application.Status.Ready).To(gomega.BeFalse())
+
+	// the data paths are restored once the governance decisions are evaluated again
+	r.PolicyManager = policyManager
+	g.Expect(cl.Delete(context.Background(), plotter)).To(gomega.Succeed())
+	_, err = r.Reconcile(context.Background(), req)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(cl.Get(context.Background(), req.NamespacedName, application)).To(gomega.Succeed())
+	g.Expect(application.Status.Generated).NotTo(gomega.BeNil())
+	g.Expect(application.Status.Reevaluation.Error).To(gomega.BeEmpty())
+	g.Expect(application.Status.Reevaluation.FailingSince).To(gomega.BeNil())
+	g.Expect(getErrorMessages(application)).To(gomega.BeEmpty())
+	g.Expect(cl.Get(context.Background(), plotterObjectKey, plotter)).To(gomega.Succeed())
+	// the pending retry keeps the restored plan
+	plotterVersion = plotter.ResourceVersion
+	_, err = r.Reconcile(context.Background(), req)
+	g.Expect(err).To(gomega.BeNil())
+	_, requested := r.reevaluations.take(namespaced)
+	g.Expect(requested).To(gomega.BeFalse())
+	g.Expect(cl.Get(context.Background(), plotterObjectKey, plotter)).To(gomega.Succeed())
+	g.Expect(plotter.ResourceVersion).To(gomega.Equal(plotterVersion))
+	r.ReevaluationTimeout = 0
+
+	// access to the dataset is revoked
+	r.PolicyManager = &revokingPolicyManager{}
+	reason := fmt.Sprintf(AssetChangedReasonTemplate, "s3/allow-dataset")
+	r.reevaluations.add(namespaced, reason)
+	_, err = r.Reconcile(context.Background(), req)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(cl.Get(context.Background(), req.NamespacedName, application)).To(gomega.Succeed())
+	g.Expect(application.Status.Reevaluation).NotTo(gomega.BeNil())
+	g.Expect(application.Status.Reevaluation.Reason).To(gomega.Equal(reason))
+	g.Expect(application.Status.GovernanceDigest).NotTo(gomega.Equal(digest))
+	cond := application.Status.AssetStates["s3/allow-dataset"].Conditions[DenyConditionIndex]
+	g.Expect(cond.Status).To(gomega.BeIdenticalTo(corev1.ConditionTrue), "Deny condition is not set")
+	g.Expect(application.Status.Generated).To(gomega.BeNil())
+	// the plotter is marked for deletion, its finalizer is removed by the plotter controller
+	g.Expect(cl.Get(context.Background(), plotterObjectKey, plotter)).To(gomega.Succeed())
+	g.Expect(plotter.DeletionTimestamp.IsZero()).To(gomega.BeFalse(), "the plotter has not been deleted")
+}
+
+// This test checks that re-evaluation requests do not block when the reconcile events are not consumed,
+// as on replicas that are not the leader
+func TestReevaluationWithoutConsumer(t *testing.T) {
+	t.Parallel()
+	g := gomega.NewGomegaWithT(t)
+	first := &fappv1.FybrikApplication{}
+	g.Expect(readObjectFromFile("../../testdata/unittests/data-usage.yaml", first)).NotTo(gomega.HaveOccurred())
+	second := first.DeepCopy()
+	second.Name = "second"
+	s := utils.NewScheme(g)
+	cl := fake.NewFakeClientWithScheme(s, first, second)
+	r := createTestFybrikApplicationController(cl, s)
+	g.Expect(r).NotTo(gomega.BeNil())
+	r.reevaluationEvents = make(chan event.GenericEvent, 1)
+
+	done := make(chan struct{})
+	go func() {
+		r.RequestReevaluation(ConfigPoliciesChanged)
+		close(done)
+	}()
+	g.Eventually(done).Should(gomega.BeClosed())
+	g.Expect(r.reevaluationEvents).To(gomega.HaveLen(1))
+	// the request of the dropped event is kept for the next reconcile
+	for _, application := range []*fappv1.FybrikApplication{first, second} {
+		reason, found := r.reevaluations.take(client.ObjectKeyFromObject(application))
+		g.Expect(found).To(gomega.BeTrue())
+		g.Expect(reason).To(gomega.Equal(ConfigPoliciesChanged))
+	}
 }
 
 // This test checks that the older plotter state does not propagate into the fybrikapp state
@@ -1971,3 +2812,289 @@ func TestEmptyInterface(t *testing.T) {
 	// check plotter creation
 	g.Expect(application.Status.Generated).ToNot(gomega.BeNil())
 }
+
+// recordingEmitter keeps the emitted lineage records
+type recordingEmitter struct {
+	records []lineage.Record
+}
+
+func (e *recordingEmitter) Emit(record *lineage.Record) error {
+	e.records = append(e.records, *record)
+	return nil
+}
+
+// This test checks that lineage records of the copy and the transformation of a dataset are emitted
+// once the plotter is ready
+func TestLineage(t *testing.T) {
+	t.Parallel()
+	g := gomega.NewGomegaWithT(t)
+	// Set the logger to development mode for verbose logs.
+	logf.SetLogger(zap.New(zap.UseDevMode(true)))
+
+	adminCRsNamespace := environment.GetAdminCRsNamespace()
+	application := &fappv1.FybrikApplication{}
+	g.Expect(readObjectFromFile("../../testdata/unittests/fybrikcopyapp-csv.yaml", application)).NotTo(gomega.HaveOccurred())
+	application.SetGeneration(1)
+	application.SetUID("21")
+	// Register operator types with the runtime scheme.
+	s := utils.NewScheme(g)
+	// Create a fake client to mock API calls.
+	cl := fake.NewFakeClientWithScheme(s, application)
+	for _, file := range []string{"implicit-copy-batch-module-csv.yaml", "module-read-csv.yaml"} {
+		module := &fappv1.FybrikModule{}
+		g.Expect(readObjectFromFile("../../testdata/unittests/"+file, module)).NotTo(gomega.HaveOccurred())
+		module.Namespace = adminCRsNamespace
+		g.Expect(cl.Create(context.Background(), module)).NotTo(gomega.HaveOccurred())
+	}
+	secret := &corev1.Secret{}
+	g.Expect(readObjectFromFile("../../testdata/unittests/credentials-theshire.yaml", secret)).NotTo(gomega.HaveOccurred())
+	secret.Namespace = adminCRsNamespace
+	g.Expect(cl.Create(context.Background(), secret)).NotTo(gomega.HaveOccurred())
+	account := &fappv2.FybrikStorageAccount{}
+	g.Expect(readStorageAccountData("../../testdata/unittests/account-theshire.yaml", account)).NotTo(gomega.HaveOccurred())
+	account.Namespace = adminCRsNamespace
+	g.Expect(cl.Create(context.Background(), account)).NotTo(gomega.HaveOccurred())
+
+	r := createTestFybrikApplicationController(cl, s)
+	g.Expect(r).NotTo(gomega.BeNil())
+	emitter := &recordingEmitter{}
+	r.Lineage = emitter
+	req := reconcile.Request{NamespacedName: client.ObjectKeyFromObject(application)}
+	_, err := r.Reconcile(context.Background(), req)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(cl.Get(context.Background(), req.NamespacedName, application)).NotTo(gomega.HaveOccurred())
+	g.Expect(application.Status.Generated).NotTo(gomega.BeNil())
+	plotter := &fappv1.Plotter{}
+	plotterKey := types.NamespacedName{Namespace: application.Status.Generated.Namespace, Name: application.Status.Generated.Name}
+	g.Expect(cl.Get(context.Background(), plotterKey, plotter)).NotTo(gomega.HaveOccurred())
+	g.Expect(plotter.Spec.Flows[0].Policies).To(gomega.ContainElement(mockup.RedactSSNPolicy))
+	// no lineage is recorded before the plotter is ready
+	g.Expect(emitter.records).To(gomega.BeEmpty())
+
+	// mark the plotter as ready, setting its generation as the API server does
+	plotter.Generation = 1
+	plotter.Status.ObservedState.Ready = true
+	g.Expect(cl.Update(context.Background(), plotter)).NotTo(gomega.HaveOccurred())
+	plotterReq := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: req.Namespace, Name: PlotterUpdatePrefix + req.Name}}
+	_, err = r.Reconcile(context.Background(), plotterReq)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(emitter.records).NotTo(gomega.BeEmpty())
+	copyRecord := emitter.records[0]
+	g.Expect(copyRecord.FlowType).To(gomega.Equal(taxonomy.CopyFlow))
+	g.Expect(copyRecord.Source.AssetID).To(gomega.Equal("s3-csv/redact-dataset"))
+	g.Expect(copyRecord.Destination.AssetID).To(gomega.Equal("s3-csv/redact-dataset-copy"))
+	g.Expect(copyRecord.Destination.Connection).NotTo(gomega.BeNil())
+	g.Expect(string(copyRecord.Lineage.Source)).To(gomega.Equal("s3-csv/redact-dataset"))
+	g.Expect(copyRecord.Lineage.Application).To(gomega.Equal(req.NamespacedName.String()))
+	g.Expect(copyRecord.Lineage.Policies).To(gomega.ContainElement(mockup.RedactSSNPolicy))
+	g.Expect(copyRecord.Lineage.DecisionIDs).NotTo(gomega.BeEmpty())
+	g.Expect(copyRecord.Lineage.DecisionIDs).To(gomega.Equal(application.Status.AssetStates["s3-csv/redact-dataset"].DecisionIDs))
+	g.Expect(copyRecord.Lineage.Steps).To(gomega.HaveLen(1))
+	g.Expect(copyRecord.Lineage.Steps[0].Capability).To(gomega.Equal(taxonomy.Capability("copy")))
+	event := copyRecord.Event()
+	g.Expect(event.Inputs).To(gomega.HaveLen(1))
+	g.Expect(event.Outputs).To(gomega.HaveLen(1))
+	g.Expect(event.Job.Name).To(gomega.Equal(req.NamespacedName.String() + "/" + copyRecord.Flow))
+	facet, err := json.Marshal(event.Run.Facets[lineage.FybrikFacet])
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(string(facet)).To(gomega.ContainSubstring(copyRecord.Lineage.DecisionIDs[0]))
+
+	// lineage is recorded once
+	count := len(emitter.records)
+	_, err = r.Reconcile(context.Background(), plotterReq)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(emitter.records).To(gomega.HaveLen(count))
+	g.Expect(cl.Get(context.Background(), req.NamespacedName, application)).NotTo(gomega.HaveOccurred())
+	g.Expect(cl.Get(context.Background(), plotterKey, plotter)).NotTo(gomega.HaveOccurred())
+	g.Expect(application.Status.LineageGenerations["s3-csv/redact-dataset"]).To(gomega.Equal(plotter.Generation))
+
+	// lineage is not recorded again when the plotter becomes ready again with the same plan
+	for _, ready := range []bool{false, true} {
+		plotter.Status.ObservedState.Ready = ready
+		g.Expect(cl.Update(context.Background(), plotter)).NotTo(gomega.HaveOccurred())
+		_, err = r.Reconcile(context.Background(), plotterReq)
+		g.Expect(err).To(gomega.BeNil())
+	}
+	g.Expect(cl.Get(context.Background(), req.NamespacedName, application)).NotTo(gomega.HaveOccurred())
+	g.Expect(application.Status.AssetStates["s3-csv/redact-dataset"].Conditions[ReadyConditionIndex].Status).
+		To(gomega.Equal(corev1.ConditionTrue))
+	g.Expect(emitter.records).To(gomega.HaveLen(count))
+
+	// lineage is not recorded again when the application is planned again without a change of the plotter
+	application.Status.ObservedGeneration = 0
+	g.Expect(cl.Status().Update(context.Background(), application)).NotTo(gomega.HaveOccurred())
+	_, err = r.Reconcile(context.Background(), req)
+	g.Expect(err).To(gomega.BeNil())
+	_, err = r.Reconcile(context.Background(), plotterReq)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(cl.Get(context.Background(), req.NamespacedName, application)).NotTo(gomega.HaveOccurred())
+	g.Expect(application.Status.ObservedGeneration).To(gomega.Equal(application.Generation))
+	g.Expect(application.Status.AssetStates["s3-csv/redact-dataset"].Conditions[ReadyConditionIndex].Status).
+		To(gomega.Equal(corev1.ConditionTrue))
+	g.Expect(emitter.records).To(gomega.HaveLen(count))
+}
+
+// recordingSink keeps the audit records
+type recordingSink struct {
+	records []audit.Record
+}
+
+func (s *recordingSink) Write(record *audit.Record) error {
+	s.records = append(s.records, *record)
+	return nil
+}
+
+// This test checks that the governance decisions, the selected data path and the storage allocation
+// of an application are audited as a chain of records
+func TestAudit(t *testing.T) {
+	t.Parallel()
+	g := gomega.NewGomegaWithT(t)
+	// Set the logger to development mode for verbose logs.
+	logf.SetLogger(zap.New(zap.UseDevMode(true)))
+
+	adminCRsNamespace := environment.GetAdminCRsNamespace()
+	application := &fappv1.FybrikApplication{}
+	g.Expect(readObjectFromFile("../../testdata/unittests/fybrikcopyapp-csv.yaml", application)).NotTo(gomega.HaveOccurred())
+	application.SetGeneration(1)
+	application.SetUID("22")
+	// Register operator types with the runtime scheme.
+	s := utils.NewScheme(g)
+	// Create a fake client to mock API calls.
+	cl := fake.NewFakeClientWithScheme(s, application)
+	for _, file := range []string{"implicit-copy-batch-module-csv.yaml", "module-read-csv.yaml"} {
+		module := &fappv1.FybrikModule{}
+		g.Expect(readObjectFromFile("../../testdata/unittests/"+file, module)).NotTo(gomega.HaveOccurred())
+		module.Namespace = adminCRsNamespace
+		g.Expect(cl.Create(context.Background(), module)).NotTo(gomega.HaveOccurred())
+	}
+	secret := &corev1.Secret{}
+	g.Expect(readObjectFromFile("../../testdata/unittests/credentials-theshire.yaml", secret)).NotTo(gomega.HaveOccurred())
+	secret.Namespace = adminCRsNamespace
+	g.Expect(cl.Create(context.Background(), secret)).NotTo(gomega.HaveOccurred())
+	account := &fappv2.FybrikStorageAccount{}
+	g.Expect(readStorageAccountData("../../testdata/unittests/account-theshire.yaml", account)).NotTo(gomega.HaveOccurred())
+	account.Namespace = adminCRsNamespace
+	g.Expect(cl.Create(context.Background(), account)).NotTo(gomega.HaveOccurred())
+
+	r := createTestFybrikApplicationController(cl, s)
+	g.Expect(r).NotTo(gomega.BeNil())
+	sink := &recordingSink{}
+	r.Audit = audit.NewAuditor(&r.Log, sink)
+	req := reconcile.Request{NamespacedName: client.ObjectKeyFromObject(application)}
+	_, err := r.Reconcile(context.Background(), req)
+	g.Expect(err).To(gomega.BeNil())
+	r.Audit.Flush()
+
+	records := map[audit.RecordType][]audit.Record{}
+	for _, record := range sink.records {
+		g.Expect(record.Version).To(gomega.Equal(audit.Version))
+		g.Expect(record.Application.Name).To(gomega.Equal(req.Name))
+		g.Expect(record.Application.UUID).NotTo(gomega.BeEmpty())
+		records[record.Type] = append(records[record.Type], record)
+	}
+	g.Expect(audit.Verify(sink.records)).To(gomega.Succeed())
+
+	// the read of the dataset is allowed with redaction
+	decisions := records[audit.GovernanceDecisionRecord]
+	g.Expect(decisions).NotTo(gomega.BeEmpty())
+	read := decisions[0].GovernanceDecision
+	g.Expect(read.Request.Action.ActionType).To(gomega.Equal(taxonomy.ReadFlow))
+	g.Expect(read.Allowed).To(gomega.BeTrue())
+	g.Expect(read.Actions).NotTo(gomega.BeEmpty())
+	g.Expect(read.Policies).To(gomega.ContainElement(mockup.RedactSSNPolicy))
+	g.Expect(records[audit.ConfigurationDecisionRecord]).To(gomega.HaveLen(1))
+
+	// the data path copies the dataset and the copy is stored in the theshire account
+	g.Expect(records[audit.DataPathRecord]).To(gomega.HaveLen(1))
+	dataPath := records[audit.DataPathRecord][0].DataPath
+	g.Expect(dataPath.Policies).To(gomega.ContainElement(mockup.RedactSSNPolicy))
+	g.Expect(dataPath.Steps).NotTo(gomega.BeEmpty())
+	g.Expect(records[audit.StorageAllocationRecord]).To(gomega.HaveLen(1))
+	allocation := records[audit.StorageAllocationRecord][0].Storage
+	g.Expect(allocation.Account).To(gomega.Equal(account.Spec.ID))
+	g.Expect(allocation.Connection).NotTo(gomega.BeNil())
+	g.Expect(allocation.Error).To(gomega.BeEmpty())
+}
+
+// listingStorageManager lists the given storage and records the deleted storage
+type listingStorageManager struct {
+	storage.StorageManagerInterface
+	listed  []storagemanager.AllocatedStorage
+	deleted []taxonomy.Connection
+}
+
+func (m *listingStorageManager) ListStorage(request *storagemanager.ListStorageRequest) (*storagemanager.ListStorageResponse,
+	error) {
+	return &storagemanager.ListStorageResponse{Storage: m.listed}, nil
+}
+
+func (m *listingStorageManager) DeleteStorage(request *storagemanager.DeleteStorageRequest) error {
+	m.deleted = append(m.deleted, request.Connection)
+	return nil
+}
+
+func s3Connection(properties map[string]interface{}) taxonomy.Connection {
+	return taxonomy.Connection{Name: "s3", AdditionalProperties: serde.Properties{Items: map[string]interface{}{"s3": properties}}}
+}
+
+// This test checks that storage which is not used by any application is reported in the status of its account,
+// and deleted once the grace period has passed, while used and persistent storage is kept
+func TestStorageGarbageCollection(t *testing.T) {
+	t.Parallel()
+	g := gomega.NewGomegaWithT(t)
+	// Set the logger to development mode for verbose logs.
+	logf.SetLogger(zap.New(zap.UseDevMode(true)))
+
+	adminCRsNamespace := environment.GetAdminCRsNamespace()
+	endpoint := "https://s3.eu-gb.cloud-object-storage.appdomain.cloud"
+	application := &fappv1.FybrikApplication{}
+	g.Expect(readObjectFromFile("../../testdata/unittests/fybrikcopyapp-csv.yaml", application)).NotTo(gomega.HaveOccurred())
+	application.Status.ProvisionedStorage = map[string]fappv1.DatasetDetails{
+		"s3-csv/redact-dataset": {Details: &fappv1.DataStore{
+			Connection: s3Connection(map[string]interface{}{"endpoint": endpoint, "bucket": "used"})}},
+	}
+	account := &fappv2.FybrikStorageAccount{}
+	g.Expect(readStorageAccountData("../../testdata/unittests/account-theshire.yaml", account)).NotTo(gomega.HaveOccurred())
+	account.Namespace = adminCRsNamespace
+	s := utils.NewScheme(g)
+	cl := fake.NewFakeClientWithScheme(s, application, account)
+
+	storageManager := &listingStorageManager{
+		StorageManagerInterface: storage.NewMockupStorageManager(),
+		listed: []storagemanager.AllocatedStorage{
+			{Name: "used", Connection: s3Connection(map[string]interface{}{"endpoint": endpoint, "bucket": "used"})},
+			{Name: "kept", Connection: s3Connection(map[string]interface{}{"endpoint": endpoint, "bucket": "kept"}), Persistent: true},
+			{Name: "orphan", Connection: s3Connection(map[string]interface{}{"endpoint": endpoint, "bucket": "orphan"}),
+				AppDetails: storagemanager.ApplicationDetails{Name: "removed", Namespace: "default"}},
+		},
+	}
+	r := createTestFybrikApplicationController(cl, s)
+	g.Expect(r).NotTo(gomega.BeNil())
+	r.StorageManager = storageManager
+	collector := r.newStorageCollector()
+	collector.gracePeriod = time.Hour
+	collector.deleteOrphans = true
+
+	// the orphan is reported
+	detected := time.Now()
+	g.Expect(collector.collect(detected)).To(gomega.Succeed())
+	g.Expect(cl.Get(context.Background(), client.ObjectKeyFromObject(account), account)).To(gomega.Succeed())
+	g.Expect(account.Status.Orphans).To(gomega.HaveLen(1))
+	g.Expect(account.Status.Orphans[0].Name).To(gomega.Equal("orphan"))
+	g.Expect(account.Status.Orphans[0].Application).To(gomega.Equal("default/removed"))
+	g.Expect(storageManager.deleted).To(gomega.BeEmpty())
+
+	// the detection time is kept until the grace period has passed
+	g.Expect(collector.collect(detected.Add(time.Minute))).To(gomega.Succeed())
+	g.Expect(cl.Get(context.Background(), client.ObjectKeyFromObject(account), account)).To(gomega.Succeed())
+	g.Expect(account.Status.Orphans).To(gomega.HaveLen(1))
+	g.Expect(account.Status.Orphans[0].DetectionTime.Unix()).To(gomega.Equal(detected.Unix()))
+	g.Expect(storageManager.deleted).To(gomega.BeEmpty())
+
+	// the orphan is deleted
+	g.Expect(collector.collect(detected.Add(2 * time.Hour))).To(gomega.Succeed())
+	g.Expect(cl.Get(context.Background(), client.ObjectKeyFromObject(account), account)).To(gomega.Succeed())
+	g.Expect(account.Status.Orphans).To(gomega.BeEmpty())
+	g.Expect(storageManager.deleted).To(gomega.HaveLen(1))
+	g.Expect(isUsed(&storageManager.deleted[0], []taxonomy.Connection{storageManager.listed[2].Connection})).To(gomega.BeTrue())
+}
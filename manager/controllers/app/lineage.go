@@ -0,0 +1,181 @@
+// Copyright 2023 IBM Corp.
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	fappv1 "fybrik.io/fybrik/manager/apis/app/v1beta1"
+	"fybrik.io/fybrik/manager/controllers/utils"
+	"fybrik.io/fybrik/pkg/lineage"
+	"fybrik.io/fybrik/pkg/logging"
+	"fybrik.io/fybrik/pkg/model/datacatalog"
+	"fybrik.io/fybrik/pkg/model/taxonomy"
+)
+
+// newLineageRecords returns the lineage records of the copies, writes and transformations of a dataset
+// that are performed by the plotter of the application, together with the generation of the plotter.
+// Records are returned only for a plotter generation whose lineage has not been emitted yet, so that they are
+// emitted once per plan, and not again when a continuous flow becomes ready after falling behind or when the
+// application is planned again without a change of the plotter, e.g., upon a re-evaluation or a failover.
+func (r *FybrikApplicationReconciler) newLineageRecords(applicationContext ApplicationContext,
+	datasetID string) ([]lineage.Record, int64) {
+	application := applicationContext.Application
+	if application.Status.Generated == nil {
+		return nil, 0
+	}
+	plotter := &fappv1.Plotter{}
+	key := types.NamespacedName{Namespace: application.Status.Generated.Namespace, Name: application.Status.Generated.Name}
+	if err := r.Get(context.Background(), key, plotter); err != nil {
+		applicationContext.Log.Warn().Err(err).Str(logging.DATASETID, datasetID).Msg("Could not get the plotter to record the lineage")
+		return nil, 0
+	}
+	if application.Status.LineageGenerations[datasetID] == plotter.Generation {
+		return nil, plotter.Generation
+	}
+	return lineageRecords(application, &plotter.Spec, datasetID), plotter.Generation
+}
+
+// lineageRecords returns a lineage record for each copy and write subflow of the dataset,
+// and for each read subflow that transforms the data
+func lineageRecords(application *fappv1.FybrikApplication, plotterSpec *fappv1.PlotterSpec, datasetID string) []lineage.Record {
+	records := []lineage.Record{}
+	now := time.Now()
+	for i := range plotterSpec.Flows {
+		flow := &plotterSpec.Flows[i]
+		if flow.AssetID != datasetID {
+			continue
+		}
+		for j := range flow.SubFlows {
+			steps := []fappv1.DataFlowStep{}
+			for _, parallel := range flow.SubFlows[j].Steps {
+				steps = append(steps, parallel...)
+			}
+			record, found := subflowLineage(plotterSpec, flow.SubFlows[j].FlowType, steps)
+			if !found {
+				continue
+			}
+			record.Flow = flow.Name
+			record.Time = now
+			record.Lineage.Application = application.Namespace + "/" + application.Name
+			record.Lineage.ApplicationUUID = utils.GetFybrikApplicationUUID(application)
+			record.Lineage.Policies = flow.Policies
+			record.Lineage.DecisionIDs = application.Status.AssetStates[datasetID].DecisionIDs
+			records = append(records, record)
+		}
+	}
+	return records
+}
+
+// subflowLineage returns the lineage record of a subflow, without the application and flow details.
+// Read subflows that do not transform the data are skipped.
+func subflowLineage(plotterSpec *fappv1.PlotterSpec, flowType taxonomy.DataFlow,
+	steps []fappv1.DataFlowStep) (lineage.Record, bool) {
+	record := lineage.Record{FlowType: flowType}
+	if len(steps) == 0 || steps[0].Parameters == nil || len(steps[0].Parameters.Arguments) == 0 {
+		return record, false
+	}
+	transformed := false
+	for i := range steps {
+		step := lineageStep(plotterSpec, &steps[i])
+		transformed = transformed || len(step.Actions) > 0
+		record.Lineage.Steps = append(record.Lineage.Steps, step)
+	}
+	first, last := steps[0].Parameters, steps[len(steps)-1].Parameters
+	switch flowType {
+	case taxonomy.CopyFlow, taxonomy.StreamFlow:
+		record.Source = assetDataset(plotterSpec, first.Arguments[0].AssetID)
+		if len(last.Arguments) > 1 {
+			record.Destination = assetDataset(plotterSpec, last.Arguments[1].AssetID)
+		}
+	case taxonomy.WriteFlow:
+		// the workload writes the data through the endpoint of the last module
+		record.Source = endpointDataset(last.API)
+		record.Destination = assetDataset(plotterSpec, first.Arguments[0].AssetID)
+	case taxonomy.ReadFlow:
+		if !transformed {
+			return record, false
+		}
+		record.Source = assetDataset(plotterSpec, first.Arguments[0].AssetID)
+		record.Destination = endpointDataset(last.API)
+	default:
+		return record, false
+	}
+	record.Lineage.Source = taxonomy.AssetID(catalogedAssetID(plotterSpec, record.Source.AssetID))
+	return record, true
+}
+
+func lineageStep(plotterSpec *fappv1.PlotterSpec, step *fappv1.DataFlowStep) datacatalog.LineageStep {
+	lineageStep := datacatalog.LineageStep{Name: step.Name, Module: step.Template, Cluster: step.Cluster}
+	if template, found := plotterSpec.Templates[step.Template]; found && len(template.Modules) > 0 {
+		lineageStep.Module = template.Modules[0].Name
+		lineageStep.Capability = template.Modules[0].Capability
+	}
+	if lineageStep.Name == "" {
+		lineageStep.Name = step.Template
+	}
+	if step.Parameters != nil {
+		lineageStep.Actions = step.Parameters.Actions
+	}
+	return lineageStep
+}
+
+func assetDataset(plotterSpec *fappv1.PlotterSpec, assetID string) lineage.Dataset {
+	dataset := lineage.Dataset{AssetID: assetID}
+	if asset, found := plotterSpec.Assets[assetID]; found {
+		dataset.Connection = asset.DataStore.Connection.DeepCopy()
+	}
+	return dataset
+}
+
+func endpointDataset(api *datacatalog.ResourceDetails) lineage.Dataset {
+	if api == nil {
+		return lineage.Dataset{}
+	}
+	return lineage.Dataset{Connection: api.Connection.DeepCopy()}
+}
+
+// catalogedAssetID returns the ID of the asset in the catalog, following copies of the asset made by Fybrik
+func catalogedAssetID(plotterSpec *fappv1.PlotterSpec, assetID string) string {
+	for i := 0; i < len(plotterSpec.Assets); i++ {
+		asset, found := plotterSpec.Assets[assetID]
+		if !found || asset.AdvertisedAssetID == "" {
+			break
+		}
+		assetID = asset.AdvertisedAssetID
+	}
+	return assetID
+}
+
+// registeredAssetLineage returns the lineage of the dataset written by the application, to be stored on the new asset
+func registeredAssetLineage(records []lineage.Record) *datacatalog.AssetLineage {
+	var assetLineage *datacatalog.AssetLineage
+	for i := range records {
+		if records[i].FlowType == taxonomy.CopyFlow || records[i].FlowType == taxonomy.StreamFlow ||
+			records[i].FlowType == taxonomy.WriteFlow {
+			assetLineage = records[i].Lineage.DeepCopy()
+		}
+	}
+	return assetLineage
+}
+
+// emitLineage exports the lineage records and records the plotter generation whose lineage has been emitted.
+// Failures are logged and do not affect the application.
+func (r *FybrikApplicationReconciler) emitLineage(applicationContext ApplicationContext, datasetID string,
+	records []lineage.Record, generation int64) {
+	if r.Lineage != nil {
+		for i := range records {
+			if err := r.Lineage.Emit(&records[i]); err != nil {
+				applicationContext.Log.Warn().Err(err).Msg("Could not emit the lineage event of flow " + records[i].Flow)
+			}
+		}
+	}
+	if applicationContext.Application.Status.LineageGenerations == nil {
+		applicationContext.Application.Status.LineageGenerations = map[string]int64{}
+	}
+	applicationContext.Application.Status.LineageGenerations[datasetID] = generation
+}
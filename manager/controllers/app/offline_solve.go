@@ -0,0 +1,391 @@
+// Copyright 2023 IBM Corp.
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"emperror.dev/errors"
+	"github.com/rs/zerolog"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	k8suuid "k8s.io/apimachinery/pkg/util/uuid"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+
+	fappv1 "fybrik.io/fybrik/manager/apis/app/v1beta1"
+	fappv2 "fybrik.io/fybrik/manager/apis/app/v1beta2"
+	"fybrik.io/fybrik/manager/controllers/utils"
+	"fybrik.io/fybrik/pkg/adminconfig"
+	dcclient "fybrik.io/fybrik/pkg/connectors/datacatalog/clients"
+	pmclient "fybrik.io/fybrik/pkg/connectors/policymanager/clients"
+	"fybrik.io/fybrik/pkg/datapath"
+	"fybrik.io/fybrik/pkg/environment"
+	"fybrik.io/fybrik/pkg/infrastructure"
+	"fybrik.io/fybrik/pkg/logging"
+	"fybrik.io/fybrik/pkg/model/datacatalog"
+	"fybrik.io/fybrik/pkg/model/policymanager"
+	"fybrik.io/fybrik/pkg/model/taxonomy"
+	"fybrik.io/fybrik/pkg/multicluster"
+	"fybrik.io/fybrik/pkg/optimizer"
+)
+
+// OfflineAsset holds the catalog metadata of an asset and the governance decisions on it.
+// It replaces the data catalog and the policy manager when data paths are selected offline.
+type OfflineAsset struct {
+	// Asset ID as referenced by the FybrikApplication
+	ID string `json:"id"`
+	// Response of the data catalog for the asset
+	Catalog datacatalog.GetAssetResponse `json:"catalog"`
+	// Governance decisions on the asset. The actions of all matching decisions are returned.
+	// Operations matching no decision are allowed without actions.
+	Decisions []OfflineDecision `json:"decisions,omitempty"`
+}
+
+// OfflineDecision holds the governance actions required for operations on an asset
+type OfflineDecision struct {
+	// Requested operation (read, write or delete); an empty value matches all operations
+	Operation taxonomy.DataFlow `json:"operation,omitempty"`
+	// Geography to which the data is sent; an empty value matches all destinations
+	Destination string `json:"destination,omitempty"`
+	// Governance actions, a Deny action forbids the operation
+	Actions []taxonomy.Action `json:"actions"`
+}
+
+// OfflineEnvironment describes the deployed modules, clusters, storage accounts, assets and policies
+// used for selecting data paths without a Kubernetes cluster
+type OfflineEnvironment struct {
+	Modules         []fappv1.FybrikModule
+	StorageAccounts []fappv2.FybrikStorageAccount
+	Clusters        []multicluster.Cluster
+	Assets          []OfflineAsset
+	ConfigEvaluator adminconfig.EvaluatorInterface
+	Infrastructure  *infrastructure.AttributeManager
+}
+
+// OfflineSolution is the outcome of selecting a data path for an asset offline
+type OfflineSolution struct {
+	AssetID string
+	// The best data paths found for the asset, from best to worst
+	DataPaths []fappv1.DataPathAlternative
+	// FlatZinc model of the best data path length, empty if the optimizer is not used
+	Model string
+	// Reason for which no data path has been found
+	Error string
+}
+
+// SolveOffline selects data paths for the assets of a FybrikApplication in the given environment,
+// going through the same steps as the FybrikApplication reconciler: validation of the application,
+// collection of the asset metadata, governance actions and configuration decisions, and data path selection.
+// Up to numDataPaths data paths are reported for each asset. No resources are accessed or created.
+func SolveOffline(offlineEnv *OfflineEnvironment, application *fappv1.FybrikApplication,
+	numDataPaths int) ([]OfflineSolution, error) {
+	r, err := newOfflineReconciler(offlineEnv)
+	if err != nil {
+		return nil, err
+	}
+	if application.UID == "" {
+		application.UID = k8suuid.NewUUID()
+	}
+	uuid := utils.GetFybrikApplicationUUID(application)
+	log := r.Log.With().Str(FybrikApplicationKind, application.Namespace+"/"+application.Name).
+		Str(utils.FybrikAppUUID, uuid).Logger()
+	applicationContext := ApplicationContext{Log: &log, Application: application, UUID: uuid}
+	application.Status = fappv1.FybrikApplicationStatus{}
+	if err = application.ValidateFybrikApplication(ApplicationTaxonomy); err != nil {
+		return nil, errors.Wrap(err, "invalid FybrikApplication")
+	}
+	initStatus(application)
+	env, requirements, _, err := r.collectRequirements(applicationContext)
+	if err != nil {
+		return nil, err
+	}
+	solutions := []OfflineSolution{}
+	solved := map[string]bool{}
+	for i := range requirements {
+		solution := OfflineSolution{AssetID: requirements[i].Context.DataSetID}
+		if err := solveOfflineDataset(env, &requirements[i], numDataPaths, &log, &solution); err != nil {
+			solution.Error = err.Error()
+		}
+		solutions = append(solutions, solution)
+		solved[solution.AssetID] = true
+	}
+	// assets for which no requirements were collected are reported with the reason from their state
+	for _, dataset := range application.Spec.Data {
+		if solved[dataset.DataSetID] {
+			continue
+		}
+		conditions := application.Status.AssetStates[dataset.DataSetID].Conditions
+		message := conditions[ErrorConditionIndex].Message
+		if conditions[DenyConditionIndex].Status == corev1.ConditionTrue {
+			message = conditions[DenyConditionIndex].Message
+		}
+		solutions = append(solutions, OfflineSolution{AssetID: dataset.DataSetID, Error: message})
+	}
+	return solutions, nil
+}
+
+// find the best data paths for a dataset and the FlatZinc model from which the best one is selected
+func solveOfflineDataset(env *datapath.Environment, dataset *datapath.DataInfo, numDataPaths int,
+	log *zerolog.Logger, solution *OfflineSolution) error {
+	if err := validateBasicConditions(env, []datapath.DataInfo{*dataset}, log); err != nil {
+		return err
+	}
+	scoredSolutions, err := findAlternatives(env, dataset, numDataPaths, log)
+	if err != nil {
+		return err
+	}
+	if len(scoredSolutions) == 0 {
+		return datapath.NewDataPathError(env, dataset)
+	}
+	solution.DataPaths = alternativesToStatus(scoredSolutions)
+	if !environment.UseCSP() {
+		return nil
+	}
+	model, err := optimizer.NewDataPathCSP(dataset, env).BuildModel(len(scoredSolutions[0].DataPath))
+	if err != nil {
+		return err
+	}
+	solution.Model = model.String()
+	return nil
+}
+
+// create a reconciler whose modules and storage accounts are read from memory,
+// and whose data catalog and policy manager are replaced by the given assets
+func newOfflineReconciler(offlineEnv *OfflineEnvironment) (*FybrikApplicationReconciler, error) {
+	scheme := runtime.NewScheme()
+	if err := fappv1.AddToScheme(scheme); err != nil {
+		return nil, err
+	}
+	if err := fappv2.AddToScheme(scheme); err != nil {
+		return nil, err
+	}
+	resources := &offlineClient{scheme: scheme}
+	for i := range offlineEnv.Modules {
+		module := offlineEnv.Modules[i].DeepCopy()
+		module.Namespace = environment.GetAdminCRsNamespace()
+		resources.modules = append(resources.modules, *module)
+	}
+	for i := range offlineEnv.StorageAccounts {
+		account := offlineEnv.StorageAccounts[i].DeepCopy()
+		account.Namespace = environment.GetAdminCRsNamespace()
+		resources.accounts = append(resources.accounts, *account)
+	}
+	assets := map[string]*OfflineAsset{}
+	for i := range offlineEnv.Assets {
+		assets[offlineEnv.Assets[i].ID] = &offlineEnv.Assets[i]
+	}
+	return &FybrikApplicationReconciler{
+		Client:          resources,
+		Name:            "OfflineSolver",
+		Log:             logging.LogInit(logging.CONTROLLER, "offline-solver"),
+		Scheme:          scheme,
+		PolicyManager:   &offlinePolicyManager{assets: assets},
+		DataCatalog:     &offlineCatalog{assets: assets},
+		ClusterManager:  &offlineClusterLister{clusters: offlineEnv.Clusters},
+		ConfigEvaluator: offlineEnv.ConfigEvaluator,
+		Infrastructure:  offlineEnv.Infrastructure,
+	}, nil
+}
+
+// errOffline is returned when resources are modified offline
+var errOffline = errors.New("resources cannot be modified offline")
+
+// offlineClient reads the modules and storage accounts of the offline environment from memory.
+// Other resources are not found, and no resource can be modified.
+type offlineClient struct {
+	scheme   *runtime.Scheme
+	modules  []fappv1.FybrikModule
+	accounts []fappv2.FybrikStorageAccount
+}
+
+func (c *offlineClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	switch obj := obj.(type) {
+	case *fappv1.FybrikModule:
+		for i := range c.modules {
+			if client.ObjectKeyFromObject(&c.modules[i]) == key {
+				c.modules[i].DeepCopyInto(obj)
+				return nil
+			}
+		}
+	case *fappv2.FybrikStorageAccount:
+		for i := range c.accounts {
+			if client.ObjectKeyFromObject(&c.accounts[i]) == key {
+				c.accounts[i].DeepCopyInto(obj)
+				return nil
+			}
+		}
+	}
+	gvk, err := apiutil.GVKForObject(obj, c.scheme)
+	if err != nil {
+		return err
+	}
+	return apierrors.NewNotFound(schema.GroupResource{Group: gvk.Group, Resource: gvk.Kind}, key.Name)
+}
+
+func (c *offlineClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	listOpts := &client.ListOptions{}
+	listOpts.ApplyOptions(opts)
+	inNamespace := func(namespace string) bool {
+		return listOpts.Namespace == "" || listOpts.Namespace == namespace
+	}
+	switch list := list.(type) {
+	case *fappv1.FybrikModuleList:
+		list.Items = []fappv1.FybrikModule{}
+		for i := range c.modules {
+			if inNamespace(c.modules[i].Namespace) {
+				list.Items = append(list.Items, *c.modules[i].DeepCopy())
+			}
+		}
+	case *fappv2.FybrikStorageAccountList:
+		list.Items = []fappv2.FybrikStorageAccount{}
+		for i := range c.accounts {
+			if inNamespace(c.accounts[i].Namespace) {
+				list.Items = append(list.Items, *c.accounts[i].DeepCopy())
+			}
+		}
+	default:
+		return fmt.Errorf("%T cannot be listed offline", list)
+	}
+	return nil
+}
+
+func (c *offlineClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	return errOffline
+}
+
+func (c *offlineClient) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	return errOffline
+}
+
+func (c *offlineClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	return errOffline
+}
+
+func (c *offlineClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	return errOffline
+}
+
+func (c *offlineClient) DeleteAllOf(ctx context.Context, obj client.Object, opts ...client.DeleteAllOfOption) error {
+	return errOffline
+}
+
+func (c *offlineClient) Status() client.StatusWriter {
+	return offlineStatusWriter{}
+}
+
+func (c *offlineClient) Scheme() *runtime.Scheme {
+	return c.scheme
+}
+
+func (c *offlineClient) RESTMapper() meta.RESTMapper {
+	return nil
+}
+
+// offlineStatusWriter rejects status updates
+type offlineStatusWriter struct{}
+
+func (offlineStatusWriter) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	return errOffline
+}
+
+func (offlineStatusWriter) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	return errOffline
+}
+
+// offlineCatalog returns the metadata of the offline assets
+type offlineCatalog struct {
+	assets map[string]*OfflineAsset
+}
+
+func (c *offlineCatalog) GetAssetInfo(in *datacatalog.GetAssetRequest, creds string) (*datacatalog.GetAssetResponse, error) {
+	asset, found := c.assets[string(in.AssetID)]
+	if !found {
+		return nil, errors.New(dcclient.AssetIDNotFound)
+	}
+	return asset.Catalog.DeepCopy(), nil
+}
+
+func (c *offlineCatalog) CreateAsset(in *datacatalog.CreateAssetRequest, creds string) (*datacatalog.CreateAssetResponse, error) {
+	return nil, errors.New("assets cannot be created offline")
+}
+
+func (c *offlineCatalog) DeleteAsset(in *datacatalog.DeleteAssetRequest, creds string) (*datacatalog.DeleteAssetResponse, error) {
+	return nil, errors.New("assets cannot be deleted offline")
+}
+
+func (c *offlineCatalog) UpdateAsset(in *datacatalog.UpdateAssetRequest, creds string) (*datacatalog.UpdateAssetResponse, error) {
+	return nil, errors.New("assets cannot be updated offline")
+}
+
+func (c *offlineCatalog) ListAssets(in *datacatalog.ListAssetsRequest, creds string) (*datacatalog.ListAssetsResponse, error) {
+	assets := []datacatalog.AssetInfo{}
+	for id, asset := range c.assets {
+		assets = append(assets, datacatalog.AssetInfo{
+			AssetID:          taxonomy.AssetID(id),
+			ResourceMetadata: *asset.Catalog.ResourceMetadata.DeepCopy(),
+			Details:          *asset.Catalog.Details.DeepCopy(),
+		})
+	}
+	return in.Page(assets), nil
+}
+
+func (c *offlineCatalog) Close() error {
+	return nil
+}
+
+// offlinePolicyManager returns the governance actions of the offline asset decisions matching the request
+type offlinePolicyManager struct {
+	assets map[string]*OfflineAsset
+}
+
+func (m *offlinePolicyManager) GetPoliciesDecisions(in *policymanager.GetPolicyDecisionsRequest,
+	creds string) (*policymanager.GetPolicyDecisionsResponse, error) {
+	response := &policymanager.GetPolicyDecisionsResponse{Result: []policymanager.ResultItem{}}
+	asset, found := m.assets[string(in.Resource.ID)]
+	if !found {
+		return response, nil
+	}
+	for i, decision := range asset.Decisions {
+		if decision.Operation != "" && decision.Operation != in.Action.ActionType {
+			continue
+		}
+		if decision.Destination != "" && decision.Destination != in.Action.Destination {
+			continue
+		}
+		for _, action := range decision.Actions {
+			response.Result = append(response.Result, policymanager.ResultItem{
+				Policy: fmt.Sprintf("decision %d of asset %s", i+1, asset.ID),
+				Action: action,
+			})
+		}
+	}
+	return response, nil
+}
+
+func (m *offlinePolicyManager) GetPoliciesDecisionsBatch(in *policymanager.GetPolicyDecisionsBatchRequest,
+	creds string) (*policymanager.GetPolicyDecisionsBatchResponse, error) {
+	return pmclient.GetPoliciesDecisionsOneByOne(m, in, creds)
+}
+
+func (m *offlinePolicyManager) Close() error {
+	return nil
+}
+
+// offlineClusterLister lists a fixed set of clusters
+type offlineClusterLister struct {
+	clusters []multicluster.Cluster
+}
+
+func (l *offlineClusterLister) GetClusters() ([]multicluster.Cluster, error) {
+	return l.clusters, nil
+}
+
+func (l *offlineClusterLister) IsMultiClusterSetup() bool {
+	return len(l.clusters) > 1
+}
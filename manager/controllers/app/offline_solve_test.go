@@ -0,0 +1,92 @@
+// Copyright 2023 IBM Corp.
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"testing"
+
+	"github.com/onsi/gomega"
+
+	fappv1 "fybrik.io/fybrik/manager/apis/app/v1beta1"
+	fappv2 "fybrik.io/fybrik/manager/apis/app/v1beta2"
+	"fybrik.io/fybrik/manager/controllers/mockup"
+	"fybrik.io/fybrik/pkg/adminconfig"
+	"fybrik.io/fybrik/pkg/environment"
+	"fybrik.io/fybrik/pkg/infrastructure"
+	"fybrik.io/fybrik/pkg/model/datacatalog"
+	"fybrik.io/fybrik/pkg/model/taxonomy"
+)
+
+// This test selects data paths offline for a read scenario requiring an implicit copy, and for an asset that may not be read.
+func TestSolveOffline(t *testing.T) {
+	t.Parallel()
+	g := gomega.NewGomegaWithT(t)
+
+	copyModule := fappv1.FybrikModule{}
+	readModule := fappv1.FybrikModule{}
+	g.Expect(readObjectFromFile("../../testdata/unittests/implicit-copy-batch-module-csv.yaml", &copyModule)).NotTo(gomega.HaveOccurred())
+	g.Expect(readObjectFromFile("../../testdata/unittests/module-read-csv.yaml", &readModule)).NotTo(gomega.HaveOccurred())
+	account := fappv2.FybrikStorageAccount{}
+	g.Expect(readStorageAccountData("../../testdata/unittests/account-theshire.yaml", &account)).NotTo(gomega.HaveOccurred())
+	clusters, err := (&mockup.ClusterLister{}).GetClusters()
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	catalogResponse, err := mockup.NewTestCatalog().GetAssetInfo(&datacatalog.GetAssetRequest{AssetID: "s3-csv/redact-dataset"}, "")
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	evaluator, err := adminconfig.NewRegoPolicyEvaluator()
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	attributeManager, err := infrastructure.NewAttributeManager()
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+
+	offlineEnv := &OfflineEnvironment{
+		Modules:         []fappv1.FybrikModule{copyModule, readModule},
+		StorageAccounts: []fappv2.FybrikStorageAccount{account},
+		Clusters:        clusters,
+		Assets: []OfflineAsset{
+			{
+				ID:      "s3-csv/redact-dataset",
+				Catalog: *catalogResponse,
+				Decisions: []OfflineDecision{
+					{Operation: taxonomy.ReadFlow, Actions: []taxonomy.Action{{Name: "RedactAction"}}},
+				},
+			},
+			{
+				ID:      "s3-csv/deny-dataset",
+				Catalog: *catalogResponse,
+				Decisions: []OfflineDecision{
+					{Operation: taxonomy.ReadFlow, Actions: []taxonomy.Action{{Name: "Deny"}}},
+				},
+			},
+		},
+		ConfigEvaluator: evaluator,
+		Infrastructure:  attributeManager,
+	}
+	application := &fappv1.FybrikApplication{}
+	g.Expect(readObjectFromFile("../../testdata/unittests/fybrikcopyapp-csv.yaml", application)).NotTo(gomega.HaveOccurred())
+	deniedDataset := application.Spec.Data[0].DeepCopy()
+	deniedDataset.DataSetID = "s3-csv/deny-dataset"
+	application.Spec.Data = append(application.Spec.Data, *deniedDataset)
+
+	solutions, err := SolveOffline(offlineEnv, application, 1)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(solutions).To(gomega.HaveLen(2))
+
+	g.Expect(solutions[0].AssetID).To(gomega.Equal("s3-csv/redact-dataset"))
+	g.Expect(solutions[0].Error).To(gomega.BeEmpty())
+	g.Expect(solutions[0].DataPaths).To(gomega.HaveLen(1))
+	steps := solutions[0].DataPaths[0].Steps
+	g.Expect(steps).To(gomega.HaveLen(2))
+	g.Expect(steps[0].Capability).To(gomega.Equal(taxonomy.Capability("copy")))
+	g.Expect(steps[0].Actions).To(gomega.ConsistOf(taxonomy.ActionName("RedactAction")))
+	g.Expect(steps[0].StorageAccount).To(gomega.Equal(account.Spec.ID))
+	g.Expect(steps[1].Capability).To(gomega.Equal(taxonomy.Capability("read")))
+	if environment.UseCSP() {
+		g.Expect(solutions[0].Model).To(gomega.ContainSubstring("solve"))
+	} else {
+		g.Expect(solutions[0].Model).To(gomega.BeEmpty())
+	}
+
+	g.Expect(solutions[1].AssetID).To(gomega.Equal("s3-csv/deny-dataset"))
+	g.Expect(solutions[1].DataPaths).To(gomega.BeEmpty())
+	g.Expect(solutions[1].Error).To(gomega.Equal(ReadAccessDenied))
+}
@@ -20,7 +20,9 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	ctrlutil "sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 
@@ -94,10 +96,12 @@ func (r *PlotterReconciler) removeFinalizers(ctx context.Context, plotter *fapp.
 	if ctrlutil.ContainsFinalizer(plotter, PlotterFinalizerName) {
 		original := plotter.DeepCopy()
 		// the finalizer is present - delete the allocated resources
+		available := clusterNames(r.ClusterManager.GetClusters())
 		for cluster, blueprint := range plotter.Status.Blueprints {
 			// TODO Check namespace deletion. Some finalizers leave namespaces in terminating state
 			err := r.ClusterManager.DeleteBlueprint(cluster, blueprint.Namespace, blueprint.Name)
-			if err != nil && client.IgnoreNotFound(err) != nil {
+			// the blueprint of a cluster that has failed or is not registered any more can not be deleted
+			if err != nil && client.IgnoreNotFound(err) != nil && (available == nil || available[cluster]) {
 				return err
 			}
 			delete(plotter.Status.Blueprints, cluster)
@@ -111,6 +115,18 @@ func (r *PlotterReconciler) removeFinalizers(ctx context.Context, plotter *fapp.
 	return nil
 }
 
+// clusterNames returns the names of the clusters returned by the cluster manager, or nil if they could not be listed
+func clusterNames(clusters []multicluster.Cluster, err error) map[string]bool {
+	if err != nil {
+		return nil
+	}
+	names := make(map[string]bool, len(clusters))
+	for i := range clusters {
+		names[clusters[i].Name] = true
+	}
+	return names
+}
+
 // PlotterModulesSpec consists of module details extracted from the Plotter structure
 type PlotterModulesSpec struct {
 	ClusterName      string
@@ -123,6 +139,8 @@ type PlotterModulesSpec struct {
 	Scope            fapp.CapabilityScope
 	Capability       taxonomy.Capability
 	ExternalServices []string
+	Continuous       bool
+	Scheduled        bool
 }
 
 // ServiceInfo stores the service API and indicates whether it is exposed to the workload
@@ -153,6 +171,26 @@ func addCredentials(dataStore *fapp.DataStore, vaultAuthPath string, flowType ta
 	dataStore.Vault = vaultMap
 }
 
+// isContinuous returns true if the subflow runs continuously rather than upon an event
+func isContinuous(subFlow *fapp.SubFlow) bool {
+	for _, trigger := range subFlow.Triggers {
+		if trigger == fapp.ContinuousTrigger {
+			return true
+		}
+	}
+	return false
+}
+
+// isScheduled returns true if the subflow runs upon scheduled times
+func isScheduled(subFlow *fapp.SubFlow) bool {
+	for _, trigger := range subFlow.Triggers {
+		if trigger == fapp.TimerTrigger {
+			return true
+		}
+	}
+	return false
+}
+
 // UniqueReleaseName returns the combination of release and the cluster
 func UniqueReleaseName(cluster, release string) string {
 	return cluster + "," + release
@@ -168,8 +206,10 @@ func (r *PlotterReconciler) convertPlotterModuleToBlueprintModule(plotter *fapp.
 			Arguments: fapp.ModuleArguments{
 				Assets: []fapp.AssetContext{},
 			},
-			AssetIDs: []string{plotterModule.AssetID},
-			Network:  fapp.ModuleNetwork{URLs: plotterModule.ExternalServices},
+			AssetIDs:   []string{plotterModule.AssetID},
+			Network:    fapp.ModuleNetwork{URLs: plotterModule.ExternalServices},
+			Continuous: plotterModule.Continuous,
+			Scheduled:  plotterModule.Scheduled,
 		},
 		ClusterName: plotterModule.ClusterName,
 		Scope:       plotterModule.Scope,
@@ -189,7 +229,7 @@ func (r *PlotterReconciler) convertPlotterModuleToBlueprintModule(plotter *fapp.
 			dataStore = &assetInfo.DataStore
 			// Get the operation of the first argument from the flow type.
 			operation := plotterModule.FlowType
-			if plotterModule.FlowType == taxonomy.CopyFlow {
+			if plotterModule.FlowType == taxonomy.CopyFlow || plotterModule.FlowType == taxonomy.StreamFlow {
 				operation = taxonomy.ReadFlow
 			}
 			addCredentials(dataStore, plotterModule.VaultAuthPath, operation)
@@ -224,6 +264,7 @@ func (r *PlotterReconciler) convertPlotterModuleToBlueprintModule(plotter *fapp.
 			AssetID:         plotterModule.AssetID,
 			Transformations: plotterModule.ModuleArguments.Actions,
 			Capability:      plotterModule.Capability,
+			Columns:         plotter.Spec.Assets[plotterModule.AssetID].Columns,
 		},
 	}
 	return blueprintModule
@@ -231,14 +272,13 @@ func (r *PlotterReconciler) convertPlotterModuleToBlueprintModule(plotter *fapp.
 
 // getBlueprintsMap constructs a map of blueprints driven by the plotter structure.
 // The key is the cluster name.
-func (r *PlotterReconciler) getBlueprintsMap(plotter *fapp.Plotter) map[string]fapp.BlueprintSpec {
+func (r *PlotterReconciler) getBlueprintsMap(plotter *fapp.Plotter, clusters []multicluster.Cluster) map[string]fapp.BlueprintSpec {
 	uuid := managerUtils.GetFybrikApplicationUUIDfromAnnotations(plotter.GetAnnotations())
 	log := r.Log.With().Str(logging.CONTROLLER, PlotterKind).Str(managerUtils.FybrikAppUUID, uuid).Logger()
 
 	log.Trace().Msg("Constructing Blueprints from Plotter")
 	moduleInstances := make([]ModuleInstanceSpec, 0)
 	serviceMap := Services{}
-	clusters, _ := r.ClusterManager.GetClusters()
 
 	for _, flow := range plotter.Spec.Flows {
 		for subFlowInd, subFlow := range flow.SubFlows {
@@ -266,7 +306,7 @@ func (r *PlotterReconciler) getBlueprintsMap(plotter *fapp.Plotter) map[string]f
 							}
 						}
 						// release name (for easier matching between release and API)
-						instanceName := managerUtils.CreateStepName(module.Name, flow.AssetID, scope)
+						instanceName := managerUtils.CreateStepName(module.Name, flow.AssetID, scope, plotter.Spec.ReleaseSuffix)
 						releaseName := managerUtils.GetReleaseName(managerUtils.GetApplicationNameFromLabels(plotter.Labels),
 							uuid, instanceName)
 
@@ -298,6 +338,8 @@ func (r *PlotterReconciler) getBlueprintsMap(plotter *fapp.Plotter) map[string]f
 							Capability:       module.Capability,
 							VaultAuthPath:    authPath,
 							ExternalServices: module.ExternalServices,
+							Continuous:       isContinuous(&flow.SubFlows[subFlowInd]),
+							Scheduled:        isScheduled(&flow.SubFlows[subFlowInd]),
 						}
 
 						blueprintModule := r.convertPlotterModuleToBlueprintModule(plotter, plotterModule)
@@ -364,9 +406,20 @@ func (r *PlotterReconciler) reconcile(plotter *fapp.Plotter) (ctrl.Result, []err
 	// Reconciliation loop per cluster
 	isReady := true
 
-	blueprintsMap := r.getBlueprintsMap(plotter)
+	clusters, clustersErr := r.ClusterManager.GetClusters()
+	blueprintsMap := r.getBlueprintsMap(plotter, clusters)
 
 	var errorCollection []error
+	// a sequential rollout updates the existing blueprints itself, the loop below only creates the new ones
+	sequential := plotter.Spec.RolloutStrategy == fapp.Sequential
+	if sequential {
+		if err := r.rollOutSequentially(plotter, blueprintsMap, &log); err != nil {
+			log.Error().Err(err).Msg("Could not roll out the plotter")
+			errorCollection = append(errorCollection, err)
+			// will retry
+			return ctrl.Result{}, errorCollection
+		}
+	}
 	noRemoteBlueprintWarnMsg := "Could not yet find remote blueprint"
 	for cluster := range blueprintsMap {
 		blueprintSpec := blueprintsMap[cluster]
@@ -396,7 +449,7 @@ func (r *PlotterReconciler) reconcile(plotter *fapp.Plotter) (ctrl.Result, []err
 					" plotter.observedGeneration " + fmt.Sprint(plotter.Status.ObservedGeneration))
 				logging.LogStructure("Expected", &blueprintSpec, &log, zerolog.WarnLevel, false, false)
 				logging.LogStructure("Received", &remoteBlueprint.Spec, &log, zerolog.WarnLevel, false, false)
-				if plotter.Generation != plotter.Status.ObservedGeneration {
+				if plotter.Generation != plotter.Status.ObservedGeneration && !sequential {
 					log.Trace().Str(logging.ACTION, logging.UPDATE).Msg("Updating blueprint...")
 					remoteBlueprint.Spec = blueprintSpec
 					err := r.ClusterManager.UpdateBlueprint(cluster, remoteBlueprint)
@@ -425,7 +478,8 @@ func (r *PlotterReconciler) reconcile(plotter *fapp.Plotter) (ctrl.Result, []err
 
 			plotter.Status.Blueprints[cluster] = fapp.CreateMetaBlueprint(remoteBlueprint)
 
-			if !remoteBlueprint.Status.ObservedState.Ready {
+			// the state of an earlier generation of the blueprint may not have been updated yet
+			if !remoteBlueprint.Status.ObservedState.Ready || remoteBlueprint.Status.ObservedGeneration != remoteBlueprint.Generation {
 				isReady = false
 			}
 
@@ -435,6 +489,11 @@ func (r *PlotterReconciler) reconcile(plotter *fapp.Plotter) (ctrl.Result, []err
 			}
 			r.updatePlotterAssetsState(assetToStatusMap, remoteBlueprint)
 		} else {
+			// the blueprints created by a rolled back generation have been deleted
+			if rolloutError(plotter) != "" {
+				isReady = false
+				continue
+			}
 			log.Warn().Msg("Found no status for cluster " + cluster)
 			blueprint := &fapp.Blueprint{
 				TypeMeta: metav1.TypeMeta{
@@ -473,16 +532,23 @@ func (r *PlotterReconciler) reconcile(plotter *fapp.Plotter) (ctrl.Result, []err
 
 	// Tidy up blueprints that have been deployed but are not in the spec any more
 	// E.g. after a plotter has been updated
+	// During a sequential rollout they keep serving until the rollout completes, and they are kept if it is rolled back.
+	// During a blue/green rollout they keep serving until the blueprints of the other clusters are ready.
+	available := clusterNames(clusters, clustersErr)
+	keep := keepsPreviousBlueprints(plotter) || (plotter.Spec.RolloutStrategy == fapp.BlueGreen && !isReady)
 	for cluster, remoteBlueprint := range plotter.Status.Blueprints {
-		if _, exists := blueprintsMap[cluster]; !exists {
+		if _, exists := blueprintsMap[cluster]; !exists && !keep {
 			err := r.ClusterManager.DeleteBlueprint(cluster, remoteBlueprint.Namespace, remoteBlueprint.Name)
-			if err != nil {
-				if !strings.HasPrefix(err.Error(), "Query channelByName error. Could not find the channel with name") {
+			if err != nil && !strings.HasPrefix(err.Error(), "Query channelByName error. Could not find the channel with name") {
+				if available == nil || available[cluster] {
 					errorCollection = append(errorCollection, err)
 					log.Error().Err(err).Str(logging.CLUSTER, cluster).Str(logging.BLUEPRINT, remoteBlueprint.Name).
 						Str(logging.ACTION, logging.DELETE).Msg("Could not delete remote blueprint after spec changed!")
 					continue
 				}
+				// the cluster has failed or is not registered any more, hence the blueprint can not be deleted
+				log.Warn().Err(err).Str(logging.CLUSTER, cluster).Str(logging.BLUEPRINT, remoteBlueprint.Name).
+					Str(logging.ACTION, logging.DELETE).Msg("Dropping the blueprint of an unavailable cluster without deleting it")
 			}
 			delete(plotter.Status.Blueprints, cluster)
 			log.Trace().Str(logging.PLOTTER, plotter.Name).Str(logging.CLUSTER, cluster).Str(logging.NAMESPACE, remoteBlueprint.Namespace).
@@ -490,6 +556,12 @@ func (r *PlotterReconciler) reconcile(plotter *fapp.Plotter) (ctrl.Result, []err
 		}
 	}
 
+	// a rolled back generation is reported as an error of the plotter
+	if msg := rolloutError(plotter); msg != "" {
+		isReady = false
+		plotter.Status.ObservedState.Error = msg
+	}
+
 	// Update observed generation
 	plotter.Status.ObservedGeneration = plotter.ObjectMeta.Generation
 	plotter.Status.ObservedState.Ready = isReady
@@ -530,24 +602,24 @@ func (r *PlotterReconciler) reconcile(plotter *fapp.Plotter) (ctrl.Result, []err
 		plotter.Status.ObservedState.Error = aggregatedError
 	}
 	// plotter is not ready
-	if r.ClusterManager.IsMultiClusterSetup() {
+	if _, watched := multicluster.BlueprintWatcherOf(r.ClusterManager); r.ClusterManager.IsMultiClusterSetup() && !watched {
 		// if an error exists it is logged in LogEnvVariables and a default value is used
 		requeueAfter, _ := environment.GetResourcesPollingInterval()
 		// TODO Once a better notification mechanism exists in razee switch to that
 		return ctrl.Result{RequeueAfter: requeueAfter}, errorCollection
 	}
-	// don't do polling for a single cluster setup, retry in case of errors
+	// don't do polling when the blueprints are watched, retry in case of errors
 	return ctrl.Result{}, errorCollection
 }
 
 // NewPlotterReconciler creates a new reconciler for Plotter resources
-func NewPlotterReconciler(mgr ctrl.Manager, name string, manager multicluster.ClusterManager) *PlotterReconciler {
+func NewPlotterReconciler(mgr ctrl.Manager, name string, clusterManager multicluster.ClusterManager) *PlotterReconciler {
 	return &PlotterReconciler{
 		Client:         mgr.GetClient(),
 		Name:           name,
 		Log:            logging.LogInit(logging.CONTROLLER, name),
 		Scheme:         mgr.GetScheme(),
-		ClusterManager: manager,
+		ClusterManager: clusterManager,
 	}
 }
 
@@ -555,14 +627,15 @@ func NewPlotterReconciler(mgr ctrl.Manager, name string, manager multicluster.Cl
 func (r *PlotterReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	numReconciles := environment.GetEnvAsInt(controllers.PlotterConcurrentReconcilesConfiguration,
 		controllers.DefaultPlotterConcurrentReconciles)
-	if r.ClusterManager.IsMultiClusterSetup() {
+	watcher, watched := multicluster.BlueprintWatcherOf(r.ClusterManager)
+	if r.ClusterManager.IsMultiClusterSetup() && !watched {
 		return ctrl.NewControllerManagedBy(mgr).
 			WithOptions(controller.Options{MaxConcurrentReconciles: numReconciles}).
 			For(&fapp.Plotter{}).
 			Complete(r)
 	}
-	// for a single cluster setup there is no need in polling since
-	// blueprints can be watched by the plotter controller
+	// for a single cluster setup, or if the cluster manager watches the remote blueprints,
+	// there is no need in polling since blueprints can be watched by the plotter controller
 	mapFn := func(obj client.Object) []reconcile.Request {
 		if !obj.GetDeletionTimestamp().IsZero() {
 			// the owned resource is deleted - no updates should be sent
@@ -580,10 +653,24 @@ func (r *PlotterReconciler) SetupWithManager(mgr ctrl.Manager) error {
 			{NamespacedName: client.ObjectKeyFromObject(obj)},
 		}
 	}
-	return ctrl.NewControllerManagedBy(mgr).
+	builder := ctrl.NewControllerManagedBy(mgr).
 		WithOptions(controller.Options{MaxConcurrentReconciles: numReconciles}).
 		For(&fapp.Plotter{}).
 		Watches(&source.Kind{Type: &fapp.Blueprint{}},
-			handler.EnqueueRequestsFromMapFunc(mapFn)).
-		Complete(r)
+			handler.EnqueueRequestsFromMapFunc(mapFn))
+	if watched {
+		// the blueprints of the remote clusters are sent by the cluster manager once the manager is started
+		events := make(chan event.GenericEvent)
+		builder = builder.Watches(&source.Channel{Source: events}, handler.EnqueueRequestsFromMapFunc(mapFn))
+		if err := mgr.Add(manager.RunnableFunc(func(ctx context.Context) error {
+			if err := watcher.WatchBlueprints(ctx, events); err != nil {
+				return err
+			}
+			<-ctx.Done()
+			return nil
+		})); err != nil {
+			return err
+		}
+	}
+	return builder.Complete(r)
 }
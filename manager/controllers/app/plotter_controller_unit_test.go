@@ -5,6 +5,7 @@ package app
 
 import (
 	"context"
+	"errors"
 	"os"
 	"strings"
 	"testing"
@@ -383,3 +384,342 @@ func TestPlotterMultipleAssets(t *testing.T) {
 		verifiedModules += 1
 	}
 }
+
+// This test checks that a sequential rollout updates the blueprints of one cluster at a time,
+// and rolls the updated clusters back when a blueprint fails.
+func TestPlotterSequentialRollout(t *testing.T) {
+	t.Parallel()
+	g := gomega.NewGomegaWithT(t)
+	// Set the logger to development mode for verbose logs.
+	logf.SetLogger(zap.New(zap.UseDevMode(true)))
+
+	namespace := environment.GetInternalCRsNamespace()
+	plotterYAML, err := os.ReadFile("../../testdata/plotter-read-transform.yaml")
+	g.Expect(err).To(gomega.BeNil(), "Cannot read plotter file for test")
+	plotter := &fapp.Plotter{}
+	err = yaml.Unmarshal(plotterYAML, plotter)
+	g.Expect(err).To(gomega.BeNil(), "Cannot read plotter file for test")
+	plotter.Namespace = namespace
+	plotter.Generation = 1
+	plotter.Spec.RolloutStrategy = fapp.Sequential
+
+	s := utils.NewScheme(g)
+	cl := fake.NewFakeClientWithScheme(s, plotter)
+	dummyManager := dummy.NewDummyClusterManager(
+		make(map[string]*fapp.Blueprint),
+		[]multicluster.Cluster{
+			{Name: "thegreendragon", Metadata: multicluster.ClusterMetadata{Region: "theshire", VaultAuthPath: "kubernetes"}},
+			{Name: "neverland-cluster", Metadata: multicluster.ClusterMetadata{Region: "neverland", VaultAuthPath: "kubernetes"}},
+		})
+	r := &PlotterReconciler{
+		Client:         cl,
+		Log:            logging.LogInit(logging.CONTROLLER, "test-controller"),
+		Scheme:         s,
+		ClusterManager: &dummyManager,
+	}
+	req := reconcile.Request{NamespacedName: types.NamespacedName{Name: plotter.Name, Namespace: namespace}}
+
+	// simulates the blueprint controller
+	observe := func(cluster string, state fapp.ObservedState) {
+		blueprint := dummyManager.DeployedBlueprints[cluster]
+		blueprint.Status.ObservedGeneration = blueprint.Generation
+		blueprint.Status.ObservedState = state
+	}
+	// changes the plotter spec, which changes the blueprints of both clusters
+	change := func(intent string) {
+		g.Expect(cl.Get(context.Background(), req.NamespacedName, plotter)).To(gomega.Succeed())
+		plotter.Spec.AppInfo.Items["intent"] = intent
+		plotter.Generation++
+		g.Expect(cl.Update(context.Background(), plotter)).To(gomega.Succeed())
+	}
+	intentOf := func(cluster string) interface{} {
+		return dummyManager.DeployedBlueprints[cluster].Spec.Application.Context.Items["intent"]
+	}
+	reconcilePlotter := func() {
+		_, err := r.Reconcile(context.Background(), req)
+		g.Expect(err).To(gomega.BeNil())
+		g.Expect(cl.Get(context.Background(), req.NamespacedName, plotter)).To(gomega.Succeed())
+	}
+
+	// the blueprints of a new plotter are created together
+	reconcilePlotter()
+	g.Expect(dummyManager.DeployedBlueprints).To(gomega.HaveLen(2))
+	g.Expect(dummyManager.DeployedBlueprints["thegreendragon"].Spec.RolloutStrategy).To(gomega.Equal(fapp.Sequential))
+	observe("thegreendragon", fapp.ObservedState{Ready: true})
+	observe("neverland-cluster", fapp.ObservedState{Ready: true})
+	reconcilePlotter()
+
+	// a change is rolled out to one cluster at a time
+	change("Marketing")
+	reconcilePlotter()
+	g.Expect(plotter.Status.Rollout.Phase).To(gomega.Equal(fapp.RolloutProgressing))
+	g.Expect(plotter.Status.Rollout.UpdatedClusters).To(gomega.Equal([]string{"neverland-cluster"}))
+	g.Expect(intentOf("neverland-cluster")).To(gomega.Equal("Marketing"))
+	g.Expect(intentOf("thegreendragon")).To(gomega.Equal("Fraud Detection"))
+
+	// the next cluster is not updated until the updated blueprint is ready
+	dummyManager.DeployedBlueprints["neverland-cluster"].Generation++
+	reconcilePlotter()
+	g.Expect(intentOf("thegreendragon")).To(gomega.Equal("Fraud Detection"))
+	observe("neverland-cluster", fapp.ObservedState{Ready: true})
+	reconcilePlotter()
+	g.Expect(intentOf("thegreendragon")).To(gomega.Equal("Marketing"))
+	dummyManager.DeployedBlueprints["thegreendragon"].Generation++
+	observe("thegreendragon", fapp.ObservedState{Ready: true})
+	reconcilePlotter()
+	g.Expect(plotter.Status.Rollout.Phase).To(gomega.Equal(fapp.RolloutCompleted))
+	g.Expect(plotter.Status.Rollout.PreviousBlueprints).To(gomega.BeEmpty())
+
+	// a failed blueprint rolls back the updated clusters, and the plotter reports the failure
+	change("Research")
+	reconcilePlotter()
+	g.Expect(intentOf("neverland-cluster")).To(gomega.Equal("Research"))
+	dummyManager.DeployedBlueprints["neverland-cluster"].Generation++
+	observe("neverland-cluster", fapp.ObservedState{Error: "RolloutFailure: release has been rolled back"})
+	reconcilePlotter()
+	g.Expect(plotter.Status.Rollout.Phase).To(gomega.Equal(fapp.RolloutRolledBack))
+	g.Expect(intentOf("neverland-cluster")).To(gomega.Equal("Marketing"))
+	g.Expect(intentOf("thegreendragon")).To(gomega.Equal("Marketing"))
+	g.Expect(plotter.Status.ObservedState.Ready).To(gomega.BeFalse())
+	g.Expect(plotter.Status.ObservedState.Error).To(gomega.ContainSubstring("has failed on cluster neverland-cluster"))
+}
+
+// This test checks that a sequential rollout that moves a module to another cluster keeps the blueprint of the
+// previous cluster until the blueprint of the new cluster is ready, and deletes the new blueprint when it fails.
+func TestPlotterSequentialRolloutToAnotherCluster(t *testing.T) {
+	t.Parallel()
+	g := gomega.NewGomegaWithT(t)
+	// Set the logger to development mode for verbose logs.
+	logf.SetLogger(zap.New(zap.UseDevMode(true)))
+
+	namespace := environment.GetInternalCRsNamespace()
+	plotterYAML, err := os.ReadFile("../../testdata/plotter-read-transform.yaml")
+	g.Expect(err).To(gomega.BeNil(), "Cannot read plotter file for test")
+	plotter := &fapp.Plotter{}
+	err = yaml.Unmarshal(plotterYAML, plotter)
+	g.Expect(err).To(gomega.BeNil(), "Cannot read plotter file for test")
+	plotter.Namespace = namespace
+	plotter.Generation = 1
+	plotter.Spec.RolloutStrategy = fapp.Sequential
+
+	s := utils.NewScheme(g)
+	cl := fake.NewFakeClientWithScheme(s, plotter)
+	dummyManager := dummy.NewDummyClusterManager(
+		make(map[string]*fapp.Blueprint),
+		[]multicluster.Cluster{
+			{Name: "thegreendragon", Metadata: multicluster.ClusterMetadata{Region: "theshire", VaultAuthPath: "kubernetes"}},
+			{Name: "neverland-cluster", Metadata: multicluster.ClusterMetadata{Region: "neverland", VaultAuthPath: "kubernetes"}},
+			{Name: "mordor", Metadata: multicluster.ClusterMetadata{Region: "mordor", VaultAuthPath: "kubernetes"}},
+		})
+	r := &PlotterReconciler{
+		Client:         cl,
+		Log:            logging.LogInit(logging.CONTROLLER, "test-controller"),
+		Scheme:         s,
+		ClusterManager: &dummyManager,
+	}
+	req := reconcile.Request{NamespacedName: types.NamespacedName{Name: plotter.Name, Namespace: namespace}}
+
+	// simulates the blueprint controller
+	observe := func(cluster string, state fapp.ObservedState) {
+		blueprint := dummyManager.DeployedBlueprints[cluster]
+		blueprint.Status.ObservedGeneration = blueprint.Generation
+		blueprint.Status.ObservedState = state
+	}
+	// moves the module that runs in neverland-cluster to another cluster
+	move := func(cluster string) {
+		g.Expect(cl.Get(context.Background(), req.NamespacedName, plotter)).To(gomega.Succeed())
+		plotter.Spec.Flows[1].SubFlows[0].Steps[0][1].Cluster = cluster
+		plotter.Generation++
+		g.Expect(cl.Update(context.Background(), plotter)).To(gomega.Succeed())
+	}
+	reconcilePlotter := func() {
+		_, err := r.Reconcile(context.Background(), req)
+		g.Expect(err).To(gomega.BeNil())
+		g.Expect(cl.Get(context.Background(), req.NamespacedName, plotter)).To(gomega.Succeed())
+	}
+
+	reconcilePlotter()
+	g.Expect(plotter.Status.Rollout.Phase).To(gomega.Equal(fapp.RolloutCompleted))
+	observe("thegreendragon", fapp.ObservedState{Ready: true})
+	observe("neverland-cluster", fapp.ObservedState{Ready: true})
+	reconcilePlotter()
+
+	// the blueprint of the previous cluster is kept until the blueprint of the new cluster is ready
+	move("mordor")
+	reconcilePlotter()
+	g.Expect(plotter.Status.Rollout.Phase).To(gomega.Equal(fapp.RolloutProgressing))
+	g.Expect(plotter.Status.Rollout.AddedClusters).To(gomega.Equal([]string{"mordor"}))
+	g.Expect(dummyManager.DeployedBlueprints).To(gomega.HaveKey("mordor"))
+	g.Expect(dummyManager.DeployedBlueprints).To(gomega.HaveKey("neverland-cluster"))
+	reconcilePlotter()
+	g.Expect(dummyManager.DeployedBlueprints).To(gomega.HaveKey("neverland-cluster"))
+	observe("mordor", fapp.ObservedState{Ready: true})
+	for i := 0; i < 3 && plotter.Status.Rollout.Phase == fapp.RolloutProgressing; i++ {
+		reconcilePlotter()
+		dummyManager.DeployedBlueprints["thegreendragon"].Generation++
+		observe("thegreendragon", fapp.ObservedState{Ready: true})
+	}
+	g.Expect(plotter.Status.Rollout.Phase).To(gomega.Equal(fapp.RolloutCompleted))
+	g.Expect(plotter.Status.Rollout.AddedClusters).To(gomega.BeEmpty())
+	g.Expect(dummyManager.DeployedBlueprints).NotTo(gomega.HaveKey("neverland-cluster"))
+	g.Expect(plotter.Status.Blueprints).NotTo(gomega.HaveKey("neverland-cluster"))
+
+	// a failed blueprint of the new cluster is deleted and the blueprint of the previous cluster is kept
+	move("neverland-cluster")
+	reconcilePlotter()
+	g.Expect(dummyManager.DeployedBlueprints).To(gomega.HaveKey("neverland-cluster"))
+	observe("neverland-cluster", fapp.ObservedState{Error: "RolloutFailure: release has been rolled back"})
+	reconcilePlotter()
+	g.Expect(plotter.Status.Rollout.Phase).To(gomega.Equal(fapp.RolloutRolledBack))
+	g.Expect(dummyManager.DeployedBlueprints).NotTo(gomega.HaveKey("neverland-cluster"))
+	g.Expect(dummyManager.DeployedBlueprints).To(gomega.HaveKey("mordor"))
+	g.Expect(plotter.Status.ObservedState.Error).To(gomega.ContainSubstring("has failed on cluster neverland-cluster"))
+	// the blueprint of the rolled back generation is not created again
+	reconcilePlotter()
+	g.Expect(dummyManager.DeployedBlueprints).NotTo(gomega.HaveKey("neverland-cluster"))
+	g.Expect(plotter.Status.Blueprints).To(gomega.HaveKey("mordor"))
+}
+
+// This test checks that the module releases of a blue/green rollout are named with the release suffix,
+// and that the blueprints of the clusters that are not used any more are kept until the plotter is ready.
+func TestPlotterBlueGreenRollout(t *testing.T) {
+	t.Parallel()
+	g := gomega.NewGomegaWithT(t)
+	// Set the logger to development mode for verbose logs.
+	logf.SetLogger(zap.New(zap.UseDevMode(true)))
+
+	namespace := environment.GetInternalCRsNamespace()
+	plotterYAML, err := os.ReadFile("../../testdata/plotter-read-transform.yaml")
+	g.Expect(err).To(gomega.BeNil(), "Cannot read plotter file for test")
+	plotter := &fapp.Plotter{}
+	err = yaml.Unmarshal(plotterYAML, plotter)
+	g.Expect(err).To(gomega.BeNil(), "Cannot read plotter file for test")
+	plotter.Namespace = namespace
+	plotter.Generation = 1
+	plotter.Spec.RolloutStrategy = fapp.BlueGreen
+	plotter.Spec.ReleaseSuffix = "-g1"
+
+	s := utils.NewScheme(g)
+	cl := fake.NewFakeClientWithScheme(s, plotter)
+	dummyManager := dummy.NewDummyClusterManager(
+		make(map[string]*fapp.Blueprint),
+		[]multicluster.Cluster{
+			{Name: "thegreendragon", Metadata: multicluster.ClusterMetadata{Region: "theshire", VaultAuthPath: "kubernetes"}},
+			{Name: "neverland-cluster", Metadata: multicluster.ClusterMetadata{Region: "neverland", VaultAuthPath: "kubernetes"}},
+		})
+	r := &PlotterReconciler{
+		Client:         cl,
+		Log:            logging.LogInit(logging.CONTROLLER, "test-controller"),
+		Scheme:         s,
+		ClusterManager: &dummyManager,
+	}
+	req := reconcile.Request{NamespacedName: types.NamespacedName{Name: plotter.Name, Namespace: namespace}}
+
+	// simulates the blueprint controller
+	observe := func(cluster string) {
+		blueprint := dummyManager.DeployedBlueprints[cluster]
+		blueprint.Status.ObservedGeneration = blueprint.Generation
+		blueprint.Status.ObservedState = fapp.ObservedState{Ready: true}
+	}
+	reconcilePlotter := func() {
+		_, err := r.Reconcile(context.Background(), req)
+		g.Expect(err).To(gomega.BeNil())
+		g.Expect(cl.Get(context.Background(), req.NamespacedName, plotter)).To(gomega.Succeed())
+	}
+
+	reconcilePlotter()
+	g.Expect(dummyManager.DeployedBlueprints).To(gomega.HaveLen(2))
+	for instanceName := range dummyManager.DeployedBlueprints["thegreendragon"].Spec.Modules {
+		g.Expect(instanceName).To(gomega.HaveSuffix("-g1"))
+	}
+	observe("thegreendragon")
+	observe("neverland-cluster")
+	reconcilePlotter()
+	g.Expect(plotter.Status.ObservedState.Ready).To(gomega.BeTrue())
+
+	// the next generation moves the module of neverland-cluster to thegreendragon
+	plotter.Spec.Flows[1].SubFlows[0].Steps[0][1].Cluster = "thegreendragon"
+	plotter.Spec.ReleaseSuffix = "-g2"
+	plotter.Generation++
+	g.Expect(cl.Update(context.Background(), plotter)).To(gomega.Succeed())
+	reconcilePlotter()
+	for instanceName := range dummyManager.DeployedBlueprints["thegreendragon"].Spec.Modules {
+		g.Expect(instanceName).To(gomega.HaveSuffix("-g2"))
+	}
+	dummyManager.DeployedBlueprints["thegreendragon"].Generation++
+	g.Expect(dummyManager.DeployedBlueprints).To(gomega.HaveKey("neverland-cluster"))
+	reconcilePlotter()
+	g.Expect(dummyManager.DeployedBlueprints).To(gomega.HaveKey("neverland-cluster"))
+	observe("thegreendragon")
+	reconcilePlotter()
+	g.Expect(plotter.Status.ObservedState.Ready).To(gomega.BeTrue())
+	g.Expect(dummyManager.DeployedBlueprints).NotTo(gomega.HaveKey("neverland-cluster"))
+}
+
+// unreachableClusterManager fails to delete the blueprints of the unreachable clusters
+type unreachableClusterManager struct {
+	dummy.MockClusterManager
+	unreachable map[string]bool
+}
+
+func (m *unreachableClusterManager) DeleteBlueprint(cluster, namespace, name string) error {
+	if m.unreachable[cluster] {
+		return errors.New("cluster " + cluster + " is unreachable")
+	}
+	return m.MockClusterManager.DeleteBlueprint(cluster, namespace, name)
+}
+
+// This test checks that the blueprints of failed clusters that are not in the plotter spec any more are dropped
+// from the plotter status, whereas the deletion of a blueprint from an available cluster is retried
+func TestPlotterUnavailableCluster(t *testing.T) {
+	t.Parallel()
+	g := gomega.NewGomegaWithT(t)
+	// Set the logger to development mode for verbose logs.
+	logf.SetLogger(zap.New(zap.UseDevMode(true)))
+
+	namespace := environment.GetInternalCRsNamespace()
+	plotterYAML, err := os.ReadFile("../../testdata/plotter-read-transform.yaml")
+	g.Expect(err).To(gomega.BeNil(), "Cannot read plotter file for test")
+	plotter := &fapp.Plotter{}
+	g.Expect(yaml.Unmarshal(plotterYAML, plotter)).To(gomega.Succeed(), "Cannot read plotter file for test")
+	plotter.Namespace = namespace
+	plotter.Generation = 1
+	// the plotter has been moved away from a failed cluster and from an available one
+	plotter.Status.Blueprints = map[string]fapp.MetaBlueprint{
+		"failed-cluster": {Name: plotter.Name, Namespace: namespace},
+		"thewoods":       {Name: plotter.Name, Namespace: namespace},
+	}
+
+	s := utils.NewScheme(g)
+	cl := fake.NewFakeClientWithScheme(s, plotter)
+	clusterManager := &unreachableClusterManager{
+		MockClusterManager: dummy.NewDummyClusterManager(
+			make(map[string]*fapp.Blueprint),
+			[]multicluster.Cluster{
+				{Name: "thegreendragon", Metadata: multicluster.ClusterMetadata{Region: "theshire", VaultAuthPath: "kubernetes"}},
+				{Name: "neverland-cluster", Metadata: multicluster.ClusterMetadata{Region: "neverland", VaultAuthPath: "kubernetes"}},
+				{Name: "thewoods", Metadata: multicluster.ClusterMetadata{Region: "theshire"}},
+			}),
+		unreachable: map[string]bool{"failed-cluster": true, "thewoods": true},
+	}
+	r := &PlotterReconciler{
+		Client:         cl,
+		Log:            logging.LogInit(logging.CONTROLLER, "test-controller"),
+		Scheme:         s,
+		ClusterManager: clusterManager,
+	}
+	req := reconcile.Request{NamespacedName: types.NamespacedName{Name: plotter.Name, Namespace: namespace}}
+	_, err = r.Reconcile(context.Background(), req)
+	g.Expect(err).To(gomega.HaveOccurred())
+	g.Expect(cl.Get(context.Background(), req.NamespacedName, plotter)).To(gomega.Succeed())
+	g.Expect(plotter.Status.Blueprints).To(gomega.HaveKey("thegreendragon"))
+	g.Expect(plotter.Status.Blueprints).To(gomega.HaveKey("thewoods"))
+	g.Expect(plotter.Status.Blueprints).NotTo(gomega.HaveKey("failed-cluster"))
+
+	// the available cluster is reachable again
+	clusterManager.unreachable["thewoods"] = false
+	_, err = r.Reconcile(context.Background(), req)
+	g.Expect(err).To(gomega.BeNil())
+	g.Expect(cl.Get(context.Background(), req.NamespacedName, plotter)).To(gomega.Succeed())
+	g.Expect(plotter.Status.Blueprints).NotTo(gomega.HaveKey("thewoods"))
+}
@@ -16,6 +16,7 @@ import (
 	fappv1 "fybrik.io/fybrik/manager/apis/app/v1beta1"
 	fappv2 "fybrik.io/fybrik/manager/apis/app/v1beta2"
 	managerUtils "fybrik.io/fybrik/manager/controllers/utils"
+	"fybrik.io/fybrik/pkg/audit"
 	storage "fybrik.io/fybrik/pkg/connectors/storagemanager/clients"
 	"fybrik.io/fybrik/pkg/datapath"
 	"fybrik.io/fybrik/pkg/environment"
@@ -43,6 +44,13 @@ type PlotterGenerator struct {
 	Owner              types.NamespacedName
 	StorageManager     storage.StorageManagerInterface
 	ProvisionedStorage map[string]NewAssetInfo
+	// DryRun indicates that the plotter is only planned, thus storage is not allocated
+	DryRun bool
+	// Audit records the storage allocations, if set
+	Audit *audit.Auditor
+	// RetainedCopies are the keys of the latest copies of scheduled assets, by the asset ids.
+	// The latest copies are retained when new copies are provisioned, and so are their storage reservations.
+	RetainedCopies map[string]string
 }
 
 // Provision allocates storage based on the selected account and generates the destination data store for the plotter
@@ -55,14 +63,34 @@ func (p *PlotterGenerator) Provision(item *datapath.DataInfo, destinationInterfa
 		AccountProperties: taxonomy.StorageAccountProperties{Properties: account.AdditionalProperties},
 		Secret:            *secretRef,
 		Opts: storagemanager.Options{
-			AppDetails:        storagemanager.ApplicationDetails{Name: p.Owner.Name, Namespace: p.Owner.Namespace, UUID: p.UUID},
-			DatasetProperties: storagemanager.DatasetDetails{Name: item.Context.DataSetID},
+			AppDetails: storagemanager.ApplicationDetails{Name: p.Owner.Name, Namespace: p.Owner.Namespace, UUID: p.UUID},
+			DatasetProperties: storagemanager.DatasetDetails{
+				Name:       item.Context.DataSetID,
+				Persistent: item.Context.Requirements.FlowParams.Catalog != "",
+			},
 			ConfigurationOpts: storagemanager.ConfigOptions{},
 		},
 	}
-	response, err := p.StorageManager.AllocateStorage(allocateRequest)
-	if err != nil {
-		return nil, err
+	// in a dry run, storage is not allocated and the connection only indicates the storage type
+	connection := taxonomy.Connection{Name: account.Type}
+	if !p.DryRun {
+		// the storage estimate is recorded in the account status before the allocation, failing if it exceeds the quota
+		owner, datasetID := p.Owner.String(), item.Context.DataSetID
+		retainedKey := p.RetainedCopies[datasetID]
+		if err := reserveStorage(p.Client, account.ID, owner, datasetID, retainedKey,
+			item.Context.Requirements.FlowParams.StorageEstimate); err != nil {
+			return nil, err
+		}
+		response, err := p.StorageManager.AllocateStorage(allocateRequest)
+		if err != nil {
+			p.auditStorageAllocation(datasetID, account, nil, err)
+			if releaseErr := cancelReservation(p.Client, owner, datasetID, retainedKey); releaseErr != nil {
+				p.Log.Error().Err(releaseErr).Str(logging.DATASETID, datasetID).Msg("Could not release the storage reservation")
+			}
+			return nil, err
+		}
+		connection = *response.Connection
+		p.auditStorageAllocation(datasetID, account, &connection, nil)
 	}
 
 	vaultSecretPath := vault.PathForReadingKubeSecret(secretRef.Namespace, secretRef.Name)
@@ -85,7 +113,7 @@ func (p *PlotterGenerator) Provision(item *datapath.DataInfo, destinationInterfa
 	}
 	datastore := &fappv1.DataStore{
 		Vault:      vaultMap,
-		Connection: *response.Connection,
+		Connection: connection,
 		Format:     destinationInterface.DataFormat,
 	}
 	assetInfo := NewAssetInfo{
@@ -237,7 +265,14 @@ func (p *PlotterGenerator) handleNewAsset(item *datapath.DataInfo, selection *da
 		Name:      item.Context.DataSetID,
 		Geography: string(element.StorageAccount.Geography),
 	}
+	// keep the schema of the new asset, if specified in the application
+	if item.DataDetails != nil {
+		resourceMetadata.Columns = item.DataDetails.ResourceMetadata.Columns
+	}
 
+	// The policies that allowed writing to the storage account govern the new asset
+	item.Policies = mergePolicies(item.Policies, item.StoragePolicies[element.StorageAccount.Geography])
+	item.DecisionIDs = appendPolicy(item.DecisionIDs, item.StorageDecisionIDs[element.StorageAccount.Geography])
 	// Reset StorageAccount to prevent re-allocation
 	element.StorageAccount.Geography = ""
 
@@ -267,9 +302,11 @@ func (p *PlotterGenerator) AddFlowInfoForAsset(item *datapath.DataInfo, applicat
 	p.Log.Trace().Str(logging.DATASETID, item.Context.DataSetID).Msg("Generating a plotter")
 	datasetID := item.Context.DataSetID
 	subflows := make([]fappv1.SubFlow, 0)
+	policies := item.Policies
 
 	plotterSpec.Assets[item.Context.DataSetID] = fappv1.AssetDetails{
 		DataStore: *p.getAssetDataStore(item),
+		Columns:   item.DataDetails.ResourceMetadata.Columns,
 	}
 	// DataStore for destination will be determined if an implicit copy is required
 	var steps []fappv1.DataFlowStep
@@ -286,7 +323,7 @@ func (p *PlotterGenerator) AddFlowInfoForAsset(item *datapath.DataInfo, applicat
 		var api *datacatalog.ResourceDetails
 		if moduleCapability.API != nil {
 			if api, err = moduleAPIToService(moduleCapability.API, moduleCapability.Scope,
-				application, element.Module.Name, datasetID); err != nil {
+				application, element.Module.Name, datasetID, plotterSpec.ReleaseSuffix); err != nil {
 				return err
 			}
 		}
@@ -297,19 +334,32 @@ func (p *PlotterGenerator) AddFlowInfoForAsset(item *datapath.DataInfo, applicat
 				p.Log.Error().Err(err).Str(logging.DATASETID, item.Context.DataSetID).Msg("Storage allocation for copy failed")
 				return err
 			}
+			// the policies that allowed writing to the storage account govern the copy as well
+			policies = mergePolicies(policies, item.StoragePolicies[element.StorageAccount.Geography])
 			steps = p.addStep(element, datasetID, api, steps, templateName)
 			copyAssetID := steps[len(steps)-1].Parameters.Arguments[1].AssetID
 			copyAsset := fappv1.AssetDetails{
 				AdvertisedAssetID: datasetID,
 				DataStore:         *sinkDataStore,
+				Columns:           item.DataDetails.ResourceMetadata.Columns,
 			}
 			plotterSpec.Assets[copyAssetID] = copyAsset
 			datasetID = copyAssetID
-			subflows = append(subflows, fappv1.SubFlow{
+			// a stream is ingested continuously into the allocated storage,
+			// other data is copied once upon init or upon the scheduled times of a periodic copy
+			subflow := fappv1.SubFlow{
 				FlowType: taxonomy.CopyFlow,
 				Triggers: []fappv1.SubFlowTrigger{fappv1.InitTrigger},
 				Steps:    [][]fappv1.DataFlowStep{steps},
-			})
+			}
+			if flowType == taxonomy.StreamFlow {
+				subflow.FlowType = taxonomy.StreamFlow
+				subflow.Triggers = []fappv1.SubFlowTrigger{fappv1.ContinuousTrigger}
+			} else if schedule := item.Context.Requirements.FlowParams.Schedule; schedule != "" && flowType == taxonomy.CopyFlow {
+				subflow.Triggers = []fappv1.SubFlowTrigger{fappv1.TimerTrigger}
+				subflow.Schedule = schedule
+			}
+			subflows = append(subflows, subflow)
 
 			// clear steps
 			steps = nil
@@ -332,14 +382,15 @@ func (p *PlotterGenerator) AddFlowInfoForAsset(item *datapath.DataInfo, applicat
 		FlowType: flowType,
 		AssetID:  item.Context.DataSetID,
 		SubFlows: subflows,
+		Policies: policies,
 	}
 	plotterSpec.Flows = append(plotterSpec.Flows, flow)
 	return nil
 }
 
 func moduleAPIToService(api *datacatalog.ResourceDetails, scope fappv1.CapabilityScope, appContext *fappv1.FybrikApplication,
-	moduleName, assetID string) (*datacatalog.ResourceDetails, error) {
-	instanceName := managerUtils.CreateStepName(moduleName, assetID, scope)
+	moduleName, assetID, releaseSuffix string) (*datacatalog.ResourceDetails, error) {
+	instanceName := managerUtils.CreateStepName(moduleName, assetID, scope, releaseSuffix)
 	releaseName := managerUtils.GetReleaseName(appContext.Name, string(appContext.UID), instanceName)
 	releaseNamespace := environment.GetDefaultModulesNamespace()
 
@@ -421,3 +472,12 @@ func resolveString(templateStr, key string, values map[string]interface{}) (inte
 	}
 	return newValue.String(), nil
 }
+
+// mergePolicies returns the union of two lists of policy IDs
+func mergePolicies(policies, other []string) []string {
+	merged := append([]string{}, policies...)
+	for _, policy := range other {
+		merged = appendPolicy(merged, policy)
+	}
+	return merged
+}
@@ -0,0 +1,236 @@
+// Copyright 2023 IBM Corp.
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/rs/zerolog"
+	"k8s.io/apimachinery/pkg/api/equality"
+
+	fapp "fybrik.io/fybrik/manager/apis/app/v1beta1"
+	"fybrik.io/fybrik/pkg/logging"
+)
+
+// rollOutSequentially updates the blueprints of the existing clusters to the plotter generation one cluster at a time.
+// The next cluster is updated once the blueprint of the previous one is ready. If an updated blueprint fails,
+// all the updated clusters are rolled back to their blueprints before the rollout.
+// Blueprints of new clusters are created by the plotter reconcile. The existing clusters are updated once they are ready,
+// and the blueprints of the clusters that are not planned any more are kept until the rollout completes.
+func (r *PlotterReconciler) rollOutSequentially(plotter *fapp.Plotter, blueprintsMap map[string]fapp.BlueprintSpec,
+	log *zerolog.Logger) error {
+	rollout := plotter.Status.Rollout
+	if rollout == nil || rollout.Generation != plotter.Generation {
+		rollout = newRollout(plotter)
+		plotter.Status.Rollout = rollout
+	}
+	if rollout.Phase == fapp.RolloutRollingBack {
+		return r.rollBack(plotter, log)
+	}
+	if rollout.Phase != fapp.RolloutProgressing {
+		return nil
+	}
+
+	// the blueprints of new clusters are created by the plotter reconcile
+	for _, cluster := range sortedClusters(blueprintsMap) {
+		if _, exists := plotter.Status.Blueprints[cluster]; !exists && !isAdded(rollout, cluster) {
+			rollout.AddedClusters = append(rollout.AddedClusters, cluster)
+		}
+	}
+
+	ready, err := r.rolloutReady(plotter, blueprintsMap, log)
+	if !ready || err != nil {
+		return err
+	}
+
+	// update the next cluster whose blueprint differs from the plotter
+	for _, cluster := range sortedClusters(blueprintsMap) {
+		meta, exists := plotter.Status.Blueprints[cluster]
+		if !exists || isUpdated(rollout, cluster) {
+			continue
+		}
+		remoteBlueprint, err := r.ClusterManager.GetBlueprint(cluster, meta.Namespace, meta.Name)
+		if err != nil {
+			return err
+		}
+		blueprintSpec := blueprintsMap[cluster]
+		if remoteBlueprint == nil || equality.Semantic.DeepEqual(&blueprintSpec, &remoteBlueprint.Spec) {
+			continue
+		}
+		// the spec of an earlier rollout that has not completed is kept, to roll back to the last completed state
+		if rollout.PreviousBlueprints == nil {
+			rollout.PreviousBlueprints = map[string]fapp.BlueprintSpec{}
+		}
+		if _, recorded := rollout.PreviousBlueprints[cluster]; !recorded {
+			rollout.PreviousBlueprints[cluster] = *remoteBlueprint.Spec.DeepCopy()
+		}
+		remoteBlueprint.Spec = blueprintSpec
+		if err := r.ClusterManager.UpdateBlueprint(cluster, remoteBlueprint); err != nil {
+			return err
+		}
+		rollout.UpdatedClusters = append(rollout.UpdatedClusters, cluster)
+		plotter.Status.Blueprints[cluster] = fapp.CreateMetaBlueprintWithoutState(remoteBlueprint)
+		log.Info().Str(logging.CLUSTER, cluster).Str(logging.ACTION, logging.UPDATE).
+			Msgf("Rolling out generation %d of the plotter to cluster %s", rollout.Generation, cluster)
+		return nil
+	}
+	rollout.Phase = fapp.RolloutCompleted
+	rollout.PreviousBlueprints = nil
+	rollout.AddedClusters = nil
+	log.Info().Msgf("Rolled out generation %d of the plotter to all the clusters", rollout.Generation)
+	return nil
+}
+
+// rolloutReady returns true if the blueprints of the added and the updated clusters are ready.
+// If one of them has failed, the rollout is rolled back.
+func (r *PlotterReconciler) rolloutReady(plotter *fapp.Plotter, blueprintsMap map[string]fapp.BlueprintSpec,
+	log *zerolog.Logger) (bool, error) {
+	rollout := plotter.Status.Rollout
+	clusters := append(append([]string{}, rollout.AddedClusters...), rollout.UpdatedClusters...)
+	for _, cluster := range clusters {
+		blueprintSpec, planned := blueprintsMap[cluster]
+		if !planned {
+			continue
+		}
+		meta, exists := plotter.Status.Blueprints[cluster]
+		if !exists {
+			// the blueprint has not been created yet
+			return false, nil
+		}
+		remoteBlueprint, err := r.ClusterManager.GetBlueprint(cluster, meta.Namespace, meta.Name)
+		if err != nil {
+			return false, err
+		}
+		if remoteBlueprint == nil || remoteBlueprint.Status.ObservedGeneration != remoteBlueprint.Generation {
+			return false, nil
+		}
+		// the update may not have been received yet
+		if !equality.Semantic.DeepEqual(&blueprintSpec, &remoteBlueprint.Spec) {
+			return false, nil
+		}
+		if remoteBlueprint.Status.ObservedState.Error != "" {
+			rollout.Phase = fapp.RolloutRollingBack
+			rollout.Message = fmt.Sprintf("the rollout of generation %d has failed on cluster %s and has been rolled back: %s",
+				rollout.Generation, cluster, strings.TrimSpace(remoteBlueprint.Status.ObservedState.Error))
+			log.Warn().Str(logging.CLUSTER, cluster).Msg("Rolling back: " + rollout.Message)
+			return false, r.rollBack(plotter, log)
+		}
+		if !remoteBlueprint.Status.ObservedState.Ready {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// newRollout starts a rollout of the plotter generation. The first deployment of the plotter has nothing to roll out.
+// If an earlier rollout has not completed, the blueprints before that rollout are kept to roll back to.
+func newRollout(plotter *fapp.Plotter) *fapp.Rollout {
+	rollout := &fapp.Rollout{
+		Generation:         plotter.Generation,
+		Phase:              fapp.RolloutProgressing,
+		PreviousBlueprints: map[string]fapp.BlueprintSpec{},
+	}
+	if len(plotter.Status.Blueprints) == 0 {
+		rollout.Phase = fapp.RolloutCompleted
+		rollout.PreviousBlueprints = nil
+		return rollout
+	}
+	if previous := plotter.Status.Rollout; previous != nil && previous.Phase == fapp.RolloutProgressing {
+		for cluster, spec := range previous.PreviousBlueprints {
+			rollout.PreviousBlueprints[cluster] = spec
+		}
+		rollout.AddedClusters = append(rollout.AddedClusters, previous.AddedClusters...)
+	}
+	return rollout
+}
+
+// rollBack deletes the blueprints of the added clusters and restores the blueprints of the updated clusters.
+// It is retried until all of them have been deleted or restored.
+func (r *PlotterReconciler) rollBack(plotter *fapp.Plotter, log *zerolog.Logger) error {
+	rollout := plotter.Status.Rollout
+	for _, cluster := range rollout.AddedClusters {
+		if meta, exists := plotter.Status.Blueprints[cluster]; exists {
+			if err := r.ClusterManager.DeleteBlueprint(cluster, meta.Namespace, meta.Name); err != nil {
+				return err
+			}
+			delete(plotter.Status.Blueprints, cluster)
+			log.Info().Str(logging.CLUSTER, cluster).Str(logging.ACTION, logging.DELETE).
+				Msg("Rolled back the blueprint of cluster " + cluster)
+		}
+		delete(rollout.PreviousBlueprints, cluster)
+	}
+	rollout.AddedClusters = nil
+	for _, cluster := range sortedClusters(rollout.PreviousBlueprints) {
+		meta, exists := plotter.Status.Blueprints[cluster]
+		if !exists {
+			delete(rollout.PreviousBlueprints, cluster)
+			continue
+		}
+		remoteBlueprint, err := r.ClusterManager.GetBlueprint(cluster, meta.Namespace, meta.Name)
+		if err != nil {
+			return err
+		}
+		if remoteBlueprint != nil {
+			remoteBlueprint.Spec = rollout.PreviousBlueprints[cluster]
+			if err := r.ClusterManager.UpdateBlueprint(cluster, remoteBlueprint); err != nil {
+				return err
+			}
+			plotter.Status.Blueprints[cluster] = fapp.CreateMetaBlueprintWithoutState(remoteBlueprint)
+			log.Info().Str(logging.CLUSTER, cluster).Str(logging.ACTION, logging.UPDATE).
+				Msg("Rolled back the blueprint of cluster " + cluster)
+		}
+		delete(rollout.PreviousBlueprints, cluster)
+	}
+	rollout.Phase = fapp.RolloutRolledBack
+	rollout.PreviousBlueprints = nil
+	return nil
+}
+
+// keepsPreviousBlueprints returns true if the sequential rollout of the plotter generation has not completed,
+// hence the blueprints of the clusters that are not planned any more are kept
+func keepsPreviousBlueprints(plotter *fapp.Plotter) bool {
+	rollout := plotter.Status.Rollout
+	return plotter.Spec.RolloutStrategy == fapp.Sequential && rollout != nil &&
+		rollout.Generation == plotter.Generation && rollout.Phase != fapp.RolloutCompleted
+}
+
+// rolloutError returns why the rollout of the plotter generation has been rolled back, or "" if it has not
+func rolloutError(plotter *fapp.Plotter) string {
+	rollout := plotter.Status.Rollout
+	if rollout == nil || rollout.Generation != plotter.Generation || rollout.Phase != fapp.RolloutRolledBack {
+		return ""
+	}
+	return rollout.Message
+}
+
+// isUpdated returns true if the blueprint of the cluster has been updated by the rollout
+func isUpdated(rollout *fapp.Rollout, cluster string) bool {
+	for _, updated := range rollout.UpdatedClusters {
+		if updated == cluster {
+			return true
+		}
+	}
+	return false
+}
+
+// isAdded returns true if the blueprint of the cluster has been created by the rollout
+func isAdded(rollout *fapp.Rollout, cluster string) bool {
+	for _, added := range rollout.AddedClusters {
+		if added == cluster {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedClusters(blueprints map[string]fapp.BlueprintSpec) []string {
+	clusters := make([]string, 0, len(blueprints))
+	for cluster := range blueprints {
+		clusters = append(clusters, cluster)
+	}
+	sort.Strings(clusters)
+	return clusters
+}
@@ -5,6 +5,7 @@ package app
 
 import (
 	"encoding/json"
+	"fmt"
 
 	"emperror.dev/errors"
 	"github.com/gdexlab/go-render/render"
@@ -70,39 +71,116 @@ func ValidatePolicyDecisionsResponse(response *policymanager.GetPolicyDecisionsR
 // - policy manager facade
 // - application info
 // - data flow and locations
-// Output:
-// - a list of governance actions (upon a successful response)
+// Output: a policy decision that holds
+// - a list of governance actions and the policies they are based on (upon a successful response)
 // - a message from the connector (upon a successful response)
 // - an error from the connector or an error formulated by Fybrik in case of Deny
 func LookupPolicyDecisions(datasetID string, resourceMetadata *datacatalog.ResourceMetadata,
 	policyManager connectors.PolicyManager, appContext ApplicationContext,
-	op *policymanager.RequestAction) ([]taxonomy.Action, string, error) {
+	op *policymanager.RequestAction) PolicyDecision {
 	// call external policy manager to get governance instructions for this operation
 	openapiReq := ConstructOpenAPIReq(datasetID, resourceMetadata, appContext.Application, op)
 	output := render.AsCode(openapiReq)
 	appContext.Log.Debug().Str(logging.DATASETID, datasetID).Msgf("request: %s", output)
 
-	var creds string
-	if appContext.Application.Spec.SecretRef != "" {
-		// creds field is constructed even if vault is not used for credential management
-		// in order to enable the connector to get the credentials directly from the secret
-		// using the secret information extracted from the creds string.
-		creds = vault.PathForReadingKubeSecret(appContext.Application.Namespace, appContext.Application.Spec.SecretRef)
+	openapiResp, err := policyManager.GetPoliciesDecisions(openapiReq, policyManagerCredentials(appContext.Application))
+	if err != nil {
+		return PolicyDecision{Err: err}
 	}
+	decision := interpretPolicyDecisions(datasetID, openapiReq, openapiResp, appContext)
+	auditGovernanceDecision(appContext, openapiReq, &decision)
+	return decision
+}
 
-	openapiResp, err := policyManager.GetPoliciesDecisions(openapiReq, creds)
-	var actions []taxonomy.Action
+// PolicyDecision holds the outcome of a policy decision request:
+// the governance actions, the IDs of the policies the decision is based on and the connector message,
+// or the error in case of Deny or an invalid response, and the decision ID returned by the policy manager
+type PolicyDecision struct {
+	Actions    []taxonomy.Action
+	Policies   []string
+	Message    string
+	Err        error
+	DecisionID string
+}
+
+// LookupPolicyDecisionsBatch provides the governance actions for the given dataset and each of the given operations,
+// requesting all of them from the policy manager in a single call.
+// The decisions are returned in the order of the operations.
+// An error is returned if the policy manager could not be called; in case of Deny or an invalid response,
+// the error is reported in the decision of the operation.
+func LookupPolicyDecisionsBatch(datasetID string, resourceMetadata *datacatalog.ResourceMetadata,
+	policyManager connectors.PolicyManager, appContext ApplicationContext,
+	ops []policymanager.RequestAction) ([]PolicyDecision, error) {
+	requests := make([]PolicyDecisionRequest, len(ops))
+	for i := range ops {
+		requests[i] = PolicyDecisionRequest{DatasetID: datasetID, ResourceMetadata: resourceMetadata, Operation: ops[i]}
+	}
+	return LookupPolicyDecisionsOfDatasets(requests, policyManager, appContext)
+}
+
+// PolicyDecisionRequest is an operation on a dataset for which governance actions are requested
+type PolicyDecisionRequest struct {
+	DatasetID        string
+	ResourceMetadata *datacatalog.ResourceMetadata
+	Operation        policymanager.RequestAction
+}
+
+// LookupPolicyDecisionsOfDatasets provides the governance actions for operations on several datasets,
+// requesting all of them from the policy manager in a single call.
+// The decisions are returned in the order of the requests.
+// An error is returned if the policy manager could not be called; in case of Deny or an invalid response,
+// the error is reported in the decision of the request.
+func LookupPolicyDecisionsOfDatasets(requests []PolicyDecisionRequest, policyManager connectors.PolicyManager,
+	appContext ApplicationContext) ([]PolicyDecision, error) {
+	batch := &policymanager.GetPolicyDecisionsBatchRequest{Requests: []policymanager.GetPolicyDecisionsRequest{}}
+	for i := range requests {
+		batch.Requests = append(batch.Requests, *ConstructOpenAPIReq(requests[i].DatasetID, requests[i].ResourceMetadata,
+			appContext.Application, &requests[i].Operation))
+	}
+	if len(batch.Requests) == 0 {
+		return []PolicyDecision{}, nil
+	}
+	appContext.Log.Debug().Msgf("batch request: %s", render.AsCode(batch))
+
+	batchResp, err := policyManager.GetPoliciesDecisionsBatch(batch, policyManagerCredentials(appContext.Application))
 	if err != nil {
-		return actions, "", err
+		return nil, err
+	}
+	if len(batchResp.Responses) != len(batch.Requests) {
+		return nil, errors.Errorf("expected %d policy decisions, received %d", len(batch.Requests), len(batchResp.Responses))
 	}
+	decisions := make([]PolicyDecision, len(batch.Requests))
+	for i := range batch.Requests {
+		decisions[i] = interpretPolicyDecisions(requests[i].DatasetID, &batch.Requests[i], &batchResp.Responses[i], appContext)
+		auditGovernanceDecision(appContext, &batch.Requests[i], &decisions[i])
+	}
+	return decisions, nil
+}
 
-	err = ValidatePolicyDecisionsResponse(openapiResp, PolicyManagerTaxonomy)
+// policyManagerCredentials returns the path of the application credentials passed to the policy manager
+func policyManagerCredentials(application *fapp.FybrikApplication) string {
+	if application.Spec.SecretRef == "" {
+		return ""
+	}
+	// creds field is constructed even if vault is not used for credential management
+	// in order to enable the connector to get the credentials directly from the secret
+	// using the secret information extracted from the creds string.
+	return vault.PathForReadingKubeSecret(application.Namespace, application.Spec.SecretRef)
+}
+
+// interpretPolicyDecisions validates the policy manager response to a request and returns the governance actions,
+// the policies they are based on and the connector message, or an error formulated by Fybrik in case of Deny
+func interpretPolicyDecisions(datasetID string, openapiReq *policymanager.GetPolicyDecisionsRequest,
+	openapiResp *policymanager.GetPolicyDecisionsResponse, appContext ApplicationContext) PolicyDecision {
+	var actions []taxonomy.Action
+	var policies []string
+	err := ValidatePolicyDecisionsResponse(openapiResp, PolicyManagerTaxonomy)
 	if err != nil {
 		appContext.Log.Error().Err(err).Str(logging.DATASETID, datasetID).Msg("error while validating policy manager response")
-		return actions, "", errors.New("Validation error: " + err.Error())
+		return PolicyDecision{Err: errors.New("Validation error: " + err.Error()), DecisionID: openapiResp.DecisionID}
 	}
 
-	output = render.AsCode(openapiResp)
+	output := render.AsCode(openapiResp)
 	appContext.Log.Info().Str(logging.DATASETID, datasetID).Msgf("response from policy manager: %s", output)
 
 	result := openapiResp.Result
@@ -116,10 +194,43 @@ func LookupPolicyDecisions(datasetID string, resourceMetadata *datacatalog.Resou
 				message = WriteNotAllowed
 			}
 			// access is denied - return the connector message that may help to understand the reason
-			return actions, openapiResp.Message, errors.New(message)
+			return PolicyDecision{Policies: appendPolicy(nil, result[i].Policy), Message: openapiResp.Message,
+				Err: errors.New(message), DecisionID: openapiResp.DecisionID}
 		}
 		actions = append(actions, result[i].Action)
+		policies = appendPolicy(policies, result[i].Policy)
 	}
+	// column actions of different policies that differ only in their columns are merged, to be applied by a single module
 	// return the action list and the connector message with additional information
-	return actions, openapiResp.Message, nil
+	return PolicyDecision{Actions: taxonomy.MergeActions(actions), Policies: policies, Message: openapiResp.Message,
+		DecisionID: openapiResp.DecisionID}
+}
+
+// appendPolicy adds a policy ID to the list, unless it is empty or already listed
+func appendPolicy(policies []string, policy string) []string {
+	if policy == "" {
+		return policies
+	}
+	for _, existing := range policies {
+		if existing == policy {
+			return policies
+		}
+	}
+	return append(policies, policy)
+}
+
+// validateActionColumns checks that the columns of the column-scoped actions exist in the schema of the asset.
+// Nested fields are referenced by their path. Assets whose schema is unknown are not checked.
+func validateActionColumns(actions []taxonomy.Action, resourceMetadata *datacatalog.ResourceMetadata) error {
+	if !resourceMetadata.HasSchema() {
+		return nil
+	}
+	for i := range actions {
+		for _, column := range actions[i].Columns() {
+			if resourceMetadata.Column(column) == nil {
+				return fmt.Errorf(UnknownActionColumn, actions[i].Name, column)
+			}
+		}
+	}
+	return nil
 }
@@ -0,0 +1,372 @@
+// Copyright 2023 IBM Corp.
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"emperror.dev/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	fappv1 "fybrik.io/fybrik/manager/apis/app/v1beta1"
+	"fybrik.io/fybrik/pkg/adminconfig"
+	"fybrik.io/fybrik/pkg/datapath"
+	"fybrik.io/fybrik/pkg/infrastructure"
+	"fybrik.io/fybrik/pkg/logging"
+	"fybrik.io/fybrik/pkg/model/datacatalog"
+	"fybrik.io/fybrik/pkg/model/taxonomy"
+	"fybrik.io/fybrik/pkg/monitor"
+)
+
+// Reasons for re-evaluation of the governance decisions
+const (
+	PeriodicReevaluation       string = "periodic re-evaluation of the governance policies"
+	ConfigPoliciesChanged      string = "configuration policies have changed"
+	InfrastructureChanged      string = "infrastructure attributes have changed"
+	AssetChangedReasonTemplate string = "asset %s has changed"
+)
+
+// reevaluationEventsBuffer is the number of events that trigger reconciles of the applications,
+// which can be pending before further events are dropped
+const reevaluationEventsBuffer = 1024
+
+// KatalogProvider is the catalog provider name of the katalog connector, whose assets are watched for changes
+const KatalogProvider = "katalog"
+
+// reevaluationRequests holds the FybrikApplications whose governance decisions should be re-evaluated,
+// together with the reasons for the re-evaluation
+type reevaluationRequests struct {
+	mutex   sync.Mutex
+	reasons map[types.NamespacedName]string
+}
+
+func (q *reevaluationRequests) add(key types.NamespacedName, reason string) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	if q.reasons == nil {
+		q.reasons = make(map[types.NamespacedName]string)
+	}
+	if existing, found := q.reasons[key]; found && !strings.Contains(existing, reason) {
+		reason = existing + Separator + reason
+	}
+	q.reasons[key] = reason
+}
+
+// take removes the re-evaluation request of an application and returns its reason
+func (q *reevaluationRequests) take(key types.NamespacedName) (string, bool) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	reason, found := q.reasons[key]
+	delete(q.reasons, key)
+	return reason, found
+}
+
+// policyDecisionsCache is implemented by policy managers that cache the policy decisions
+type policyDecisionsCache interface {
+	Invalidate()
+	InvalidateAsset(assetID taxonomy.AssetID)
+}
+
+// RequestReevaluation requests re-evaluation of the governance decisions of all FybrikApplications
+func (r *FybrikApplicationReconciler) RequestReevaluation(reason string) {
+	applications := &fappv1.FybrikApplicationList{}
+	if err := r.List(context.Background(), applications); err != nil {
+		r.Log.Error().Err(err).Msg("Could not list FybrikApplications for re-evaluation")
+		return
+	}
+	for i := range applications.Items {
+		application := &applications.Items[i]
+		r.reevaluations.add(client.ObjectKeyFromObject(application), reason)
+		r.enqueueReconcile(application)
+	}
+}
+
+// enqueueReconcile triggers a reconcile of the application without blocking the caller.
+// The events are not consumed until the controller has started, e.g. on replicas that are not the leader.
+// If the buffer of events is full, the event is dropped: the request is kept,
+// and is handled by the next reconcile of the application, at the latest when the controller starts.
+func (r *FybrikApplicationReconciler) enqueueReconcile(application *fappv1.FybrikApplication) {
+	if r.reevaluationEvents == nil {
+		return
+	}
+	select {
+	case r.reevaluationEvents <- event.GenericEvent{Object: application}:
+	default:
+		r.Log.Warn().Str(FybrikApplicationKind, application.Namespace+"/"+application.Name).
+			Msg("The reconcile events are not consumed, the request is handled by the next reconcile of the application")
+	}
+}
+
+// assetToApplications requests re-evaluation of the FybrikApplications that use a changed katalog asset
+func (r *FybrikApplicationReconciler) assetToApplications(asset client.Object) []reconcile.Request {
+	assetID := asset.GetNamespace() + "/" + asset.GetName()
+	if cache, ok := r.PolicyManager.(policyDecisionsCache); ok {
+		cache.InvalidateAsset(taxonomy.AssetID(assetID))
+	}
+	applications := &fappv1.FybrikApplicationList{}
+	if err := r.List(context.Background(), applications); err != nil {
+		r.Log.Error().Err(err).Msg("Could not list FybrikApplications for re-evaluation")
+		return []reconcile.Request{}
+	}
+	requests := []reconcile.Request{}
+	for i := range applications.Items {
+		application := &applications.Items[i]
+		for _, dataset := range application.Spec.Data {
+			if dataset.DataSetID != assetID {
+				continue
+			}
+			key := client.ObjectKeyFromObject(application)
+			r.reevaluations.add(key, fmt.Sprintf(AssetChangedReasonTemplate, assetID))
+			requests = append(requests, reconcile.Request{NamespacedName: key})
+			break
+		}
+	}
+	return requests
+}
+
+// periodicReevaluation returns a runnable that requests re-evaluation of all FybrikApplications at the given interval.
+// The cached policy decisions are dropped before each re-evaluation, so that the policy manager is queried again.
+func (r *FybrikApplicationReconciler) periodicReevaluation(interval time.Duration) manager.RunnableFunc {
+	return func(ctx context.Context) error {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-ticker.C:
+				if cache, ok := r.PolicyManager.(policyDecisionsCache); ok {
+					cache.Invalidate()
+				}
+				r.RequestReevaluation(PeriodicReevaluation)
+			}
+		}
+	}
+}
+
+// reevaluationSubscriber requests re-evaluation of all FybrikApplications when the monitored files change
+type reevaluationSubscriber struct {
+	reconciler *FybrikApplicationReconciler
+	options    monitor.FileMonitorOptions
+	reason     string
+}
+
+func (s *reevaluationSubscriber) GetOptions() monitor.FileMonitorOptions {
+	return s.options
+}
+
+func (s *reevaluationSubscriber) OnError(err error) {
+	s.reconciler.Log.Error().Err(err).Msg("Error monitoring " + s.options.Path)
+}
+
+func (s *reevaluationSubscriber) OnNotify() {
+	s.reconciler.RequestReevaluation(s.reason)
+}
+
+// ReevaluationSubscribers returns file monitor subscribers that request re-evaluation of all FybrikApplications
+// when the configuration policies or the infrastructure attributes change.
+// They should be subscribed after the policy evaluator and the attribute manager that reload these files.
+func (r *FybrikApplicationReconciler) ReevaluationSubscribers() []monitor.Subscriber {
+	return []monitor.Subscriber{
+		&reevaluationSubscriber{
+			reconciler: r,
+			options:    monitor.FileMonitorOptions{Path: adminconfig.RegoPolicyDirectory, Extension: ".rego"},
+			reason:     ConfigPoliciesChanged,
+		},
+		&reevaluationSubscriber{
+			reconciler: r,
+			options:    monitor.FileMonitorOptions{Path: infrastructure.RegoPolicyDirectory, Extension: ".json"},
+			reason:     InfrastructureChanged,
+		},
+	}
+}
+
+// reevaluationRequired checks, if a re-evaluation of the application has been requested, whether the governance decisions
+// have changed since its plan has been generated. If so, the reason for the re-evaluation is recorded in the status.
+// The plan is generated again if the governance decisions can not be evaluated, in order to report the errors,
+// and once they are evaluated again, in order to clear them.
+func (r *FybrikApplicationReconciler) reevaluationRequired(applicationContext ApplicationContext) bool {
+	application := applicationContext.Application
+	reason, requested := r.reevaluations.take(client.ObjectKeyFromObject(application))
+	if !requested {
+		return false
+	}
+	digest, err := r.evaluateGovernanceDigest(applicationContext)
+	if err != nil {
+		return r.reevaluationFailed(applicationContext, reason, err)
+	}
+	if digest == application.Status.GovernanceDigest {
+		if application.Status.Reevaluation == nil || application.Status.Reevaluation.Error == "" {
+			applicationContext.Log.Debug().Msg("Governance decisions have not changed after re-evaluation: " + reason)
+			return false
+		}
+		applicationContext.Log.Info().Bool(logging.FORUSER, true).Bool(logging.AUDIT, true).
+			Msg("Governance decisions have been re-evaluated after a failure, generating the plan again: " + reason)
+	} else {
+		applicationContext.Log.Info().Bool(logging.FORUSER, true).Bool(logging.AUDIT, true).
+			Msg("Governance decisions have changed, generating a new plan: " + reason)
+	}
+	application.Status.Reevaluation = &fappv1.Reevaluation{Reason: reason, Time: metav1.Now()}
+	return true
+}
+
+// reevaluationFailed records that the governance decisions of the application could not be re-evaluated, and requests
+// another re-evaluation. The plan is generated again in order to report the errors. The current plan is kept until
+// the re-evaluations have failed for longer than the re-evaluation timeout; then the generated resource is deleted,
+// so that the data paths of the application do not outlive the governance decisions they are based on.
+func (r *FybrikApplicationReconciler) reevaluationFailed(applicationContext ApplicationContext, reason string, err error) bool {
+	application := applicationContext.Application
+	now := metav1.Now()
+	failingSince := now
+	if application.Status.Reevaluation != nil && application.Status.Reevaluation.FailingSince != nil {
+		failingSince = *application.Status.Reevaluation.FailingSince
+	}
+	application.Status.Reevaluation = &fappv1.Reevaluation{Reason: reason, Time: now, Error: err.Error(), FailingSince: &failingSince}
+	r.reevaluations.add(client.ObjectKeyFromObject(application), reason)
+	if r.ReevaluationTimeout == 0 || now.Sub(failingSince.Time) < r.ReevaluationTimeout || application.Status.Generated == nil {
+		applicationContext.Log.Warn().Err(err).Bool(logging.FORUSER, true).Bool(logging.AUDIT, true).
+			Msg("Could not re-evaluate the governance decisions, keeping the current plan: " + reason)
+		return true
+	}
+	applicationContext.Log.Warn().Err(err).Bool(logging.FORUSER, true).Bool(logging.AUDIT, true).
+		Msgf("Governance decisions could not be re-evaluated since %s, removing the data paths: %s",
+			failingSince.Format(time.RFC3339), reason)
+	if err := r.deleteGeneratedResource(applicationContext); err != nil {
+		applicationContext.Log.Error().Err(err).Msg("Could not remove the data paths")
+	}
+	return true
+}
+
+// governanceEvaluated records the digest of the governance decisions that the plan of the application is based on,
+// which ends a failure of the re-evaluations
+func governanceEvaluated(application *fappv1.FybrikApplication, digest string) {
+	application.Status.GovernanceDigest = digest
+	if application.Status.Reevaluation != nil {
+		application.Status.Reevaluation.Error = ""
+		application.Status.Reevaluation.FailingSince = nil
+	}
+}
+
+// replanRequests checks whether the application should be planned again without a change of its spec, either because
+// clusters that it is deployed on have failed, or because a re-evaluation has changed the governance decisions
+func (r *FybrikApplicationReconciler) replanRequests(applicationContext ApplicationContext,
+	specChanged bool) (failover, reevaluation bool) {
+	if specChanged {
+		return false, false
+	}
+	if r.failoverRequired(applicationContext) {
+		// pending re-evaluations are handled by the next reconcile
+		return true, false
+	}
+	return false, r.reevaluationRequired(applicationContext)
+}
+
+// replan generates a new plan for the application. If the plan is generated following a re-evaluation of the governance
+// decisions or a failover, a failed re-evaluation is retried, and the readiness of the updated plotter is taken from its
+// current status. Failover requests are kept until a new plan has been generated.
+func (r *FybrikApplicationReconciler) replan(applicationContext ApplicationContext,
+	reevaluation, failover bool) (ctrl.Result, error) {
+	result, err := r.reconcile(applicationContext)
+	if !reevaluation && !failover {
+		return result, err
+	}
+	application := applicationContext.Application
+	if err != nil || result.Requeue || (result.RequeueAfter > 0) {
+		if reevaluation {
+			r.reevaluations.add(client.ObjectKeyFromObject(application), application.Status.Reevaluation.Reason)
+		}
+		return result, err
+	}
+	if application.Status.Generated != nil {
+		// later changes of the plotter status are received as plotter updates
+		if resourceStatus, err := r.ResourceInterface.GetResourceStatus(application.Status.Generated); err == nil {
+			r.checkReadiness(applicationContext, resourceStatus)
+		}
+	}
+	return result, nil
+}
+
+// evaluateGovernanceDigest collects the requirements of the application datasets without changing its status,
+// and returns their digest
+func (r *FybrikApplicationReconciler) evaluateGovernanceDigest(applicationContext ApplicationContext) (string, error) {
+	application := applicationContext.Application.DeepCopy()
+	initStatus(application)
+	_, requirements, _, err := r.collectRequirements(ApplicationContext{
+		Log:         applicationContext.Log,
+		Application: application,
+		UUID:        applicationContext.UUID,
+	})
+	if err != nil {
+		return "", err
+	}
+	if errMsg := getErrorMessages(application); errMsg != "" {
+		return "", errors.New(errMsg)
+	}
+	return r.governanceDigest(application, requirements)
+}
+
+// governanceInput is the part of the requirements of a dataset that is determined by the governance and configuration
+// policies and by the asset metadata
+type governanceInput struct {
+	DataSetID           string                                            `json:"dataSetID"`
+	Denied              string                                            `json:"denied,omitempty"`
+	DataDetails         *datacatalog.GetAssetResponse                     `json:"dataDetails,omitempty"`
+	Actions             []taxonomy.Action                                 `json:"actions,omitempty"`
+	StorageRequirements map[taxonomy.ProcessingLocation][]taxonomy.Action `json:"storageRequirements,omitempty"`
+	Configuration       *adminconfig.EvaluatorOutput                      `json:"configuration,omitempty"`
+}
+
+// governanceDigest returns a digest of the governance decisions, asset metadata and infrastructure attributes
+// the plan of the application is based on
+func (r *FybrikApplicationReconciler) governanceDigest(application *fappv1.FybrikApplication,
+	requirements []datapath.DataInfo) (string, error) {
+	inputs := []governanceInput{}
+	for _, dataset := range application.Spec.Data {
+		input := governanceInput{DataSetID: dataset.DataSetID}
+		if state, found := application.Status.AssetStates[dataset.DataSetID]; found &&
+			state.Conditions[DenyConditionIndex].Status == corev1.ConditionTrue {
+			input.Denied = state.Conditions[DenyConditionIndex].Message
+		}
+		for i := range requirements {
+			if requirements[i].Context.DataSetID == dataset.DataSetID {
+				input.DataDetails = requirements[i].DataDetails
+				input.Actions = requirements[i].Actions
+				input.StorageRequirements = requirements[i].StorageRequirements
+				input.Configuration = &requirements[i].Configuration
+				break
+			}
+		}
+		inputs = append(inputs, input)
+	}
+	digest := sha256.New()
+	content, err := json.Marshal(inputs)
+	if err != nil {
+		return "", err
+	}
+	digest.Write(content)
+	if r.Infrastructure != nil {
+		r.Infrastructure.Mux.RLock()
+		content, err = json.Marshal(r.Infrastructure.Attributes)
+		r.Infrastructure.Mux.RUnlock()
+		if err != nil {
+			return "", err
+		}
+		digest.Write(content)
+	}
+	return hex.EncodeToString(digest.Sum(nil)), nil
+}
@@ -0,0 +1,216 @@
+// Copyright 2023 IBM Corp.
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	fappv1 "fybrik.io/fybrik/manager/apis/app/v1beta1"
+	"fybrik.io/fybrik/pkg/logging"
+	"fybrik.io/fybrik/pkg/model/taxonomy"
+	"fybrik.io/fybrik/pkg/schedule"
+)
+
+// ScheduledCopyKeySeparator separates the asset identifier from the creation time in the keys of previous copies
+// in the provisioned storage
+const ScheduledCopyKeySeparator = "@"
+
+// scheduledCopyRequirements returns the flow requirements of a scheduled copy of the given asset, or nil if the asset
+// is not copied according to a schedule
+func scheduledCopyRequirements(application *fappv1.FybrikApplication, assetID string) *fappv1.FlowRequirements {
+	for i := range application.Spec.Data {
+		dataCtx := &application.Spec.Data[i]
+		if dataCtx.DataSetID == assetID && dataCtx.Flow == taxonomy.CopyFlow && dataCtx.Requirements.FlowParams.Schedule != "" {
+			return &dataCtx.Requirements.FlowParams
+		}
+	}
+	return nil
+}
+
+// nextScheduledCopy returns the time of the next copy of a scheduled asset, based on the time of its latest copy.
+// The zero time is returned if the asset has not been copied yet or the schedule is invalid.
+func nextScheduledCopy(application *fappv1.FybrikApplication, flowParams *fappv1.FlowRequirements, assetID string) time.Time {
+	latest, found := application.Status.ProvisionedStorage[assetID]
+	if !found || latest.CreationTime == nil {
+		return time.Time{}
+	}
+	cron, err := schedule.Parse(flowParams.Schedule)
+	if err != nil {
+		return time.Time{}
+	}
+	return cron.Next(latest.CreationTime.UTC())
+}
+
+// scheduledCopiesDue returns true if a new copy of a scheduled asset should be made
+func (r *FybrikApplicationReconciler) scheduledCopiesDue(applicationContext ApplicationContext) bool {
+	application := applicationContext.Application
+	now := time.Now()
+	for _, dataCtx := range application.Spec.Data {
+		flowParams := scheduledCopyRequirements(application, dataCtx.DataSetID)
+		if flowParams == nil {
+			continue
+		}
+		if next := nextScheduledCopy(application, flowParams, dataCtx.DataSetID); !next.IsZero() && !now.Before(next) {
+			applicationContext.Log.Info().Bool(logging.FORUSER, true).Bool(logging.AUDIT, true).
+				Str(logging.DATASETID, dataCtx.DataSetID).Msg("A scheduled copy is due, generating a new plan")
+			return true
+		}
+	}
+	return false
+}
+
+// retainPreviousCopy keeps the storage of the latest copy of a scheduled asset as a previous copy,
+// before it is replaced by the storage of a new copy. It returns false if the asset is not copied according to a schedule.
+func retainPreviousCopy(application *fappv1.FybrikApplication, assetID string) bool {
+	if scheduledCopyRequirements(application, assetID) == nil {
+		return false
+	}
+	latest, found := application.Status.ProvisionedStorage[assetID]
+	if !found {
+		return true
+	}
+	if latest.CreationTime == nil {
+		now := metav1.Now()
+		latest.CreationTime = &now
+	}
+	latest.ScheduledCopyOf = assetID
+	application.Status.ProvisionedStorage[previousCopyKey(assetID, latest.CreationTime.Time)] = latest
+	return true
+}
+
+// retainedCopies returns the keys under which the latest copies of the scheduled assets are retained
+// when new copies are provisioned, by the asset ids
+func retainedCopies(application *fappv1.FybrikApplication) map[string]string {
+	keys := map[string]string{}
+	for _, dataCtx := range application.Spec.Data {
+		if scheduledCopyRequirements(application, dataCtx.DataSetID) == nil {
+			continue
+		}
+		if latest, found := application.Status.ProvisionedStorage[dataCtx.DataSetID]; found && latest.CreationTime != nil {
+			keys[dataCtx.DataSetID] = previousCopyKey(dataCtx.DataSetID, latest.CreationTime.Time)
+		}
+	}
+	return keys
+}
+
+func previousCopyKey(assetID string, created time.Time) string {
+	return assetID + ScheduledCopyKeySeparator + created.UTC().Format("20060102T150405Z")
+}
+
+// copyExpired returns true if a previous copy exceeds the retention. The index of the copy starts with 0 for the
+// most recent previous copy, which is the second copy since the latest copy is counted as well.
+func copyExpired(retention *fappv1.CopyRetention, index int, created, now time.Time) bool {
+	if retention == nil {
+		return true
+	}
+	if retention.Count > 0 && index+2 > int(retention.Count) {
+		return true
+	}
+	return retention.TTL != nil && now.Sub(created) > retention.TTL.Duration
+}
+
+// pruneScheduledCopies deletes the previous copies of scheduled assets whose retention has expired.
+// The previous copies of an asset are kept until its latest copy has completed, which is then counted as well.
+// The storage of copies that have been registered in a catalog is persistent, thus they are only removed from the status.
+// Copies that could not be deleted are kept for another attempt.
+func (r *FybrikApplicationReconciler) pruneScheduledCopies(applicationContext ApplicationContext) {
+	application := applicationContext.Application
+	previousCopies := make(map[string][]string)
+	for key, details := range application.Status.ProvisionedStorage {
+		if details.ScheduledCopyOf != "" {
+			previousCopies[details.ScheduledCopyOf] = append(previousCopies[details.ScheduledCopyOf], key)
+		}
+	}
+	if len(previousCopies) == 0 || r.readyPlotter(applicationContext) == nil {
+		return
+	}
+	owner := client.ObjectKeyFromObject(application).String()
+	now := time.Now()
+	for assetID, keys := range previousCopies {
+		if !copyCompleted(application, assetID) {
+			continue
+		}
+		var retention *fappv1.CopyRetention
+		if flowParams := scheduledCopyRequirements(application, assetID); flowParams != nil {
+			retention = flowParams.Retention
+		}
+		// the most recent copies first
+		sort.Slice(keys, func(i, j int) bool {
+			return creationTime(application, keys[i]).After(creationTime(application, keys[j]))
+		})
+		for index, key := range keys {
+			details := application.Status.ProvisionedStorage[key]
+			if !copyExpired(retention, index, creationTime(application, key), now) && !storageExpired(&details, now) {
+				continue
+			}
+			if !details.Persistent {
+				if err := r.deleteTemporaryStorage(applicationContext, key, details); err != nil {
+					applicationContext.Log.Error().Err(err).Str(logging.DATASETID, assetID).Msg("Could not delete a previous copy")
+					continue
+				}
+			}
+			if err := releaseStorage(r.Client, owner, key); err != nil {
+				applicationContext.Log.Error().Err(err).Str(logging.DATASETID, assetID).Msg("Could not release the storage of a previous copy")
+				continue
+			}
+			applicationContext.Log.Info().Str(logging.DATASETID, assetID).Msg("Previous copy " + key + " has been removed")
+			delete(application.Status.ProvisionedStorage, key)
+		}
+	}
+}
+
+// copyCompleted returns true if the latest copy of a scheduled asset has completed.
+// An asset that is not copied any more has no latest copy to wait for.
+func copyCompleted(application *fappv1.FybrikApplication, assetID string) bool {
+	if scheduledCopyRequirements(application, assetID) == nil {
+		return true
+	}
+	state, found := application.Status.AssetStates[assetID]
+	return found && state.Conditions[ReadyConditionIndex].Status == corev1.ConditionTrue
+}
+
+func creationTime(application *fappv1.FybrikApplication, key string) time.Time {
+	if created := application.Status.ProvisionedStorage[key].CreationTime; created != nil {
+		return created.Time
+	}
+	return time.Time{}
+}
+
+// nextScheduledEvent returns the time until the next scheduled copy, the next expiration of a previous copy
+// or the next expiration of temporary storage, or 0 if there are none.
+// Events that are past due are handled by the current reconcile, or retried upon errors.
+func nextScheduledEvent(application *fappv1.FybrikApplication, now time.Time) time.Duration {
+	var next time.Time
+	earliest := func(t time.Time) {
+		if t.After(now) && (next.IsZero() || t.Before(next)) {
+			next = t
+		}
+	}
+	for _, dataCtx := range application.Spec.Data {
+		if flowParams := scheduledCopyRequirements(application, dataCtx.DataSetID); flowParams != nil {
+			earliest(nextScheduledCopy(application, flowParams, dataCtx.DataSetID))
+		}
+	}
+	for key, details := range application.Status.ProvisionedStorage {
+		if !details.Persistent && details.ExpirationTime != nil {
+			earliest(details.ExpirationTime.Time)
+		}
+		if details.ScheduledCopyOf == "" {
+			continue
+		}
+		if flowParams := scheduledCopyRequirements(application, details.ScheduledCopyOf); flowParams != nil &&
+			flowParams.Retention != nil && flowParams.Retention.TTL != nil {
+			earliest(creationTime(application, key).Add(flowParams.Retention.TTL.Duration))
+		}
+	}
+	if next.IsZero() {
+		return 0
+	}
+	return next.Sub(now)
+}
@@ -37,10 +37,11 @@ func (p *PathBuilder) solve() (datapath.Solution, error) {
 	// No data path found for the asset
 	if len(solutions) == 0 {
 		msg := "Deployed modules do not provide the functionality required to construct a data path"
-		p.Log.Error().Str(logging.DATASETID, p.Asset.Context.DataSetID).Msg(msg)
+		dataPathErr := datapath.NewDataPathError(p.Env, p.Asset)
+		p.Log.Error().Err(dataPathErr).Str(logging.DATASETID, p.Asset.Context.DataSetID).Msg(msg)
 		logging.LogStructure("Data Item Context", p.Asset, p.Log, zerolog.TraceLevel, true, true)
 		logging.LogStructure("Module Map", p.Env.Modules, p.Log, zerolog.TraceLevel, true, true)
-		return datapath.Solution{}, errors.New(msg + " for " + p.Asset.Context.DataSetID)
+		return datapath.Solution{}, dataPathErr
 	}
 	return solutions[0], nil
 }
@@ -96,7 +97,8 @@ func (p *PathBuilder) validateStorageRequirements(element *datapath.ResolvedEdge
 	}
 	// select a storage account that
 	// 1. satisfies admin config restrictions on storage
-	// 2. writing to this storage is not forbidden by governance policies
+	// 2. has capacity for the storage estimate of the dataset
+	// 3. writing to this storage is not forbidden by governance policies
 	for accountInd := range p.Env.StorageAccounts {
 		// validate restrictions
 		moduleCapability := element.Module.Spec.Capabilities[element.CapabilityIndex]
@@ -118,6 +120,11 @@ func (p *PathBuilder) validateStorageRequirements(element *datapath.ResolvedEdge
 				account.Name)
 			continue
 		}
+		if !account.HasCapacity(p.Asset.Context.Requirements.FlowParams.StorageEstimate) {
+			p.Log.Debug().Str(logging.DATASETID, p.Asset.Context.DataSetID).Msgf("storage account %s exceeds its quota",
+				account.Name)
+			continue
+		}
 		// query the policy manager whether WRITE operation is allowed
 		actions, found = p.Asset.StorageRequirements[account.Spec.Geography]
 		if !found {
@@ -316,9 +323,8 @@ func GetDependencies(module *fapp.FybrikModule, moduleMap map[string]*fapp.Fybri
 func supportsGovernanceAction(edge *datapath.Edge, action taxonomy.Action) bool {
 	// Loop over the data transforms (actions) performed by the module for this capability
 	capability := edge.Module.Spec.Capabilities[edge.CapabilityIndex]
-	for _, act := range capability.Actions {
-		// TODO(shlomitk1): check for matching of additional fields declared by the module
-		if act.Name == action.Name {
+	for i := range capability.Actions {
+		if capability.Actions[i].Supports(&action) {
 			return true
 		}
 	}
@@ -21,9 +21,8 @@ import (
 // satisfying governance and admin policies
 // with respect to the optimization strategy
 func solveSingleDataset(env *datapath.Environment, dataset *datapath.DataInfo, log *zerolog.Logger) (datapath.Solution, error) {
-	cspPath := environment.GetCSPPath()
-	if environment.UseCSP() && cspPath != "" {
-		cspOptimizer := optimizer.NewOptimizer(env, dataset, cspPath, log)
+	if environment.UseCSP() {
+		cspOptimizer := optimizer.NewOptimizer(env, dataset, optimizer.NewSolverFromEnv(), log)
 		solution, err := cspOptimizer.Solve()
 		if err == nil {
 			if len(solution.DataPath) > 0 { // solver found a solution
@@ -31,10 +30,11 @@ func solveSingleDataset(env *datapath.Environment, dataset *datapath.DataInfo, l
 			}
 			if len(solution.DataPath) == 0 { // solver returned UNSAT
 				msg := "Data path cannot be constructed given the deployed modules and the active restrictions"
-				log.Error().Str(logging.DATASETID, dataset.Context.DataSetID).Msg(msg)
+				dataPathErr := datapath.NewDataPathError(env, dataset)
+				log.Error().Err(dataPathErr).Str(logging.DATASETID, dataset.Context.DataSetID).Msg(msg)
 				logging.LogStructure("Data Item Context", dataset, log, zerolog.TraceLevel, true, true)
 				logging.LogStructure("Module Map", env.Modules, log, zerolog.TraceLevel, true, true)
-				return datapath.Solution{}, errors.New(msg + " for " + dataset.Context.DataSetID)
+				return datapath.Solution{}, dataPathErr
 			}
 		} else {
 			msg := "Error solving CSP. Fybrik will now search for a solution without considering optimization goals."
@@ -52,6 +52,11 @@ func solve(env *datapath.Environment, datasets []datapath.DataInfo, log *zerolog
 	if err := validateBasicConditions(env, datasets, log); err != nil {
 		return solutions, err
 	}
+	if environment.UseCSP() && environment.UseJointCSP() && len(datasets) > 1 {
+		if jointSolutions, ok := solveJointly(env, datasets, log); ok {
+			return jointSolutions, nil
+		}
+	}
 	for i := range datasets {
 		solution, err := solveSingleDataset(env, &datasets[i], log)
 		if err != nil {
@@ -62,6 +67,25 @@ func solve(env *datapath.Environment, datasets []datapath.DataInfo, log *zerolog
 	return solutions, nil
 }
 
+// find a solution for all data paths together, optimizing their joint quality
+// returns false if no joint solution was found, in which case each dataset should be solved on its own
+func solveJointly(env *datapath.Environment, datasets []datapath.DataInfo, log *zerolog.Logger) ([]datapath.Solution, bool) {
+	jointOptimizer := optimizer.NewJointOptimizer(env, datasets, optimizer.NewSolverFromEnv(), log)
+	solutions, err := jointOptimizer.Solve()
+	if err != nil {
+		msg := "Error solving joint CSP. Fybrik will now search for a solution for each dataset separately."
+		log.Error().Err(err).Msg(msg)
+		return nil, false
+	}
+	for i := range solutions {
+		if len(solutions[i].DataPath) == 0 {
+			log.Warn().Msg("No joint solution found. Fybrik will now search for a solution for each dataset separately.")
+			return nil, false
+		}
+	}
+	return solutions, true
+}
+
 // perform basic checks before searching for a solution for a dataset
 func validateBasicConditions(env *datapath.Environment, datasets []datapath.DataInfo, log *zerolog.Logger) error {
 	if len(env.Modules) == 0 {
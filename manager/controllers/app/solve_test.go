@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"testing"
 
+	"emperror.dev/errors"
 	"github.com/onsi/gomega"
 	"github.com/rs/zerolog"
 
@@ -1107,3 +1108,188 @@ func TestClusterAttribute(t *testing.T) {
 	solution := solutions[0]
 	g.Expect(solution.DataPath[0].Cluster).To(gomega.Equal(allowedCluster.Name))
 }
+
+// The explanations of a failure to construct a data path
+func dataPathError(g *gomega.WithT, err error) *datapath.DataPathError {
+	var dataPathErr *datapath.DataPathError
+	g.Expect(errors.As(err, &dataPathErr)).To(gomega.BeTrue())
+	return dataPathErr
+}
+
+// A governance action which is not supported by any module is explained
+func TestExplainUnsupportedAction(t *testing.T) {
+	t.Parallel()
+	g := gomega.NewGomegaWithT(t)
+	env := newEnvironment()
+	readModule := &fapp.FybrikModule{}
+	g.Expect(readObjectFromFile("../../testdata/unittests/module-read-csv.yaml", readModule)).NotTo(gomega.HaveOccurred())
+	addModule(env, readModule)
+	addCluster(env, multicluster.Cluster{Name: "cluster1", Metadata: multicluster.ClusterMetadata{Region: "theshire"}})
+	asset := createReadRequest()
+	asset.Actions = []taxonomy.Action{{Name: "RedactAction"}}
+	_, err := solve(env, []datapath.DataInfo{*asset}, &testLog)
+	g.Expect(err).To(gomega.HaveOccurred())
+	dataPathErr := dataPathError(g, err)
+	g.Expect(dataPathErr.DataSetID).To(gomega.Equal(asset.Context.DataSetID))
+	g.Expect(dataPathErr.HasReason(datapath.UnsupportedAction)).To(gomega.BeTrue())
+	g.Expect(err.Error()).To(gomega.ContainSubstring("RedactAction"))
+}
+
+// A column action is supported only by modules that support the column scope of the action
+func TestActionScopes(t *testing.T) {
+	t.Parallel()
+	g := gomega.NewGomegaWithT(t)
+	env := newEnvironment()
+	readModule := &fapp.FybrikModule{}
+	g.Expect(readObjectFromFile("../../testdata/unittests/module-read-write.yaml", readModule)).NotTo(gomega.HaveOccurred())
+	addModule(env, readModule)
+	addCluster(env, multicluster.Cluster{Name: "cluster1", Metadata: multicluster.ClusterMetadata{Region: "theshire"}})
+	asset := createReadRequest()
+	asset.Actions = taxonomy.MergeActions([]taxonomy.Action{
+		taxonomy.NewColumnAction("RedactAction", []string{"nameOrig"}),
+		taxonomy.NewColumnAction("RedactAction", []string{"nameDest", "nameOrig"}),
+	})
+	g.Expect(asset.Actions).To(gomega.HaveLen(1))
+	g.Expect(asset.Actions[0].Scope()).To(gomega.Equal(taxonomy.ColumnScope))
+	g.Expect(asset.Actions[0].Columns()).To(gomega.Equal([]string{"nameOrig", "nameDest"}))
+	// the module redacts whole datasets only
+	for i := range readModule.Spec.Capabilities {
+		for j := range readModule.Spec.Capabilities[i].Actions {
+			readModule.Spec.Capabilities[i].Actions[j].Scopes = []taxonomy.ActionScope{taxonomy.DatasetScope}
+		}
+	}
+	_, err := solve(env, []datapath.DataInfo{*asset}, &testLog)
+	g.Expect(err).To(gomega.HaveOccurred())
+	g.Expect(dataPathError(g, err).HasReason(datapath.UnsupportedAction)).To(gomega.BeTrue())
+	g.Expect(err.Error()).To(gomega.ContainSubstring("column scope"))
+	for i := range readModule.Spec.Capabilities {
+		for j := range readModule.Spec.Capabilities[i].Actions {
+			readModule.Spec.Capabilities[i].Actions[j].Scopes = append(readModule.Spec.Capabilities[i].Actions[j].Scopes,
+				taxonomy.ColumnScope)
+		}
+	}
+	solutions, err := solve(env, []datapath.DataInfo{*asset}, &testLog)
+	g.Expect(err).ToNot(gomega.HaveOccurred())
+	g.Expect(solutions[0].DataPath).To(gomega.HaveLen(1))
+	g.Expect(solutions[0].DataPath[0].Actions).To(gomega.HaveLen(1))
+}
+
+// Columns of actions are validated against the asset schema, including nested fields and fields of list elements
+func TestActionColumnsInSchema(t *testing.T) {
+	t.Parallel()
+	g := gomega.NewGomegaWithT(t)
+	metadata := &datacatalog.ResourceMetadata{Columns: []datacatalog.ResourceColumn{{Name: "nameOrig"}}}
+	actions := []taxonomy.Action{taxonomy.NewColumnAction("RedactAction", []string{"address.street"})}
+	// the schema is unknown
+	g.Expect(validateActionColumns(actions, metadata)).To(gomega.Succeed())
+	metadata.Columns = []datacatalog.ResourceColumn{
+		{Name: "nameOrig", Type: "string"},
+		{Name: "address", Type: datacatalog.StructType, Fields: []datacatalog.ResourceColumn{{Name: "street", Type: "string"}}},
+		{Name: "transactions", Type: datacatalog.ListType, Fields: []datacatalog.ResourceColumn{
+			{Name: "item", Type: datacatalog.StructType, Fields: []datacatalog.ResourceColumn{{Name: "amount", Type: "float64"}}},
+		}},
+	}
+	actions = append(actions, taxonomy.NewColumnAction("RedactAction", []string{"nameOrig", "transactions.amount"}))
+	g.Expect(validateActionColumns(actions, metadata)).To(gomega.Succeed())
+	g.Expect(metadata.Column("transactions.amount").Type).To(gomega.BeEquivalentTo("float64"))
+	actions = append(actions, taxonomy.NewColumnAction("RedactAction", []string{"address.city"}))
+	err := validateActionColumns(actions, metadata)
+	g.Expect(err).To(gomega.HaveOccurred())
+	g.Expect(err.Error()).To(gomega.Equal(fmt.Sprintf(UnknownActionColumn, "RedactAction", "address.city")))
+}
+
+// A cluster restriction which eliminates the last candidate cluster is explained
+func TestExplainClusterRestriction(t *testing.T) {
+	t.Parallel()
+	g := gomega.NewGomegaWithT(t)
+	env := newEnvironment()
+	readModule := &fapp.FybrikModule{}
+	g.Expect(readObjectFromFile("../../testdata/unittests/module-read-csv.yaml", readModule)).NotTo(gomega.HaveOccurred())
+	addModule(env, readModule)
+	addCluster(env, multicluster.Cluster{Name: "cluster1", Metadata: multicluster.ClusterMetadata{Region: "theshire"}})
+	asset := createReadRequest()
+	asset.Configuration.ConfigDecisions["read"] = adminconfig.Decision{
+		Deploy: adminconfig.StatusTrue,
+		DeploymentRestrictions: adminconfig.Restrictions{
+			Clusters: []adminconfig.Restriction{{Property: "metadata.region", Values: adminconfig.StringList{"neverland"}}}},
+		Policy: adminconfig.DecisionPolicy{ID: "read-in-neverland"},
+	}
+	_, err := solve(env, []datapath.DataInfo{*asset}, &testLog)
+	g.Expect(err).To(gomega.HaveOccurred())
+	dataPathErr := dataPathError(g, err)
+	g.Expect(dataPathErr.HasReason(datapath.MissingCapability)).To(gomega.BeTrue())
+	g.Expect(dataPathErr.HasReason(datapath.BrokenInterfaceChain)).To(gomega.BeTrue())
+	g.Expect(err.Error()).To(gomega.ContainSubstring("last candidate cluster cluster1"))
+	g.Expect(err.Error()).To(gomega.ContainSubstring("metadata.region in [neverland]"))
+	g.Expect(err.Error()).To(gomega.ContainSubstring("read-in-neverland"))
+}
+
+// A storage account which cannot be used by the write module is explained
+func TestExplainStorageMismatch(t *testing.T) {
+	t.Parallel()
+	g := gomega.NewGomegaWithT(t)
+	env := newEnvironment()
+	writeModule := &fapp.FybrikModule{}
+	g.Expect(readObjectFromFile("../../testdata/unittests/module-read-write.yaml", writeModule)).NotTo(gomega.HaveOccurred())
+	addModule(env, writeModule)
+	account := &saApi.FybrikStorageAccount{}
+	g.Expect(readStorageAccountData("../../testdata/unittests/account-theshire.yaml", account)).NotTo(gomega.HaveOccurred())
+	account.Spec.Type = "mysql"
+	addStorageAccount(env, account)
+	addCluster(env, multicluster.Cluster{Metadata: multicluster.ClusterMetadata{Region: string(account.Spec.Geography)}})
+	asset := createWriteNewAssetRequest()
+	asset.StorageRequirements[account.Spec.Geography] = []taxonomy.Action{}
+	_, err := solve(env, []datapath.DataInfo{*asset}, &testLog)
+	g.Expect(err).To(gomega.HaveOccurred())
+	dataPathErr := dataPathError(g, err)
+	g.Expect(dataPathErr.HasReason(datapath.NoAllowedStorageAccount)).To(gomega.BeTrue())
+	g.Expect(err.Error()).To(gomega.ContainSubstring("mysql"))
+}
+
+// Read scenario, three clusters with costs
+// Without the optimizer, a single data path is found, since the module is deployed on the first allowed cluster
+// With the optimizer, two alternative data paths are reported, and the cheapest cluster is ranked first
+func TestDataPathAlternatives(t *testing.T) {
+	t.Parallel()
+	g := gomega.NewGomegaWithT(t)
+	env := newEnvironment()
+	readModule := &fapp.FybrikModule{}
+	g.Expect(readObjectFromFile("../../testdata/unittests/module-read-csv.yaml", readModule)).NotTo(gomega.HaveOccurred())
+	addModule(env, readModule)
+	addMetrics(env, &taxonomy.InfrastructureMetrics{Name: "cost", Type: taxonomy.Numeric, Scale: &taxonomy.RangeType{Max: 100}})
+	for i, cost := range []int{30, 10, 20} {
+		name := genName("cluster", i)
+		addCluster(env, multicluster.Cluster{Name: name, Metadata: multicluster.ClusterMetadata{Region: genName("region", i)}})
+		addAttribute(env, &taxonomy.InfrastructureElement{
+			Name:       "cluster-cost",
+			MetricName: "cost",
+			Value:      fmt.Sprintf("%d", cost),
+			Object:     taxonomy.Cluster,
+			Instance:   name,
+		})
+	}
+	asset := createReadRequest()
+	asset.Configuration.OptimizationStrategy = []adminconfig.AttributeOptimization{
+		{Attribute: "cluster-cost", Directive: adminconfig.Minimize},
+	}
+	solutions, err := findAlternatives(env, asset, 2, &testLog)
+	g.Expect(err).ToNot(gomega.HaveOccurred())
+	alternatives := alternativesToStatus(solutions)
+	g.Expect(alternatives).NotTo(gomega.BeEmpty())
+	for i := range alternatives {
+		g.Expect(alternatives[i].Rank).To(gomega.Equal(i + 1))
+		g.Expect(alternatives[i].Steps).To(gomega.HaveLen(1))
+		g.Expect(alternatives[i].Steps[0].Module).To(gomega.Equal(readModule.Name))
+	}
+	if !environment.UseCSP() {
+		g.Expect(alternatives).To(gomega.HaveLen(1))
+		g.Expect(alternatives[0].Score).To(gomega.BeEmpty())
+		return
+	}
+	g.Expect(alternatives).To(gomega.HaveLen(2))
+	g.Expect(alternatives[0].Steps[0].Cluster).To(gomega.Equal("cluster1"))
+	g.Expect(alternatives[1].Steps[0].Cluster).To(gomega.Equal("cluster2"))
+	g.Expect(alternatives[0].Score).NotTo(gomega.BeEmpty())
+	g.Expect(alternatives[0].Goals).To(gomega.HaveLen(1))
+	g.Expect(alternatives[0].Goals[0].Attribute).To(gomega.Equal("cluster-cost"))
+}
@@ -0,0 +1,265 @@
+// Copyright 2023 IBM Corp.
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	fappv1 "fybrik.io/fybrik/manager/apis/app/v1beta1"
+	fappv2 "fybrik.io/fybrik/manager/apis/app/v1beta2"
+	"fybrik.io/fybrik/pkg/audit"
+	storage "fybrik.io/fybrik/pkg/connectors/storagemanager/clients"
+	"fybrik.io/fybrik/pkg/environment"
+	"fybrik.io/fybrik/pkg/logging"
+	"fybrik.io/fybrik/pkg/model/storagemanager"
+	"fybrik.io/fybrik/pkg/model/taxonomy"
+)
+
+// storageCollector finds storage allocated by Fybrik in the storage accounts that is not used by any FybrikApplication.
+// Such orphaned storage remains if the manager fails in the middle of a flow, or if a FybrikApplication is removed
+// without running its finalizer. Orphans are reported in the status of their storage account, and deleted once they
+// have been found orphaned for the grace period, if enabled. Persistent storage is never collected.
+type storageCollector struct {
+	client         client.Client
+	storageManager storage.StorageManagerInterface
+	log            *zerolog.Logger
+	audit          *audit.Auditor
+	gracePeriod    time.Duration
+	deleteOrphans  bool
+}
+
+// newStorageCollector returns a collector of the storage allocated by the application controller
+func (r *FybrikApplicationReconciler) newStorageCollector() *storageCollector {
+	return &storageCollector{
+		client:         r.Client,
+		storageManager: r.StorageManager,
+		log:            &r.Log,
+		audit:          r.Audit,
+		gracePeriod:    environment.GetStorageGCGracePeriod(),
+		deleteOrphans:  environment.StorageGCDeletesOrphans(),
+	}
+}
+
+// periodicCollection returns a runnable that collects orphaned storage at the given interval
+func (c *storageCollector) periodicCollection(interval time.Duration) manager.RunnableFunc {
+	return func(ctx context.Context) error {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-ticker.C:
+				if err := c.collect(time.Now()); err != nil {
+					c.log.Error().Err(err).Msg("Storage garbage collection failed")
+				}
+			}
+		}
+	}
+}
+
+// collect checks the storage allocated in each storage account against the storage provisioned for the applications
+func (c *storageCollector) collect(now time.Time) error {
+	used, err := c.usedStorage()
+	if err != nil {
+		return err
+	}
+	accounts, err := listStorageAccounts(c.client)
+	if err != nil {
+		return err
+	}
+	for i := range accounts {
+		if err := c.collectAccount(&accounts[i], used, now); err != nil {
+			c.log.Error().Err(err).Msg("Could not collect the storage of account " + accounts[i].Name)
+		}
+	}
+	return nil
+}
+
+// usedStorage returns the connections of the storage provisioned for all the applications
+func (c *storageCollector) usedStorage() ([]taxonomy.Connection, error) {
+	applications := &fappv1.FybrikApplicationList{}
+	if err := c.client.List(context.Background(), applications); err != nil {
+		return nil, err
+	}
+	used := []taxonomy.Connection{}
+	for i := range applications.Items {
+		for _, details := range applications.Items[i].Status.ProvisionedStorage {
+			if details.Details != nil {
+				used = append(used, details.Details.Connection)
+			}
+		}
+	}
+	return used, nil
+}
+
+// collectAccount finds the orphaned storage of an account, deletes orphans whose grace period has passed if enabled,
+// and reports the remaining orphans in the account status
+func (c *storageCollector) collectAccount(account *fappv2.FybrikStorageAccount, used []taxonomy.Connection, now time.Time) error {
+	secretRef := taxonomy.SecretRef{Name: account.Spec.SecretRef, Namespace: environment.GetAdminCRsNamespace()}
+	response, err := c.storageManager.ListStorage(&storagemanager.ListStorageRequest{
+		AccountType:       account.Spec.Type,
+		AccountProperties: taxonomy.StorageAccountProperties{Properties: account.Spec.AdditionalProperties},
+		Secret:            secretRef,
+	})
+	if err != nil {
+		if err.Error() == storage.StorageTypeNotSupported {
+			c.log.Debug().Msg("Allocated storage can not be listed in account " + account.Name)
+			return nil
+		}
+		return err
+	}
+	detected := make(map[string]metav1.Time)
+	for _, orphan := range account.Status.Orphans {
+		detected[orphan.Name] = orphan.DetectionTime
+	}
+	orphans := []fappv2.OrphanedStorage{}
+	for i := range response.Storage {
+		allocated := &response.Storage[i]
+		if allocated.Persistent || isUsed(&allocated.Connection, used) {
+			continue
+		}
+		orphan := fappv2.OrphanedStorage{
+			Name:          allocated.Name,
+			Application:   allocated.AppDetails.Namespace + "/" + allocated.AppDetails.Name,
+			DetectionTime: metav1.NewTime(now),
+		}
+		if created, err := time.Parse(time.RFC3339, allocated.CreationTime); err == nil {
+			orphan.CreationTime = &metav1.Time{Time: created}
+		}
+		if detectionTime, found := detected[allocated.Name]; found {
+			orphan.DetectionTime = detectionTime
+		} else {
+			c.log.Warn().Bool(logging.FORUSER, true).Msgf("Storage %s allocated in account %s for %s is not used by any application",
+				allocated.Name, account.Name, orphan.Application)
+		}
+		if c.deleteOrphans && now.Sub(orphan.DetectionTime.Time) >= c.gracePeriod {
+			err := c.storageManager.DeleteStorage(&storagemanager.DeleteStorageRequest{Connection: allocated.Connection, Secret: secretRef})
+			c.auditOrphanDeletion(account, allocated, err)
+			if err == nil {
+				c.log.Info().Bool(logging.FORUSER, true).Bool(logging.AUDIT, true).
+					Msgf("Orphaned storage %s has been deleted from account %s", allocated.Name, account.Name)
+				continue
+			}
+			c.log.Error().Err(err).Msgf("Could not delete orphaned storage %s from account %s", allocated.Name, account.Name)
+		}
+		orphans = append(orphans, orphan)
+	}
+	return c.updateOrphans(account, orphans)
+}
+
+// updateOrphans records the orphaned storage in the status of the account
+func (c *storageCollector) updateOrphans(account *fappv2.FybrikStorageAccount, orphans []fappv2.OrphanedStorage) error {
+	if len(orphans) == 0 {
+		orphans = nil
+	}
+	if equality.Semantic.DeepEqual(account.Status.Orphans, orphans) {
+		return nil
+	}
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if err := c.client.Get(context.Background(), client.ObjectKeyFromObject(account), account); err != nil {
+			return client.IgnoreNotFound(err)
+		}
+		account.Status.Orphans = orphans
+		return c.client.Status().Update(context.Background(), account)
+	})
+}
+
+// auditOrphanDeletion records the deletion of orphaned storage
+func (c *storageCollector) auditOrphanDeletion(account *fappv2.FybrikStorageAccount, allocated *storagemanager.AllocatedStorage,
+	err error) {
+	if c.audit == nil {
+		return
+	}
+	record := &audit.Record{
+		Type: audit.StorageDeletionRecord,
+		Application: audit.Application{
+			Namespace: allocated.AppDetails.Namespace,
+			Name:      allocated.AppDetails.Name,
+			UUID:      allocated.AppDetails.UUID,
+		},
+		Storage: &audit.Storage{
+			Account:    account.Spec.ID,
+			Type:       account.Spec.Type,
+			Geography:  account.Spec.Geography,
+			Connection: allocated.Connection.DeepCopy(),
+			Orphaned:   true,
+		},
+	}
+	if err != nil {
+		record.Storage.Error = err.Error()
+	}
+	c.audit.Record(record)
+}
+
+// isUsed returns true if listed storage is identified by the connection of storage provisioned for an application,
+// i.e., if the properties of the listed connection have the same values in the provisioned connection.
+// Storage whose listed connection has no properties can not be identified, thus it is considered used.
+func isUsed(listed *taxonomy.Connection, used []taxonomy.Connection) bool {
+	listedProps, _ := listed.AdditionalProperties.Items[string(listed.Name)].(map[string]interface{})
+	if len(listedProps) == 0 {
+		return true
+	}
+	for i := range used {
+		if used[i].Name != listed.Name {
+			continue
+		}
+		props, _ := used[i].AdditionalProperties.Items[string(used[i].Name)].(map[string]interface{})
+		identified := true
+		for key, value := range listedProps {
+			if fmt.Sprint(props[key]) != fmt.Sprint(value) {
+				identified = false
+				break
+			}
+		}
+		if identified {
+			return true
+		}
+	}
+	return false
+}
+
+// storageExpiration returns the expiration time of storage allocated for a dataset of the application in the given account,
+// or nil if the storage does not expire. Storage of datasets that are registered in a catalog is persistent, thus it does
+// not expire.
+func storageExpiration(application *fappv1.FybrikApplication, datasetID string, account *fappv2.FybrikStorageAccountSpec,
+	now time.Time) *metav1.Time {
+	if account == nil || account.TTL == nil {
+		return nil
+	}
+	for _, dataCtx := range application.Spec.Data {
+		if dataCtx.DataSetID == datasetID && dataCtx.Requirements.FlowParams.Catalog != "" {
+			return nil
+		}
+	}
+	return &metav1.Time{Time: now.Add(account.TTL.Duration)}
+}
+
+// storageExpired returns true if temporary storage has expired
+func storageExpired(details *fappv1.DatasetDetails, now time.Time) bool {
+	return !details.Persistent && details.ExpirationTime != nil && !now.Before(details.ExpirationTime.Time)
+}
+
+// temporaryStorageExpired returns true if temporary storage of the application has expired, thus a new copy should be made.
+// Previous copies of scheduled assets are not renewed, they are removed once they expire.
+func (r *FybrikApplicationReconciler) temporaryStorageExpired(applicationContext ApplicationContext) bool {
+	now := time.Now()
+	for datasetID, details := range applicationContext.Application.Status.ProvisionedStorage {
+		if details.ScheduledCopyOf == "" && storageExpired(&details, now) {
+			applicationContext.Log.Info().Bool(logging.FORUSER, true).Bool(logging.AUDIT, true).
+				Str(logging.DATASETID, datasetID).Msg("Temporary storage has expired, generating a new plan")
+			return true
+		}
+	}
+	return false
+}
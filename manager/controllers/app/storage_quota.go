@@ -0,0 +1,152 @@
+// Copyright 2023 IBM Corp.
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"context"
+
+	"emperror.dev/errors"
+	"github.com/c2h5oh/datasize"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	fappv2 "fybrik.io/fybrik/manager/apis/app/v1beta2"
+	"fybrik.io/fybrik/pkg/environment"
+)
+
+// reserveStorage records the storage allocated for a dataset of the owner application in the status of the storage account
+// with the given id, and removes previous allocations of the dataset from other accounts.
+// If retainedKey is set, the previous allocation of the dataset is kept under that key rather than removed,
+// since the previous storage is retained, e.g., as a previous copy of a scheduled asset.
+// It fails if the storage estimate of the dataset exceeds the remaining quota of the account.
+func reserveStorage(cl client.Client, accountID, owner, datasetID, retainedKey string, estimate datasize.ByteSize) error {
+	accounts, err := listStorageAccounts(cl)
+	if err != nil {
+		return err
+	}
+	if retainedKey == "" {
+		return allocateStorage(cl, accounts, accountID, owner, datasetID, estimate)
+	}
+	if err := renameStorage(cl, accounts, owner, datasetID, retainedKey); err != nil {
+		return err
+	}
+	if err := allocateStorage(cl, accounts, accountID, owner, datasetID, estimate); err != nil {
+		// the previous storage remains the storage of the dataset
+		if renameErr := renameStorage(cl, accounts, owner, retainedKey, datasetID); renameErr != nil {
+			return errors.Combine(err, renameErr)
+		}
+		return err
+	}
+	return nil
+}
+
+func allocateStorage(cl client.Client, accounts []fappv2.FybrikStorageAccount, accountID, owner, datasetID string,
+	estimate datasize.ByteSize) error {
+	found := false
+	for i := range accounts {
+		if accounts[i].Spec.ID != accountID {
+			if err := releaseAccountStorage(cl, &accounts[i], owner, datasetID); err != nil {
+				return err
+			}
+			continue
+		}
+		found = true
+		account := &accounts[i]
+		err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+			if err := cl.Get(context.Background(), client.ObjectKeyFromObject(account), account); err != nil {
+				return err
+			}
+			// the previous allocation of the dataset is replaced
+			account.Status.Release(owner, datasetID)
+			if !account.HasCapacity(estimate) {
+				return errors.Errorf("storage account %s does not have capacity for dataset %s: the storage estimate is %s, "+
+					"while %s out of the quota of %s are already allocated", account.Name, datasetID, estimate.HR(),
+					account.Status.EstimatedUsage.String(), account.Spec.Quota.String())
+			}
+			account.Status.Allocate(owner, datasetID, estimate)
+			return cl.Status().Update(context.Background(), account)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if !found {
+		return errors.Errorf("storage account %s is not found", accountID)
+	}
+	return nil
+}
+
+// releaseStorage removes the allocation of a dataset of the owner application from the storage accounts,
+// or all the allocations of the application if the dataset is empty
+func releaseStorage(cl client.Client, owner, datasetID string) error {
+	accounts, err := listStorageAccounts(cl)
+	if err != nil {
+		return err
+	}
+	for i := range accounts {
+		if err := releaseAccountStorage(cl, &accounts[i], owner, datasetID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cancelReservation releases the storage reserved for a dataset of the owner application,
+// and restores the allocation of the previous storage of the dataset if it has been retained under retainedKey
+func cancelReservation(cl client.Client, owner, datasetID, retainedKey string) error {
+	if err := releaseStorage(cl, owner, datasetID); err != nil || retainedKey == "" {
+		return err
+	}
+	accounts, err := listStorageAccounts(cl)
+	if err != nil {
+		return err
+	}
+	return renameStorage(cl, accounts, owner, retainedKey, datasetID)
+}
+
+// renameStorage moves the allocations of a dataset of the owner application to another dataset identifier
+func renameStorage(cl client.Client, accounts []fappv2.FybrikStorageAccount, owner, datasetID, newDatasetID string) error {
+	for i := range accounts {
+		account := &accounts[i]
+		if !account.DeepCopy().Status.Rename(owner, datasetID, newDatasetID) {
+			continue
+		}
+		err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+			if err := cl.Get(context.Background(), client.ObjectKeyFromObject(account), account); err != nil {
+				return client.IgnoreNotFound(err)
+			}
+			if !account.Status.Rename(owner, datasetID, newDatasetID) {
+				return nil
+			}
+			return cl.Status().Update(context.Background(), account)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func releaseAccountStorage(cl client.Client, account *fappv2.FybrikStorageAccount, owner, datasetID string) error {
+	if !account.DeepCopy().Status.Release(owner, datasetID) {
+		return nil
+	}
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if err := cl.Get(context.Background(), client.ObjectKeyFromObject(account), account); err != nil {
+			return client.IgnoreNotFound(err)
+		}
+		if !account.Status.Release(owner, datasetID) {
+			return nil
+		}
+		return cl.Status().Update(context.Background(), account)
+	})
+}
+
+func listStorageAccounts(cl client.Client) ([]fappv2.FybrikStorageAccount, error) {
+	var accountList fappv2.FybrikStorageAccountList
+	if err := cl.List(context.Background(), &accountList, client.InNamespace(environment.GetAdminCRsNamespace())); err != nil {
+		return nil, err
+	}
+	return accountList.Items, nil
+}
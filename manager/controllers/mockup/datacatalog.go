@@ -63,6 +63,20 @@ func (m *DataCatalogDummy) UpdateAsset(in *datacatalog.UpdateAssetRequest, creds
 	return &datacatalog.UpdateAssetResponse{Status: "UpdateAsset not implemented in DataCatalogDummy"}, nil
 }
 
+// ListAssets lists an asset named after the resource for each catalog of the mock
+func (d *DataCatalogDummy) ListAssets(in *datacatalog.ListAssetsRequest, creds string) (*datacatalog.ListAssetsResponse, error) {
+	assets := []datacatalog.AssetInfo{}
+	for catalogID := range d.dataDetails {
+		dataDetails := d.dataDetails[catalogID]
+		assets = append(assets, datacatalog.AssetInfo{
+			AssetID:          taxonomy.AssetID(catalogID + "/" + dataDetails.ResourceMetadata.Name),
+			ResourceMetadata: dataDetails.ResourceMetadata,
+			Details:          dataDetails.Details,
+		})
+	}
+	return in.Page(assets), nil
+}
+
 func (d *DataCatalogDummy) Close() error {
 	return nil
 }
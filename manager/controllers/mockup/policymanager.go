@@ -20,6 +20,8 @@ const (
 	DenyAction   = "Deny"
 	RedactAction = "RedactAction"
 	FilterAction = "FilterAction"
+	// RedactSSNPolicy is the policy that requires redaction of the SSN column of datasets
+	RedactSSNPolicy = "redact SSN of all datasets"
 )
 
 // MockPolicyManager is a mock for PolicyManager interface used in tests
@@ -144,6 +146,7 @@ func (m *MockPolicyManager) GetPoliciesDecisions(input *policymanager.GetPolicyD
 			return nil, err
 		}
 		policyManagerResult.Action = actionOnCols
+		policyManagerResult.Policy = RedactSSNPolicy
 		respResult = append(respResult, policyManagerResult)
 	}
 
@@ -159,3 +162,9 @@ func (m *MockPolicyManager) GetPoliciesDecisions(input *policymanager.GetPolicyD
 
 	return policyManagerResp, nil
 }
+
+// GetPoliciesDecisionsBatch evaluates each request of the batch with GetPoliciesDecisions
+func (m *MockPolicyManager) GetPoliciesDecisionsBatch(input *policymanager.GetPolicyDecisionsBatchRequest,
+	creds string) (*policymanager.GetPolicyDecisionsBatchResponse, error) {
+	return connectors.GetPoliciesDecisionsOneByOne(m, input, creds)
+}
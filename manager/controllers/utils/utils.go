@@ -32,11 +32,12 @@ func GetReleaseName(applicationName, uuid, instanceName string) string {
 
 // Create a name for a step in a blueprint.
 // Since this is part of the name of a release, this should be done in a central location to make testing easier
-func CreateStepName(moduleName, assetID string, moduleScope fapp.CapabilityScope) string {
+// The suffix distinguishes the releases of different generations that run side by side, e.g., in a blue/green rollout
+func CreateStepName(moduleName, assetID string, moduleScope fapp.CapabilityScope, suffix string) string {
 	if moduleScope == fapp.Asset {
-		return moduleName + "-" + utils.Hash(assetID, utils.StepNameHashLength)
+		return moduleName + "-" + utils.Hash(assetID, utils.StepNameHashLength) + suffix
 	}
-	return moduleName
+	return moduleName + suffix
 }
 
 // UpdateStatus updates the resource status
@@ -24,11 +24,13 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 
+	katalogv1alpha1 "fybrik.io/fybrik/connectors/katalog/pkg/apis/katalog/v1alpha1"
 	fappv1 "fybrik.io/fybrik/manager/apis/app/v1beta1"
 	fappv2 "fybrik.io/fybrik/manager/apis/app/v1beta2"
 	"fybrik.io/fybrik/manager/controllers"
 	"fybrik.io/fybrik/manager/controllers/app"
 	"fybrik.io/fybrik/pkg/adminconfig"
+	"fybrik.io/fybrik/pkg/audit"
 	dcclient "fybrik.io/fybrik/pkg/connectors/datacatalog/clients"
 	pmclient "fybrik.io/fybrik/pkg/connectors/policymanager/clients"
 	storage "fybrik.io/fybrik/pkg/connectors/storagemanager/clients"
@@ -38,6 +40,8 @@ import (
 	"fybrik.io/fybrik/pkg/logging"
 	"fybrik.io/fybrik/pkg/monitor"
 	"fybrik.io/fybrik/pkg/multicluster"
+	"fybrik.io/fybrik/pkg/multicluster/inventory"
+	"fybrik.io/fybrik/pkg/multicluster/kubeconfig"
 	"fybrik.io/fybrik/pkg/multicluster/local"
 	"fybrik.io/fybrik/pkg/multicluster/razee"
 	"fybrik.io/fybrik/pkg/utils"
@@ -58,6 +62,7 @@ func init() {
 	_ = corev1.AddToScheme(scheme)
 	_ = netv1.AddToScheme(scheme)
 	_ = coordinationv1.AddToScheme(scheme)
+	_ = katalogv1alpha1.AddToScheme(scheme)
 }
 
 //nolint:funlen,gocyclo
@@ -84,6 +89,7 @@ func run(namespace, metricsAddr, healthProbeAddr string, enableLeaderElection bo
 		&corev1.Secret{}:               {Field: internalCRsNamespaceSelector}, // pull image secrets for blueprints
 		&fappv1.FybrikModule{}:         {Field: adminCRsNamespaceSelector},
 		&fappv2.FybrikStorageAccount{}: {Field: adminCRsNamespaceSelector},
+		&katalogv1alpha1.Asset{}:       {Field: applicationNamespaceSelector},
 	}
 
 	if environment.IsNPEnabled() {
@@ -132,12 +138,20 @@ func run(namespace, metricsAddr, healthProbeAddr string, enableLeaderElection bo
 	// Initialize ClusterManager
 	setupLog.Trace().Msg("creating cluster manager")
 	var clusterManager multicluster.ClusterManager
+	var clusterInventory *inventory.ClusterManager
 	if enableApplicationController || enablePlotterController {
 		clusterManager, err = newClusterManager(mgr)
 		if err != nil {
 			setupLog.Error().Err(err).Msg("unable to initialize cluster manager")
 			return 1
 		}
+		// the clusters are reported with the metadata of their FybrikClusters, which are kept up to date by a heartbeat
+		clusterInventory = inventory.NewClusterManager(clusterManager, mgr.GetClient())
+		if err = mgr.Add(clusterInventory.PeriodicHeartbeat(environment.GetClusterHeartbeatInterval())); err != nil {
+			setupLog.Error().Err(err).Msg("unable to add the cluster heartbeat")
+			return 1
+		}
+		clusterManager = clusterInventory
 	}
 
 	if enableApplicationController {
@@ -189,6 +203,15 @@ func run(namespace, metricsAddr, healthProbeAddr string, enableLeaderElection bo
 			}
 		}()
 
+		// audit records of governance decisions, data paths and storage are written to the configured sinks
+		auditor, err := audit.NewAuditorFromEnvironment(mgr.GetEventRecorderFor("fybrik-audit"), &setupLog)
+		if err != nil {
+			setupLog.Error().Err(err).Str(logging.CONTROLLER, "FybrikApplication").Msg("unable to create the audit sinks")
+			return 1
+		}
+		// records that are queued when the manager stops are written before it exits
+		defer auditor.Flush()
+
 		// Initiate the FybrikApplication Controller
 		applicationController := app.NewFybrikApplicationReconciler(
 			mgr,
@@ -199,12 +222,17 @@ func run(namespace, metricsAddr, healthProbeAddr string, enableLeaderElection bo
 			storageManager,
 			evaluator,
 			infrastructureManager,
+			auditor,
 		)
 		if err = applicationController.SetupWithManager(mgr); err != nil {
 			setupLog.Error().Err(err).Str(logging.CONTROLLER, "FybrikApplication").Msg("unable to create controller")
 			return 1
 		}
+		// applications that are deployed on failed clusters are planned again on the other clusters
+		clusterInventory.Subscribe(applicationController)
 		if os.Getenv("ENABLE_WEBHOOKS") != "false" {
+			// dry-run requests are served over TLS by the webhook server, and are authenticated and authorized per request
+			mgr.GetWebhookServer().Register(app.DryRunPath, applicationController.DryRunHandler())
 			if err = (&fappv1.FybrikApplication{}).SetupWebhookWithManager(mgr); err != nil {
 				setupLog.Error().Err(err).Str(logging.WEBHOOK, "FybrikApplication").Msg("unable to create webhook")
 				return 1
@@ -223,6 +251,12 @@ func run(namespace, metricsAddr, healthProbeAddr string, enableLeaderElection bo
 		if err = fileMonitor.Subscribe(infrastructureManager); err != nil {
 			setupLog.Error().Err(err).Str(logging.CONTROLLER, "FybrikApplication").Msg("unable to monitor attribute changes")
 		}
+		// re-evaluate the applications once the policies and attributes have been reloaded
+		for _, subscriber := range applicationController.ReevaluationSubscribers() {
+			if err = fileMonitor.Subscribe(subscriber); err != nil {
+				setupLog.Error().Err(err).Str(logging.CONTROLLER, "FybrikApplication").Msg("unable to monitor changes for re-evaluation")
+			}
+		}
 		watcher, err := fsnotify.NewWatcher()
 		if err != nil {
 			setupLog.Err(err).Msg("error creating a file system watcher")
@@ -340,17 +374,32 @@ func newPolicyManager() (pmclient.PolicyManager, error) {
 	setupLog.Info().Str(logging.CONNECTOR, mainPolicyManagerName).Str("URL", mainPolicyManagerURL).
 		Msg("setting main policy manager client")
 
-	return pmclient.NewOpenAPIPolicyManager(
+	policyManager, err := pmclient.NewOpenAPIPolicyManager(
 		mainPolicyManagerName,
 		mainPolicyManagerURL,
 	)
+	if err != nil {
+		return nil, err
+	}
+	cacheTTL, err := environment.GetPolicyDecisionsCacheTTL()
+	if err != nil {
+		return nil, err
+	}
+	if cacheTTL > 0 {
+		setupLog.Info().Str("TTL", cacheTTL.String()).Msg("caching policy decisions")
+		policyManager = pmclient.NewCachingPolicyManager(policyManager, cacheTTL)
+	}
+	return policyManager, nil
 }
 
 // newClusterManager decides based on the environment variables that are set which
 // cluster manager instance should be initiated.
 func newClusterManager(mgr manager.Manager) (multicluster.ClusterManager, error) {
 	multiClusterGroup := os.Getenv("MULTICLUSTER_GROUP")
-	if user, razeeLocal := os.LookupEnv("RAZEE_USER"); razeeLocal {
+	if environment.IsUsingKubeconfigSecrets() {
+		setupLog.Info().Msg("Using kubeconfig secrets in " + environment.GetControllerNamespace())
+		return kubeconfig.NewClusterManager(mgr.GetClient(), mgr.GetAPIReader(), environment.GetControllerNamespace())
+	} else if user, razeeLocal := os.LookupEnv("RAZEE_USER"); razeeLocal {
 		razeeURL := strings.TrimSpace(os.Getenv("RAZEE_URL"))
 		password := strings.TrimSpace(os.Getenv("RAZEE_PASSWORD"))
 
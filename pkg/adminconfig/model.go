@@ -150,6 +150,21 @@ func (restrict Restriction) SatisfiedByResource(attrManager *infrastructure.Attr
 	return true
 }
 
+// String returns a human-readable form of the restriction, e.g. "metadata.region in [theshire]"
+func (restrict Restriction) String() string {
+	if restrict.Range != nil {
+		bounds := []string{}
+		if restrict.Range.Min > 0 {
+			bounds = append(bounds, ">= "+strconv.Itoa(restrict.Range.Min))
+		}
+		if restrict.Range.Max > 0 {
+			bounds = append(bounds, "<= "+strconv.Itoa(restrict.Range.Max))
+		}
+		return restrict.Property + " " + strings.Join(bounds, " and ")
+	}
+	return restrict.Property + " in [" + strings.Join(restrict.Values, ", ") + "]"
+}
+
 func NestedFieldNoCopy(obj map[string]interface{}, fields ...string) (interface{}, bool, error) {
 	var val interface{} = obj
 
@@ -0,0 +1,44 @@
+// Copyright 2023 IBM Corp.
+// SPDX-License-Identifier: Apache-2.0
+
+package adminconfig_test
+
+import (
+	"testing"
+
+	"github.com/onsi/gomega"
+
+	"fybrik.io/fybrik/pkg/adminconfig"
+	"fybrik.io/fybrik/pkg/infrastructure"
+	"fybrik.io/fybrik/pkg/model/taxonomy"
+	"fybrik.io/fybrik/pkg/multicluster"
+)
+
+func TestClusterRestrictions(t *testing.T) {
+	t.Parallel()
+	g := gomega.NewGomegaWithT(t)
+	attributeManager := &infrastructure.AttributeManager{}
+	cluster := multicluster.Cluster{Name: "cluster1", Metadata: multicluster.ClusterMetadata{
+		Region:     "theshire",
+		Labels:     map[string]string{"compliance": "pci"},
+		Attributes: map[string]string{"gpus": "4"},
+	}}
+
+	// labels and attributes of FybrikClusters can be restricted like the other cluster properties
+	satisfied := []adminconfig.Restriction{
+		{Property: "metadata.region", Values: adminconfig.StringList{"theshire"}},
+		{Property: "metadata.labels.compliance", Values: adminconfig.StringList{"pci", "hipaa"}},
+		{Property: "metadata.attributes.gpus", Range: &taxonomy.RangeType{Min: 2}},
+	}
+	for _, restrict := range satisfied {
+		g.Expect(restrict.SatisfiedByResource(attributeManager, &cluster, cluster.Name)).To(gomega.BeTrue(), restrict.String())
+	}
+	violated := []adminconfig.Restriction{
+		{Property: "metadata.labels.compliance", Values: adminconfig.StringList{"hipaa"}},
+		{Property: "metadata.attributes.gpus", Range: &taxonomy.RangeType{Min: 8}},
+		{Property: "metadata.labels.tier", Values: adminconfig.StringList{"gold"}},
+	}
+	for _, restrict := range violated {
+		g.Expect(restrict.SatisfiedByResource(attributeManager, &cluster, cluster.Name)).To(gomega.BeFalse(), restrict.String())
+	}
+}
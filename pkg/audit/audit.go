@@ -0,0 +1,206 @@
+// Copyright 2023 IBM Corp.
+// SPDX-License-Identifier: Apache-2.0
+
+package audit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"fybrik.io/fybrik/pkg/adminconfig"
+	"fybrik.io/fybrik/pkg/model/policymanager"
+	"fybrik.io/fybrik/pkg/model/taxonomy"
+)
+
+// Version of the audit record schema. It is increased upon incompatible changes of the records.
+const Version = "v1"
+
+// RecordType is the type of the audited event
+type RecordType string
+
+// Audited events
+const (
+	// GovernanceDecisionRecord is a decision of the policy manager whether and how a dataset may be used
+	GovernanceDecisionRecord RecordType = "GovernanceDecision"
+	// ConfigurationDecisionRecord is a decision of the configuration policies (adminconfig) about the deployment of modules
+	ConfigurationDecisionRecord RecordType = "ConfigurationDecision"
+	// DataPathRecord is the data path selected for a dataset
+	DataPathRecord RecordType = "DataPathSelection"
+	// StorageAllocationRecord is the allocation of storage for a copy or a new dataset
+	StorageAllocationRecord RecordType = "StorageAllocation"
+	// StorageDeletionRecord is the deletion of storage allocated by Fybrik
+	StorageDeletionRecord RecordType = "StorageDeletion"
+)
+
+// Application identifies the FybrikApplication that caused the audited event
+type Application struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	UUID      string `json:"uuid,omitempty"`
+}
+
+// GovernanceDecision is a policy manager decision
+type GovernanceDecision struct {
+	// Request sent to the policy manager
+	Request *policymanager.GetPolicyDecisionsRequest `json:"request"`
+	// DecisionID returned by the policy manager
+	DecisionID string `json:"decisionID,omitempty"`
+	// Allowed is false if the operation has been denied
+	Allowed bool `json:"allowed"`
+	// Actions to be performed on the data
+	Actions []taxonomy.Action `json:"actions,omitempty"`
+	// Policies the decision is based on
+	Policies []string `json:"policies,omitempty"`
+	// Message returned by the policy manager
+	Message string `json:"message,omitempty"`
+	// Error that prevented the operation, e.g. a denial or an invalid response
+	Error string `json:"error,omitempty"`
+}
+
+// ConfigurationDecision is the outcome of evaluating the configuration policies
+type ConfigurationDecision struct {
+	// Valid is false if the decisions of the policies conflict
+	Valid bool `json:"valid"`
+	// PolicySetID used in the evaluation
+	PolicySetID string `json:"policySetID,omitempty"`
+	// Decisions per capability
+	Decisions adminconfig.DecisionPerCapabilityMap `json:"decisions,omitempty"`
+	// Policies that affected the decisions
+	Policies []adminconfig.DecisionPolicy `json:"policies,omitempty"`
+}
+
+// DataPathStep is a module deployed in the data path
+type DataPathStep struct {
+	Module         string              `json:"module"`
+	Capability     taxonomy.Capability `json:"capability"`
+	Cluster        string              `json:"cluster,omitempty"`
+	Source         *taxonomy.Interface `json:"source,omitempty"`
+	Sink           *taxonomy.Interface `json:"sink,omitempty"`
+	StorageAccount string              `json:"storageAccount,omitempty"`
+	Actions        []taxonomy.Action   `json:"actions,omitempty"`
+}
+
+// DataPath is the data path selected for a dataset
+type DataPath struct {
+	// Steps of the data path, from the data source to the workload
+	Steps []DataPathStep `json:"steps"`
+	// Policies the governance actions are based on
+	Policies []string `json:"policies,omitempty"`
+	// ConfigurationPolicies are the IDs of the configuration policies that restricted or optimized the data path
+	ConfigurationPolicies []string `json:"configurationPolicies,omitempty"`
+}
+
+// Storage is storage allocated or deleted by Fybrik
+type Storage struct {
+	// Account is the ID of the storage account
+	Account    string                      `json:"account,omitempty"`
+	Type       taxonomy.ConnectionType     `json:"type,omitempty"`
+	Geography  taxonomy.ProcessingLocation `json:"geography,omitempty"`
+	Connection *taxonomy.Connection        `json:"connection,omitempty"`
+	Persistent bool                        `json:"persistent,omitempty"`
+	// Orphaned storage is not used by any application, it is deleted by the storage garbage collection
+	Orphaned bool `json:"orphaned,omitempty"`
+	// Error that prevented the operation
+	Error string `json:"error,omitempty"`
+}
+
+// Record is an audit record.
+// Records are chained: each record holds the hash of the previous one, so that a removed or modified record is detected.
+type Record struct {
+	Version string `json:"version"`
+	// Sequence number of the record
+	Sequence uint64     `json:"sequence"`
+	Time     time.Time  `json:"time"`
+	Type     RecordType `json:"type"`
+	// Application that caused the event
+	Application Application `json:"application"`
+	// DatasetID of the dataset the event refers to
+	DatasetID string `json:"datasetID,omitempty"`
+	// Details of the event, according to its type
+	GovernanceDecision    *GovernanceDecision    `json:"governanceDecision,omitempty"`
+	ConfigurationDecision *ConfigurationDecision `json:"configurationDecision,omitempty"`
+	DataPath              *DataPath              `json:"dataPath,omitempty"`
+	Storage               *Storage               `json:"storage,omitempty"`
+	// PreviousHash is the hash of the previous record, empty for the first record
+	PreviousHash string `json:"previousHash"`
+	// Hash of the record, computed with an empty hash
+	Hash string `json:"hash"`
+}
+
+// ComputeHash returns the SHA-256 hash of the record, excluding its hash field
+func (r *Record) ComputeHash() (string, error) {
+	record := *r
+	record.Hash = ""
+	content, err := json.Marshal(&record)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Failed returns true if the record reports a denial or a failure
+func (r *Record) Failed() bool {
+	switch {
+	case r.GovernanceDecision != nil:
+		return !r.GovernanceDecision.Allowed
+	case r.ConfigurationDecision != nil:
+		return !r.ConfigurationDecision.Valid
+	case r.Storage != nil:
+		return r.Storage.Error != ""
+	}
+	return false
+}
+
+// Summary returns a short human readable description of the record
+func (r *Record) Summary() string {
+	outcome := "succeeded"
+	if r.Failed() {
+		outcome = "failed"
+	}
+	switch {
+	case r.GovernanceDecision != nil:
+		if r.GovernanceDecision.Allowed {
+			outcome = "allowed"
+		} else {
+			outcome = "denied"
+		}
+		var operation taxonomy.DataFlow
+		if r.GovernanceDecision.Request != nil {
+			operation = r.GovernanceDecision.Request.Action.ActionType
+		}
+		return fmt.Sprintf("%s of dataset %s %s by policies %v with %d actions, decision ID %s",
+			operation, r.DatasetID, outcome, r.GovernanceDecision.Policies,
+			len(r.GovernanceDecision.Actions), r.GovernanceDecision.DecisionID)
+	case r.DataPath != nil:
+		return fmt.Sprintf("data path of %d modules selected for dataset %s", len(r.DataPath.Steps), r.DatasetID)
+	case r.Storage != nil:
+		return fmt.Sprintf("%s of %s storage in account %s for dataset %s %s",
+			r.Type, r.Storage.Type, r.Storage.Account, r.DatasetID, outcome)
+	}
+	return fmt.Sprintf("%s for dataset %s %s", r.Type, r.DatasetID, outcome)
+}
+
+// Verify checks that the records form an unbroken chain, i.e. that no record has been modified, removed or reordered.
+// The first record may continue an earlier chain.
+func Verify(records []Record) error {
+	for i := range records {
+		hash, err := records[i].ComputeHash()
+		if err != nil {
+			return err
+		}
+		if hash != records[i].Hash {
+			return fmt.Errorf("audit record %d has been modified", records[i].Sequence)
+		}
+		if i == 0 {
+			continue
+		}
+		if records[i].PreviousHash != records[i-1].Hash || records[i].Sequence != records[i-1].Sequence+1 {
+			return fmt.Errorf("audit records are missing before record %d", records[i].Sequence)
+		}
+	}
+	return nil
+}
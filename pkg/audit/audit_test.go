@@ -0,0 +1,144 @@
+// Copyright 2023 IBM Corp.
+// SPDX-License-Identifier: Apache-2.0
+
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/onsi/gomega"
+
+	"fybrik.io/fybrik/pkg/logging"
+)
+
+func readRecords(g *gomega.WithT, path string) []Record {
+	file, err := os.Open(path)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	defer file.Close()
+	records := []Record{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		record := Record{}
+		g.Expect(json.Unmarshal(scanner.Bytes(), &record)).To(gomega.Succeed())
+		records = append(records, record)
+	}
+	g.Expect(scanner.Err()).NotTo(gomega.HaveOccurred())
+	return records
+}
+
+func newRecord(datasetID string) *Record {
+	return &Record{
+		Type:        StorageDeletionRecord,
+		Application: Application{Namespace: "default", Name: "notebook"},
+		DatasetID:   datasetID,
+		Storage:     &Storage{Account: "theshire-object-store"},
+	}
+}
+
+func TestRecordChain(t *testing.T) {
+	t.Parallel()
+	g := gomega.NewGomegaWithT(t)
+	log := logging.LogInit(logging.SETUP, "audit-test")
+	path := filepath.Join(t.TempDir(), "audit.log")
+	sink, err := NewFileSink(path, 0, 0)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	auditor := NewAuditor(&log, sink)
+	for _, datasetID := range []string{"s3/a", "s3/b", "s3/c"} {
+		auditor.Record(newRecord(datasetID))
+	}
+	auditor.Flush()
+	g.Expect(sink.Close()).To(gomega.Succeed())
+
+	records := readRecords(g, path)
+	g.Expect(records).To(gomega.HaveLen(3))
+	g.Expect(records[0].Sequence).To(gomega.Equal(uint64(1)))
+	g.Expect(records[0].PreviousHash).To(gomega.BeEmpty())
+	g.Expect(Verify(records)).To(gomega.Succeed())
+
+	// a restarted auditor continues the chain
+	sink, err = NewFileSink(path, 0, 0)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	auditor = NewAuditor(&log, sink)
+	auditor.Record(newRecord("s3/d"))
+	auditor.Flush()
+	g.Expect(sink.Close()).To(gomega.Succeed())
+	records = readRecords(g, path)
+	g.Expect(records).To(gomega.HaveLen(4))
+	g.Expect(Verify(records)).To(gomega.Succeed())
+
+	// modified and removed records are detected
+	modified := append([]Record{}, records...)
+	modified[1].DatasetID = "s3/other"
+	g.Expect(Verify(modified)).NotTo(gomega.Succeed())
+	removed := append(append([]Record{}, records[:1]...), records[2:]...)
+	g.Expect(Verify(removed)).NotTo(gomega.Succeed())
+}
+
+func TestFileRotation(t *testing.T) {
+	t.Parallel()
+	g := gomega.NewGomegaWithT(t)
+	log := logging.LogInit(logging.SETUP, "audit-test")
+	path := filepath.Join(t.TempDir(), "audit.log")
+	// each file holds a single record
+	sink, err := NewFileSink(path, 1, 2)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	auditor := NewAuditor(&log, sink)
+	for _, datasetID := range []string{"s3/a", "s3/b", "s3/c", "s3/d"} {
+		auditor.Record(newRecord(datasetID))
+	}
+	auditor.Flush()
+	g.Expect(sink.Close()).To(gomega.Succeed())
+
+	g.Expect(readRecords(g, path)[0].DatasetID).To(gomega.Equal("s3/d"))
+	g.Expect(readRecords(g, path+".1")[0].DatasetID).To(gomega.Equal("s3/c"))
+	g.Expect(readRecords(g, path+".2")[0].DatasetID).To(gomega.Equal("s3/b"))
+	_, err = os.Stat(path + ".3")
+	g.Expect(os.IsNotExist(err)).To(gomega.BeTrue())
+
+	// the rotated files form a chain
+	records := []Record{}
+	for _, file := range []string{path + ".2", path + ".1", path} {
+		records = append(records, readRecords(g, file)...)
+	}
+	g.Expect(Verify(records)).To(gomega.Succeed())
+}
+
+// blockingSink keeps the records it writes once it is released
+type blockingSink struct {
+	release chan struct{}
+	records []Record
+}
+
+func (s *blockingSink) Write(record *Record) error {
+	<-s.release
+	s.records = append(s.records, *record)
+	return nil
+}
+
+func TestSlowSink(t *testing.T) {
+	t.Parallel()
+	g := gomega.NewGomegaWithT(t)
+	log := logging.LogInit(logging.SETUP, "audit-test")
+	sink := &blockingSink{release: make(chan struct{})}
+	auditor := NewAuditor(&log, sink)
+	// records are chained and queued without waiting for the sink
+	recorded := make(chan struct{})
+	go func() {
+		for _, datasetID := range []string{"s3/a", "s3/b", "s3/c"} {
+			auditor.Record(newRecord(datasetID))
+		}
+		close(recorded)
+	}()
+	g.Eventually(recorded, time.Second).Should(gomega.BeClosed())
+
+	close(sink.release)
+	auditor.Flush()
+	g.Expect(sink.records).To(gomega.HaveLen(3))
+	g.Expect(sink.records[2].DatasetID).To(gomega.Equal("s3/c"))
+	g.Expect(Verify(sink.records)).To(gomega.Succeed())
+}
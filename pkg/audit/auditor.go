@@ -0,0 +1,117 @@
+// Copyright 2023 IBM Corp.
+// SPDX-License-Identifier: Apache-2.0
+
+package audit
+
+import (
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"fybrik.io/fybrik/pkg/logging"
+)
+
+// Sink stores or forwards audit records
+type Sink interface {
+	Write(record *Record) error
+}
+
+// chainedSink is a sink that keeps the records it has written, so that the chain of records continues after a restart
+type chainedSink interface {
+	Sink
+	LastRecord() (*Record, error)
+}
+
+// queueSize is the number of records that can wait to be written to the sinks.
+// Recording a record while the queue is full waits until there is room in the queue.
+const queueSize = 1000
+
+// Auditor completes the audit records with their version, sequence number, time and hashes,
+// and writes them to the sinks in the background, in the order of their sequence numbers.
+// A nil auditor ignores the records.
+type Auditor struct {
+	sinks    []Sink
+	log      *zerolog.Logger
+	mutex    sync.Mutex
+	sequence uint64
+	lastHash string
+	queue    chan *Record
+	pending  sync.WaitGroup
+}
+
+// NewAuditor returns an auditor that writes the records to the given sinks.
+// The chain of records continues the last record written by the sinks, if any.
+func NewAuditor(log *zerolog.Logger, sinks ...Sink) *Auditor {
+	auditor := &Auditor{sinks: sinks, log: log, queue: make(chan *Record, queueSize)}
+	for _, sink := range sinks {
+		chained, ok := sink.(chainedSink)
+		if !ok {
+			continue
+		}
+		last, err := chained.LastRecord()
+		if err != nil {
+			log.Warn().Err(err).Msg("Could not read the last audit record, a new chain of records is started")
+			continue
+		}
+		if last != nil {
+			auditor.sequence, auditor.lastHash = last.Sequence, last.Hash
+			break
+		}
+	}
+	go auditor.run()
+	return auditor
+}
+
+// Record completes the record and queues it to be written to all the sinks.
+// Records are not dropped: if the sinks fall behind and the queue is full, Record waits for room in the queue.
+func (a *Auditor) Record(record *Record) {
+	if a == nil {
+		return
+	}
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	record.Version = Version
+	record.Time = time.Now().UTC()
+	record.Sequence = a.sequence + 1
+	record.PreviousHash = a.lastHash
+	hash, err := record.ComputeHash()
+	if err != nil {
+		a.log.Error().Err(err).Str(logging.DATASETID, record.DatasetID).Msg("Could not compute the hash of the audit record")
+		return
+	}
+	record.Hash = hash
+	a.sequence, a.lastHash = record.Sequence, record.Hash
+	// the record is queued while holding the lock, so that the records are written in the order of the chain
+	queued := *record
+	a.pending.Add(1)
+	select {
+	case a.queue <- &queued:
+	default:
+		a.log.Warn().Str(logging.DATASETID, record.DatasetID).
+			Msg("The audit sinks fall behind, waiting to queue the audit record " + record.Summary())
+		a.queue <- &queued
+	}
+}
+
+// Flush waits until the queued records are written to the sinks
+func (a *Auditor) Flush() {
+	if a == nil {
+		return
+	}
+	a.pending.Wait()
+}
+
+// run writes the queued records to the sinks.
+// Failures to write a record are logged, and the record is still written to the other sinks.
+func (a *Auditor) run() {
+	for record := range a.queue {
+		for _, sink := range a.sinks {
+			if err := sink.Write(record); err != nil {
+				a.log.Error().Err(err).Str(logging.DATASETID, record.DatasetID).Bool(logging.AUDIT, true).
+					Msg("Could not write the audit record " + record.Summary())
+			}
+		}
+		a.pending.Done()
+	}
+}
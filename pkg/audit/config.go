@@ -0,0 +1,35 @@
+// Copyright 2023 IBM Corp.
+// SPDX-License-Identifier: Apache-2.0
+
+package audit
+
+import (
+	"github.com/rs/zerolog"
+	"k8s.io/client-go/tools/record"
+
+	"fybrik.io/fybrik/pkg/environment"
+)
+
+// NewAuditorFromEnvironment returns an auditor that writes the records to the sinks configured in the environment:
+// a rotated file, an HTTP webhook and Kubernetes events reported by the given recorder.
+// It returns nil if no sink is configured.
+func NewAuditorFromEnvironment(recorder record.EventRecorder, log *zerolog.Logger) (*Auditor, error) {
+	sinks := []Sink{}
+	if path := environment.GetAuditFile(); path != "" {
+		fileSink, err := NewFileSink(path, environment.GetAuditFileMaxSize(), environment.GetAuditFileMaxBackups())
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, fileSink)
+	}
+	if url := environment.GetAuditWebhookURL(); url != "" {
+		sinks = append(sinks, NewWebhookSink(url))
+	}
+	if environment.AuditEventsEnabled() && recorder != nil {
+		sinks = append(sinks, NewEventSink(recorder))
+	}
+	if len(sinks) == 0 {
+		return nil, nil
+	}
+	return NewAuditor(log, sinks...), nil
+}
@@ -0,0 +1,46 @@
+// Copyright 2023 IBM Corp.
+// SPDX-License-Identifier: Apache-2.0
+
+package audit
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+const (
+	applicationAPIVersion = "app.fybrik.io/v1beta1"
+	applicationKind       = "FybrikApplication"
+)
+
+// EventSink reports the records as Kubernetes events of the FybrikApplications.
+// Denials and failures are reported as warnings.
+// Events are rate limited and expire, thus they complement a durable sink rather than replace it.
+type EventSink struct {
+	recorder record.EventRecorder
+}
+
+// NewEventSink returns a sink that reports the records using the given recorder
+func NewEventSink(recorder record.EventRecorder) *EventSink {
+	return &EventSink{recorder: recorder}
+}
+
+// Write reports the record as an event of its application
+func (s *EventSink) Write(auditRecord *Record) error {
+	if auditRecord.Application.Name == "" {
+		return nil
+	}
+	object := &corev1.ObjectReference{
+		APIVersion: applicationAPIVersion,
+		Kind:       applicationKind,
+		Namespace:  auditRecord.Application.Namespace,
+		Name:       auditRecord.Application.Name,
+	}
+	eventType := corev1.EventTypeNormal
+	if auditRecord.Failed() {
+		eventType = corev1.EventTypeWarning
+	}
+	s.recorder.AnnotatedEventf(object, map[string]string{"fybrik.io/audit-hash": auditRecord.Hash},
+		eventType, string(auditRecord.Type), "%s", auditRecord.Summary())
+	return nil
+}
@@ -0,0 +1,149 @@
+// Copyright 2023 IBM Corp.
+// SPDX-License-Identifier: Apache-2.0
+
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+)
+
+const (
+	fileMode = 0o600
+	// maxRecordSize is the maximal size of a record that is read from the file
+	maxRecordSize = 1 << 20
+)
+
+// FileSink writes the records to a file as JSON lines.
+// The file is rotated once it exceeds its maximal size: the file is renamed with the suffix .1,
+// older files are shifted to the next suffix and files beyond the maximal number of backups are removed.
+type FileSink struct {
+	path       string
+	maxSize    int64
+	maxBackups int
+	mutex      sync.Mutex
+	file       *os.File
+	size       int64
+}
+
+// NewFileSink returns a sink that writes to the given file, appending to it if it exists.
+// A maxSize of 0 disables the rotation.
+func NewFileSink(path string, maxSize int64, maxBackups int) (*FileSink, error) {
+	sink := &FileSink{path: path, maxSize: maxSize, maxBackups: maxBackups}
+	if err := sink.open(); err != nil {
+		return nil, err
+	}
+	return sink, nil
+}
+
+func (s *FileSink) open() error {
+	file, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, fileMode)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	s.file, s.size = file, info.Size()
+	return nil
+}
+
+// Write appends the record to the file, and syncs the file so that the record is not lost
+func (s *FileSink) Write(record *Record) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.maxSize > 0 && s.size > 0 && s.size+int64(len(line)) > s.maxSize {
+		if err = s.rotate(); err != nil {
+			return err
+		}
+	}
+	written, err := s.file.Write(line)
+	s.size += int64(written)
+	if err != nil {
+		return err
+	}
+	return s.file.Sync()
+}
+
+func (s *FileSink) rotate() error {
+	if err := s.file.Close(); err != nil {
+		return err
+	}
+	if s.maxBackups > 0 {
+		for i := s.maxBackups - 1; i > 0; i-- {
+			if err := os.Rename(s.backup(i), s.backup(i+1)); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+		if err := os.Rename(s.path, s.backup(1)); err != nil {
+			return err
+		}
+	} else if err := os.Remove(s.path); err != nil {
+		return err
+	}
+	return s.open()
+}
+
+func (s *FileSink) backup(index int) string {
+	return fmt.Sprintf("%s.%d", s.path, index)
+}
+
+// LastRecord returns the last record in the file, or in its latest backup if the file is empty.
+// It returns nil if no record has been written.
+func (s *FileSink) LastRecord() (*Record, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, path := range []string{s.path, s.backup(1)} {
+		record, err := lastRecord(path)
+		if err != nil || record != nil {
+			return record, err
+		}
+	}
+	return nil, nil
+}
+
+func lastRecord(path string) (*Record, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	var last []byte
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, maxRecordSize)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) > 0 {
+			last = append(last[:0], scanner.Bytes()...)
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		return nil, err
+	}
+	if last == nil {
+		return nil, nil
+	}
+	record := &Record{}
+	if err = json.Unmarshal(last, record); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// Close closes the file
+func (s *FileSink) Close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.file.Close()
+}
@@ -15,6 +15,7 @@ type DataCatalog interface {
 	CreateAsset(in *datacatalog.CreateAssetRequest, creds string) (*datacatalog.CreateAssetResponse, error)
 	DeleteAsset(in *datacatalog.DeleteAssetRequest, creds string) (*datacatalog.DeleteAssetResponse, error)
 	UpdateAsset(in *datacatalog.UpdateAssetRequest, creds string) (*datacatalog.UpdateAssetResponse, error)
+	ListAssets(in *datacatalog.ListAssetsRequest, creds string) (*datacatalog.ListAssetsResponse, error)
 	io.Closer
 }
 
@@ -144,6 +144,24 @@ func (m *openAPIDataCatalog) UpdateAsset(in *datacatalog.UpdateAssetRequest, cre
 	return &resp, nil
 }
 
+//nolint:dupl
+func (m *openAPIDataCatalog) ListAssets(in *datacatalog.ListAssetsRequest, creds string) (*datacatalog.ListAssetsResponse, error) {
+	printErr := func() string { return fmt.Sprintf("list assets from %s failed", m.name) }
+	resp, httpResponse, err :=
+		m.client.DefaultApi.ListAssets(context.Background()).XRequestDatacatalogCred(creds).ListAssetsRequest(*in).Execute()
+	if httpResponse == nil {
+		if err != nil {
+			return nil, errors.Wrap(err, printErr())
+		}
+		return nil, errors.New(printErr())
+	}
+	defer httpResponse.Body.Close()
+	if err != nil {
+		return nil, getDetailedError(httpResponse, errors.Wrap(err, printErr()))
+	}
+	return &resp, nil
+}
+
 func (m *openAPIDataCatalog) Close() error {
 	return nil
 }
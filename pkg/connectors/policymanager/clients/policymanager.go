@@ -12,5 +12,26 @@ import (
 // PolicyManager is an interface of a facade to connect to a policy manager.
 type PolicyManager interface {
 	GetPoliciesDecisions(in *policymanager.GetPolicyDecisionsRequest, creds string) (*policymanager.GetPolicyDecisionsResponse, error)
+	// GetPoliciesDecisionsBatch evaluates several requests in a single call,
+	// the responses are returned in the order of the requests
+	GetPoliciesDecisionsBatch(in *policymanager.GetPolicyDecisionsBatchRequest,
+		creds string) (*policymanager.GetPolicyDecisionsBatchResponse, error)
 	io.Closer
 }
+
+// GetPoliciesDecisionsOneByOne evaluates the requests of a batch by calling the policy manager for each of them.
+// It serves batch requests for policy managers that cannot evaluate several requests at once.
+func GetPoliciesDecisionsOneByOne(m PolicyManager, in *policymanager.GetPolicyDecisionsBatchRequest,
+	creds string) (*policymanager.GetPolicyDecisionsBatchResponse, error) {
+	response := &policymanager.GetPolicyDecisionsBatchResponse{
+		Responses: make([]policymanager.GetPolicyDecisionsResponse, 0, len(in.Requests)),
+	}
+	for i := range in.Requests {
+		resp, err := m.GetPoliciesDecisions(&in.Requests[i], creds)
+		if err != nil {
+			return nil, err
+		}
+		response.Responses = append(response.Responses, *resp)
+	}
+	return response, nil
+}
@@ -0,0 +1,172 @@
+// Copyright 2023 IBM Corp.
+// SPDX-License-Identifier: Apache-2.0
+
+package clients
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"fybrik.io/fybrik/pkg/model/policymanager"
+	"fybrik.io/fybrik/pkg/model/taxonomy"
+)
+
+// maxCachedDecisions bounds the number of cached decisions. Expired decisions are evicted when the bound is reached,
+// and the cache is cleared if all of them are still valid.
+const maxCachedDecisions = 10000
+
+var _ PolicyManager = (*CachingPolicyManager)(nil)
+
+// CachingPolicyManager is a PolicyManager facade that caches the decisions of another PolicyManager.
+// Decisions are keyed by the requested asset, its metadata, the application properties, the operation and its locations,
+// and are kept until their time to live expires or they are invalidated.
+// Errors are not cached.
+type CachingPolicyManager struct {
+	policyManager PolicyManager
+	ttl           time.Duration
+	mutex         sync.Mutex
+	entries       map[string]*cachedDecision
+}
+
+type cachedDecision struct {
+	assetID  taxonomy.AssetID
+	response *policymanager.GetPolicyDecisionsResponse
+	expiry   time.Time
+}
+
+// NewCachingPolicyManager creates a PolicyManager facade that caches the decisions of the given policy manager
+// for the given time to live
+func NewCachingPolicyManager(policyManager PolicyManager, ttl time.Duration) *CachingPolicyManager {
+	return &CachingPolicyManager{
+		policyManager: policyManager,
+		ttl:           ttl,
+		entries:       map[string]*cachedDecision{},
+	}
+}
+
+func (m *CachingPolicyManager) GetPoliciesDecisions(in *policymanager.GetPolicyDecisionsRequest,
+	creds string) (*policymanager.GetPolicyDecisionsResponse, error) {
+	key, err := decisionKey(in, creds)
+	if err != nil {
+		return nil, err
+	}
+	if response := m.lookup(key); response != nil {
+		return response, nil
+	}
+	response, err := m.policyManager.GetPoliciesDecisions(in, creds)
+	if err != nil {
+		return nil, err
+	}
+	m.store(key, in.Resource.ID, response)
+	return response.DeepCopy(), nil
+}
+
+// GetPoliciesDecisionsBatch returns the cached decisions of the batch requests,
+// and sends the other requests to the policy manager in a single batch
+func (m *CachingPolicyManager) GetPoliciesDecisionsBatch(in *policymanager.GetPolicyDecisionsBatchRequest,
+	creds string) (*policymanager.GetPolicyDecisionsBatchResponse, error) {
+	response := &policymanager.GetPolicyDecisionsBatchResponse{
+		Responses: make([]policymanager.GetPolicyDecisionsResponse, len(in.Requests)),
+	}
+	keys := make([]string, len(in.Requests))
+	// indices of the requests to be sent to the policy manager
+	missing := []int{}
+	missingRequests := &policymanager.GetPolicyDecisionsBatchRequest{}
+	for i := range in.Requests {
+		key, err := decisionKey(&in.Requests[i], creds)
+		if err != nil {
+			return nil, err
+		}
+		keys[i] = key
+		if cached := m.lookup(key); cached != nil {
+			response.Responses[i] = *cached
+			continue
+		}
+		missing = append(missing, i)
+		missingRequests.Requests = append(missingRequests.Requests, in.Requests[i])
+	}
+	if len(missing) == 0 {
+		return response, nil
+	}
+	missingResponses, err := m.policyManager.GetPoliciesDecisionsBatch(missingRequests, creds)
+	if err != nil {
+		return nil, err
+	}
+	for j, i := range missing {
+		decision := &missingResponses.Responses[j]
+		m.store(keys[i], in.Requests[i].Resource.ID, decision)
+		decision.DeepCopyInto(&response.Responses[i])
+	}
+	return response, nil
+}
+
+// Invalidate drops all the cached decisions, e.g., when governance policies change
+func (m *CachingPolicyManager) Invalidate() {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.entries = map[string]*cachedDecision{}
+}
+
+// InvalidateAsset drops the cached decisions on the given asset, e.g., when its metadata changes
+func (m *CachingPolicyManager) InvalidateAsset(assetID taxonomy.AssetID) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	for key, entry := range m.entries {
+		if entry.assetID == assetID {
+			delete(m.entries, key)
+		}
+	}
+}
+
+func (m *CachingPolicyManager) Close() error {
+	m.Invalidate()
+	return m.policyManager.Close()
+}
+
+// lookup returns a copy of a valid cached decision, or nil if there is none
+func (m *CachingPolicyManager) lookup(key string) *policymanager.GetPolicyDecisionsResponse {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	entry, found := m.entries[key]
+	if !found {
+		return nil
+	}
+	if !time.Now().Before(entry.expiry) {
+		delete(m.entries, key)
+		return nil
+	}
+	return entry.response.DeepCopy()
+}
+
+func (m *CachingPolicyManager) store(key string, assetID taxonomy.AssetID, response *policymanager.GetPolicyDecisionsResponse) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	now := time.Now()
+	if len(m.entries) >= maxCachedDecisions {
+		for k, entry := range m.entries {
+			if !now.Before(entry.expiry) {
+				delete(m.entries, k)
+			}
+		}
+		if len(m.entries) >= maxCachedDecisions {
+			m.entries = map[string]*cachedDecision{}
+		}
+	}
+	m.entries[key] = &cachedDecision{assetID: assetID, response: response.DeepCopy(), expiry: now.Add(m.ttl)}
+}
+
+// decisionKey returns a hash of the request and the credentials used for evaluating it.
+// Maps are serialized with sorted keys, so equal requests have equal keys.
+func decisionKey(in *policymanager.GetPolicyDecisionsRequest, creds string) (string, error) {
+	requestBytes, err := json.Marshal(in)
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.New()
+	hash.Write(requestBytes)
+	hash.Write([]byte(creds))
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
@@ -0,0 +1,176 @@
+// Copyright 2023 IBM Corp.
+// SPDX-License-Identifier: Apache-2.0
+
+package clients_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"emperror.dev/errors"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"fybrik.io/fybrik/pkg/connectors/policymanager/clients"
+	"fybrik.io/fybrik/pkg/model/policymanager"
+	"fybrik.io/fybrik/pkg/model/taxonomy"
+)
+
+// countingPolicyManager redacts data written to neverland, and counts the requests it receives
+type countingPolicyManager struct {
+	requests int
+	batches  int
+	fail     bool
+}
+
+func (m *countingPolicyManager) GetPoliciesDecisions(in *policymanager.GetPolicyDecisionsRequest,
+	creds string) (*policymanager.GetPolicyDecisionsResponse, error) {
+	m.requests++
+	if m.fail {
+		return nil, errors.New("policy manager is unavailable")
+	}
+	response := &policymanager.GetPolicyDecisionsResponse{Result: []policymanager.ResultItem{}}
+	if in.Action.Destination == "neverland" {
+		response.Result = append(response.Result, policymanager.ResultItem{
+			Policy: "redact data written to neverland",
+			Action: taxonomy.Action{Name: "RedactAction"},
+		})
+	}
+	return response, nil
+}
+
+func (m *countingPolicyManager) GetPoliciesDecisionsBatch(in *policymanager.GetPolicyDecisionsBatchRequest,
+	creds string) (*policymanager.GetPolicyDecisionsBatchResponse, error) {
+	m.batches++
+	return clients.GetPoliciesDecisionsOneByOne(m, in, creds)
+}
+
+func (m *countingPolicyManager) Close() error {
+	return nil
+}
+
+func decisionRequest(assetID, destination string) *policymanager.GetPolicyDecisionsRequest {
+	return &policymanager.GetPolicyDecisionsRequest{
+		Action:   policymanager.RequestAction{ActionType: taxonomy.WriteFlow, Destination: destination},
+		Resource: policymanager.Resource{ID: taxonomy.AssetID(assetID)},
+	}
+}
+
+var _ = Describe("CachingPolicyManager", func() {
+	var backend *countingPolicyManager
+	var cache *clients.CachingPolicyManager
+
+	BeforeEach(func() {
+		backend = &countingPolicyManager{}
+		cache = clients.NewCachingPolicyManager(backend, time.Hour)
+	})
+
+	It("returns cached decisions of identical requests", func() {
+		response, err := cache.GetPoliciesDecisions(decisionRequest("s3/asset", "neverland"), "")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response.Result).To(HaveLen(1))
+		// modifying a returned decision does not affect the cache
+		response.Result = nil
+
+		response, err = cache.GetPoliciesDecisions(decisionRequest("s3/asset", "neverland"), "")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response.Result).To(HaveLen(1))
+		Expect(backend.requests).To(Equal(1))
+
+		_, err = cache.GetPoliciesDecisions(decisionRequest("s3/asset", "theshire"), "")
+		Expect(err).ToNot(HaveOccurred())
+		_, err = cache.GetPoliciesDecisions(decisionRequest("s3/asset", "neverland"), "other-credentials")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(backend.requests).To(Equal(3))
+	})
+
+	It("does not cache errors", func() {
+		backend.fail = true
+		_, err := cache.GetPoliciesDecisions(decisionRequest("s3/asset", "neverland"), "")
+		Expect(err).To(HaveOccurred())
+		backend.fail = false
+		_, err = cache.GetPoliciesDecisions(decisionRequest("s3/asset", "neverland"), "")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(backend.requests).To(Equal(2))
+	})
+
+	It("drops expired and invalidated decisions", func() {
+		expiring := clients.NewCachingPolicyManager(backend, time.Nanosecond)
+		for i := 0; i < 2; i++ {
+			_, err := expiring.GetPoliciesDecisions(decisionRequest("s3/asset", "neverland"), "")
+			Expect(err).ToNot(HaveOccurred())
+		}
+		Expect(backend.requests).To(Equal(2))
+
+		backend.requests = 0
+		for _, assetID := range []string{"s3/asset", "s3/other"} {
+			_, err := cache.GetPoliciesDecisions(decisionRequest(assetID, "neverland"), "")
+			Expect(err).ToNot(HaveOccurred())
+		}
+		cache.InvalidateAsset("s3/asset")
+		for _, assetID := range []string{"s3/asset", "s3/other"} {
+			_, err := cache.GetPoliciesDecisions(decisionRequest(assetID, "neverland"), "")
+			Expect(err).ToNot(HaveOccurred())
+		}
+		Expect(backend.requests).To(Equal(3))
+		cache.Invalidate()
+		_, err := cache.GetPoliciesDecisions(decisionRequest("s3/other", "neverland"), "")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(backend.requests).To(Equal(4))
+	})
+
+	It("sends the uncached requests of a batch in a single batch", func() {
+		_, err := cache.GetPoliciesDecisions(decisionRequest("s3/asset", "neverland"), "")
+		Expect(err).ToNot(HaveOccurred())
+		batch := &policymanager.GetPolicyDecisionsBatchRequest{Requests: []policymanager.GetPolicyDecisionsRequest{
+			*decisionRequest("s3/asset", "theshire"),
+			*decisionRequest("s3/asset", "neverland"),
+			*decisionRequest("s3/other", "neverland"),
+		}}
+		response, err := cache.GetPoliciesDecisionsBatch(batch, "")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response.Responses).To(HaveLen(3))
+		Expect(response.Responses[0].Result).To(BeEmpty())
+		Expect(response.Responses[1].Result).To(HaveLen(1))
+		Expect(response.Responses[2].Result).To(HaveLen(1))
+		Expect(backend.batches).To(Equal(1))
+		Expect(backend.requests).To(Equal(3))
+
+		// all the decisions are cached now
+		_, err = cache.GetPoliciesDecisionsBatch(batch, "")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(backend.batches).To(Equal(1))
+	})
+})
+
+var _ = Describe("OpenAPI policy manager", func() {
+	It("falls back to single requests if the connector does not serve batches", func() {
+		singleRequests := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/getPoliciesDecisions" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			singleRequests++
+			w.Header().Add("Content-Type", "application/json")
+			Expect(json.NewEncoder(w).Encode(&policymanager.GetPolicyDecisionsResponse{
+				Result: []policymanager.ResultItem{{Policy: "allow", Action: taxonomy.Action{Name: "Allow"}}},
+			})).To(Succeed())
+		}))
+		defer server.Close()
+
+		policyManager, err := clients.NewOpenAPIPolicyManager("opa", server.URL)
+		Expect(err).ToNot(HaveOccurred())
+		batch := &policymanager.GetPolicyDecisionsBatchRequest{Requests: []policymanager.GetPolicyDecisionsRequest{
+			*decisionRequest("s3/asset", "theshire"),
+			*decisionRequest("s3/asset", "neverland"),
+		}}
+		response, err := policyManager.GetPoliciesDecisionsBatch(batch, "")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response.Responses).To(HaveLen(2))
+		Expect(response.Responses[1].Result[0].Policy).To(Equal("allow"))
+		Expect(singleRequests).To(Equal(2))
+	})
+})
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"sync/atomic"
 
 	"emperror.dev/errors"
 
@@ -22,6 +23,8 @@ var _ PolicyManager = (*openAPIPolicyManager)(nil)
 type openAPIPolicyManager struct {
 	name   string
 	client *openapiclient.APIClient
+	// set if the connector does not serve batch requests
+	batchUnsupported atomic.Bool
 }
 
 // NewopenApiPolicyManager creates a PolicyManager facade that connects to a openApi service
@@ -77,6 +80,37 @@ func (m *openAPIPolicyManager) GetPoliciesDecisions(in *policymanager.GetPolicyD
 	return &resp, nil
 }
 
+// GetPoliciesDecisionsBatch sends the batch to the connector. Connectors that do not serve batch requests
+// are called for each request of the batch instead.
+func (m *openAPIPolicyManager) GetPoliciesDecisionsBatch(in *policymanager.GetPolicyDecisionsBatchRequest,
+	creds string) (*policymanager.GetPolicyDecisionsBatchResponse, error) {
+	if m.batchUnsupported.Load() {
+		return GetPoliciesDecisionsOneByOne(m, in, creds)
+	}
+	printErr := func() string { return fmt.Sprintf("get policies decisions batch from %s failed", m.name) }
+	resp, httpResponse, err := m.client.DefaultApi.GetPoliciesDecisionsBatch(context.Background()).XRequestCred(creds).
+		GetPolicyDecisionsBatchRequest(*in).Execute()
+
+	if httpResponse == nil {
+		if err != nil {
+			return nil, errors.Wrap(err, printErr())
+		}
+		return nil, errors.New(printErr())
+	}
+	defer httpResponse.Body.Close()
+	if httpResponse.StatusCode == http.StatusNotFound || httpResponse.StatusCode == http.StatusMethodNotAllowed {
+		m.batchUnsupported.Store(true)
+		return GetPoliciesDecisionsOneByOne(m, in, creds)
+	}
+	if err != nil {
+		return nil, getDetailedError(httpResponse, err, printErr())
+	}
+	if len(resp.Responses) != len(in.Requests) {
+		return nil, errors.Errorf("%s: expected %d responses, received %d", printErr(), len(in.Requests), len(resp.Responses))
+	}
+	return &resp, nil
+}
+
 func (m *openAPIPolicyManager) Close() error {
 	return nil
 }
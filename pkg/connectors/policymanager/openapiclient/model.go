@@ -8,3 +8,5 @@ import "fybrik.io/fybrik/pkg/model/policymanager"
 // TODO(roee88): rename
 type GetPolicyDecisionsRequest = policymanager.GetPolicyDecisionsRequest
 type GetPolicyDecisionsResponse = policymanager.GetPolicyDecisionsResponse
+type GetPolicyDecisionsBatchRequest = policymanager.GetPolicyDecisionsBatchRequest
+type GetPolicyDecisionsBatchResponse = policymanager.GetPolicyDecisionsBatchResponse
@@ -32,6 +32,11 @@ func (m *mockupStorageManager) DeleteStorage(request *storagemanager.DeleteStora
 	return nil
 }
 
+func (m *mockupStorageManager) ListStorage(request *storagemanager.ListStorageRequest) (*storagemanager.ListStorageResponse,
+	error) {
+	return &storagemanager.ListStorageResponse{Storage: []storagemanager.AllocatedStorage{}}, nil
+}
+
 func (m *mockupStorageManager) GetSupportedStorageTypes() (*storagemanager.GetSupportedStorageTypesResponse, error) {
 	return &storagemanager.GetSupportedStorageTypesResponse{ConnectionTypes: []taxonomy.ConnectionType{"mysql", "db2", "s3"}}, nil
 }
@@ -17,6 +17,8 @@ type StorageManagerInterface interface {
 	AllocateStorage(request *storagemanager.AllocateStorageRequest) (*storagemanager.AllocateStorageResponse, error)
 	// DeleteStorage deletes the allocated storage
 	DeleteStorage(request *storagemanager.DeleteStorageRequest) error
+	// ListStorage lists the storage allocated in a storage account
+	ListStorage(request *storagemanager.ListStorageRequest) (*storagemanager.ListStorageResponse, error)
 	// GetSupportedStorageTypes returns a list of supported connection types
 	GetSupportedStorageTypes() (*storagemanager.GetSupportedStorageTypesResponse, error)
 	io.Closer
@@ -90,6 +90,26 @@ func (m *openAPIStorageManager) DeleteStorage(request *storagemanager.DeleteStor
 	return err
 }
 
+// request to list allocated storage
+func (m *openAPIStorageManager) ListStorage(request *storagemanager.ListStorageRequest) (*storagemanager.ListStorageResponse, error) {
+	resp, httpResponse, err :=
+		m.Client.DefaultApi.ListStorage(context.Background()).ListStorageRequest(*request).Execute()
+	if httpResponse == nil {
+		if err != nil {
+			return nil, err
+		}
+		return nil, errors.New(StorageManagerCommunicationError)
+	}
+	defer httpResponse.Body.Close()
+	if httpResponse.StatusCode == http.StatusNotImplemented {
+		return nil, errors.New(StorageTypeNotSupported)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
 // request to get supported connections
 func (m *openAPIStorageManager) GetSupportedStorageTypes() (*storagemanager.GetSupportedStorageTypesResponse, error) {
 	resp, httpResponse, err :=
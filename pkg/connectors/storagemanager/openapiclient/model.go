@@ -8,4 +8,6 @@ import "fybrik.io/fybrik/pkg/model/storagemanager"
 type AllocateStorageRequest = storagemanager.AllocateStorageRequest
 type DeleteStorageRequest = storagemanager.DeleteStorageRequest
 type AllocateStorageResponse = storagemanager.AllocateStorageResponse
+type ListStorageRequest = storagemanager.ListStorageRequest
+type ListStorageResponse = storagemanager.ListStorageResponse
 type GetSupportedStorageTypesResponse = storagemanager.GetSupportedStorageTypesResponse
@@ -0,0 +1,413 @@
+// Copyright 2023 IBM Corp.
+// SPDX-License-Identifier: Apache-2.0
+
+package datapath
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	fappv1 "fybrik.io/fybrik/manager/apis/app/v1beta1"
+	fappv2 "fybrik.io/fybrik/manager/apis/app/v1beta2"
+	"fybrik.io/fybrik/pkg/adminconfig"
+	"fybrik.io/fybrik/pkg/model/taxonomy"
+)
+
+// The capability whose restrictions apply to modules, clusters and storage accounts which perform governance actions
+const transformCapability taxonomy.Capability = "transform"
+
+// A module capability which may be placed on a data path
+type candidate struct {
+	module        *fappv1.FybrikModule
+	capabilityIdx int
+	eliminatedBy  string // why the module capability cannot be used (empty if it can be used)
+}
+
+func (c *candidate) capability() *fappv1.ModuleCapability {
+	return &c.module.Spec.Capabilities[c.capabilityIdx]
+}
+
+func (c *candidate) String() string {
+	return fmt.Sprintf("module %s (capability %s)", c.module.Name, c.capability().Capability)
+}
+
+// diagnoser checks the constraints of a data-path problem one by one
+type diagnoser struct {
+	env        *Environment
+	dataInfo   *DataInfo
+	candidates []*candidate
+}
+
+// Explain checks the constraints of a data-path problem separately, and returns an explanation for each constraint
+// that cannot be satisfied. If each constraint can be satisfied on its own, a ConflictingConstraints explanation is returned.
+func Explain(env *Environment, dataInfo *DataInfo) []Explanation {
+	d := newDiagnoser(env, dataInfo)
+	explanations := d.explainCapabilities()
+	explanations = append(explanations, d.explainActions()...)
+	chainLength, chainExplanations := d.explainInterfaceChain()
+	explanations = append(explanations, chainExplanations...)
+	explanations = append(explanations, d.explainStorage(chainLength)...)
+	if len(explanations) == 0 {
+		explanations = append(explanations, Explanation{Reason: ConflictingConstraints,
+			Message: "each constraint can be satisfied on its own, but no data path satisfies the required actions, " +
+				"interfaces and restrictions together"})
+	}
+	return explanations
+}
+
+func newDiagnoser(env *Environment, dataInfo *DataInfo) *diagnoser {
+	d := diagnoser{env: env, dataInfo: dataInfo}
+	moduleNames := []string{}
+	for name := range env.Modules {
+		moduleNames = append(moduleNames, name)
+	}
+	sort.Strings(moduleNames)
+	for _, name := range moduleNames {
+		module := env.Modules[name]
+		for idx := range module.Spec.Capabilities {
+			c := &candidate{module: module, capabilityIdx: idx}
+			c.eliminatedBy = d.candidateElimination(c, c.capability().Capability)
+			d.candidates = append(d.candidates, c)
+		}
+	}
+	return &d
+}
+
+// Returns why a module capability cannot be used under the decisions for the given capability (empty if it can be used)
+func (d *diagnoser) candidateElimination(c *candidate, capability taxonomy.Capability) string {
+	decision := d.dataInfo.Configuration.ConfigDecisions[capability]
+	if decision.Deploy == adminconfig.StatusFalse {
+		return fmt.Sprintf("capability %s is forbidden%s", capability, policySuffix(decision.Policy))
+	}
+	oldPrefix := "capabilities."
+	newPrefix := oldPrefix + strconv.Itoa(c.capabilityIdx) + "."
+	for _, restriction := range decision.DeploymentRestrictions.Modules {
+		restriction.Property = strings.Replace(restriction.Property, oldPrefix, newPrefix, 1)
+		if !restriction.SatisfiedByResource(d.env.AttributeManager, c.module.Spec, c.module.Name) {
+			return fmt.Sprintf("module %s violates restriction '%s' on capability %s%s",
+				c.module.Name, restriction.String(), capability, policySuffix(decision.Policy))
+		}
+	}
+	return d.clusterElimination(capability)
+}
+
+// Returns why no cluster can be used for the given capability (empty if some cluster can be used)
+func (d *diagnoser) clusterElimination(capability taxonomy.Capability) string {
+	if len(d.env.Clusters) == 0 {
+		return "no cluster is available"
+	}
+	decision := d.dataInfo.Configuration.ConfigDecisions[capability]
+	reason := ""
+	for i := range d.env.Clusters {
+		cluster := &d.env.Clusters[i]
+		restriction, violated := firstViolated(d.env, decision.DeploymentRestrictions.Clusters, cluster, cluster.Name)
+		if !violated {
+			return ""
+		}
+		reason = fmt.Sprintf("no cluster satisfies the restrictions on capability %s: the last candidate cluster %s violates "+
+			"restriction '%s'%s", capability, cluster.Name, restriction.String(), policySuffix(decision.Policy))
+	}
+	return reason
+}
+
+// Explains capabilities which must be deployed but cannot be
+func (d *diagnoser) explainCapabilities() []Explanation {
+	capabilities := []string{}
+	for capability, decision := range d.dataInfo.Configuration.ConfigDecisions {
+		if decision.Deploy == adminconfig.StatusTrue {
+			capabilities = append(capabilities, string(capability))
+		}
+	}
+	sort.Strings(capabilities)
+
+	explanations := []Explanation{}
+	for _, capability := range capabilities {
+		offering := []*candidate{}
+		for _, c := range d.candidates {
+			if string(c.capability().Capability) == capability {
+				offering = append(offering, c)
+			}
+		}
+		var msg string
+		if len(offering) == 0 {
+			msg = fmt.Sprintf("capability %s must be deployed, but it is not offered by any deployed module", capability)
+		} else if !anyUsable(offering) {
+			msg = fmt.Sprintf("capability %s must be deployed, but all modules offering it were eliminated: %s",
+				capability, eliminatedList(offering, nil))
+		} else {
+			continue
+		}
+		explanations = append(explanations, Explanation{Reason: MissingCapability, Message: msg})
+	}
+	return explanations
+}
+
+// Explains governance actions which cannot be applied by any usable module
+func (d *diagnoser) explainActions() []Explanation {
+	explanations := []Explanation{}
+	for i := range d.dataInfo.Actions {
+		action := &d.dataInfo.Actions[i]
+		supporting := []*candidate{}
+		for _, c := range d.candidates {
+			for j := range c.capability().Actions {
+				if c.capability().Actions[j].Supports(action) {
+					supporting = append(supporting, c)
+					break
+				}
+			}
+		}
+		if len(supporting) == 0 {
+			explanations = append(explanations, Explanation{Reason: UnsupportedAction,
+				Message: fmt.Sprintf("governance action %s is not supported in %s scope by any deployed module",
+					action.Name, action.Scope())})
+			continue
+		}
+		// modules applying governance actions must also satisfy the restrictions on the transform capability
+		transformEliminations := map[*candidate]string{}
+		usable := false
+		for _, c := range supporting {
+			if c.eliminatedBy == "" {
+				transformEliminations[c] = d.candidateElimination(c, transformCapability)
+				usable = usable || transformEliminations[c] == ""
+			}
+		}
+		if !usable {
+			explanations = append(explanations, Explanation{Reason: UnsupportedAction,
+				Message: fmt.Sprintf("governance action %s is only supported by modules which were eliminated: %s",
+					action.Name, eliminatedList(supporting, transformEliminations))})
+		}
+	}
+	return explanations
+}
+
+// Explains a broken chain of interfaces between the asset and the workload.
+// Also returns the minimal number of usable modules needed to connect the asset and the workload (0 if not connected).
+func (d *diagnoser) explainInterfaceChain() (int, []Explanation) {
+	assetIntfc := d.assetInterface()
+	requestedIntfc := d.dataInfo.Context.Requirements.Interface
+	if assetIntfc == nil || requestedIntfc == nil {
+		return 0, nil
+	}
+	start, end := assetIntfc, requestedIntfc
+	startName, endName := "asset", "requested"
+	if d.dataInfo.Context.Flow == taxonomy.WriteFlow {
+		start, end = end, start
+		startName, endName = endName, startName
+	}
+	if length := d.chainLength(start, end, false); length > 0 {
+		return length, nil
+	}
+
+	msg := fmt.Sprintf("no chain of modules connects the %s interface (%s) to the %s interface (%s)",
+		startName, interfaceString(start), endName, interfaceString(end))
+	if d.chainLength(start, end, true) > 0 {
+		msg += "; modules which could complete the chain were eliminated: " + eliminatedList(d.candidates, nil)
+	}
+	return 0, []Explanation{{Reason: BrokenInterfaceChain, Message: msg}}
+}
+
+// Returns the minimal number of modules needed to connect the start interface to the end interface (0 if not connected)
+func (d *diagnoser) chainLength(start, end *taxonomy.Interface, includeEliminated bool) int {
+	reached := map[taxonomy.Interface]bool{*start: true}
+	for length := 1; length <= len(d.candidates); length++ {
+		next := []taxonomy.Interface{}
+		for _, c := range d.candidates {
+			if c.eliminatedBy != "" && !includeEliminated {
+				continue
+			}
+			for _, edge := range candidateEdges(c) {
+				if !matchesAny(edge[0], reached) {
+					continue
+				}
+				if interfacesMatch(edge[1], end) {
+					return length
+				}
+				if !reached[*edge[1]] {
+					next = append(next, *edge[1])
+				}
+			}
+		}
+		if len(next) == 0 {
+			break
+		}
+		for _, intfc := range next {
+			reached[intfc] = true
+		}
+	}
+	return 0
+}
+
+// Explains why no storage account can be used, if data must be stored
+func (d *diagnoser) explainStorage(chainLength int) []Explanation {
+	context := d.dataInfo.Context
+	storageNeeded := context.Flow == taxonomy.CopyFlow || context.Flow == taxonomy.StreamFlow || chainLength > 1 ||
+		(context.Flow == taxonomy.WriteFlow && context.Requirements.FlowParams.IsNewDataSet)
+	if !storageNeeded {
+		return nil
+	}
+	if len(d.env.StorageAccounts) == 0 {
+		return []Explanation{{Reason: NoAllowedStorageAccount, Message: "data must be stored, but no storage account is available"}}
+	}
+	reason := ""
+	for _, c := range d.candidates {
+		if c.eliminatedBy != "" || !hasStorageSink(c) {
+			continue
+		}
+		for _, account := range d.env.StorageAccounts {
+			reason = d.storageAccountElimination(c, account)
+			if reason == "" {
+				return nil
+			}
+		}
+	}
+	if reason == "" { // no usable module writes to storage, which is explained by the interface chain
+		return nil
+	}
+	return []Explanation{{Reason: NoAllowedStorageAccount,
+		Message: "data must be stored, but no storage account can be used: the last candidate " + reason}}
+}
+
+// Returns why a storage account cannot be used by a module capability (empty if it can be used)
+func (d *diagnoser) storageAccountElimination(c *candidate, account *fappv2.FybrikStorageAccount) string {
+	supportsType := false
+	for _, intfc := range c.capability().SupportedInterfaces {
+		if intfc.Sink != nil && intfc.Sink.Protocol == account.Spec.Type {
+			supportsType = true
+		}
+	}
+	if !supportsType {
+		return fmt.Sprintf("storage account %s has type %s, which %s cannot write to", account.Name, account.Spec.Type, c)
+	}
+	capability := c.capability().Capability
+	decision := d.dataInfo.Configuration.ConfigDecisions[capability]
+	if restriction, violated := firstViolated(d.env, decision.DeploymentRestrictions.StorageAccounts,
+		&account.Spec, account.Name); violated {
+		return fmt.Sprintf("storage account %s violates restriction '%s' on capability %s%s",
+			account.Name, restriction.String(), capability, policySuffix(decision.Policy))
+	}
+	if estimate := d.dataInfo.Context.Requirements.FlowParams.StorageEstimate; !account.HasCapacity(estimate) {
+		return fmt.Sprintf("storage account %s has %s allocated out of its quota of %s, which leaves no room for %s",
+			account.Name, account.Status.EstimatedUsage.String(), account.Spec.Quota.String(), estimate.HR())
+	}
+	if _, allowed := d.dataInfo.StorageRequirements[account.Spec.Geography]; !allowed {
+		return fmt.Sprintf("storage account %s is in %s, where writing the data is forbidden by governance policies",
+			account.Name, account.Spec.Geography)
+	}
+	return ""
+}
+
+func (d *diagnoser) assetInterface() *taxonomy.Interface {
+	if d.dataInfo.DataDetails == nil || d.dataInfo.DataDetails.Details.Connection.Name == "" {
+		return nil
+	}
+	return &taxonomy.Interface{
+		Protocol:   d.dataInfo.DataDetails.Details.Connection.Name,
+		DataFormat: d.dataInfo.DataDetails.Details.DataFormat,
+	}
+}
+
+// ----- helper functions -----
+
+// Returns the (source, sink) interface pairs of a module capability. A missing source or sink is replaced by the API.
+func candidateEdges(c *candidate) [][2]*taxonomy.Interface {
+	capability := c.capability()
+	var apiIntfc *taxonomy.Interface
+	if capability.API != nil {
+		apiIntfc = &taxonomy.Interface{Protocol: capability.API.Connection.Name, DataFormat: capability.API.DataFormat}
+	}
+	edges := [][2]*taxonomy.Interface{}
+	for _, inOut := range capability.SupportedInterfaces {
+		source, sink := inOut.Source, inOut.Sink
+		if source == nil {
+			source = apiIntfc
+		}
+		if sink == nil {
+			sink = apiIntfc
+		}
+		if source != nil && sink != nil {
+			edges = append(edges, [2]*taxonomy.Interface{source, sink})
+		}
+	}
+	return edges
+}
+
+func hasStorageSink(c *candidate) bool {
+	for _, inOut := range c.capability().SupportedInterfaces {
+		if inOut.Sink != nil {
+			return true
+		}
+	}
+	return false
+}
+
+func anyUsable(candidates []*candidate) bool {
+	for _, c := range candidates {
+		if c.eliminatedBy == "" {
+			return true
+		}
+	}
+	return false
+}
+
+// Lists the eliminated candidates with the reasons for their elimination.
+// Additional reasons may be given for candidates which are otherwise usable.
+func eliminatedList(candidates []*candidate, additionalReasons map[*candidate]string) string {
+	items := []string{}
+	for _, c := range candidates {
+		reason := c.eliminatedBy
+		if reason == "" {
+			reason = additionalReasons[c]
+		}
+		if reason != "" {
+			items = append(items, fmt.Sprintf("%s - %s", c, reason))
+		}
+	}
+	return strings.Join(items, ", ")
+}
+
+func firstViolated(env *Environment, restrictions []adminconfig.Restriction, spec interface{},
+	instanceName string) (adminconfig.Restriction, bool) {
+	for _, restriction := range restrictions {
+		if !restriction.SatisfiedByResource(env.AttributeManager, spec, instanceName) {
+			return restriction, true
+		}
+	}
+	return adminconfig.Restriction{}, false
+}
+
+func policySuffix(policy adminconfig.DecisionPolicy) string {
+	if policy.ID == "" {
+		return ""
+	}
+	return " (policy " + policy.ID + ")"
+}
+
+// An empty protocol or data format matches any protocol or data format
+func interfacesMatch(intfc1, intfc2 *taxonomy.Interface) bool {
+	if intfc1 == nil || intfc2 == nil {
+		return false
+	}
+	if intfc1.Protocol != "" && intfc2.Protocol != "" && intfc1.Protocol != intfc2.Protocol {
+		return false
+	}
+	return intfc1.DataFormat == "" || intfc2.DataFormat == "" || intfc1.DataFormat == intfc2.DataFormat
+}
+
+func matchesAny(intfc *taxonomy.Interface, intfcs map[taxonomy.Interface]bool) bool {
+	for other := range intfcs {
+		other := other
+		if interfacesMatch(intfc, &other) {
+			return true
+		}
+	}
+	return false
+}
+
+func interfaceString(intfc *taxonomy.Interface) string {
+	if intfc.DataFormat == "" {
+		return string(intfc.Protocol)
+	}
+	return string(intfc.Protocol) + ", " + string(intfc.DataFormat)
+}
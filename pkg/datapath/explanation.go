@@ -0,0 +1,65 @@
+// Copyright 2023 IBM Corp.
+// SPDX-License-Identifier: Apache-2.0
+
+package datapath
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ExplanationReason classifies the reasons for failing to construct a data path
+type ExplanationReason string
+
+const (
+	// A capability that must be deployed cannot be deployed
+	MissingCapability ExplanationReason = "MissingCapability"
+	// A required governance action cannot be applied by any of the usable modules
+	UnsupportedAction ExplanationReason = "UnsupportedAction"
+	// No chain of usable modules connects the asset interface to the interface requested by the application
+	BrokenInterfaceChain ExplanationReason = "BrokenInterfaceChain"
+	// Data must be stored, but none of the storage accounts can be used
+	NoAllowedStorageAccount ExplanationReason = "NoAllowedStorageAccount"
+	// Each constraint can be satisfied on its own, but no data path satisfies all of them together
+	ConflictingConstraints ExplanationReason = "ConflictingConstraints"
+)
+
+// Explanation describes a single reason for failing to construct a data path
+type Explanation struct {
+	Reason  ExplanationReason
+	Message string
+}
+
+func (e Explanation) String() string {
+	return string(e.Reason) + ": " + e.Message
+}
+
+// DataPathError is returned when no data path satisfying all constraints exists for a dataset.
+// It holds explanations of the constraints that could not be satisfied.
+type DataPathError struct {
+	DataSetID    string
+	Explanations []Explanation
+}
+
+// NewDataPathError diagnoses why no data path can be constructed for the given dataset
+func NewDataPathError(env *Environment, dataInfo *DataInfo) *DataPathError {
+	return &DataPathError{DataSetID: dataInfo.Context.DataSetID, Explanations: Explain(env, dataInfo)}
+}
+
+func (e *DataPathError) Error() string {
+	msgs := make([]string, len(e.Explanations))
+	for i := range e.Explanations {
+		msgs[i] = e.Explanations[i].String()
+	}
+	return fmt.Sprintf("Data path cannot be constructed for %s: %s", e.DataSetID, strings.Join(msgs, "; "))
+}
+
+// HasReason returns true if one of the explanations is of the given reason
+func (e *DataPathError) HasReason(reason ExplanationReason) bool {
+	for i := range e.Explanations {
+		if e.Explanations[i].Reason == reason {
+			return true
+		}
+	}
+	return false
+}
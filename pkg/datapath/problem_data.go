@@ -27,6 +27,14 @@ type DataInfo struct {
 	Actions []taxonomy.Action
 	// Potential actions to be taken on storing this asset in a specific location
 	StorageRequirements map[taxonomy.ProcessingLocation][]taxonomy.Action
+	// IDs of the policies the required governance actions are based on
+	Policies []string
+	// IDs of the policies the potential actions on storing this asset in a specific location are based on
+	StoragePolicies map[taxonomy.ProcessingLocation][]string
+	// IDs of the policy manager decisions the required governance actions are based on
+	DecisionIDs []string
+	// IDs of the policy manager decisions on storing this asset in a specific location
+	StorageDecisionIDs map[taxonomy.ProcessingLocation]string
 }
 
 // Environment defines the available resources (clusters, modules, storageAccounts)
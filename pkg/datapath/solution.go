@@ -8,6 +8,7 @@ import (
 
 	fappv1 "fybrik.io/fybrik/manager/apis/app/v1beta1"
 	fappv2 "fybrik.io/fybrik/manager/apis/app/v1beta2"
+	"fybrik.io/fybrik/pkg/adminconfig"
 	"fybrik.io/fybrik/pkg/model/taxonomy"
 )
 
@@ -41,6 +42,20 @@ type Solution struct {
 	DataPath []*ResolvedEdge
 }
 
+// GoalScore is the value of a single optimization goal in a given solution
+type GoalScore struct {
+	Goal  adminconfig.AttributeOptimization
+	Value float64
+}
+
+// ScoredSolution is a solution together with its score (the smaller the better) and the values of the optimization goals.
+// The score is NaN if no optimization goals are set.
+type ScoredSolution struct {
+	Solution
+	Score float64
+	Goals []GoalScore
+}
+
 func (re *ResolvedEdge) String() string {
 	return fmt.Sprintf("Source: %v, Sink: %v, Module:%v, CapIndex: %v, Actions: %v, Cluster: %v, SA: %v",
 		re.Source, re.Sink, re.Module.Name, re.CapabilityIndex, re.Actions, re.Cluster, re.StorageAccount)
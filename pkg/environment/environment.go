@@ -29,8 +29,27 @@ const (
 	CatalogProviderNameKey            string = "CATALOG_PROVIDER_NAME"
 	DatapathLimitKey                  string = "DATAPATH_LIMIT"
 	UseCSPKey                         string = "USE_CSP"
+	UseJointCSPKey                    string = "USE_JOINT_CSP"
+	DataPathAlternativesKey           string = "DATAPATH_ALTERNATIVES"
+	PolicyDecisionsCacheTTLKey        string = "POLICY_DECISIONS_CACHE_TTL"
+	GovernanceReevaluationIntervalKey string = "GOVERNANCE_REEVALUATION_INTERVAL"
+	GovernanceReevaluationTimeoutKey  string = "GOVERNANCE_REEVALUATION_TIMEOUT"
+	StorageGCIntervalKey              string = "STORAGE_GC_INTERVAL"
+	StorageGCGracePeriodKey           string = "STORAGE_GC_GRACE_PERIOD"
+	StorageGCDeleteOrphansKey         string = "STORAGE_GC_DELETE_ORPHANS"
+	OpenLineageURLKey                 string = "OPENLINEAGE_URL"
+	AuditFileKey                      string = "AUDIT_FILE"
+	AuditFileMaxSizeKey               string = "AUDIT_FILE_MAX_SIZE"
+	AuditFileMaxBackupsKey            string = "AUDIT_FILE_MAX_BACKUPS"
+	AuditWebhookURLKey                string = "AUDIT_WEBHOOK_URL"
+	AuditEventsKey                    string = "AUDIT_EVENTS"
+	KubeconfigSecretsKey              string = "MULTICLUSTER_KUBECONFIG_SECRETS"
+	ClusterHeartbeatIntervalKey       string = "CLUSTER_HEARTBEAT_INTERVAL"
+	ClusterFailoverDelayKey           string = "CLUSTER_FAILOVER_DELAY"
+	RolloutStrategyKey                string = "ROLLOUT_STRATEGY"
 	CSPPathKey                        string = "CSP_PATH"
 	CSPArgsKey                        string = "CSP_ARGS"
+	CSPTimeLimitKey                   string = "CSP_TIME_LIMIT"
 	DataDir                           string = "DATA_DIR"
 	ModuleNamespace                   string = "MODULES_NAMESPACE"
 	ControllerNamespace               string = "CONTROLLER_NAMESPACE"
@@ -44,6 +63,7 @@ const (
 	LocalZone                         string = "Zone"
 	LocalRegion                       string = "Region"
 	LocalVaultAuthPath                string = "VaultAuthPath"
+	LocalFybrikVersion                string = "FybrikVersion"
 	ResourcesPollingInterval          string = "RESOURCE_POLLING_INTERVAL"
 	DiscoveryBurst                    string = "DISCOVERY_BURST"
 	DiscoveryQPS                      string = "DISCOVERY_QPS"
@@ -79,6 +99,10 @@ func GetLocalVaultAuthPath() string {
 	return os.Getenv(LocalVaultAuthPath)
 }
 
+func GetLocalFybrikVersion() string {
+	return os.Getenv(LocalFybrikVersion)
+}
+
 func GetCatalogProvider() string {
 	return os.Getenv(CatalogProviderNameKey)
 }
@@ -248,12 +272,166 @@ func GetDataPathMaxSize() (int, error) {
 	return limit, nil
 }
 
+// GetDataPathAlternatives returns the number of best data paths to record in the status of each asset.
+// Alternative data paths are not recorded (0 is returned) if the DataPathAlternativesKey env var is undefined.
+func GetDataPathAlternatives() (int, error) {
+	alternativesStr := os.Getenv(DataPathAlternativesKey)
+	if alternativesStr == "" {
+		return 0, nil
+	}
+	alternatives, err := strconv.Atoi(alternativesStr)
+	if err != nil || alternatives < 0 {
+		return 0, fmt.Errorf("bad value for %s: %s", DataPathAlternativesKey, alternativesStr)
+	}
+	return alternatives, nil
+}
+
+// GetPolicyDecisionsCacheTTL returns for how long policy decisions are cached by the manager.
+// The time to live is specified in seconds. Decisions are not cached (0 is returned)
+// if the PolicyDecisionsCacheTTLKey env var is undefined.
+func GetPolicyDecisionsCacheTTL() (time.Duration, error) {
+	ttlStr := os.Getenv(PolicyDecisionsCacheTTLKey)
+	if ttlStr == "" {
+		return 0, nil
+	}
+	ttl, err := strconv.Atoi(ttlStr)
+	if err != nil || ttl < 0 {
+		return 0, fmt.Errorf("bad value for %s: %s", PolicyDecisionsCacheTTLKey, ttlStr)
+	}
+	return time.Duration(ttl) * time.Second, nil
+}
+
+// GetGovernanceReevaluationInterval returns how often the governance decisions of all FybrikApplications are re-evaluated.
+// The interval is specified in seconds. Periodic re-evaluation is disabled (0 is returned)
+// if the GovernanceReevaluationIntervalKey env var is undefined.
+func GetGovernanceReevaluationInterval() (time.Duration, error) {
+	intervalStr := os.Getenv(GovernanceReevaluationIntervalKey)
+	if intervalStr == "" {
+		return 0, nil
+	}
+	interval, err := strconv.Atoi(intervalStr)
+	if err != nil || interval < 0 {
+		return 0, fmt.Errorf("bad value for %s: %s", GovernanceReevaluationIntervalKey, intervalStr)
+	}
+	return time.Duration(interval) * time.Second, nil
+}
+
+// GetGovernanceReevaluationTimeout returns how long the governance decisions of a FybrikApplication can fail to be
+// re-evaluated before the data paths of the application are removed. The timeout is specified in seconds.
+// The data paths are kept (0 is returned) if the GovernanceReevaluationTimeoutKey env var is undefined.
+func GetGovernanceReevaluationTimeout() time.Duration {
+	return time.Duration(GetEnvAsInt(GovernanceReevaluationTimeoutKey, 0)) * time.Second
+}
+
+// DefaultStorageGCGracePeriod is the default time in seconds orphaned storage is kept before it is deleted
+const DefaultStorageGCGracePeriod = 3600
+
+// GetStorageGCInterval returns how often the storage allocated in the storage accounts is checked for orphaned storage.
+// The interval is specified in seconds. The storage garbage collection is disabled (0 is returned)
+// if the StorageGCIntervalKey env var is undefined.
+func GetStorageGCInterval() (time.Duration, error) {
+	intervalStr := os.Getenv(StorageGCIntervalKey)
+	if intervalStr == "" {
+		return 0, nil
+	}
+	interval, err := strconv.Atoi(intervalStr)
+	if err != nil || interval < 0 {
+		return 0, fmt.Errorf("bad value for %s: %s", StorageGCIntervalKey, intervalStr)
+	}
+	return time.Duration(interval) * time.Second, nil
+}
+
+// GetStorageGCGracePeriod returns for how long storage must be found orphaned before it is deleted.
+// The grace period is specified in seconds.
+func GetStorageGCGracePeriod() time.Duration {
+	return time.Duration(GetEnvAsInt(StorageGCGracePeriodKey, DefaultStorageGCGracePeriod)) * time.Second
+}
+
+// StorageGCDeletesOrphans returns true if orphaned storage is deleted, rather than only reported
+func StorageGCDeletesOrphans() bool {
+	return os.Getenv(StorageGCDeleteOrphansKey) == "true"
+}
+
+// DefaultClusterHeartbeatInterval is the default time in seconds between checks of the reachability of the clusters
+const DefaultClusterHeartbeatInterval = 60
+
+// GetClusterHeartbeatInterval returns how often the clusters are checked and their FybrikCluster resources are updated.
+// The interval is specified in seconds.
+func GetClusterHeartbeatInterval() time.Duration {
+	return time.Duration(GetEnvAsInt(ClusterHeartbeatIntervalKey, DefaultClusterHeartbeatInterval)) * time.Second
+}
+
+// DefaultClusterFailoverDelay is the default time in seconds that a cluster can be unreachable before a failover
+const DefaultClusterFailoverDelay = 180
+
+// GetClusterFailoverDelay returns how long a cluster can be unreachable before the applications that are deployed on it
+// are planned again on the other clusters. The delay is specified in seconds.
+func GetClusterFailoverDelay() time.Duration {
+	return time.Duration(GetEnvAsInt(ClusterFailoverDelayKey, DefaultClusterFailoverDelay)) * time.Second
+}
+
+// GetRolloutStrategy returns how changes of the applications are rolled out to the clusters unless an application
+// sets its own strategy, or "" for the default strategy
+func GetRolloutStrategy() string {
+	return os.Getenv(RolloutStrategyKey)
+}
+
+// IsUsingKubeconfigSecrets returns true if the clusters of a multicluster setup are accessed with the kubeconfig
+// secrets in the controller namespace
+func IsUsingKubeconfigSecrets() bool {
+	return os.Getenv(KubeconfigSecretsKey) == "true"
+}
+
+// GetOpenLineageURL returns the URL of the OpenLineage server that receives the lineage events,
+// or "" if lineage events are only logged
+func GetOpenLineageURL() string {
+	return os.Getenv(OpenLineageURLKey)
+}
+
+// Defaults of the audit file rotation: the maximal size of a file in megabytes and the number of rotated files that are kept
+const (
+	DefaultAuditFileMaxSize    = 100
+	DefaultAuditFileMaxBackups = 10
+)
+
+// GetAuditFile returns the path of the file the audit records are written to, or "" if they are not written to a file
+func GetAuditFile() string {
+	return os.Getenv(AuditFileKey)
+}
+
+// GetAuditFileMaxSize returns the size in bytes at which the audit file is rotated
+func GetAuditFileMaxSize() int64 {
+	const megabyte = 1 << 20
+	return int64(GetEnvAsInt(AuditFileMaxSizeKey, DefaultAuditFileMaxSize)) * megabyte
+}
+
+// GetAuditFileMaxBackups returns the number of rotated audit files that are kept
+func GetAuditFileMaxBackups() int {
+	return GetEnvAsInt(AuditFileMaxBackupsKey, DefaultAuditFileMaxBackups)
+}
+
+// GetAuditWebhookURL returns the URL the audit records are posted to, or "" if they are not posted
+func GetAuditWebhookURL() string {
+	return os.Getenv(AuditWebhookURLKey)
+}
+
+// AuditEventsEnabled returns true if the audit records are reported as Kubernetes events of the FybrikApplications
+func AuditEventsEnabled() bool {
+	return os.Getenv(AuditEventsKey) == "true"
+}
+
 // UseCSP return true if a CSP solver should be used when generating a plotter
 func UseCSP() bool {
 	return os.Getenv(UseCSPKey) == "true"
 }
 
-// GetCSPPath returns the path of the CSP solver to use when generating a plotter, or "" if no CSP solver is defined
+// UseJointCSP returns true if the data paths of all datasets in an application should be optimized together
+func UseJointCSP() bool {
+	return os.Getenv(UseJointCSPKey) == "true"
+}
+
+// GetCSPPath returns the path of an external CSP solver to use when generating a plotter,
+// or "" if the built-in solver should be used
 func GetCSPPath() string {
 	return os.Getenv(CSPPathKey)
 }
@@ -263,6 +441,16 @@ func GetCSPArgs() string {
 	return os.Getenv(CSPArgsKey)
 }
 
+// DefaultCSPTimeLimit is the default time in seconds the CSP solver can spend on finding the data paths of a dataset
+const DefaultCSPTimeLimit = 30
+
+// GetCSPTimeLimit returns how long the CSP solver can spend on finding the data paths of a dataset,
+// or of all datasets when they are optimized together. The time limit is specified in seconds.
+// 0 means no time limit.
+func GetCSPTimeLimit() time.Duration {
+	return time.Duration(GetEnvAsInt(CSPTimeLimitKey, DefaultCSPTimeLimit)) * time.Second
+}
+
 // GetDataCatalogServiceAddress returns the address where data catalog is running
 func GetDataCatalogServiceAddress() string {
 	return os.Getenv(CatalogConnectorServiceAddressKey)
@@ -297,7 +485,11 @@ func LogEnvVariables(log *zerolog.Logger) {
 	envVarArray := [...]string{CatalogConnectorServiceAddressKey, StorageManagerAddressKey, VaultAddressKey, VaultModulesRoleKey,
 		EnableWebhooksKey, MainPolicyManagerConnectorURLKey,
 		MainPolicyManagerNameKey, LoggingVerbosityKey, PrettyLoggingKey,
-		DataDir, ModuleNamespace, ControllerNamespace, ApplicationNamespace, MinTLSVersion, NPEnabled}
+		DataDir, ModuleNamespace, ControllerNamespace, ApplicationNamespace, MinTLSVersion, NPEnabled, OpenLineageURLKey,
+		StorageGCGracePeriodKey, StorageGCDeleteOrphansKey,
+		AuditFileKey, AuditFileMaxSizeKey, AuditFileMaxBackupsKey, AuditWebhookURLKey, AuditEventsKey, KubeconfigSecretsKey,
+		ClusterHeartbeatIntervalKey, ClusterFailoverDelayKey, RolloutStrategyKey, GovernanceReevaluationTimeoutKey,
+		CSPTimeLimitKey}
 
 	log.Info().Msg("Manager configured with the following environment variables:")
 	for _, envVar := range envVarArray {
@@ -312,4 +504,12 @@ func LogEnvVariables(log *zerolog.Logger) {
 	logEnvVarUpdatedValue(log, DiscoveryQPS, fmt.Sprintf("%f", discoveryQPS), err)
 	dataPathMaxSize, err := GetDataPathMaxSize()
 	logEnvVarUpdatedValue(log, DatapathLimitKey, strconv.Itoa(dataPathMaxSize), err)
+	dataPathAlternatives, err := GetDataPathAlternatives()
+	logEnvVarUpdatedValue(log, DataPathAlternativesKey, strconv.Itoa(dataPathAlternatives), err)
+	cacheTTL, err := GetPolicyDecisionsCacheTTL()
+	logEnvVarUpdatedValue(log, PolicyDecisionsCacheTTLKey, cacheTTL.String(), err)
+	reevaluationInterval, err := GetGovernanceReevaluationInterval()
+	logEnvVarUpdatedValue(log, GovernanceReevaluationIntervalKey, reevaluationInterval.String(), err)
+	storageGCInterval, err := GetStorageGCInterval()
+	logEnvVarUpdatedValue(log, StorageGCIntervalKey, storageGCInterval.String(), err)
 }
@@ -36,6 +36,7 @@ type Interface interface {
 		releaseName string, vals map[string]interface{}) (*release.Release, error)
 	Upgrade(ctx context.Context, cfg *action.Configuration, chart *chart.Chart, kubeNamespace string,
 		releaseName string, vals map[string]interface{}) (*release.Release, error)
+	Rollback(cfg *action.Configuration, releaseName string, revision int) error
 	Status(cfg *action.Configuration, releaseName string) (*release.Release, error)
 	Pull(cfg *action.Configuration, ref string, destination string) error
 	IsInstalled(cfg *action.Configuration, releaseName string) (bool, error)
@@ -82,6 +83,16 @@ func (r *Fake) Upgrade(ctx context.Context, cfg *action.Configuration, chrt *cha
 	return r.release, nil
 }
 
+// Rollback helm release to a previous revision
+func (r *Fake) Rollback(cfg *action.Configuration, releaseName string, revision int) error {
+	r.release = &release.Release{
+		Name:    releaseName,
+		Version: revision,
+		Info:    &release.Info{Status: release.StatusDeployed},
+	}
+	return nil
+}
+
 // Status of helm release
 func (r *Fake) Status(cfg *action.Configuration, releaseName string) (*release.Release, error) {
 	return r.release, nil
@@ -193,6 +204,13 @@ func (r *Impl) Upgrade(ctx context.Context, cfg *action.Configuration, chrt *cha
 	return upgrade.RunWithContext(ctx, releaseName, chrt, vals)
 }
 
+// Rollback helm release to a previous revision
+func (r *Impl) Rollback(cfg *action.Configuration, releaseName string, revision int) error {
+	rollback := action.NewRollback(cfg)
+	rollback.Version = revision
+	return rollback.Run(releaseName)
+}
+
 // Status of helm release
 func (r *Impl) Status(cfg *action.Configuration, releaseName string) (*release.Release, error) {
 	status := action.NewStatus(cfg)
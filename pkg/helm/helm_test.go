@@ -227,6 +227,13 @@ func TestHelmRelease(t *testing.T) {
 	assert.Equal(t, kstatus.CurrentStatus, computedResult.Status)
 	Log(t, "getResources", err)
 
+	err = impl.Rollback(cfg, releaseName, 1)
+	assert.Nil(t, err)
+	Log(t, "rollback", err)
+	rel, err = impl.Status(cfg, releaseName)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, rel.Version)
+
 	_, err = impl.Uninstall(cfg, releaseName)
 	assert.Nil(t, err)
 	Log(t, "uninstall", err)
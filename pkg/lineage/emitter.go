@@ -0,0 +1,94 @@
+// Copyright 2023 IBM Corp.
+// SPDX-License-Identifier: Apache-2.0
+
+package lineage
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"fybrik.io/fybrik/pkg/logging"
+)
+
+// EndpointPath is the path of the OpenLineage HTTP endpoint, relative to its URL
+const EndpointPath = "/api/v1/lineage"
+
+const requestTimeout = 10 * time.Second
+
+// queueSize is the number of events that can wait to be sent to the OpenLineage server.
+// Events emitted while the queue is full are dropped.
+const queueSize = 1000
+
+// NewEmitter returns an emitter that logs the OpenLineage events of the lineage records as audit messages,
+// and sends them to the OpenLineage server at the given URL, unless it is empty.
+// The events are sent in the background, so that emitting them does not wait for the server.
+func NewEmitter(url string, log *zerolog.Logger) Emitter {
+	emitter := &eventEmitter{log: log}
+	if url != "" {
+		emitter.endpoint = strings.TrimSuffix(url, "/") + EndpointPath
+		emitter.client = &http.Client{Timeout: requestTimeout}
+		emitter.queue = make(chan []byte, queueSize)
+		go emitter.run()
+	}
+	return emitter
+}
+
+type eventEmitter struct {
+	log      *zerolog.Logger
+	endpoint string
+	client   *http.Client
+	queue    chan []byte
+}
+
+// Emit logs the event of the record and queues it to be sent to the OpenLineage server.
+// An error is returned if the event can not be queued.
+func (e *eventEmitter) Emit(record *Record) error {
+	event := record.Event()
+	logging.LogStructure("Lineage event", event, e.log, zerolog.InfoLevel, false, true)
+	if e.queue == nil {
+		return nil
+	}
+	content, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	select {
+	case e.queue <- content:
+		return nil
+	default:
+		return fmt.Errorf("the lineage event has been dropped since %d events are waiting to be sent to %s", queueSize, e.endpoint)
+	}
+}
+
+// run sends the queued events to the OpenLineage server, logging the failures
+func (e *eventEmitter) run() {
+	for content := range e.queue {
+		if err := e.send(content); err != nil {
+			e.log.Warn().Err(err).Msg("Could not send a lineage event")
+		}
+	}
+}
+
+func (e *eventEmitter) send(content []byte) error {
+	request, err := http.NewRequestWithContext(context.Background(), http.MethodPost, e.endpoint, bytes.NewReader(content))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	response, err := e.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode < http.StatusOK || response.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("the lineage endpoint %s responded with status %s", e.endpoint, response.Status)
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+// Copyright 2023 IBM Corp.
+// SPDX-License-Identifier: Apache-2.0
+
+package lineage
+
+import (
+	"time"
+
+	"fybrik.io/fybrik/pkg/model/datacatalog"
+	"fybrik.io/fybrik/pkg/model/taxonomy"
+)
+
+// Dataset is the source or the destination of a data flow
+type Dataset struct {
+	// AssetID of the dataset, or an empty string for the endpoint of a module that serves the workload
+	AssetID string `json:"assetID,omitempty"`
+	// Connection to the dataset or to the module endpoint
+	Connection *taxonomy.Connection `json:"connection,omitempty"`
+}
+
+// Record describes a copy, write or transformation of a dataset performed by Fybrik
+type Record struct {
+	// Flow is the name of the plotter flow that moved the data
+	Flow string `json:"flow"`
+	// FlowType is the type of the subflow that moved the data: copy, stream, write or read
+	FlowType taxonomy.DataFlow `json:"flowType"`
+	// Source of the data
+	Source Dataset `json:"source"`
+	// Destination of the data
+	Destination Dataset `json:"destination"`
+	// Lineage holds the application, the steps and the policies of the flow, and is stored on registered assets
+	Lineage datacatalog.AssetLineage `json:"lineage"`
+	// Time the data flow became ready
+	Time time.Time `json:"time"`
+}
+
+// Emitter exports lineage records
+type Emitter interface {
+	Emit(record *Record) error
+}
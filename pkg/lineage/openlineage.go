@@ -0,0 +1,113 @@
+// Copyright 2023 IBM Corp.
+// SPDX-License-Identifier: Apache-2.0
+
+package lineage
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"fybrik.io/fybrik/pkg/model/taxonomy"
+)
+
+// OpenLineage event properties, see https://openlineage.io/spec/1-0-5/OpenLineage.json
+const (
+	Producer          = "https://github.com/fybrik/fybrik"
+	RunEventSchemaURL = "https://openlineage.io/spec/1-0-5/OpenLineage.json#/definitions/RunEvent"
+	CompleteEventType = "COMPLETE"
+	RunningEventType  = "RUNNING"
+	JobNamespace      = "fybrik"
+	// FybrikFacet is the name of the custom facet that holds the lineage of a flow
+	FybrikFacet = "fybrik"
+	// ConnectionFacet is the name of the custom facet that holds the connection to a dataset
+	ConnectionFacet = "fybrik_connection"
+	facetSchemaURL  = "https://fybrik.io/schemas/lineage/1-0-0/facets.json"
+)
+
+// RunEvent is an OpenLineage event that reports the completion of a data flow, or the start of a continuous one
+type RunEvent struct {
+	EventType string    `json:"eventType"`
+	EventTime time.Time `json:"eventTime"`
+	Producer  string    `json:"producer"`
+	SchemaURL string    `json:"schemaURL"`
+	Run       Run       `json:"run"`
+	Job       Job       `json:"job"`
+	Inputs    []DataSet `json:"inputs"`
+	Outputs   []DataSet `json:"outputs"`
+}
+
+// Run identifies a single execution of a job
+type Run struct {
+	RunID  string                 `json:"runId"`
+	Facets map[string]interface{} `json:"facets,omitempty"`
+}
+
+// Job identifies the data flow
+type Job struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+}
+
+// DataSet is an input or an output of a job
+type DataSet struct {
+	Namespace string                 `json:"namespace"`
+	Name      string                 `json:"name"`
+	Facets    map[string]interface{} `json:"facets,omitempty"`
+}
+
+// Facet is a custom OpenLineage facet
+type Facet struct {
+	Producer  string      `json:"_producer"`
+	SchemaURL string      `json:"_schemaURL"`
+	Content   interface{} `json:"content"`
+}
+
+func newFacet(content interface{}) Facet {
+	return Facet{Producer: Producer, SchemaURL: facetSchemaURL, Content: content}
+}
+
+// Event returns the OpenLineage event of the record.
+// The job is named after the application and the flow, and the lineage of the flow is reported in a run facet.
+func (r *Record) Event() *RunEvent {
+	event := &RunEvent{
+		EventType: CompleteEventType,
+		EventTime: r.Time,
+		Producer:  Producer,
+		SchemaURL: RunEventSchemaURL,
+		Run: Run{
+			RunID:  uuid.New().String(),
+			Facets: map[string]interface{}{FybrikFacet: newFacet(r.Lineage)},
+		},
+		Job:     Job{Namespace: JobNamespace, Name: r.Lineage.Application + "/" + r.Flow},
+		Inputs:  []DataSet{},
+		Outputs: []DataSet{},
+	}
+	if r.FlowType == taxonomy.StreamFlow {
+		event.EventType = RunningEventType
+	}
+	if source := r.Source.dataSet(); source != nil {
+		event.Inputs = append(event.Inputs, *source)
+	}
+	if destination := r.Destination.dataSet(); destination != nil {
+		event.Outputs = append(event.Outputs, *destination)
+	}
+	return event
+}
+
+// dataSet returns the OpenLineage dataset, named after the asset ID in the namespace of its connection type,
+// or nil if the dataset is unknown
+func (d *Dataset) dataSet() *DataSet {
+	if d.Connection == nil {
+		return nil
+	}
+	name := d.AssetID
+	if name == "" {
+		name = "endpoint"
+	}
+	return &DataSet{
+		Namespace: string(d.Connection.Name),
+		Name:      name,
+		Facets:    map[string]interface{}{ConnectionFacet: newFacet(d.Connection)},
+	}
+}
@@ -44,6 +44,10 @@ type CreateAssetRequest struct {
 	// +kubebuilder:validation:Optional
 	// The vault plugin path where the destination data credentials will be stored as kubernetes secrets
 	Credentials string `json:"credentials"`
+
+	// +kubebuilder:validation:Optional
+	// Lineage of the new asset, if it has been produced by Fybrik
+	Lineage *AssetLineage `json:"lineage,omitempty"`
 }
 
 type CreateAssetResponse struct {
@@ -81,3 +85,44 @@ type UpdateAssetResponse struct {
 	// The updation status
 	Status string `json:"status,omitempty"`
 }
+
+type ListAssetsRequest struct {
+	// +kubebuilder:validation:Optional
+	// The catalog whose assets are listed, e.g., a namespace in katalog.
+	// The assets of all catalogs are listed if not specified
+	CatalogID string `json:"catalogID,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// Only assets that have all the given tags with the given values are listed
+	Tags *taxonomy.Tags `json:"tags,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// Only assets of the given owner are listed
+	Owner string `json:"owner,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// Only assets in the given geography are listed
+	Geography string `json:"geography,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// Only assets with the given connection type are listed
+	ConnectionType taxonomy.ConnectionType `json:"connectionType,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	// The maximal number of assets to return. All the matching assets are returned if not specified
+	Limit int32 `json:"limit,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// The continue token returned in the previous response, to list the next page of assets
+	Continue string `json:"continue,omitempty"`
+}
+
+type ListAssetsResponse struct {
+	// The assets that match the request, ordered by their asset ID
+	Assets []AssetInfo `json:"assets"`
+
+	// +kubebuilder:validation:Optional
+	// A token to list the next page of assets. It is empty if there are no more assets to list
+	Continue string `json:"continue,omitempty"`
+}
@@ -0,0 +1,65 @@
+// Copyright 2023 IBM Corp.
+// SPDX-License-Identifier: Apache-2.0
+
+package datacatalog
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Matches returns true if the asset satisfies all the filters of the request.
+// Asset IDs are assumed to start with the catalog ID, followed by a slash.
+func (r *ListAssetsRequest) Matches(asset *AssetInfo) bool {
+	if r.CatalogID != "" && !strings.HasPrefix(string(asset.AssetID), r.CatalogID+"/") {
+		return false
+	}
+	metadata := &asset.ResourceMetadata
+	if r.Owner != "" && r.Owner != metadata.Owner {
+		return false
+	}
+	if r.Geography != "" && r.Geography != metadata.Geography {
+		return false
+	}
+	if r.ConnectionType != "" && r.ConnectionType != asset.Details.Connection.Name {
+		return false
+	}
+	if r.Tags == nil {
+		return true
+	}
+	for tag, value := range r.Tags.Items {
+		if metadata.Tags == nil {
+			return false
+		}
+		assetValue, found := metadata.Tags.Items[tag]
+		if !found || !reflect.DeepEqual(value, assetValue) {
+			return false
+		}
+	}
+	return true
+}
+
+// Page returns the assets that match the request, ordered by their asset ID, starting after the continue token
+// of the request and limited to the requested number of assets.
+// The continue token of the response is the ID of its last asset if more matching assets remain.
+func (r *ListAssetsRequest) Page(assets []AssetInfo) *ListAssetsResponse {
+	sorted := make([]AssetInfo, len(assets))
+	copy(sorted, assets)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].AssetID < sorted[j].AssetID })
+	response := &ListAssetsResponse{Assets: []AssetInfo{}}
+	for i := range sorted {
+		if r.Continue != "" && string(sorted[i].AssetID) <= r.Continue {
+			continue
+		}
+		if !r.Matches(&sorted[i]) {
+			continue
+		}
+		if r.Limit > 0 && len(response.Assets) == int(r.Limit) {
+			response.Continue = string(response.Assets[len(response.Assets)-1].AssetID)
+			break
+		}
+		response.Assets = append(response.Assets, sorted[i])
+	}
+	return response
+}
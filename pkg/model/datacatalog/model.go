@@ -27,12 +27,48 @@ type ResourceMetadata struct {
 	Columns []ResourceColumn `json:"columns,omitempty"`
 }
 
-// ResourceColumn represents a column in a tabular resource
+// AssetInfo describes an asset that is listed in the catalog, without its credentials
+type AssetInfo struct {
+	// Asset ID to be used in a FybrikApplication
+	AssetID taxonomy.AssetID `json:"assetID"`
+	// Asset metadata like asset name, owner, geography, etc
+	ResourceMetadata ResourceMetadata `json:"resourceMetadata"`
+	// Asset details like connection and data format
+	Details ResourceDetails `json:"details"`
+}
+
+// ColumnType is the type of the values of a column, following the Apache Arrow data types.
+// The values of list, map and struct columns are described by the nested fields of the column.
+// +kubebuilder:validation:Enum=null;bool;int8;int16;int32;int64;uint8;uint16;uint32;uint64;float16;float32;float64;decimal;string;binary;date;time;timestamp;duration;list;map;struct
+type ColumnType string
+
+// List of column types with nested fields
+const (
+	// List values are described by a single nested field
+	ListType ColumnType = "list"
+	// Map values are described by two nested fields, named key and value
+	MapType ColumnType = "map"
+	// Struct values are described by a nested field per struct field
+	StructType ColumnType = "struct"
+)
+
+// ResourceColumn represents a column in a tabular resource, or a field nested in a column
 type ResourceColumn struct {
 	// Name of the column
 	Name string `json:"name"`
 	// Tags associated with the column
 	Tags *taxonomy.Tags `json:"tags,omitempty"`
+	// +kubebuilder:validation:Optional
+	// Type of the column values
+	Type ColumnType `json:"type,omitempty"`
+	// +kubebuilder:validation:Optional
+	// Indicates whether the column may hold null values
+	Nullable *bool `json:"nullable,omitempty"`
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Schemaless
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// Fields nested in the values of list, map and struct columns
+	Fields []ResourceColumn `json:"fields,omitempty"`
 }
 
 // ResourceDetails includes asset connection details
@@ -42,3 +78,41 @@ type ResourceDetails struct {
 	// Data format
 	DataFormat taxonomy.DataFormat `json:"dataFormat,omitempty"`
 }
+
+// AssetLineage describes how an asset has been produced by Fybrik
+type AssetLineage struct {
+	// +kubebuilder:validation:Optional
+	// ID of the asset the data has been read from, if any
+	Source taxonomy.AssetID `json:"source,omitempty"`
+	// FybrikApplication that produced the asset, as namespace/name
+	Application string `json:"application"`
+	// +kubebuilder:validation:Optional
+	// UUID of the FybrikApplication
+	ApplicationUUID string `json:"applicationUUID,omitempty"`
+	// +kubebuilder:validation:Optional
+	// Steps of the data flow that produced the asset, in the order of their execution
+	Steps []LineageStep `json:"steps,omitempty"`
+	// +kubebuilder:validation:Optional
+	// IDs of the governance policies whose decisions applied to the data flow
+	Policies []string `json:"policies,omitempty"`
+	// +kubebuilder:validation:Optional
+	// IDs of the policy manager decisions that applied to the data flow
+	DecisionIDs []string `json:"decisionIDs,omitempty"`
+}
+
+// LineageStep is a step of a data flow that produced an asset
+type LineageStep struct {
+	// Name of the step
+	Name string `json:"name"`
+	// Module that performed the step
+	Module string `json:"module"`
+	// +kubebuilder:validation:Optional
+	// Capability of the module used in the step
+	Capability taxonomy.Capability `json:"capability,omitempty"`
+	// +kubebuilder:validation:Optional
+	// Cluster the module has been deployed on
+	Cluster string `json:"cluster,omitempty"`
+	// +kubebuilder:validation:Optional
+	// Governance actions applied on the data by the module
+	Actions []taxonomy.Action `json:"actions,omitempty"`
+}
@@ -0,0 +1,46 @@
+// Copyright 2023 IBM Corp.
+// SPDX-License-Identifier: Apache-2.0
+
+package datacatalog
+
+import "strings"
+
+// FieldPathSeparator separates the names of the nested fields in the path of a field, e.g., address.street
+const FieldPathSeparator = "."
+
+// HasSchema returns true if the types of the columns of the resource are known
+func (m *ResourceMetadata) HasSchema() bool {
+	for i := range m.Columns {
+		if m.Columns[i].Type != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// Column returns the column or nested field with the given path, or nil if it is not found.
+// The fields of list elements are referenced directly under the list column, e.g., transactions.amount.
+func (m *ResourceMetadata) Column(path string) *ResourceColumn {
+	names := strings.Split(path, FieldPathSeparator)
+	column := findColumn(m.Columns, names[0])
+	for _, name := range names[1:] {
+		if column == nil {
+			return nil
+		}
+		nested := findColumn(column.Fields, name)
+		if nested == nil && column.Type == ListType && len(column.Fields) == 1 {
+			nested = findColumn(column.Fields[0].Fields, name)
+		}
+		column = nested
+	}
+	return column
+}
+
+func findColumn(columns []ResourceColumn, name string) *ResourceColumn {
+	for i := range columns {
+		if columns[i].Name == name {
+			return &columns[i]
+		}
+	}
+	return nil
+}
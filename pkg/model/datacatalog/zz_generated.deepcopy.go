@@ -11,11 +11,65 @@ import (
 	"fybrik.io/fybrik/pkg/model/taxonomy"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AssetInfo) DeepCopyInto(out *AssetInfo) {
+	*out = *in
+	in.ResourceMetadata.DeepCopyInto(&out.ResourceMetadata)
+	in.Details.DeepCopyInto(&out.Details)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AssetInfo.
+func (in *AssetInfo) DeepCopy() *AssetInfo {
+	if in == nil {
+		return nil
+	}
+	out := new(AssetInfo)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AssetLineage) DeepCopyInto(out *AssetLineage) {
+	*out = *in
+	if in.Steps != nil {
+		in, out := &in.Steps, &out.Steps
+		*out = make([]LineageStep, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Policies != nil {
+		in, out := &in.Policies, &out.Policies
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.DecisionIDs != nil {
+		in, out := &in.DecisionIDs, &out.DecisionIDs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AssetLineage.
+func (in *AssetLineage) DeepCopy() *AssetLineage {
+	if in == nil {
+		return nil
+	}
+	out := new(AssetLineage)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CreateAssetRequest) DeepCopyInto(out *CreateAssetRequest) {
 	*out = *in
 	in.ResourceMetadata.DeepCopyInto(&out.ResourceMetadata)
 	in.Details.DeepCopyInto(&out.Details)
+	if in.Lineage != nil {
+		in, out := &in.Lineage, &out.Lineage
+		*out = new(AssetLineage)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CreateAssetRequest.
@@ -105,6 +159,70 @@ func (in *GetAssetResponse) DeepCopy() *GetAssetResponse {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LineageStep) DeepCopyInto(out *LineageStep) {
+	*out = *in
+	if in.Actions != nil {
+		in, out := &in.Actions, &out.Actions
+		*out = make([]taxonomy.Action, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LineageStep.
+func (in *LineageStep) DeepCopy() *LineageStep {
+	if in == nil {
+		return nil
+	}
+	out := new(LineageStep)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ListAssetsRequest) DeepCopyInto(out *ListAssetsRequest) {
+	*out = *in
+	if in.Tags != nil {
+		in, out := &in.Tags, &out.Tags
+		*out = new(taxonomy.Tags)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ListAssetsRequest.
+func (in *ListAssetsRequest) DeepCopy() *ListAssetsRequest {
+	if in == nil {
+		return nil
+	}
+	out := new(ListAssetsRequest)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ListAssetsResponse) DeepCopyInto(out *ListAssetsResponse) {
+	*out = *in
+	if in.Assets != nil {
+		in, out := &in.Assets, &out.Assets
+		*out = make([]AssetInfo, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ListAssetsResponse.
+func (in *ListAssetsResponse) DeepCopy() *ListAssetsResponse {
+	if in == nil {
+		return nil
+	}
+	out := new(ListAssetsResponse)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ResourceColumn) DeepCopyInto(out *ResourceColumn) {
 	*out = *in
@@ -113,6 +231,18 @@ func (in *ResourceColumn) DeepCopyInto(out *ResourceColumn) {
 		*out = new(taxonomy.Tags)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Nullable != nil {
+		in, out := &in.Nullable, &out.Nullable
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Fields != nil {
+		in, out := &in.Fields, &out.Fields
+		*out = make([]ResourceColumn, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceColumn.
@@ -20,3 +20,13 @@ type GetPolicyDecisionsResponse struct {
 	// Result of policy evaluation
 	Result []ResultItem `json:"result"`
 }
+
+// GetPolicyDecisionsBatchRequest holds several policy decision requests that are evaluated in a single call
+type GetPolicyDecisionsBatchRequest struct {
+	Requests []GetPolicyDecisionsRequest `json:"requests"`
+}
+
+// GetPolicyDecisionsBatchResponse holds the responses to a batch request, in the order of the requests
+type GetPolicyDecisionsBatchResponse struct {
+	Responses []GetPolicyDecisionsResponse `json:"responses"`
+}
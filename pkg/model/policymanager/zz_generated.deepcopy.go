@@ -11,6 +11,50 @@ import (
 	"fybrik.io/fybrik/pkg/model/datacatalog"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GetPolicyDecisionsBatchRequest) DeepCopyInto(out *GetPolicyDecisionsBatchRequest) {
+	*out = *in
+	if in.Requests != nil {
+		in, out := &in.Requests, &out.Requests
+		*out = make([]GetPolicyDecisionsRequest, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GetPolicyDecisionsBatchRequest.
+func (in *GetPolicyDecisionsBatchRequest) DeepCopy() *GetPolicyDecisionsBatchRequest {
+	if in == nil {
+		return nil
+	}
+	out := new(GetPolicyDecisionsBatchRequest)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GetPolicyDecisionsBatchResponse) DeepCopyInto(out *GetPolicyDecisionsBatchResponse) {
+	*out = *in
+	if in.Responses != nil {
+		in, out := &in.Responses, &out.Responses
+		*out = make([]GetPolicyDecisionsResponse, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GetPolicyDecisionsBatchResponse.
+func (in *GetPolicyDecisionsBatchResponse) DeepCopy() *GetPolicyDecisionsBatchResponse {
+	if in == nil {
+		return nil
+	}
+	out := new(GetPolicyDecisionsBatchResponse)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GetPolicyDecisionsRequest) DeepCopyInto(out *GetPolicyDecisionsRequest) {
 	*out = *in
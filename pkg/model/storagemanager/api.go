@@ -36,3 +36,17 @@ type GetSupportedStorageTypesResponse struct {
 	// connection types supported by StorageManager for storage allocation/deletion
 	ConnectionTypes []taxonomy.ConnectionType `json:"connectionTypes"`
 }
+
+type ListStorageRequest struct {
+	// Type of the storage account, e.g., s3
+	AccountType taxonomy.ConnectionType `json:"accountType"`
+	// Account properties, e.g., endpoint
+	AccountProperties taxonomy.StorageAccountProperties `json:"accountProperties"`
+	// Reference to the secret with credentials
+	Secret taxonomy.SecretRef `json:"secret,omitempty"`
+}
+
+type ListStorageResponse struct {
+	// Storage allocated by the storage manager in the account
+	Storage []AllocatedStorage `json:"storage"`
+}
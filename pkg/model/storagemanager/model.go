@@ -3,6 +3,10 @@
 
 package storagemanager
 
+import (
+	"fybrik.io/fybrik/pkg/model/taxonomy"
+)
+
 // Details of the owner application
 type ApplicationDetails struct {
 	// Application name
@@ -17,6 +21,9 @@ type ApplicationDetails struct {
 // The current implementation includes only a name provided in the write flow for a new asset
 type DatasetDetails struct {
 	Name string `json:"name"`
+	// Persistent indicates that the dataset is registered in a catalog, thus its storage is kept
+	// after the owner application is deleted
+	Persistent bool `json:"persistent,omitempty"`
 }
 
 // Configuration options
@@ -32,3 +39,17 @@ type Options struct {
 	DatasetProperties DatasetDetails     `json:"datasetProperties"`
 	ConfigurationOpts ConfigOptions      `json:"configurationOpts"`
 }
+
+// Storage allocated by the storage manager, as found in a storage account
+type AllocatedStorage struct {
+	// Name of the storage, e.g., a bucket or a topic
+	Name string `json:"name"`
+	// Connection object with the properties that identify the storage in the account
+	Connection taxonomy.Connection `json:"connection"`
+	// Details of the application the storage has been allocated for
+	AppDetails ApplicationDetails `json:"appDetails"`
+	// Persistent storage is kept after the owner application is deleted
+	Persistent bool `json:"persistent,omitempty"`
+	// Creation time of the storage in RFC 3339 format, if known
+	CreationTime string `json:"creationTime,omitempty"`
+}
@@ -0,0 +1,159 @@
+// Copyright 2023 IBM Corp.
+// SPDX-License-Identifier: Apache-2.0
+
+package taxonomy
+
+import (
+	"encoding/json"
+
+	"github.com/mohae/deepcopy"
+
+	"fybrik.io/fybrik/pkg/serde"
+)
+
+// ActionScope indicates which part of a dataset an action applies to
+// +kubebuilder:validation:Enum=dataset;column;row
+type ActionScope string
+
+const (
+	// DatasetScope indicates an action that applies to the whole dataset, e.g., encryption
+	DatasetScope ActionScope = "dataset"
+
+	// ColumnScope indicates an action that applies to a list of columns, e.g., masking of columns
+	ColumnScope ActionScope = "column"
+
+	// RowScope indicates an action that filters the rows of a dataset according to a predicate expression
+	RowScope ActionScope = "row"
+)
+
+const (
+	// ColumnsKey is the property of a column-scoped action that lists the columns it applies to
+	ColumnsKey = "columns"
+
+	// PredicateKey is the property of a row filter that holds the predicate expression.
+	// Rows that satisfy the predicate are kept.
+	PredicateKey = "predicate"
+)
+
+// NewColumnAction returns an action that applies to the given columns
+func NewColumnAction(name ActionName, columns []string) Action {
+	return Action{Name: name, AdditionalProperties: serde.Properties{Items: map[string]interface{}{
+		ColumnsKey: columns,
+	}}}
+}
+
+// NewRowFilterAction returns an action that keeps the rows satisfying the given predicate expression
+func NewRowFilterAction(name ActionName, predicate string) Action {
+	return Action{Name: name, AdditionalProperties: serde.Properties{Items: map[string]interface{}{
+		PredicateKey: predicate,
+	}}}
+}
+
+// Scope returns the scope of the action: row if it has a predicate, column if it has columns, and dataset otherwise
+func (o *Action) Scope() ActionScope {
+	switch {
+	case o.Predicate() != "":
+		return RowScope
+	case len(o.Columns()) > 0:
+		return ColumnScope
+	default:
+		return DatasetScope
+	}
+}
+
+// Columns returns the columns a column-scoped action applies to
+func (o *Action) Columns() []string {
+	var columns []string
+	switch value := o.property(ColumnsKey).(type) {
+	case []string:
+		columns = value
+	case []interface{}:
+		for _, column := range value {
+			if name, ok := column.(string); ok {
+				columns = append(columns, name)
+			}
+		}
+	}
+	return columns
+}
+
+// Predicate returns the predicate expression of a row filter, or an empty string if the action does not filter rows
+func (o *Action) Predicate() string {
+	predicate, _ := o.property(PredicateKey).(string)
+	return predicate
+}
+
+// Encode returns the JSON encoding of the action name and properties, which identifies actions with equal properties.
+// The properties of an action are decoded from JSON, hence they can always be encoded back.
+func (o *Action) Encode() string {
+	content, _ := json.Marshal(o)
+	return string(content)
+}
+
+// properties of an action are either specified next to its name, or nested under a property named after the action
+func (o *Action) properties() map[string]interface{} {
+	if nested, ok := o.AdditionalProperties.Items[string(o.Name)].(map[string]interface{}); ok {
+		return nested
+	}
+	return o.AdditionalProperties.Items
+}
+
+func (o *Action) property(key string) interface{} {
+	return o.properties()[key]
+}
+
+// MergeActions merges column-scoped actions that have the same name and properties, except for their columns,
+// into a single action that applies to all their columns. Duplicate actions are removed.
+// The order of the actions is preserved.
+func MergeActions(actions []Action) []Action {
+	if len(actions) < 2 {
+		return actions
+	}
+	merged := []Action{}
+	index := map[string]int{}
+	for i := range actions {
+		key := mergeKey(&actions[i])
+		pos, found := index[key]
+		if !found {
+			index[key] = len(merged)
+			merged = append(merged, actions[i])
+			continue
+		}
+		if actions[i].Scope() != ColumnScope {
+			continue
+		}
+		action := Action{Name: merged[pos].Name}
+		action.AdditionalProperties = *merged[pos].AdditionalProperties.DeepCopy()
+		action.properties()[ColumnsKey] = unionColumns(merged[pos].Columns(), actions[i].Columns())
+		merged[pos] = action
+	}
+	return merged
+}
+
+// mergeKey identifies actions that can be merged: the name and the properties of an action except for its columns
+func mergeKey(action *Action) string {
+	key := action
+	if action.Scope() == ColumnScope {
+		properties, _ := deepcopy.Copy(action.AdditionalProperties.Items).(map[string]interface{})
+		key = &Action{Name: action.Name, AdditionalProperties: serde.Properties{Items: properties}}
+		delete(key.properties(), ColumnsKey)
+	}
+	return string(action.Scope()) + "/" + key.Encode()
+}
+
+func unionColumns(columns, other []string) []string {
+	union := append([]string{}, columns...)
+	for _, column := range other {
+		found := false
+		for _, existing := range union {
+			if existing == column {
+				found = true
+				break
+			}
+		}
+		if !found {
+			union = append(union, column)
+		}
+	}
+	return union
+}
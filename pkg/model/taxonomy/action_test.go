@@ -0,0 +1,93 @@
+// Copyright 2023 IBM Corp.
+// SPDX-License-Identifier: Apache-2.0
+
+package taxonomy
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/onsi/gomega"
+)
+
+func decodeAction(g *gomega.WithT, content string) *Action {
+	action := &Action{}
+	g.Expect(json.Unmarshal([]byte(content), action)).To(gomega.Succeed())
+	return action
+}
+
+func TestActionScope(t *testing.T) {
+	t.Parallel()
+	g := gomega.NewGomegaWithT(t)
+
+	g.Expect(decodeAction(g, `{"name": "Encrypt"}`).Scope()).To(gomega.Equal(DatasetScope))
+	g.Expect(decodeAction(g, `{"name": "Redact", "columns": []}`).Scope()).To(gomega.Equal(DatasetScope))
+	g.Expect(decodeAction(g, `{"name": "Redact", "columns": ["name", "ssn"]}`).Scope()).To(gomega.Equal(ColumnScope))
+	g.Expect(decodeAction(g, `{"name": "Filter", "predicate": "age > 18"}`).Scope()).To(gomega.Equal(RowScope))
+	// a predicate takes precedence over columns
+	g.Expect(decodeAction(g, `{"name": "Filter", "predicate": "age > 18", "columns": ["age"]}`).Scope()).
+		To(gomega.Equal(RowScope))
+}
+
+func TestNestedActionProperties(t *testing.T) {
+	t.Parallel()
+	g := gomega.NewGomegaWithT(t)
+
+	action := decodeAction(g, `{"name": "Redact", "Redact": {"columns": ["name", "ssn"], "symbol": "#"}}`)
+	g.Expect(action.Scope()).To(gomega.Equal(ColumnScope))
+	g.Expect(action.Columns()).To(gomega.Equal([]string{"name", "ssn"}))
+
+	action = decodeAction(g, `{"name": "Filter", "Filter": {"predicate": "age > 18"}}`)
+	g.Expect(action.Scope()).To(gomega.Equal(RowScope))
+	g.Expect(action.Predicate()).To(gomega.Equal("age > 18"))
+
+	// properties nested under another name are not the properties of the action
+	action = decodeAction(g, `{"name": "Redact", "Mask": {"columns": ["name"]}}`)
+	g.Expect(action.Scope()).To(gomega.Equal(DatasetScope))
+}
+
+func TestMergeActions(t *testing.T) {
+	t.Parallel()
+	g := gomega.NewGomegaWithT(t)
+
+	actions := []Action{
+		*decodeAction(g, `{"name": "Redact", "columns": ["name", "ssn"]}`),
+		*decodeAction(g, `{"name": "Encrypt"}`),
+		NewColumnAction("Redact", []string{"ssn", "address"}),
+		*decodeAction(g, `{"name": "Encrypt"}`),
+		// differs from the first action in a property other than its columns
+		*decodeAction(g, `{"name": "Redact", "columns": ["phone"], "symbol": "#"}`),
+		NewRowFilterAction("Filter", "age > 18"),
+		NewRowFilterAction("Filter", "country = 'IL'"),
+	}
+	merged := MergeActions(actions)
+	g.Expect(merged).To(gomega.HaveLen(5))
+	g.Expect(merged[0].Name).To(gomega.Equal(ActionName("Redact")))
+	g.Expect(merged[0].Columns()).To(gomega.Equal([]string{"name", "ssn", "address"}))
+	g.Expect(merged[1].Encode()).To(gomega.Equal(`{"name":"Encrypt"}`))
+	g.Expect(merged[2].Columns()).To(gomega.Equal([]string{"phone"}))
+	g.Expect(merged[3].Predicate()).To(gomega.Equal("age > 18"))
+	g.Expect(merged[4].Predicate()).To(gomega.Equal("country = 'IL'"))
+	// the merged actions are not changed
+	g.Expect(actions[0].Columns()).To(gomega.Equal([]string{"name", "ssn"}))
+
+	// the columns of nested properties are merged, keeping the other properties
+	merged = MergeActions([]Action{
+		*decodeAction(g, `{"name": "Redact", "Redact": {"columns": ["name"], "symbol": "#"}}`),
+		*decodeAction(g, `{"name": "Redact", "Redact": {"columns": ["ssn", "name"], "symbol": "#"}}`),
+	})
+	g.Expect(merged).To(gomega.HaveLen(1))
+	g.Expect(merged[0].Encode()).To(gomega.Equal(`{"Redact":{"columns":["name","ssn"],"symbol":"#"},"name":"Redact"}`))
+}
+
+func TestEncodeAction(t *testing.T) {
+	t.Parallel()
+	g := gomega.NewGomegaWithT(t)
+
+	// the encoding does not depend on the order of the properties
+	first := decodeAction(g, `{"name": "Filter", "predicate": "age > 18", "engine": "sql"}`)
+	second := decodeAction(g, `{"engine": "sql", "predicate": "age > 18", "name": "Filter"}`)
+	g.Expect(first.Encode()).To(gomega.Equal(second.Encode()))
+	third := NewRowFilterAction("Filter", "age > 21")
+	g.Expect(first.Encode()).NotTo(gomega.Equal(third.Encode()))
+}
@@ -19,8 +19,8 @@ type PluginType string
 // Action names should be defined in additional taxonomy layers
 type ActionName string
 
-// DataFlow indicates how the data is used by the workload, e.g., it is being read, copied, written or deleted
-// +kubebuilder:validation:Enum=read;write;delete;copy
+// DataFlow indicates how the data is used by the workload, e.g., it is being read, copied, written, deleted or streamed
+// +kubebuilder:validation:Enum=read;write;delete;copy;stream
 type DataFlow string
 
 const (
@@ -35,6 +35,9 @@ const (
 
 	// CopyFlow indicates a data set is being copied
 	CopyFlow DataFlow = "copy"
+
+	// StreamFlow indicates a data set is being continuously ingested from a stream
+	StreamFlow DataFlow = "stream"
 )
 
 // Action to be performed on the data, e.g., masking
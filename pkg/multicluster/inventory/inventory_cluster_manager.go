@@ -0,0 +1,256 @@
+// Copyright 2023 IBM Corp.
+// SPDX-License-Identifier: Apache-2.0
+
+package inventory
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"emperror.dev/errors"
+	"github.com/rs/zerolog"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	app "fybrik.io/fybrik/manager/apis/app/v1beta1"
+	"fybrik.io/fybrik/pkg/environment"
+	"fybrik.io/fybrik/pkg/logging"
+	"fybrik.io/fybrik/pkg/multicluster"
+)
+
+// FailureSubscriber is notified about clusters that have been unreachable for longer than the failover delay
+type FailureSubscriber interface {
+	OnClusterFailure(cluster string, reason string)
+}
+
+// ClusterManager keeps the inventory of the clusters as FybrikCluster resources.
+// The clusters are found by the underlying cluster manager, e.g. Razee, and their metadata is taken from their
+// FybrikClusters, so that every cluster lister reports the attributes that the administrator has set.
+// A cluster is found unreachable when the underlying cluster manager does not return it any more. Only the kubeconfig
+// cluster manager checks the connectivity of the clusters: Razee returns the registered clusters whether they are
+// reachable or not, and the local cluster manager returns the cluster that the manager runs on.
+type ClusterManager struct {
+	multicluster.ClusterManager
+	Client client.Client
+	Log    zerolog.Logger
+	// FailoverDelay is how long a cluster can be unreachable before the subscribers are notified about its failure
+	FailoverDelay time.Duration
+
+	subscribers []FailureSubscriber
+	// the start of the outage of each cluster whose failure has been notified
+	notified map[string]time.Time
+}
+
+// NewClusterManager returns a cluster manager that reports the clusters of the given cluster manager with the metadata
+// of their FybrikCluster resources
+func NewClusterManager(cm multicluster.ClusterManager, cl client.Client) *ClusterManager {
+	return &ClusterManager{
+		ClusterManager: cm,
+		Client:         cl,
+		Log:            logging.LogInit(logging.CONTROLLER, "ClusterInventory"),
+		FailoverDelay:  environment.GetClusterFailoverDelay(),
+		notified:       map[string]time.Time{},
+	}
+}
+
+// Subscribe adds a subscriber that is notified once about each outage of a cluster that has failed.
+// It should be called before the heartbeat is started.
+func (cm *ClusterManager) Subscribe(subscriber FailureSubscriber) {
+	cm.subscribers = append(cm.subscribers, subscriber)
+}
+
+// Unwrap returns the underlying cluster manager
+func (cm *ClusterManager) Unwrap() multicluster.ClusterManager {
+	return cm.ClusterManager
+}
+
+// GetClusters returns the clusters found by the underlying cluster manager.
+// The fields that are set in the FybrikCluster of a cluster take precedence over the metadata of the cluster manager.
+// Clusters that have been found unreachable are not returned until a heartbeat finds them reachable again,
+// so that they are not used for new plans.
+func (cm *ClusterManager) GetClusters() ([]multicluster.Cluster, error) {
+	clusters, err := cm.ClusterManager.GetClusters()
+	if err != nil {
+		return nil, err
+	}
+	registered, err := cm.registeredClusters()
+	if err != nil {
+		return nil, err
+	}
+	healthy := make([]multicluster.Cluster, 0, len(clusters))
+	for i := range clusters {
+		fybrikCluster, found := registered[clusters[i].Name]
+		if !found {
+			healthy = append(healthy, clusters[i])
+			continue
+		}
+		if fybrikCluster.Status.UnreachableSince != nil {
+			cm.Log.Debug().Str(logging.CLUSTER, clusters[i].Name).Msg("Skipping an unreachable cluster")
+			continue
+		}
+		clusters[i].Metadata = metadataOf(fybrikCluster, &clusters[i].Metadata)
+		healthy = append(healthy, clusters[i])
+	}
+	return healthy, nil
+}
+
+// Heartbeat registers a FybrikCluster for each new cluster found by the underlying cluster manager, and updates the
+// reachability of all the FybrikClusters. The subscribers are notified about the clusters that have been unreachable
+// for longer than the failover delay, once per outage.
+func (cm *ClusterManager) Heartbeat(now time.Time) error {
+	clusters, err := cm.ClusterManager.GetClusters()
+	if err != nil {
+		return err
+	}
+	registered, err := cm.registeredClusters()
+	if err != nil {
+		return err
+	}
+	reachable := map[string]*multicluster.Cluster{}
+	for i := range clusters {
+		cluster := &clusters[i]
+		reachable[cluster.Name] = cluster
+		if _, found := registered[cluster.Name]; found {
+			continue
+		}
+		fybrikCluster, err := cm.register(cluster)
+		if err != nil {
+			cm.Log.Error().Err(err).Str(logging.CLUSTER, cluster.Name).Msg("could not register the cluster")
+			continue
+		}
+		registered[cluster.Name] = fybrikCluster
+	}
+	for name, fybrikCluster := range registered {
+		if err := cm.updateStatus(fybrikCluster, reachable[name], now); err != nil {
+			cm.Log.Error().Err(err).Str(logging.CLUSTER, name).Msg("could not update the cluster status")
+			continue
+		}
+		since := fybrikCluster.Status.UnreachableSince
+		if since == nil {
+			delete(cm.notified, name)
+			continue
+		}
+		if notified, found := cm.notified[name]; found && notified.Equal(since.Time) {
+			continue
+		}
+		if now.Sub(since.Time) >= cm.FailoverDelay {
+			cm.notifyFailure(name, since.Time)
+			cm.notified[name] = since.Time
+		}
+	}
+	return nil
+}
+
+// notifyFailure notifies the subscribers that a cluster has failed
+func (cm *ClusterManager) notifyFailure(cluster string, since time.Time) {
+	reason := fmt.Sprintf("cluster %s has been unreachable since %s", cluster, since.UTC().Format(time.RFC3339))
+	cm.Log.Warn().Str(logging.CLUSTER, cluster).Msg(reason)
+	for _, subscriber := range cm.subscribers {
+		subscriber.OnClusterFailure(cluster, reason)
+	}
+}
+
+// PeriodicHeartbeat returns a runnable that updates the inventory of the clusters at the given interval
+func (cm *ClusterManager) PeriodicHeartbeat(interval time.Duration) manager.RunnableFunc {
+	return func(ctx context.Context) error {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if err := cm.Heartbeat(time.Now()); err != nil {
+				cm.Log.Error().Err(err).Msg("Cluster heartbeat failed")
+			}
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-ticker.C:
+			}
+		}
+	}
+}
+
+// registeredClusters returns the FybrikClusters by their names
+func (cm *ClusterManager) registeredClusters() (map[string]*app.FybrikCluster, error) {
+	list := &app.FybrikClusterList{}
+	if err := cm.Client.List(context.Background(), list); err != nil {
+		return nil, errors.Wrap(err, "could not list the FybrikClusters")
+	}
+	registered := make(map[string]*app.FybrikCluster, len(list.Items))
+	for i := range list.Items {
+		registered[list.Items[i].Name] = &list.Items[i]
+	}
+	return registered, nil
+}
+
+// register creates a FybrikCluster with the metadata that the cluster manager reports for the cluster
+func (cm *ClusterManager) register(cluster *multicluster.Cluster) (*app.FybrikCluster, error) {
+	fybrikCluster := &app.FybrikCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: cluster.Name},
+		Spec: app.FybrikClusterSpec{
+			Region:        cluster.Metadata.Region,
+			Zone:          cluster.Metadata.Zone,
+			VaultAuthPath: cluster.Metadata.VaultAuthPath,
+			Labels:        cluster.Metadata.Labels,
+			Attributes:    cluster.Metadata.Attributes,
+		},
+	}
+	cm.Log.Info().Str(logging.CLUSTER, cluster.Name).Msg("Registering a new cluster")
+	if err := cm.Client.Create(context.Background(), fybrikCluster); err != nil {
+		if !apierrors.IsAlreadyExists(err) {
+			return nil, err
+		}
+		if err := cm.Client.Get(context.Background(), client.ObjectKeyFromObject(fybrikCluster), fybrikCluster); err != nil {
+			return nil, err
+		}
+	}
+	return fybrikCluster, nil
+}
+
+// updateStatus updates the status of a FybrikCluster, the cluster is nil if it has not been found by the cluster manager
+func (cm *ClusterManager) updateStatus(fybrikCluster *app.FybrikCluster, cluster *multicluster.Cluster, now time.Time) error {
+	status := fybrikCluster.Status.DeepCopy()
+	status.Reachable = cluster != nil
+	if cluster != nil {
+		status.LastHeartbeatTime = &metav1.Time{Time: now}
+		status.FybrikVersion = cluster.Metadata.FybrikVersion
+		status.UnreachableSince = nil
+	} else if status.UnreachableSince == nil {
+		status.UnreachableSince = &metav1.Time{Time: now}
+	}
+	if equality.Semantic.DeepEqual(status, &fybrikCluster.Status) {
+		return nil
+	}
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if err := cm.Client.Get(context.Background(), client.ObjectKeyFromObject(fybrikCluster), fybrikCluster); err != nil {
+			return client.IgnoreNotFound(err)
+		}
+		fybrikCluster.Status = *status
+		return cm.Client.Status().Update(context.Background(), fybrikCluster)
+	})
+}
+
+// metadataOf returns the metadata of a cluster, where the fields that are set in its FybrikCluster take precedence
+func metadataOf(fybrikCluster *app.FybrikCluster, reported *multicluster.ClusterMetadata) multicluster.ClusterMetadata {
+	metadata := *reported
+	spec := &fybrikCluster.Spec
+	if spec.Region != "" {
+		metadata.Region = spec.Region
+	}
+	if spec.Zone != "" {
+		metadata.Zone = spec.Zone
+	}
+	if spec.VaultAuthPath != "" {
+		metadata.VaultAuthPath = spec.VaultAuthPath
+	}
+	if spec.Labels != nil {
+		metadata.Labels = spec.Labels
+	}
+	if spec.Attributes != nil {
+		metadata.Attributes = spec.Attributes
+	}
+	return metadata
+}
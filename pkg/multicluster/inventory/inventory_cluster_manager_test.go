@@ -0,0 +1,144 @@
+// Copyright 2023 IBM Corp.
+// SPDX-License-Identifier: Apache-2.0
+
+package inventory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	app "fybrik.io/fybrik/manager/apis/app/v1beta1"
+	"fybrik.io/fybrik/pkg/multicluster"
+)
+
+// clusterLister is a cluster manager that finds a fixed list of clusters
+type clusterLister struct {
+	multicluster.ClusterManager
+	clusters []multicluster.Cluster
+}
+
+func (l *clusterLister) GetClusters() ([]multicluster.Cluster, error) {
+	clusters := make([]multicluster.Cluster, len(l.clusters))
+	copy(clusters, l.clusters)
+	return clusters, nil
+}
+
+// failures records the notifications about the clusters that have failed
+type failures map[string][]string
+
+func (f failures) OnClusterFailure(cluster, reason string) {
+	f[cluster] = append(f[cluster], reason)
+}
+
+func TestClusterInventory(t *testing.T) {
+	t.Parallel()
+	g := gomega.NewGomegaWithT(t)
+	scheme := runtime.NewScheme()
+	g.Expect(app.AddToScheme(scheme)).To(gomega.Succeed())
+	// the administrator has set the attributes of cluster2 and has registered a cluster that no longer exists
+	cl := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&app.FybrikCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster2"},
+			Spec: app.FybrikClusterSpec{Region: "neverland", Labels: map[string]string{"compliance": "pci"},
+				Attributes: map[string]string{"cost": "5"}},
+		},
+		&app.FybrikCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "removed"},
+			Spec:       app.FybrikClusterSpec{Region: "theshire"},
+			Status:     app.FybrikClusterStatus{Reachable: true},
+		},
+	).Build()
+	lister := &clusterLister{clusters: []multicluster.Cluster{
+		{Name: "cluster1", Metadata: multicluster.ClusterMetadata{Region: "theshire", Zone: "hobbiton", FybrikVersion: "1.3.0"}},
+		{Name: "cluster2", Metadata: multicluster.ClusterMetadata{Region: "mordor", VaultAuthPath: "kubernetes",
+			FybrikVersion: "1.2.0"}},
+	}}
+	inventory := NewClusterManager(lister, cl)
+
+	// the metadata of the FybrikCluster takes precedence
+	clusters, err := inventory.GetClusters()
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(clusters).To(gomega.ConsistOf(
+		lister.clusters[0],
+		multicluster.Cluster{Name: "cluster2", Metadata: multicluster.ClusterMetadata{Region: "neverland", VaultAuthPath: "kubernetes",
+			FybrikVersion: "1.2.0", Labels: map[string]string{"compliance": "pci"}, Attributes: map[string]string{"cost": "5"}}},
+	))
+
+	// new clusters are registered and the status of all the clusters is updated
+	now := time.Date(2023, 3, 1, 12, 0, 0, 0, time.UTC)
+	g.Expect(inventory.Heartbeat(now)).To(gomega.Succeed())
+	registered := &app.FybrikCluster{}
+	g.Expect(cl.Get(context.Background(), client.ObjectKey{Name: "cluster1"}, registered)).To(gomega.Succeed())
+	g.Expect(registered.Spec).To(gomega.Equal(app.FybrikClusterSpec{Region: "theshire", Zone: "hobbiton"}))
+	g.Expect(registered.Status.Reachable).To(gomega.BeTrue())
+	g.Expect(registered.Status.FybrikVersion).To(gomega.Equal("1.3.0"))
+	g.Expect(registered.Status.LastHeartbeatTime.Time).To(gomega.BeTemporally("==", now))
+
+	g.Expect(cl.Get(context.Background(), client.ObjectKey{Name: "cluster2"}, registered)).To(gomega.Succeed())
+	g.Expect(registered.Spec.Region).To(gomega.Equal("neverland"))
+	g.Expect(registered.Status.Reachable).To(gomega.BeTrue())
+	g.Expect(registered.Status.FybrikVersion).To(gomega.Equal("1.2.0"))
+
+	g.Expect(cl.Get(context.Background(), client.ObjectKey{Name: "removed"}, registered)).To(gomega.Succeed())
+	g.Expect(registered.Status.Reachable).To(gomega.BeFalse())
+	g.Expect(registered.Status.LastHeartbeatTime).To(gomega.BeNil())
+	g.Expect(registered.Status.UnreachableSince.Time).To(gomega.BeTemporally("==", now))
+}
+
+func TestClusterFailover(t *testing.T) {
+	t.Parallel()
+	g := gomega.NewGomegaWithT(t)
+	scheme := runtime.NewScheme()
+	g.Expect(app.AddToScheme(scheme)).To(gomega.Succeed())
+	cl := fake.NewClientBuilder().WithScheme(scheme).Build()
+	cluster1 := multicluster.Cluster{Name: "cluster1", Metadata: multicluster.ClusterMetadata{Region: "theshire"}}
+	cluster2 := multicluster.Cluster{Name: "cluster2", Metadata: multicluster.ClusterMetadata{Region: "mordor"}}
+	lister := &clusterLister{clusters: []multicluster.Cluster{cluster1, cluster2}}
+	inventory := NewClusterManager(lister, cl)
+	inventory.FailoverDelay = 3 * time.Minute
+	failed := failures{}
+	inventory.Subscribe(failed)
+	now := time.Date(2023, 3, 1, 12, 0, 0, 0, time.UTC)
+	g.Expect(inventory.Heartbeat(now)).To(gomega.Succeed())
+
+	// an unreachable cluster is not used for new plans, but it fails only after the failover delay
+	lister.clusters = []multicluster.Cluster{cluster1}
+	g.Expect(inventory.Heartbeat(now.Add(time.Minute))).To(gomega.Succeed())
+	g.Expect(failed).To(gomega.BeEmpty())
+	lister.clusters = []multicluster.Cluster{cluster1, cluster2}
+	clusters, err := inventory.GetClusters()
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(clusters).To(gomega.ConsistOf(cluster1))
+	lister.clusters = []multicluster.Cluster{cluster1}
+	g.Expect(inventory.Heartbeat(now.Add(2 * time.Minute))).To(gomega.Succeed())
+	g.Expect(failed).To(gomega.BeEmpty())
+	g.Expect(inventory.Heartbeat(now.Add(4 * time.Minute))).To(gomega.Succeed())
+	outage := "cluster cluster2 has been unreachable since 2023-03-01T12:01:00Z"
+	g.Expect(failed).To(gomega.Equal(failures{"cluster2": {outage}}))
+	// the subscribers are notified once per outage
+	g.Expect(inventory.Heartbeat(now.Add(5 * time.Minute))).To(gomega.Succeed())
+	g.Expect(failed).To(gomega.Equal(failures{"cluster2": {outage}}))
+
+	// a cluster that is reachable again is used for new plans after the next heartbeat
+	lister.clusters = []multicluster.Cluster{cluster1, cluster2}
+	g.Expect(inventory.Heartbeat(now.Add(6 * time.Minute))).To(gomega.Succeed())
+	clusters, err = inventory.GetClusters()
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(clusters).To(gomega.ConsistOf(cluster1, cluster2))
+	registered := &app.FybrikCluster{}
+	g.Expect(cl.Get(context.Background(), client.ObjectKey{Name: "cluster2"}, registered)).To(gomega.Succeed())
+	g.Expect(registered.Status.UnreachableSince).To(gomega.BeNil())
+
+	// a new outage is notified again
+	lister.clusters = []multicluster.Cluster{cluster1}
+	g.Expect(inventory.Heartbeat(now.Add(7 * time.Minute))).To(gomega.Succeed())
+	g.Expect(inventory.Heartbeat(now.Add(10 * time.Minute))).To(gomega.Succeed())
+	g.Expect(failed).To(gomega.Equal(failures{"cluster2": {outage, "cluster cluster2 has been unreachable since 2023-03-01T12:07:00Z"}}))
+}
@@ -0,0 +1,453 @@
+// Copyright 2023 IBM Corp.
+// SPDX-License-Identifier: Apache-2.0
+
+package kubeconfig
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"emperror.dev/errors"
+	"github.com/rs/zerolog"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/rest"
+	toolscache "k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/clientcmd"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	app "fybrik.io/fybrik/manager/apis/app/v1beta1"
+	"fybrik.io/fybrik/pkg/environment"
+	"fybrik.io/fybrik/pkg/logging"
+	"fybrik.io/fybrik/pkg/multicluster"
+)
+
+const (
+	// KubeconfigLabel marks the secrets in the controller namespace that hold the kubeconfig of a cluster
+	KubeconfigLabel = "fybrik.io/cluster-kubeconfig"
+	// KubeconfigKey is the key of the kubeconfig in the secret data
+	KubeconfigKey = "kubeconfig"
+	// ClusterMetadataConfigMap is the name of the config map that holds the metadata of a cluster
+	ClusterMetadataConfigMap = "cluster-metadata"
+	// DefaultProbeInterval is how long the metadata of a cluster, or the failure to read it, is reused
+	// before the metadata is read again
+	DefaultProbeInterval = 30 * time.Second
+	// requestTimeout bounds the requests to the remote clusters, so that an unreachable cluster is detected
+	requestTimeout = 10 * time.Second
+)
+
+var (
+	scheme = runtime.NewScheme()
+)
+
+func init() {
+	_ = corev1.AddToScheme(scheme)
+	_ = app.AddToScheme(scheme)
+}
+
+// ClientFactory creates a client of a cluster from its rest configuration
+type ClientFactory func(config *rest.Config) (client.Client, error)
+
+// CacheFactory creates a cache of the blueprints of a cluster from its rest configuration
+type CacheFactory func(config *rest.Config) (cache.Cache, error)
+
+// remoteCluster is a cluster registered with a kubeconfig secret
+type remoteCluster struct {
+	// resourceVersion is the version of the secret that the client has been created from
+	resourceVersion string
+	config          *rest.Config
+	client          client.Client
+	// cache holds the blueprints of the cluster once they are watched
+	cache cache.Cache
+	// stop stops watching the blueprints of the cluster
+	stop context.CancelFunc
+
+	// probeMu guards the metadata of the cluster, which is read without holding the lock of the cluster manager
+	probeMu sync.Mutex
+	// cluster and probeErr are the result of the last attempt to read the metadata of the cluster at probed
+	cluster  multicluster.Cluster
+	probeErr error
+	probed   time.Time
+}
+
+// kubeconfigClusterManager accesses the clusters directly, with the kubeconfig secrets that are found in the controller namespace.
+// The local cluster is always registered, and it is accessed with the client of the manager.
+// Requests to the remote clusters are never sent while holding the lock, so that an unreachable cluster does not delay
+// the access to the other clusters.
+type kubeconfigClusterManager struct {
+	Client    client.Client
+	Reader    client.Reader
+	Namespace string
+	NewClient ClientFactory
+	NewCache  CacheFactory
+	Log       zerolog.Logger
+	// ProbeInterval is how long the metadata of a cluster is reused before it is read again
+	ProbeInterval time.Duration
+
+	mu sync.Mutex
+	// remotes holds the clients of the registered secrets by the secret name
+	remotes map[string]*remoteCluster
+	// clients holds the clients of the registered clusters by the cluster name
+	clients map[string]client.Client
+	// readers holds the blueprint caches of the watched clusters by the cluster name
+	readers map[string]client.Reader
+	// watchCtx and events are set once the blueprints are watched
+	watchCtx context.Context
+	events   chan<- event.GenericEvent
+}
+
+// GetClusters returns a list of registered clusters.
+// The secrets are listed on every call, so that clusters can be added and removed while the manager is running.
+// A cluster whose metadata cannot be read is not returned, so that it does not block the deployment on the other clusters.
+// The metadata is read again once the probe interval has passed or the secret of the cluster has changed.
+func (cm *kubeconfigClusterManager) GetClusters() ([]multicluster.Cluster, error) {
+	return cm.refresh()
+}
+
+func (cm *kubeconfigClusterManager) IsMultiClusterSetup() bool {
+	return true
+}
+
+// WatchBlueprints starts an informer of the blueprints in each remote cluster, which sends an event whenever a
+// blueprint is changed. The local cluster is not watched, since its blueprints are watched by the controller itself.
+func (cm *kubeconfigClusterManager) WatchBlueprints(ctx context.Context, events chan<- event.GenericEvent) error {
+	cm.mu.Lock()
+	if cm.events != nil {
+		cm.mu.Unlock()
+		return errors.New("the blueprints are already watched")
+	}
+	cm.watchCtx = ctx
+	cm.events = events
+	cm.mu.Unlock()
+	_, err := cm.refresh()
+	return err
+}
+
+// GetBlueprint returns a blueprint matching the given name, namespace and cluster details.
+// The blueprint is read from the cache of the cluster if its blueprints are watched.
+func (cm *kubeconfigClusterManager) GetBlueprint(cluster, namespace, name string) (*app.Blueprint, error) {
+	cl, err := cm.clientOf(cluster)
+	if err != nil {
+		return nil, err
+	}
+	key := client.ObjectKey{Name: name, Namespace: namespace}
+	blueprint := &app.Blueprint{}
+	if reader := cm.readerOf(cluster); reader != nil {
+		err = reader.Get(context.Background(), key, blueprint)
+		if !errors.As(err, new(*cache.ErrCacheNotStarted)) {
+			return blueprint, err
+		}
+	}
+	err = cl.Get(context.Background(), key, blueprint)
+	return blueprint, err
+}
+
+// CreateBlueprint creates a blueprint resource or updates an existing one
+func (cm *kubeconfigClusterManager) CreateBlueprint(cluster string, blueprint *app.Blueprint) error {
+	return cm.UpdateBlueprint(cluster, blueprint)
+}
+
+// UpdateBlueprint updates the given blueprint or creates a new one if it does not exist
+func (cm *kubeconfigClusterManager) UpdateBlueprint(cluster string, blueprint *app.Blueprint) error {
+	cl, err := cm.clientOf(cluster)
+	if err != nil {
+		return err
+	}
+	resource := &app.Blueprint{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      blueprint.Name,
+			Namespace: blueprint.Namespace,
+		},
+	}
+	_, err = ctrl.CreateOrUpdate(context.Background(), cl, resource, func() error {
+		resource.Spec = blueprint.Spec
+		resource.ObjectMeta.Finalizers = blueprint.ObjectMeta.Finalizers
+		resource.ObjectMeta.Labels = blueprint.ObjectMeta.Labels
+		resource.ObjectMeta.Annotations = blueprint.ObjectMeta.Annotations
+		return nil
+	})
+	return err
+}
+
+// DeleteBlueprint deletes the blueprint resource
+func (cm *kubeconfigClusterManager) DeleteBlueprint(cluster, namespace, name string) error {
+	cl, err := cm.clientOf(cluster)
+	if err != nil {
+		return err
+	}
+	blueprint := &app.Blueprint{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}
+	return cl.Delete(context.Background(), blueprint)
+}
+
+// clientOf returns the client of a registered cluster.
+// The secrets are listed again if the cluster is unknown, since it might have been registered recently.
+func (cm *kubeconfigClusterManager) clientOf(cluster string) (client.Client, error) {
+	if cl, found := cm.registeredClient(cluster); found {
+		return cl, nil
+	}
+	if _, err := cm.refresh(); err != nil {
+		return nil, err
+	}
+	if cl, found := cm.registeredClient(cluster); found {
+		return cl, nil
+	}
+	return nil, fmt.Errorf("unregistered cluster: %s", cluster)
+}
+
+func (cm *kubeconfigClusterManager) registeredClient(cluster string) (client.Client, bool) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	cl, found := cm.clients[cluster]
+	return cl, found
+}
+
+// readerOf returns the blueprint cache of a cluster, or nil if the blueprints of the cluster are not watched
+func (cm *kubeconfigClusterManager) readerOf(cluster string) client.Reader {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	return cm.readers[cluster]
+}
+
+// refresh lists the kubeconfig secrets, updates the clients of the clusters and returns the registered clusters.
+// The metadata of the clusters is read concurrently without holding the lock.
+func (cm *kubeconfigClusterManager) refresh() ([]multicluster.Cluster, error) {
+	secrets := &corev1.SecretList{}
+	if err := cm.Reader.List(context.Background(), secrets, client.InNamespace(cm.Namespace),
+		client.HasLabels{KubeconfigLabel}); err != nil {
+		return nil, errors.Wrap(err, "could not list the kubeconfig secrets")
+	}
+	remotes := cm.remoteClustersOf(secrets.Items)
+	metadata, errs := cm.clustersOf(remotes)
+
+	local := multicluster.Cluster{
+		Name: environment.GetLocalClusterName(),
+		Metadata: multicluster.ClusterMetadata{
+			Region:        environment.GetLocalRegion(),
+			Zone:          environment.GetLocalZone(),
+			VaultAuthPath: environment.GetLocalVaultAuthPath(),
+			FybrikVersion: environment.GetLocalFybrikVersion(),
+		},
+	}
+	clusters := []multicluster.Cluster{local}
+	clients := map[string]client.Client{local.Name: cm.Client}
+	readers := map[string]client.Reader{}
+	registered := map[string]*remoteCluster{}
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	for i := range remotes {
+		secret := &secrets.Items[i]
+		remote := remotes[i]
+		if remote == nil {
+			continue
+		}
+		// a concurrent refresh might have registered another client of the same secret
+		if existing, found := cm.remotes[secret.Name]; found && existing.resourceVersion == remote.resourceVersion {
+			remote = existing
+		}
+		registered[secret.Name] = remote
+		if errs[i] != nil {
+			cm.Log.Error().Err(errs[i]).Str(logging.NAME, secret.Name).Msg("could not get the cluster metadata")
+			continue
+		}
+		cluster := metadata[i]
+		if _, found := clients[cluster.Name]; found {
+			cm.Log.Warn().Str(logging.NAME, secret.Name).Msgf("cluster %s is already registered", cluster.Name)
+			continue
+		}
+		clusters = append(clusters, cluster)
+		clients[cluster.Name] = remote.client
+		if err := cm.watch(cluster.Name, remote); err != nil {
+			cm.Log.Error().Err(err).Str(logging.CLUSTER, cluster.Name).Msg("could not watch the blueprints of the cluster")
+		}
+		if remote.cache != nil {
+			readers[cluster.Name] = remote.cache
+		}
+	}
+	// stop watching the clusters whose secrets have been removed or changed
+	for name, remote := range cm.remotes {
+		if registered[name] != remote && remote.stop != nil {
+			remote.stop()
+		}
+	}
+	cm.remotes = registered
+	cm.clients = clients
+	cm.readers = readers
+	return clusters, nil
+}
+
+// watch starts an informer of the blueprints of a remote cluster, unless the blueprints are not watched yet or the
+// cluster is already watched. It must be called while holding the lock.
+func (cm *kubeconfigClusterManager) watch(cluster string, remote *remoteCluster) error {
+	if cm.events == nil || remote.cache != nil {
+		return nil
+	}
+	blueprints, err := cm.NewCache(remote.config)
+	if err != nil {
+		return err
+	}
+	informer, err := blueprints.GetInformer(cm.watchCtx, &app.Blueprint{})
+	if err != nil {
+		return err
+	}
+	ctx, stop := context.WithCancel(cm.watchCtx)
+	events := cm.events
+	notify := func(obj interface{}) {
+		if tombstone, ok := obj.(toolscache.DeletedFinalStateUnknown); ok {
+			obj = tombstone.Obj
+		}
+		blueprint, ok := obj.(*app.Blueprint)
+		if !ok {
+			return
+		}
+		select {
+		case events <- event.GenericEvent{Object: blueprint}:
+		case <-ctx.Done():
+		}
+	}
+	informer.AddEventHandler(toolscache.ResourceEventHandlerFuncs{
+		AddFunc:    notify,
+		UpdateFunc: func(_, obj interface{}) { notify(obj) },
+		DeleteFunc: notify,
+	})
+	go func() {
+		if err := blueprints.Start(ctx); err != nil {
+			cm.Log.Error().Err(err).Str(logging.CLUSTER, cluster).Msg("blueprint informer stopped")
+		}
+	}()
+	remote.cache = blueprints
+	remote.stop = stop
+	return nil
+}
+
+// remoteClustersOf returns the clients of the kubeconfig secrets, or nil for the secrets that are not valid
+func (cm *kubeconfigClusterManager) remoteClustersOf(secrets []corev1.Secret) []*remoteCluster {
+	remotes := make([]*remoteCluster, len(secrets))
+	for i := range secrets {
+		remote, err := cm.remoteClusterOf(&secrets[i])
+		if err != nil {
+			cm.Log.Error().Err(err).Str(logging.NAME, secrets[i].Name).Msg("could not create a client from the kubeconfig secret")
+			continue
+		}
+		remotes[i] = remote
+	}
+	return remotes
+}
+
+// clustersOf reads the metadata of the remote clusters concurrently
+func (cm *kubeconfigClusterManager) clustersOf(remotes []*remoteCluster) ([]multicluster.Cluster, []error) {
+	clusters := make([]multicluster.Cluster, len(remotes))
+	errs := make([]error, len(remotes))
+	var wg sync.WaitGroup
+	for i := range remotes {
+		if remotes[i] == nil {
+			continue
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			clusters[i], errs[i] = cm.clusterOf(remotes[i])
+		}(i)
+	}
+	wg.Wait()
+	return clusters, errs
+}
+
+// remoteClusterOf returns the client of a kubeconfig secret, which is created again only if the secret has changed
+func (cm *kubeconfigClusterManager) remoteClusterOf(secret *corev1.Secret) (*remoteCluster, error) {
+	cm.mu.Lock()
+	remote, found := cm.remotes[secret.Name]
+	cm.mu.Unlock()
+	if found && remote.resourceVersion == secret.ResourceVersion {
+		return remote, nil
+	}
+	kubeconfig, found := secret.Data[KubeconfigKey]
+	if !found {
+		return nil, fmt.Errorf("secret %s has no %s key", secret.Name, KubeconfigKey)
+	}
+	config, err := clientcmd.RESTConfigFromKubeConfig(kubeconfig)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid kubeconfig in the secret %s", secret.Name)
+	}
+	// the requests of the client are bounded, unlike the watches of the blueprint cache
+	clientConfig := rest.CopyConfig(config)
+	clientConfig.Timeout = requestTimeout
+	cl, err := cm.NewClient(clientConfig)
+	if err != nil {
+		return nil, err
+	}
+	return &remoteCluster{resourceVersion: secret.ResourceVersion, config: config, client: cl}, nil
+}
+
+// clusterOf returns the cluster details from the metadata config map of the cluster.
+// The result is reused for the probe interval, and concurrent calls for the same cluster read the config map once.
+func (cm *kubeconfigClusterManager) clusterOf(remote *remoteCluster) (multicluster.Cluster, error) {
+	remote.probeMu.Lock()
+	defer remote.probeMu.Unlock()
+	if !remote.probed.IsZero() && time.Since(remote.probed) < cm.ProbeInterval {
+		return remote.cluster, remote.probeErr
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+	configMap := corev1.ConfigMap{}
+	err := remote.client.Get(ctx, client.ObjectKey{Name: ClusterMetadataConfigMap, Namespace: cm.Namespace}, &configMap)
+	cluster := multicluster.Cluster{}
+	if err == nil {
+		cluster = multicluster.CreateCluster(configMap)
+		if cluster.Name == "" {
+			err = fmt.Errorf("config map %s has no cluster name", ClusterMetadataConfigMap)
+		}
+	}
+	remote.cluster, remote.probeErr, remote.probed = cluster, err, time.Now()
+	return cluster, err
+}
+
+// newClient creates a client of a cluster that supports the resources used by the cluster manager.
+// The resources of the cluster are discovered with the first request, so that no request is sent while the client is created.
+func newClient(config *rest.Config) (client.Client, error) {
+	mapper, err := apiutil.NewDynamicRESTMapper(config, apiutil.WithLazyDiscovery)
+	if err != nil {
+		return nil, err
+	}
+	return client.New(config, client.Options{Scheme: scheme, Mapper: mapper})
+}
+
+// newCache creates a cache of the blueprints in the namespace of the internal resources of a cluster.
+// The resources of the cluster are discovered when the cache is started.
+func newCache(config *rest.Config) (cache.Cache, error) {
+	mapper, err := apiutil.NewDynamicRESTMapper(config, apiutil.WithLazyDiscovery)
+	if err != nil {
+		return nil, err
+	}
+	return cache.New(config, cache.Options{Scheme: scheme, Mapper: mapper, Namespace: environment.GetInternalCRsNamespace()})
+}
+
+// NewClusterManager creates an instance of ClusterManager that accesses the clusters with the kubeconfig secrets in
+// the given namespace. The client is used for the local cluster, and the reader is used to list the secrets.
+func NewClusterManager(cl client.Client, reader client.Reader, namespace string) (multicluster.ClusterManager, error) {
+	return NewClusterManagerWithFactories(cl, reader, namespace, newClient, newCache)
+}
+
+// NewClusterManagerWithFactories creates an instance of ClusterManager that creates the clients and the blueprint
+// caches of the remote clusters with the given factories
+func NewClusterManagerWithFactories(cl client.Client, reader client.Reader, namespace string,
+	clientFactory ClientFactory, cacheFactory CacheFactory) (multicluster.ClusterManager, error) {
+	return &kubeconfigClusterManager{
+		Client:        cl,
+		Reader:        reader,
+		Namespace:     namespace,
+		NewClient:     clientFactory,
+		NewCache:      cacheFactory,
+		Log:           logging.LogInit(logging.CONTROLLER, "KubeconfigClusterManager"),
+		ProbeInterval: DefaultProbeInterval,
+		remotes:       map[string]*remoteCluster{},
+		clients:       map[string]client.Client{},
+		readers:       map[string]client.Reader{},
+	}, nil
+}
@@ -0,0 +1,321 @@
+// Copyright 2023 IBM Corp.
+// SPDX-License-Identifier: Apache-2.0
+
+package kubeconfig
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/cache/informertest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/envtest"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	app "fybrik.io/fybrik/manager/apis/app/v1beta1"
+	"fybrik.io/fybrik/pkg/environment"
+	"fybrik.io/fybrik/pkg/multicluster"
+)
+
+const namespace = "fybrik-system"
+
+// kubeconfigOf returns a kubeconfig of a cluster with the given server
+func kubeconfigOf(g *gomega.WithT, server string) []byte {
+	config := clientcmdapi.NewConfig()
+	config.Clusters["cluster"] = &clientcmdapi.Cluster{Server: server}
+	config.AuthInfos["user"] = &clientcmdapi.AuthInfo{Token: "token"}
+	config.Contexts["context"] = &clientcmdapi.Context{Cluster: "cluster", AuthInfo: "user"}
+	config.CurrentContext = "context"
+	kubeconfig, err := clientcmd.Write(*config)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	return kubeconfig
+}
+
+func kubeconfigSecret(name string, kubeconfig []byte) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: map[string]string{KubeconfigLabel: "true"}},
+		Data:       map[string][]byte{KubeconfigKey: kubeconfig},
+	}
+}
+
+func clusterMetadata(name, region string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: ClusterMetadataConfigMap, Namespace: namespace},
+		Data:       map[string]string{"ClusterName": name, "Region": region},
+	}
+}
+
+func blueprint() *app.Blueprint {
+	return &app.Blueprint{
+		ObjectMeta: metav1.ObjectMeta{Name: "notebook", Namespace: namespace, Labels: map[string]string{"app": "notebook"}},
+		Spec:       app.BlueprintSpec{Cluster: "worker", ModulesNamespace: "fybrik-blueprints"},
+	}
+}
+
+// blueprintCache is a fake cache of a cluster, which reads the blueprints with the client of the cluster
+type blueprintCache struct {
+	*informertest.FakeInformers
+	client client.Client
+}
+
+func (c *blueprintCache) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	return c.client.Get(ctx, key, obj, opts...)
+}
+
+// factoriesOf returns factories that create the clients and the caches of the given clusters by their hosts
+func factoriesOf(workers map[string]client.Client) (ClientFactory, CacheFactory) {
+	clientFactory := func(config *rest.Config) (client.Client, error) {
+		if cl, found := workers[config.Host]; found {
+			return cl, nil
+		}
+		return nil, fmt.Errorf("no such host %s", config.Host)
+	}
+	cacheFactory := func(config *rest.Config) (cache.Cache, error) {
+		cl, err := clientFactory(config)
+		if err != nil {
+			return nil, err
+		}
+		return &blueprintCache{FakeInformers: &informertest.FakeInformers{Scheme: scheme}, client: cl}, nil
+	}
+	return clientFactory, cacheFactory
+}
+
+func TestKubeconfigClusterManager(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	t.Setenv(environment.LocalClusterName, "coordinator")
+	t.Setenv(environment.LocalRegion, "theshire")
+
+	workers := map[string]client.Client{
+		"https://worker:6443": fake.NewClientBuilder().WithScheme(scheme).WithObjects(clusterMetadata("worker", "neverland")).Build(),
+		// a cluster without metadata is not registered
+		"https://unknown:6443": fake.NewClientBuilder().WithScheme(scheme).Build(),
+	}
+	created := 0
+	clientFactory, cacheFactory := factoriesOf(workers)
+	factory := func(config *rest.Config) (client.Client, error) {
+		created++
+		return clientFactory(config)
+	}
+	secrets := []client.Object{
+		kubeconfigSecret("worker", kubeconfigOf(g, "https://worker:6443")),
+		kubeconfigSecret("unknown", kubeconfigOf(g, "https://unknown:6443")),
+		kubeconfigSecret("invalid", []byte("not a kubeconfig")),
+		// secrets without the label are ignored
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: namespace},
+			Data: map[string][]byte{KubeconfigKey: kubeconfigOf(g, "https://other:6443")}},
+	}
+	coordinator := fake.NewClientBuilder().WithScheme(scheme).WithObjects(secrets...).Build()
+	manager, err := NewClusterManagerWithFactories(coordinator, coordinator, namespace, factory, cacheFactory)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(manager.IsMultiClusterSetup()).To(gomega.BeTrue())
+
+	clusters, err := manager.GetClusters()
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(clusters).To(gomega.ConsistOf(
+		multicluster.Cluster{Name: "coordinator", Metadata: multicluster.ClusterMetadata{Region: "theshire"}},
+		multicluster.Cluster{Name: "worker", Metadata: multicluster.ClusterMetadata{Region: "neverland"}},
+	))
+	// the clients are not created again as long as the secrets do not change
+	_, err = manager.GetClusters()
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(created).To(gomega.Equal(2))
+
+	// blueprints are deployed directly to the cluster
+	g.Expect(manager.CreateBlueprint("worker", blueprint())).To(gomega.Succeed())
+	deployed := &app.Blueprint{}
+	g.Expect(workers["https://worker:6443"].Get(context.Background(), client.ObjectKeyFromObject(blueprint()), deployed)).
+		To(gomega.Succeed())
+	g.Expect(deployed.Spec.Cluster).To(gomega.Equal("worker"))
+	g.Expect(deployed.Labels).To(gomega.HaveKeyWithValue("app", "notebook"))
+
+	updated := blueprint()
+	updated.Spec.ModulesNamespace = "modules"
+	g.Expect(manager.UpdateBlueprint("worker", updated)).To(gomega.Succeed())
+	deployed, err = manager.GetBlueprint("worker", namespace, "notebook")
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(deployed.Spec.ModulesNamespace).To(gomega.Equal("modules"))
+
+	g.Expect(manager.DeleteBlueprint("worker", namespace, "notebook")).To(gomega.Succeed())
+	_, err = manager.GetBlueprint("worker", namespace, "notebook")
+	g.Expect(apierrors.IsNotFound(err)).To(gomega.BeTrue())
+
+	// the local cluster is accessed with the client of the manager
+	g.Expect(manager.CreateBlueprint("coordinator", blueprint())).To(gomega.Succeed())
+	g.Expect(coordinator.Get(context.Background(), client.ObjectKeyFromObject(blueprint()), &app.Blueprint{})).To(gomega.Succeed())
+
+	// a removed secret unregisters its cluster once the clusters are listed again
+	g.Expect(coordinator.Delete(context.Background(), secrets[0])).To(gomega.Succeed())
+	clusters, err = manager.GetClusters()
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(clusters).To(gomega.HaveLen(1))
+	_, err = manager.GetBlueprint("worker", namespace, "notebook")
+	g.Expect(err).To(gomega.MatchError("unregistered cluster: worker"))
+}
+
+func TestKubeconfigClusterManagerWatchesBlueprints(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	t.Setenv(environment.LocalClusterName, "coordinator")
+
+	worker := fake.NewClientBuilder().WithScheme(scheme).WithObjects(clusterMetadata("worker", "neverland")).Build()
+	clientFactory, cacheFactory := factoriesOf(map[string]client.Client{"https://worker:6443": worker})
+	secret := kubeconfigSecret("worker", kubeconfigOf(g, "https://worker:6443"))
+	coordinator := fake.NewClientBuilder().WithScheme(scheme).WithObjects(secret).Build()
+	manager, err := NewClusterManagerWithFactories(coordinator, coordinator, namespace, clientFactory, cacheFactory)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	watcher, ok := multicluster.BlueprintWatcherOf(manager)
+	g.Expect(ok).To(gomega.BeTrue())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events := make(chan event.GenericEvent, 1)
+	g.Expect(watcher.WatchBlueprints(ctx, events)).To(gomega.Succeed())
+	g.Expect(watcher.WatchBlueprints(ctx, events)).NotTo(gomega.Succeed())
+
+	// a change of a blueprint in the worker cluster is sent as an event
+	g.Expect(manager.CreateBlueprint("worker", blueprint())).To(gomega.Succeed())
+	blueprints := manager.(*kubeconfigClusterManager).remotes["worker"].cache.(*blueprintCache)
+	informer, err := blueprints.FakeInformerFor(&app.Blueprint{})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	informer.Add(blueprint())
+	g.Eventually(events, time.Second).Should(gomega.Receive(gomega.WithTransform(
+		func(e event.GenericEvent) client.ObjectKey { return client.ObjectKeyFromObject(e.Object) },
+		gomega.Equal(client.ObjectKeyFromObject(blueprint())))))
+
+	// the blueprints are read from the cache of the cluster
+	deployed, err := manager.GetBlueprint("worker", namespace, "notebook")
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(deployed.Spec.Cluster).To(gomega.Equal("worker"))
+
+	// the cluster is no longer watched once its secret is removed
+	g.Expect(coordinator.Delete(context.Background(), secret)).To(gomega.Succeed())
+	_, err = manager.GetClusters()
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(manager.(*kubeconfigClusterManager).readers).To(gomega.BeEmpty())
+}
+
+// unresponsiveClient counts the reads of the cluster metadata, and does not respond to them after the first one
+// until it is released
+type unresponsiveClient struct {
+	client.Client
+	reads   int32
+	release chan struct{}
+}
+
+func (c *unresponsiveClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	if atomic.AddInt32(&c.reads, 1) > 1 {
+		select {
+		case <-c.release:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return c.Client.Get(ctx, key, obj, opts...)
+}
+
+func TestKubeconfigClusterManagerWithUnresponsiveCluster(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	t.Setenv(environment.LocalClusterName, "coordinator")
+
+	worker := fake.NewClientBuilder().WithScheme(scheme).WithObjects(clusterMetadata("worker", "neverland")).Build()
+	slow := &unresponsiveClient{
+		Client:  fake.NewClientBuilder().WithScheme(scheme).WithObjects(clusterMetadata("slow", "neverland")).Build(),
+		release: make(chan struct{}),
+	}
+	clientFactory, cacheFactory := factoriesOf(map[string]client.Client{"https://worker:6443": worker, "https://slow:6443": slow})
+	coordinator := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		kubeconfigSecret("worker", kubeconfigOf(g, "https://worker:6443")),
+		kubeconfigSecret("slow", kubeconfigOf(g, "https://slow:6443"))).Build()
+	manager, err := NewClusterManagerWithFactories(coordinator, coordinator, namespace, clientFactory, cacheFactory)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	clusters, err := manager.GetClusters()
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(clusters).To(gomega.HaveLen(3))
+
+	// the metadata is reused within the probe interval
+	_, err = manager.GetClusters()
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(atomic.LoadInt32(&slow.reads)).To(gomega.Equal(int32(1)))
+
+	// the other clusters are accessed while the metadata of an unresponsive cluster is read
+	manager.(*kubeconfigClusterManager).ProbeInterval = 0
+	listed := make(chan []multicluster.Cluster, 1)
+	go func() {
+		clusters, _ := manager.GetClusters()
+		listed <- clusters
+	}()
+	g.Eventually(func() int32 { return atomic.LoadInt32(&slow.reads) }, time.Second).Should(gomega.Equal(int32(2)))
+	g.Expect(manager.CreateBlueprint("worker", blueprint())).To(gomega.Succeed())
+	_, err = manager.GetBlueprint("worker", namespace, "notebook")
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Consistently(listed).ShouldNot(gomega.Receive())
+
+	close(slow.release)
+	g.Eventually(listed, time.Second).Should(gomega.Receive(gomega.HaveLen(3)))
+}
+
+// TestKubeconfigClusterManagerWithAPIServers deploys blueprints from a coordinator API server to a worker API server
+func TestKubeconfigClusterManagerWithAPIServers(t *testing.T) {
+	if os.Getenv("KUBEBUILDER_ASSETS") == "" {
+		if _, err := os.Stat("/usr/local/kubebuilder/bin"); err != nil {
+			t.Skip("the envtest binaries are not installed")
+		}
+	}
+	g := gomega.NewGomegaWithT(t)
+	t.Setenv(environment.LocalClusterName, "coordinator")
+
+	newEnvironment := func() (*envtest.Environment, client.Client) {
+		env := &envtest.Environment{
+			CRDDirectoryPaths:     []string{filepath.Join("..", "..", "..", "charts", "fybrik-crd", "templates")},
+			ErrorIfCRDPathMissing: true,
+		}
+		config, err := env.Start()
+		g.Expect(err).NotTo(gomega.HaveOccurred())
+		t.Cleanup(func() { _ = env.Stop() })
+		cl, err := client.New(config, client.Options{Scheme: scheme})
+		g.Expect(err).NotTo(gomega.HaveOccurred())
+		g.Expect(cl.Create(context.Background(), &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace}})).
+			To(gomega.Succeed())
+		return env, cl
+	}
+	_, coordinator := newEnvironment()
+	worker, workerClient := newEnvironment()
+	g.Expect(workerClient.Create(context.Background(), clusterMetadata("worker", "neverland"))).To(gomega.Succeed())
+
+	// the kubeconfig of a user of the worker API server is stored in the coordinator API server
+	user, err := worker.AddUser(envtest.User{Name: "fybrik", Groups: []string{"system:masters"}}, nil)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	kubeconfig, err := user.KubeConfig()
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(coordinator.Create(context.Background(), kubeconfigSecret("worker", kubeconfig))).To(gomega.Succeed())
+
+	manager, err := NewClusterManager(coordinator, coordinator, namespace)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	clusters, err := manager.GetClusters()
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(clusters).To(gomega.HaveLen(2))
+	g.Expect(clusters[1]).To(gomega.Equal(multicluster.Cluster{Name: "worker",
+		Metadata: multicluster.ClusterMetadata{Region: "neverland"}}))
+
+	g.Expect(manager.CreateBlueprint("worker", blueprint())).To(gomega.Succeed())
+	g.Expect(workerClient.Get(context.Background(), client.ObjectKeyFromObject(blueprint()), &app.Blueprint{})).To(gomega.Succeed())
+	err = coordinator.Get(context.Background(), client.ObjectKeyFromObject(blueprint()), &app.Blueprint{})
+	g.Expect(apierrors.IsNotFound(err)).To(gomega.BeTrue())
+
+	g.Expect(manager.DeleteBlueprint("worker", namespace, "notebook")).To(gomega.Succeed())
+	err = workerClient.Get(context.Background(), client.ObjectKeyFromObject(blueprint()), &app.Blueprint{})
+	g.Expect(apierrors.IsNotFound(err)).To(gomega.BeTrue())
+}
@@ -29,6 +29,7 @@ func (cm *localClusterManager) GetClusters() ([]multicluster.Cluster, error) {
 			Region:        environment.GetLocalRegion(),
 			Zone:          environment.GetLocalZone(),
 			VaultAuthPath: environment.GetLocalVaultAuthPath(),
+			FybrikVersion: environment.GetLocalFybrikVersion(),
 		},
 	}}
 	return clusters, nil
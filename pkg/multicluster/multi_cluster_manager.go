@@ -4,9 +4,12 @@
 package multicluster
 
 import (
+	"context"
+
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 
 	app "fybrik.io/fybrik/manager/apis/app/v1beta1"
 )
@@ -24,10 +27,38 @@ type ClusterManager interface {
 	DeleteBlueprint(cluster string, namespace string, name string) error
 }
 
+// BlueprintWatcher is implemented by cluster managers that can notify about changes of the blueprints in the
+// remote clusters, so that the plotter controller does not need to poll them.
+// Only the kubeconfig cluster manager implements it; the blueprints of Razee clusters are polled.
+type BlueprintWatcher interface {
+	// WatchBlueprints sends an event to the channel whenever a blueprint changes in one of the clusters.
+	// It keeps watching the clusters that are registered later on, until the context is done.
+	WatchBlueprints(ctx context.Context, events chan<- event.GenericEvent) error
+}
+
+// BlueprintWatcherOf returns the blueprint watcher of a cluster manager, if the cluster manager or one of the
+// cluster managers that it wraps implements it
+func BlueprintWatcherOf(cm ClusterLister) (BlueprintWatcher, bool) {
+	for cm != nil {
+		if watcher, ok := cm.(BlueprintWatcher); ok {
+			return watcher, true
+		}
+		wrapper, ok := cm.(interface{ Unwrap() ClusterManager })
+		if !ok {
+			return nil, false
+		}
+		cm = wrapper.Unwrap()
+	}
+	return nil, false
+}
+
 type ClusterMetadata struct {
-	Region        string `json:"region"`
-	Zone          string `json:"zone,omitempty"`
-	VaultAuthPath string `json:"vaultAuthPath,omitempty"`
+	Region        string            `json:"region"`
+	Zone          string            `json:"zone,omitempty"`
+	VaultAuthPath string            `json:"vaultAuthPath,omitempty"`
+	FybrikVersion string            `json:"fybrikVersion,omitempty"`
+	Labels        map[string]string `json:"labels,omitempty"`
+	Attributes    map[string]string `json:"attributes,omitempty"`
 }
 
 type Cluster struct {
@@ -42,6 +73,7 @@ func CreateCluster(cm corev1.ConfigMap) Cluster {
 			Region:        cm.Data["Region"],
 			Zone:          cm.Data["Zone"],
 			VaultAuthPath: cm.Data["VaultAuthPath"],
+			FybrikVersion: cm.Data["FybrikVersion"],
 		},
 	}
 	return cluster
@@ -44,6 +44,9 @@ func init() {
 	_ = app.AddToScheme(scheme)
 }
 
+// razeeClusterManager deploys the blueprints through Razee channels and subscriptions, and reads them from the
+// resources reported by the Razee agents. Razee does not notify about changes of the reported resources, therefore
+// it does not implement multicluster.BlueprintWatcher, and the plotter controller polls the blueprints instead.
 type razeeClusterManager struct {
 	orgID        string
 	clusterGroup string
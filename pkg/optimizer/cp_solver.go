@@ -0,0 +1,1169 @@
+// Copyright 2023 IBM Corp.
+// SPDX-License-Identifier: Apache-2.0
+
+package optimizer
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/*
+This file implements CPSolver: a pure-Go solver for the FlatZinc models built by this package.
+The solver consumes a FlatZincModel directly, so no model file is written and no external executable is needed.
+It supports the FlatZinc constraints listed in flatzinc_model.go, which are the only ones DataPathCSP uses.
+
+Solving is done by a depth-first search over the decision variables (variables not annotated with is_defined_var),
+interleaved with bounds propagation over all constraints. For minimize/maximize goals, each solution found adds
+a bound on the objective, and the search continues until the search space is exhausted (branch and bound).
+*/
+
+const (
+	// bounds used for variables of type "int", which have no declared domain
+	unboundedMin = -(1 << 40)
+	unboundedMax = 1 << 40
+	// domains larger than this are represented by their bounds only
+	maxEnumeratedDomainSize = 1 << 16
+	// how often (in search nodes) the deadline is checked
+	timeCheckInterval = 1024
+)
+
+// CPSolver is a Solver running in-process.
+// Search is stopped at the deadline. The best solution found so far is then returned.
+// If no solution was found, an error is returned.
+type CPSolver struct{}
+
+func NewCPSolver() *CPSolver {
+	return &CPSolver{}
+}
+
+func (slv *CPSolver) Solve(fzModel *FlatZincModel, deadline time.Time) (CPSolution, error) {
+	model, err := newCPModel(fzModel)
+	if err != nil {
+		return nil, err
+	}
+	search := newCPSearch(model, deadline)
+	return search.run()
+}
+
+// ***********
+// Domains and search state
+// ***********
+
+// The domain of a single integer variable (Boolean variables have the domain 0..1)
+type domain struct {
+	lo, hi int64
+	base   int64  // the original lower bound
+	holes  []bool // holes[v-base] is true if v was removed from [lo, hi]. Allocated on first removal.
+}
+
+func newDomain(lo, hi int64) domain {
+	return domain{lo: lo, hi: hi, base: lo}
+}
+
+func (d *domain) size() int64 {
+	return d.hi - d.lo + 1
+}
+
+func (d *domain) contains(v int64) bool {
+	return v >= d.lo && v <= d.hi && (d.holes == nil || !d.holes[v-d.base])
+}
+
+// skips holes at the domain bounds
+func (d *domain) normalize() {
+	if d.holes == nil {
+		return
+	}
+	for d.lo <= d.hi && d.holes[d.lo-d.base] {
+		d.lo++
+	}
+	for d.lo <= d.hi && d.holes[d.hi-d.base] {
+		d.hi--
+	}
+}
+
+func (d *domain) clone() domain {
+	res := *d
+	if d.holes != nil {
+		res.holes = make([]bool, len(d.holes))
+		copy(res.holes, d.holes)
+	}
+	return res
+}
+
+// A term in a constraint is either a constant or a reference to a variable
+type term struct {
+	varIdx int // -1 for a constant
+	value  int64
+}
+
+func constTerm(value int64) term {
+	return term{varIdx: -1, value: value}
+}
+
+func varTerm(varIdx int) term {
+	return term{varIdx: varIdx}
+}
+
+// The domains of all variables in a search node
+type cpState struct {
+	doms  []domain
+	dirty []int // variables whose domain was reduced since the last propagation
+}
+
+func (st *cpState) clone() *cpState {
+	res := cpState{doms: make([]domain, len(st.doms))}
+	for i := range st.doms {
+		res.doms[i] = st.doms[i].clone()
+	}
+	return &res
+}
+
+func (st *cpState) min(t term) int64 {
+	if t.varIdx < 0 {
+		return t.value
+	}
+	return st.doms[t.varIdx].lo
+}
+
+func (st *cpState) max(t term) int64 {
+	if t.varIdx < 0 {
+		return t.value
+	}
+	return st.doms[t.varIdx].hi
+}
+
+func (st *cpState) isFixed(t term) bool {
+	return st.min(t) == st.max(t)
+}
+
+func (st *cpState) contains(t term, v int64) bool {
+	if t.varIdx < 0 {
+		return t.value == v
+	}
+	return st.doms[t.varIdx].contains(v)
+}
+
+// Removes all values smaller than v from the domain of t. Returns false if the domain becomes empty.
+func (st *cpState) setMin(t term, v int64) bool {
+	if t.varIdx < 0 {
+		return t.value >= v
+	}
+	d := &st.doms[t.varIdx]
+	if v <= d.lo {
+		return true
+	}
+	d.lo = v
+	d.normalize()
+	st.dirty = append(st.dirty, t.varIdx)
+	return d.lo <= d.hi
+}
+
+// Removes all values larger than v from the domain of t. Returns false if the domain becomes empty.
+func (st *cpState) setMax(t term, v int64) bool {
+	if t.varIdx < 0 {
+		return t.value <= v
+	}
+	d := &st.doms[t.varIdx]
+	if v >= d.hi {
+		return true
+	}
+	d.hi = v
+	d.normalize()
+	st.dirty = append(st.dirty, t.varIdx)
+	return d.lo <= d.hi
+}
+
+func (st *cpState) fix(t term, v int64) bool {
+	return st.contains(t, v) && st.setMin(t, v) && st.setMax(t, v)
+}
+
+// Removes v from the domain of t. Returns false if the domain becomes empty.
+// Values in the middle of a domain which is too large to be enumerated are not removed.
+func (st *cpState) remove(t term, v int64) bool {
+	if t.varIdx < 0 {
+		return t.value != v
+	}
+	d := &st.doms[t.varIdx]
+	switch {
+	case !d.contains(v):
+		return true
+	case v == d.lo:
+		return st.setMin(t, v+1)
+	case v == d.hi:
+		return st.setMax(t, v-1)
+	}
+	if d.holes == nil {
+		if d.hi-d.base >= maxEnumeratedDomainSize {
+			return true
+		}
+		d.holes = make([]bool, d.hi-d.base+1)
+	}
+	d.holes[v-d.base] = true
+	st.dirty = append(st.dirty, t.varIdx)
+	return true
+}
+
+// returns the values in the domain of t, which must not be too large to enumerate
+func (st *cpState) values(t term) []int64 {
+	res := []int64{}
+	for v := st.min(t); v <= st.max(t); v++ {
+		if st.contains(t, v) {
+			res = append(res, v)
+		}
+	}
+	return res
+}
+
+// ***********
+// Propagators - one for each supported FlatZinc constraint
+// Each propagator must detect a violation of its constraint once all of its variables are fixed.
+// ***********
+
+type propagator interface {
+	terms() []term
+	propagate(st *cpState) bool // returns false if the constraint cannot be satisfied
+}
+
+// sum(coeffs[i]*vars[i]) <= rhs (or == rhs if isEq is set)
+// used for bool_lin_eq, bool_lin_le, int_lin_eq, bool_le and bool_not
+type linearProp struct {
+	coeffs []int64
+	vars   []term
+	rhs    int64
+	isEq   bool
+}
+
+func (p *linearProp) terms() []term {
+	return p.vars
+}
+
+func (p *linearProp) propagate(st *cpState) bool {
+	if !propagateLinearLe(st, p.coeffs, p.vars, p.rhs) {
+		return false
+	}
+	if !p.isEq {
+		return true
+	}
+	negCoeffs := make([]int64, len(p.coeffs))
+	for i, c := range p.coeffs {
+		negCoeffs[i] = -c
+	}
+	return propagateLinearLe(st, negCoeffs, p.vars, -p.rhs)
+}
+
+// propagates bounds for sum(coeffs[i]*vars[i]) <= rhs
+func propagateLinearLe(st *cpState, coeffs []int64, vars []term, rhs int64) bool {
+	minTerms := make([]int64, len(vars))
+	minSum := int64(0)
+	for i, v := range vars {
+		if coeffs[i] >= 0 {
+			minTerms[i] = coeffs[i] * st.min(v)
+		} else {
+			minTerms[i] = coeffs[i] * st.max(v)
+		}
+		minSum += minTerms[i]
+	}
+	if minSum > rhs {
+		return false
+	}
+	for i, v := range vars {
+		slack := rhs - minSum + minTerms[i] // coeffs[i]*vars[i] <= slack
+		switch {
+		case coeffs[i] > 0:
+			if !st.setMax(v, floorDiv(slack, coeffs[i])) {
+				return false
+			}
+		case coeffs[i] < 0:
+			if !st.setMin(v, ceilDiv(slack, coeffs[i])) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// result == OR(vars); used for array_bool_or
+type boolOrProp struct {
+	vars   []term
+	result term
+}
+
+func (p *boolOrProp) terms() []term {
+	return append([]term{p.result}, p.vars...)
+}
+
+func (p *boolOrProp) propagate(st *cpState) bool {
+	unfixed := []term{}
+	for _, v := range p.vars {
+		if st.min(v) == 1 {
+			return st.setMin(p.result, 1)
+		}
+		if st.max(v) == 1 {
+			unfixed = append(unfixed, v)
+		}
+	}
+	if len(unfixed) == 0 {
+		return st.setMax(p.result, 0)
+	}
+	if st.max(p.result) == 0 {
+		for _, v := range unfixed {
+			if !st.setMax(v, 0) {
+				return false
+			}
+		}
+	} else if st.min(p.result) == 1 && len(unfixed) == 1 {
+		return st.setMin(unfixed[0], 1)
+	}
+	return true
+}
+
+// result == AND(vars); used for array_bool_and
+type boolAndProp struct {
+	vars   []term
+	result term
+}
+
+func (p *boolAndProp) terms() []term {
+	return append([]term{p.result}, p.vars...)
+}
+
+func (p *boolAndProp) propagate(st *cpState) bool {
+	unfixed := []term{}
+	for _, v := range p.vars {
+		if st.max(v) == 0 {
+			return st.setMax(p.result, 0)
+		}
+		if st.min(v) == 0 {
+			unfixed = append(unfixed, v)
+		}
+	}
+	if len(unfixed) == 0 {
+		return st.setMin(p.result, 1)
+	}
+	if st.min(p.result) == 1 {
+		for _, v := range unfixed {
+			if !st.setMin(v, 1) {
+				return false
+			}
+		}
+	} else if st.max(p.result) == 0 && len(unfixed) == 1 {
+		return st.setMax(unfixed[0], 0)
+	}
+	return true
+}
+
+// indicator <-> (x == y), or indicator <-> (x != y) if negated; used for int_eq_reif and int_ne_reif
+type intEqReifProp struct {
+	x, y      term
+	indicator term
+	negated   bool
+}
+
+func (p *intEqReifProp) terms() []term {
+	return []term{p.x, p.y, p.indicator}
+}
+
+func (p *intEqReifProp) propagate(st *cpState) bool {
+	eqValue := int64(1) // the indicator value meaning x == y
+	if p.negated {
+		eqValue = 0
+	}
+	if st.isFixed(p.indicator) {
+		if st.min(p.indicator) == eqValue {
+			return st.setMin(p.x, st.min(p.y)) && st.setMax(p.x, st.max(p.y)) &&
+				st.setMin(p.y, st.min(p.x)) && st.setMax(p.y, st.max(p.x)) &&
+				(!st.isFixed(p.x) || st.fix(p.y, st.min(p.x))) &&
+				(!st.isFixed(p.y) || st.fix(p.x, st.min(p.y)))
+		}
+		return (!st.isFixed(p.x) || st.remove(p.y, st.min(p.x))) &&
+			(!st.isFixed(p.y) || st.remove(p.x, st.min(p.y)))
+	}
+	switch {
+	case st.isFixed(p.x) && st.isFixed(p.y):
+		if st.min(p.x) == st.min(p.y) {
+			return st.fix(p.indicator, eqValue)
+		}
+		return st.fix(p.indicator, 1-eqValue)
+	case st.max(p.x) < st.min(p.y) || st.max(p.y) < st.min(p.x),
+		st.isFixed(p.x) && !st.contains(p.y, st.min(p.x)),
+		st.isFixed(p.y) && !st.contains(p.x, st.min(p.y)):
+		return st.fix(p.indicator, 1-eqValue)
+	}
+	return true
+}
+
+// indicator <-> (x <= y); used for int_le_reif
+type intLeReifProp struct {
+	x, y      term
+	indicator term
+}
+
+func (p *intLeReifProp) terms() []term {
+	return []term{p.x, p.y, p.indicator}
+}
+
+func (p *intLeReifProp) propagate(st *cpState) bool {
+	if st.isFixed(p.indicator) {
+		if st.min(p.indicator) == 1 { // x <= y
+			return st.setMax(p.x, st.max(p.y)) && st.setMin(p.y, st.min(p.x))
+		}
+		// x > y
+		return st.setMin(p.x, st.min(p.y)+1) && st.setMax(p.y, st.max(p.x)-1)
+	}
+	if st.max(p.x) <= st.min(p.y) {
+		return st.fix(p.indicator, 1)
+	}
+	if st.min(p.x) > st.max(p.y) {
+		return st.fix(p.indicator, 0)
+	}
+	return true
+}
+
+// indicator <-> (x in set); used for set_in_reif
+type setInReifProp struct {
+	x         term
+	set       map[int64]bool
+	indicator term
+}
+
+func (p *setInReifProp) terms() []term {
+	return []term{p.x, p.indicator}
+}
+
+func (p *setInReifProp) propagate(st *cpState) bool {
+	if st.max(p.x)-st.min(p.x) >= maxEnumeratedDomainSize {
+		return true // this does not happen in our models, as all variables used in sets have small domains
+	}
+	if st.isFixed(p.indicator) {
+		wantIn := st.min(p.indicator) == 1
+		for _, v := range st.values(p.x) {
+			if p.set[v] != wantIn && !st.remove(p.x, v) {
+				return false
+			}
+		}
+		return true
+	}
+	someIn, someOut := false, false
+	for _, v := range st.values(p.x) {
+		if p.set[v] {
+			someIn = true
+		} else {
+			someOut = true
+		}
+	}
+	switch {
+	case !someOut:
+		return st.fix(p.indicator, 1)
+	case !someIn:
+		return st.fix(p.indicator, 0)
+	}
+	return true
+}
+
+// result == array[index] (1-based index); used for array_var_int_element
+type elementProp struct {
+	index  term
+	array  []term
+	result term
+}
+
+func (p *elementProp) terms() []term {
+	return append([]term{p.index, p.result}, p.array...)
+}
+
+func (p *elementProp) propagate(st *cpState) bool {
+	if !st.setMin(p.index, 1) || !st.setMax(p.index, int64(len(p.array))) {
+		return false
+	}
+	resultMin, resultMax := int64(unboundedMax), int64(unboundedMin)
+	for _, i := range st.values(p.index) {
+		elem := p.array[i-1]
+		if st.max(elem) < st.min(p.result) || st.min(elem) > st.max(p.result) {
+			if !st.remove(p.index, i) {
+				return false
+			}
+			continue
+		}
+		resultMin = minInt64(resultMin, st.min(elem))
+		resultMax = maxInt64(resultMax, st.max(elem))
+	}
+	if !st.setMin(p.result, resultMin) || !st.setMax(p.result, resultMax) {
+		return false
+	}
+	if !st.isFixed(p.index) {
+		return true
+	}
+	elem := p.array[st.min(p.index)-1]
+	return st.setMin(elem, st.min(p.result)) && st.setMax(elem, st.max(p.result)) &&
+		st.setMin(p.result, st.min(elem)) && st.setMax(p.result, st.max(elem))
+}
+
+// result == max(vars); used for array_int_maximum
+type maxProp struct {
+	vars   []term
+	result term
+}
+
+func (p *maxProp) terms() []term {
+	return append([]term{p.result}, p.vars...)
+}
+
+func (p *maxProp) propagate(st *cpState) bool {
+	maxOfMins, maxOfMaxs := int64(unboundedMin), int64(unboundedMin)
+	for _, v := range p.vars {
+		maxOfMins = maxInt64(maxOfMins, st.min(v))
+		maxOfMaxs = maxInt64(maxOfMaxs, st.max(v))
+	}
+	if !st.setMin(p.result, maxOfMins) || !st.setMax(p.result, maxOfMaxs) {
+		return false
+	}
+	candidates := []term{} // vars which can still be the maximum
+	for _, v := range p.vars {
+		if !st.setMax(v, st.max(p.result)) {
+			return false
+		}
+		if st.max(v) >= st.min(p.result) {
+			candidates = append(candidates, v)
+		}
+	}
+	if len(candidates) == 0 {
+		return false
+	}
+	if len(candidates) == 1 {
+		return st.setMin(candidates[0], st.min(p.result))
+	}
+	return true
+}
+
+// ***********
+// Translating a FlatZincModel into variables and propagators
+// ***********
+
+type cpModel struct {
+	fzModel    *FlatZincModel
+	doms       []domain
+	isDecision []bool            // whether a variable is a decision variable (not defined by other variables)
+	scalars    map[string]term   // scalar params and variables
+	arrays     map[string][]term // array params and variables
+	props      []propagator
+	objective  term
+	goal       SolveGoal
+}
+
+func newCPModel(fzModel *FlatZincModel) (*cpModel, error) {
+	model := cpModel{fzModel: fzModel, scalars: map[string]term{}, arrays: map[string][]term{}}
+	for _, decl := range mapValuesSortedByKey(fzModel.ParamMap) {
+		param, ok := decl.(*FlatZincParam)
+		if !ok {
+			return nil, fmt.Errorf("unexpected parameter declaration %v", decl)
+		}
+		if err := model.addParam(param); err != nil {
+			return nil, err
+		}
+	}
+
+	// first declare all non-assigned variables; assigned variables may refer to them
+	assignedVars := []*FlatZincVariable{}
+	for _, decl := range mapValuesSortedByKey(fzModel.VarMap) {
+		variable, ok := decl.(*FlatZincVariable)
+		if !ok {
+			return nil, fmt.Errorf("unexpected variable declaration %v", decl)
+		}
+		if variable.Assignment != "" {
+			assignedVars = append(assignedVars, variable)
+			continue
+		}
+		if err := model.addVariable(variable); err != nil {
+			return nil, err
+		}
+	}
+	for _, variable := range assignedVars {
+		if err := model.addAssignedVariable(variable); err != nil {
+			return nil, err
+		}
+	}
+
+	for i := range fzModel.Constraints {
+		if err := model.addConstraint(&fzModel.Constraints[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	model.goal = fzModel.SolveTarget.goal
+	if model.goal != Satisfy {
+		objective, err := model.parseTerm(fzModel.SolveTarget.expr)
+		if err != nil {
+			return nil, err
+		}
+		model.objective = objective
+	}
+	return &model, nil
+}
+
+func (model *cpModel) addParam(param *FlatZincParam) error {
+	if !param.IsArray {
+		value, err := parseConstant(param.Assignment)
+		if err != nil {
+			return err
+		}
+		model.scalars[param.Name] = constTerm(value)
+		return nil
+	}
+	values := []term{}
+	for _, element := range splitCompoundLiteral(param.Assignment) {
+		value, err := parseConstant(element)
+		if err != nil {
+			return err
+		}
+		values = append(values, constTerm(value))
+	}
+	model.arrays[param.Name] = values
+	return nil
+}
+
+func (model *cpModel) newVar(varType string, isDecision bool) (term, error) {
+	var dom domain
+	switch {
+	case varType == BoolType:
+		dom = newDomain(0, 1)
+	case varType == IntType:
+		dom = newDomain(unboundedMin, unboundedMax)
+	case strings.Contains(varType, ".."):
+		bounds := strings.SplitN(varType, "..", 2)
+		lo, err := parseConstant(bounds[0])
+		if err != nil {
+			return term{}, fmt.Errorf("unsupported variable type %s: %w", varType, err)
+		}
+		hi, err := parseConstant(bounds[1])
+		if err != nil {
+			return term{}, fmt.Errorf("unsupported variable type %s: %w", varType, err)
+		}
+		dom = newDomain(lo, hi)
+	default:
+		return term{}, fmt.Errorf("unsupported variable type %s", varType)
+	}
+	model.doms = append(model.doms, dom)
+	model.isDecision = append(model.isDecision, isDecision)
+	return varTerm(len(model.doms) - 1), nil
+}
+
+func (model *cpModel) addVariable(variable *FlatZincVariable) error {
+	isDecision := !variable.hasAnnotation(DefinedVarAnnotation)
+	if !variable.IsArray {
+		newVar, err := model.newVar(variable.Type, isDecision)
+		if err != nil {
+			return err
+		}
+		model.scalars[variable.Name] = newVar
+		return nil
+	}
+	elements := make([]term, variable.Size)
+	for i := range elements {
+		newVar, err := model.newVar(variable.Type, isDecision)
+		if err != nil {
+			return err
+		}
+		elements[i] = newVar
+	}
+	model.arrays[variable.Name] = elements
+	return nil
+}
+
+// A variable with an assignment is an alias for the assigned expression
+func (model *cpModel) addAssignedVariable(variable *FlatZincVariable) error {
+	if !variable.IsArray {
+		assigned, err := model.parseTerm(variable.Assignment)
+		if err != nil {
+			return err
+		}
+		model.scalars[variable.Name] = assigned
+		return nil
+	}
+	elements, err := model.parseArray(variable.Assignment)
+	if err != nil {
+		return err
+	}
+	if len(elements) != variable.Size {
+		return fmt.Errorf("size mismatch in the assignment of variable %s", variable.Name)
+	}
+	model.arrays[variable.Name] = elements
+	return nil
+}
+
+//nolint:gocyclo // a flat switch over the supported constraints
+func (model *cpModel) addConstraint(constraint *FlatZincConstraint) error {
+	exprs := constraint.Expressions
+	parsed, err := model.parseTerms(constraint)
+	if err != nil {
+		return err
+	}
+	var prop propagator
+	switch constraint.Identifier {
+	case BoolLeConstraint: // a <= b
+		prop = &linearProp{coeffs: []int64{1, -1}, vars: parsed, rhs: 0}
+	case BoolNotEqConstraint: // a + b == 1
+		prop = &linearProp{coeffs: []int64{1, 1}, vars: parsed, rhs: 1, isEq: true}
+	case BoolLinEqConstraint, BoolLinLeConstraint, IntLinEqConstraint:
+		coeffs, vars, rhs, err := model.parseLinear(exprs)
+		if err != nil {
+			return err
+		}
+		prop = &linearProp{coeffs: coeffs, vars: vars, rhs: rhs, isEq: constraint.Identifier != BoolLinLeConstraint}
+	case ArrBoolOrConstraint:
+		vars, err := model.parseArray(exprs[0])
+		if err != nil {
+			return err
+		}
+		prop = &boolOrProp{vars: vars, result: parsed[1]}
+	case ArrBoolAndConstraint:
+		vars, err := model.parseArray(exprs[0])
+		if err != nil {
+			return err
+		}
+		prop = &boolAndProp{vars: vars, result: parsed[1]}
+	case IntEqConstraint, IntNotEqConstraint:
+		prop = &intEqReifProp{x: parsed[0], y: parsed[1], indicator: parsed[2], negated: constraint.Identifier == IntNotEqConstraint}
+	case IntLeConstraint:
+		prop = &intLeReifProp{x: parsed[0], y: parsed[1], indicator: parsed[2]}
+	case SetInConstraint:
+		set, err := parseSetLiteral(exprs[1])
+		if err != nil {
+			return err
+		}
+		prop = &setInReifProp{x: parsed[0], set: set, indicator: parsed[2]}
+	case ArrIntElemConstraint:
+		array, err := model.parseArray(exprs[1])
+		if err != nil {
+			return err
+		}
+		prop = &elementProp{index: parsed[0], array: array, result: parsed[2]}
+	case IntMaxConstraint:
+		vars, err := model.parseArray(exprs[1])
+		if err != nil {
+			return err
+		}
+		prop = &maxProp{vars: vars, result: parsed[0]}
+	default:
+		return fmt.Errorf("unsupported constraint %s", constraint.Identifier)
+	}
+	model.props = append(model.props, prop)
+	return nil
+}
+
+// The number of arguments each supported constraint takes, and which of them are scalar terms
+var scalarArgsOfConstraint = map[string]struct {
+	numArgs    int
+	scalarArgs []int
+}{
+	BoolLeConstraint:     {2, []int{0, 1}},
+	BoolNotEqConstraint:  {2, []int{0, 1}},
+	BoolLinEqConstraint:  {3, nil},
+	BoolLinLeConstraint:  {3, nil},
+	IntLinEqConstraint:   {3, nil},
+	ArrBoolOrConstraint:  {2, []int{1}},
+	ArrBoolAndConstraint: {2, []int{1}},
+	IntEqConstraint:      {3, []int{0, 1, 2}},
+	IntNotEqConstraint:   {3, []int{0, 1, 2}},
+	IntLeConstraint:      {3, []int{0, 1, 2}},
+	SetInConstraint:      {3, []int{0, 2}},
+	ArrIntElemConstraint: {3, []int{0, 2}},
+	IntMaxConstraint:     {2, []int{0}},
+}
+
+// Checks the number of arguments of a constraint and parses its scalar arguments.
+// The i-th returned term holds the i-th argument, if it is scalar.
+func (model *cpModel) parseTerms(constraint *FlatZincConstraint) ([]term, error) {
+	argsInfo, supported := scalarArgsOfConstraint[constraint.Identifier]
+	if !supported {
+		return nil, fmt.Errorf("unsupported constraint %s", constraint.Identifier)
+	}
+	if len(constraint.Expressions) != argsInfo.numArgs {
+		return nil, fmt.Errorf("wrong number of arguments for constraint %s", constraint.Identifier)
+	}
+	res := make([]term, argsInfo.numArgs)
+	for _, argIdx := range argsInfo.scalarArgs {
+		parsed, err := model.parseTerm(constraint.Expressions[argIdx])
+		if err != nil {
+			return nil, err
+		}
+		res[argIdx] = parsed
+	}
+	return res, nil
+}
+
+func (model *cpModel) parseLinear(exprs []string) ([]int64, []term, int64, error) {
+	coeffTerms, err := model.parseArray(exprs[0])
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	vars, err := model.parseArray(exprs[1])
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	if len(coeffTerms) != len(vars) {
+		return nil, nil, 0, fmt.Errorf("size mismatch in linear constraint over %s", exprs[1])
+	}
+	coeffs := make([]int64, len(coeffTerms))
+	for i, coeff := range coeffTerms {
+		if coeff.varIdx >= 0 {
+			return nil, nil, 0, fmt.Errorf("linear constraint over %s has non-constant coefficients", exprs[1])
+		}
+		coeffs[i] = coeff.value
+	}
+	rhs, err := model.parseTerm(exprs[2])
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	if rhs.varIdx >= 0 { // sum(coeffs[i]*vars[i]) == rhs  <==>  sum(coeffs[i]*vars[i]) - rhs == 0
+		return append(coeffs, -1), append(append([]term{}, vars...), rhs), 0, nil
+	}
+	return coeffs, vars, rhs.value, nil
+}
+
+// Parses a constant, a scalar identifier or an array access (e.g. "x[3]")
+func (model *cpModel) parseTerm(expr string) (term, error) {
+	expr = strings.TrimSpace(expr)
+	if value, err := parseConstant(expr); err == nil {
+		return constTerm(value), nil
+	}
+	if leftBracketPos := strings.Index(expr, "["); leftBracketPos > 0 && strings.HasSuffix(expr, "]") {
+		array, found := model.arrays[expr[:leftBracketPos]]
+		if !found {
+			return term{}, fmt.Errorf("unknown array %s", expr[:leftBracketPos])
+		}
+		pos, err := strconv.Atoi(expr[leftBracketPos+1 : len(expr)-1])
+		if err != nil || pos < 1 || pos > len(array) {
+			return term{}, fmt.Errorf("bad array access %s", expr)
+		}
+		return array[pos-1], nil
+	}
+	scalar, found := model.scalars[expr]
+	if !found {
+		return term{}, fmt.Errorf("unknown identifier %s", expr)
+	}
+	return scalar, nil
+}
+
+// Parses an array literal (e.g., "[x[1], 3, y]") or an array identifier
+func (model *cpModel) parseArray(expr string) ([]term, error) {
+	expr = strings.TrimSpace(expr)
+	if !strings.HasPrefix(expr, "[") {
+		array, found := model.arrays[expr]
+		if !found {
+			return nil, fmt.Errorf("unknown array %s", expr)
+		}
+		return array, nil
+	}
+	res := []term{}
+	for _, element := range splitCompoundLiteral(expr) {
+		parsed, err := model.parseTerm(element)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, parsed)
+	}
+	return res, nil
+}
+
+// Parses a set literal (e.g., "{1, 3, 4}") or a range (e.g., "1..3")
+func parseSetLiteral(expr string) (map[int64]bool, error) {
+	expr = strings.TrimSpace(expr)
+	res := map[int64]bool{}
+	if bounds := strings.SplitN(expr, "..", 2); len(bounds) == 2 {
+		lo, err := parseConstant(bounds[0])
+		if err != nil {
+			return nil, err
+		}
+		hi, err := parseConstant(bounds[1])
+		if err != nil {
+			return nil, err
+		}
+		for v := lo; v <= hi; v++ {
+			res[v] = true
+		}
+		return res, nil
+	}
+	for _, element := range splitCompoundLiteral(expr) {
+		value, err := parseConstant(element)
+		if err != nil {
+			return nil, err
+		}
+		res[value] = true
+	}
+	return res, nil
+}
+
+// Returns the elements of an array literal or a set literal
+func splitCompoundLiteral(literal string) []string {
+	literal = strings.TrimSpace(literal)
+	literal = strings.TrimPrefix(strings.TrimPrefix(literal, "["), "{")
+	literal = strings.TrimSuffix(strings.TrimSuffix(literal, "]"), "}")
+	if strings.TrimSpace(literal) == "" {
+		return []string{}
+	}
+	elements := strings.Split(literal, ",")
+	for i := range elements {
+		elements[i] = strings.TrimSpace(elements[i])
+	}
+	return elements
+}
+
+// Parses an integer or a Boolean constant (Booleans are represented as 0 and 1)
+func parseConstant(expr string) (int64, error) {
+	switch strings.TrimSpace(expr) {
+	case TrueValue:
+		return 1, nil
+	case FalseValue:
+		return 0, nil
+	}
+	value, err := strconv.Atoi(strings.TrimSpace(expr))
+	return int64(value), err
+}
+
+// ***********
+// Search
+// ***********
+
+var errSearchTimeout = errors.New("time limit exceeded before a solution was found")
+
+type cpSearch struct {
+	model     *cpModel
+	watchers  [][]int // for each variable, the propagators it participates in
+	deadline  time.Time
+	nodes     int
+	timedOut  bool
+	best      *cpState
+	bestValue int64 // the objective value of best (negated for maximize goals)
+}
+
+func newCPSearch(model *cpModel, deadline time.Time) *cpSearch {
+	search := cpSearch{model: model, watchers: make([][]int, len(model.doms)), deadline: deadline}
+	for propIdx, prop := range model.props {
+		for _, t := range prop.terms() {
+			if t.varIdx >= 0 {
+				search.watchers[t.varIdx] = append(search.watchers[t.varIdx], propIdx)
+			}
+		}
+	}
+	return &search
+}
+
+func (search *cpSearch) run() (CPSolution, error) {
+	root := &cpState{doms: make([]domain, len(search.model.doms))}
+	copy(root.doms, search.model.doms)
+	allProps := make([]int, len(search.model.props))
+	for i := range allProps {
+		allProps[i] = i
+	}
+	if search.propagate(root, allProps) {
+		search.dfs(root)
+	}
+	if search.best == nil {
+		if search.timedOut {
+			return nil, errSearchTimeout
+		}
+		return CPSolution{}, nil // UNSAT
+	}
+	return search.solution(search.best), nil
+}
+
+// Runs the given propagators, and any propagator whose variables are changed, until a fixpoint is reached
+func (search *cpSearch) propagate(st *cpState, initialProps []int) bool {
+	queue := append([]int{}, initialProps...)
+	inQueue := make(map[int]bool, len(queue))
+	for _, propIdx := range queue {
+		inQueue[propIdx] = true
+	}
+	enqueueWatchers := func() {
+		for _, varIdx := range st.dirty {
+			for _, watcher := range search.watchers[varIdx] {
+				if !inQueue[watcher] {
+					inQueue[watcher] = true
+					queue = append(queue, watcher)
+				}
+			}
+		}
+		st.dirty = st.dirty[:0]
+	}
+	enqueueWatchers() // propagators of variables changed before this call
+	for len(queue) > 0 {
+		propIdx := queue[0]
+		queue = queue[1:]
+		inQueue[propIdx] = false
+		if !search.model.props[propIdx].propagate(st) {
+			return false
+		}
+		enqueueWatchers()
+	}
+	return true
+}
+
+// Returns false if search should stop
+func (search *cpSearch) dfs(st *cpState) bool {
+	search.nodes++
+	if !search.deadline.IsZero() && search.nodes%timeCheckInterval == 0 && time.Now().After(search.deadline) {
+		search.timedOut = true
+		return false
+	}
+
+	varIdx := search.selectVariable(st)
+	if varIdx < 0 { // all variables are fixed: a solution is found
+		return search.recordSolution(st)
+	}
+
+	dom := &st.doms[varIdx]
+	var branches [][2]int64 // each branch restricts the variable to a range
+	if dom.size() <= maxEnumeratedDomainSize {
+		for _, v := range st.values(varTerm(varIdx)) {
+			branches = append(branches, [2]int64{v, v})
+		}
+	} else {
+		mid := (dom.lo + dom.hi) >> 1 // bisect the domain
+		branches = [][2]int64{{dom.lo, mid}, {mid + 1, dom.hi}}
+	}
+
+	for _, branch := range branches {
+		child := st.clone()
+		if !child.setMin(varTerm(varIdx), branch[0]) || !child.setMax(varTerm(varIdx), branch[1]) {
+			continue
+		}
+		if !search.boundObjective(child) || !search.propagate(child, nil) {
+			continue
+		}
+		if !search.dfs(child) {
+			return false
+		}
+	}
+	return true
+}
+
+// Selects an unfixed variable to branch on, or returns -1 if all variables are fixed.
+// Decision variables come first, and among them the one with the smallest domain.
+func (search *cpSearch) selectVariable(st *cpState) int {
+	selected := -1
+	for varIdx := range st.doms {
+		if st.doms[varIdx].size() == 1 {
+			continue
+		}
+		if selected < 0 {
+			selected = varIdx
+			continue
+		}
+		if search.model.isDecision[varIdx] != search.model.isDecision[selected] {
+			if search.model.isDecision[varIdx] {
+				selected = varIdx
+			}
+			continue
+		}
+		if st.doms[varIdx].size() < st.doms[selected].size() {
+			selected = varIdx
+		}
+	}
+	return selected
+}
+
+// Returns false if search should stop
+func (search *cpSearch) recordSolution(st *cpState) bool {
+	if search.model.goal == Satisfy {
+		search.best = st
+		return false
+	}
+	value := st.min(search.model.objective)
+	if search.model.goal == Maximize {
+		value = -value
+	}
+	if search.best == nil || value < search.bestValue {
+		search.best = st
+		search.bestValue = value
+	}
+	return true
+}
+
+// Requires the objective value of any further solution to be better than the best solution so far
+func (search *cpSearch) boundObjective(st *cpState) bool {
+	if search.best == nil || search.model.goal == Satisfy {
+		return true
+	}
+	if search.model.goal == Maximize {
+		return st.setMin(search.model.objective, -search.bestValue+1)
+	}
+	return st.setMax(search.model.objective, search.bestValue-1)
+}
+
+// Translates a search state where all variables are fixed into a CPSolution holding all output variables
+func (search *cpSearch) solution(st *cpState) CPSolution {
+	res := CPSolution{}
+	for name, decl := range search.model.fzModel.VarMap {
+		variable, ok := decl.(*FlatZincVariable)
+		if !ok || !variable.isOutput() {
+			continue
+		}
+		if variable.IsArray {
+			values := []string{}
+			for _, element := range search.model.arrays[name] {
+				values = append(values, formatValue(st.min(element), variable.Type))
+			}
+			res[name] = values
+		} else {
+			res[name] = []string{formatValue(st.min(search.model.scalars[name]), variable.Type)}
+		}
+	}
+	return res
+}
+
+// ----- helper functions -----
+
+func (fzv *FlatZincVariable) hasAnnotation(annotation string) bool {
+	for _, a := range fzv.Annotations {
+		if a == annotation {
+			return true
+		}
+	}
+	return false
+}
+
+func (fzv *FlatZincVariable) isOutput() bool {
+	for _, a := range fzv.Annotations {
+		if a == OutputVarAnnotation || strings.HasPrefix(a, "output_array") {
+			return true
+		}
+	}
+	return false
+}
+
+func formatValue(value int64, varType string) string {
+	if varType == BoolType {
+		return strconv.FormatBool(value != 0)
+	}
+	return strconv.Itoa(int(value))
+}
+
+func floorDiv(a, b int64) int64 {
+	q := a / b
+	if (a%b != 0) && ((a < 0) != (b < 0)) {
+		q--
+	}
+	return q
+}
+
+func ceilDiv(a, b int64) int64 {
+	return -floorDiv(-a, b)
+}
+
+func minInt64(a, b int64) int64 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt64(a, b int64) int64 {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,113 @@
+// Copyright 2023 IBM Corp.
+// SPDX-License-Identifier: Apache-2.0
+
+package optimizer
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// x in 1..3, y in 1..3, x != y, cost = costs[x] + costs[y]
+func getTestCPModel(goal SolveGoal) *FlatZincModel {
+	fzModel := NewFlatZincModel()
+	fzModel.AddParamArray("costs", IntType, 3, "[5, 1, 3]")
+	fzModel.AddVariable("x", "1..3", false, true)
+	fzModel.AddVariable("y", "1..3", false, true)
+	fzModel.AddVariable("xCost", IntType, true, false)
+	fzModel.AddVariable("yCost", IntType, true, false)
+	fzModel.AddVariable("cost", IntType, true, true)
+	fzModel.AddConstraint(IntNotEqConstraint, []string{"x", "y", TrueValue})
+	fzModel.AddConstraint(ArrIntElemConstraint, []string{"x", "costs", "xCost"}, GetDefinesVarAnnotation("xCost"))
+	fzModel.AddConstraint(ArrIntElemConstraint, []string{"y", "costs", "yCost"}, GetDefinesVarAnnotation("yCost"))
+	setVarAsWeightedSum(fzModel, "cost", []string{"xCost", "yCost"}, []string{oneStr, oneStr})
+	fzModel.SetSolveTarget(goal, "cost")
+	return fzModel
+}
+
+func TestCPSolverSatisfy(t *testing.T) {
+	solution, err := NewCPSolver().Solve(getTestCPModel(Satisfy), time.Time{})
+	if err != nil {
+		t.Fatalf("Failed solving constraint problem: %v", err)
+	}
+	if len(solution) == 0 {
+		t.Fatal("Expecting a solution")
+	}
+	if solution["x"][0] == solution["y"][0] {
+		t.Errorf("Solution violates constraints: %v", solution)
+	}
+	if _, found := solution["xCost"]; found {
+		t.Errorf("Solution should only hold output variables: %v", solution)
+	}
+}
+
+func TestCPSolverMinimize(t *testing.T) {
+	solution, err := NewCPSolver().Solve(getTestCPModel(Minimize), time.Time{})
+	if err != nil {
+		t.Fatalf("Failed solving constraint problem: %v", err)
+	}
+	if solution["cost"][0] != "4" {
+		t.Errorf("Expecting an optimal cost of 4, got %v", solution)
+	}
+}
+
+func TestCPSolverMaximize(t *testing.T) {
+	solution, err := NewCPSolver().Solve(getTestCPModel(Maximize), time.Time{})
+	if err != nil {
+		t.Fatalf("Failed solving constraint problem: %v", err)
+	}
+	if solution["cost"][0] != "8" {
+		t.Errorf("Expecting an optimal cost of 8, got %v", solution)
+	}
+}
+
+func TestCPSolverUNSAT(t *testing.T) {
+	fzModel := getTestCPModel(Minimize)
+	fzModel.AddVariableArray("b", BoolType, 2, false, true)
+	fzModel.AddConstraint(BoolNotEqConstraint, []string{"b[1]", "b[2]"})
+	fzModel.AddConstraint(ArrBoolOrConstraint, []string{"b", FalseValue})
+	solution, err := NewCPSolver().Solve(fzModel, time.Time{})
+	if err != nil {
+		t.Fatalf("Failed solving constraint problem: %v", err)
+	}
+	if len(solution) > 0 {
+		t.Errorf("Expecting an empty solution, got %v", solution)
+	}
+}
+
+func TestCPSolverUnsupportedConstraint(t *testing.T) {
+	fzModel := getTestCPModel(Satisfy)
+	fzModel.AddConstraint("int_times", []string{"x", "y", "cost"})
+	if _, err := NewCPSolver().Solve(fzModel, time.Time{}); err == nil {
+		t.Error("Expecting an error on an unsupported constraint")
+	}
+}
+
+func TestCPSolverDataPathModel(t *testing.T) {
+	env := getTestEnv()
+	dpCSP := NewDataPathCSP(getDataInfo(env), env)
+	fzModel, err := dpCSP.BuildModel(2)
+	if err != nil {
+		t.Fatalf("Failed building a CSP model: %s", err)
+	}
+	solverSolution, err := NewCPSolver().Solve(fzModel, time.Time{})
+	if err != nil {
+		t.Fatalf("Failed solving constraint problem: %v", err)
+	}
+	solution, score, err := dpCSP.decodeSolverSolution(solverSolution, 2)
+	if err != nil {
+		t.Fatalf("Failed decoding solution: %v", err)
+	}
+	if len(solution.DataPath) != 2 {
+		t.Fatalf("Expecting a data path of length 2, got %d", len(solution.DataPath))
+	}
+	for _, edge := range solution.DataPath {
+		if edge.Cluster != "cluster2" {
+			t.Errorf("Expecting all modules to be deployed on the cheapest cluster, got %s", edge.Cluster)
+		}
+	}
+	if math.IsNaN(score) {
+		t.Error("Expecting the solution to have a score")
+	}
+}
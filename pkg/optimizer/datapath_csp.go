@@ -5,6 +5,7 @@ package optimizer
 
 import (
 	"fmt"
+	"hash/fnv"
 	"math"
 	"strconv"
 	"strings"
@@ -26,7 +27,7 @@ const (
 	saVarname        = "storageAccount"        // Var's value says which storage account to use
 	srcIntfcVarname  = "moduleSourceInterface" // Var's value says which interface to use as source
 	sinkIntfcVarname = "moduleSinkInterface"   // Var's value says which interface to use as sink
-	actionVarname    = "action_%s"             // Vars for each required action, say whether the action was applied
+	actionVarname    = "action_%s_%x"          // Vars for each required action, say whether the action was applied
 	jointGoalVarname = "jointGoal"             // Var's value indicates the quality of the data path w.r.t. optimization goals
 
 	// The following variables are only allocated and used when inter-region goals are set
@@ -42,6 +43,8 @@ const (
 	minusOneStr = "-1"
 	zeroStr     = "0"
 	oneStr      = "1"
+
+	floatToIntRatio = 100. // goal weights are multiplied by this ratio to make them integers
 )
 
 // Couples together a module and one of its capabilities
@@ -65,6 +68,15 @@ type DataPathCSP struct {
 	requiredActions     map[string]taxonomy.Action  // A map from action variables to the actions they represent
 	fzModel             *FlatZincModel
 	noStorageAccountVal int
+	partOfJointModel    bool          // usage goals are not added, as they are computed by JointDataPathCSP over all data paths
+	goals               []goalInModel // The optimization goals in the model, and the variables holding their values
+	excludedSolutions   int           // The number of solutions excluded from the model by excludeSolution()
+}
+
+// Couples together an optimization goal and the model variable holding its value
+type goalInModel struct {
+	goal    adminconfig.AttributeOptimization
+	varname string
 }
 
 // The ctor also enumerates all available (module x capabilities) and all available interfaces
@@ -157,13 +169,23 @@ func (dpc *DataPathCSP) addInterface(intfc *taxonomy.Interface) {
 	}
 }
 
-// This is the main method for building a FlatZinc CSP out of the data-path parameters and constraints.
+// Builds a FlatZinc CSP out of the data-path parameters and constraints, and dumps it to a file.
 // Returns a file name where the model was dumped
+func (dpc *DataPathCSP) BuildFzModel(pathLength int) (string, error) {
+	fzModel, err := dpc.BuildModel(pathLength)
+	if err != nil {
+		return "", err
+	}
+	return fzModel.Dump()
+}
+
+// This is the main method for building a FlatZinc CSP out of the data-path parameters and constraints.
 //
 // NOTE: Minimal index of FlatZinc arrays is always 1. Hence, we use 1-based modeling all over the place to avoid
 // confusion. The only exception is with interfaces (0 means nil)
-func (dpc *DataPathCSP) BuildFzModel(pathLength int) (string, error) {
+func (dpc *DataPathCSP) BuildModel(pathLength int) (*FlatZincModel, error) {
 	dpc.fzModel.Clear() // This function can be called multiple times - clear vars and constraints from last call
+	dpc.excludedSolutions = 0
 	// Variables to select the module capability we use on each data-path location
 	moduleCapabilityVarType := fznRangeVarType(1, len(dpc.modulesCapabilities))
 	dpc.fzModel.AddVariableArray(modCapVarname, moduleCapabilityVarType, pathLength, false, true)
@@ -183,16 +205,17 @@ func (dpc *DataPathCSP) BuildFzModel(pathLength int) (string, error) {
 
 	dpc.addInterfaceConstraints(pathLength)
 	dpc.addGovernanceActionConstraints(pathLength)
+	dpc.addStorageQuotaConstraints(pathLength)
 	err := dpc.addAdminConfigRestrictions(pathLength)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	err = dpc.addOptimizationGoals(pathLength)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	return dpc.fzModel.Dump()
+	return dpc.fzModel, nil
 }
 
 // enforce restrictions from admin configuration decisions.
@@ -350,6 +373,15 @@ func (dpc *DataPathCSP) addGovernanceActionConstraints(pathLength int) {
 	}
 }
 
+// Prevents selecting storage accounts whose quota does not allow storing the dataset
+func (dpc *DataPathCSP) addStorageQuotaConstraints(pathLength int) {
+	for saIdx, sa := range dpc.env.StorageAccounts {
+		if !sa.HasCapacity(dpc.problemData.Context.Requirements.FlowParams.StorageEstimate) {
+			preventAssignments(dpc.fzModel, []string{saVarname}, []int{saIdx + 1}, pathLength)
+		}
+	}
+}
+
 // Returns an *output* array of Booleans variable to mark whether the current action is applied at location i
 func (dpc *DataPathCSP) addActionIndicator(action taxonomy.Action, pathLength int) string {
 	actionVar := getActionVarname(action)
@@ -362,9 +394,10 @@ func (dpc *DataPathCSP) addActionIndicator(action taxonomy.Action, pathLength in
 	// accumulate module-capabilities that support the current action
 	moduleCapabilitiesStrs := []string{}
 	for modCapIdx, modCap := range dpc.modulesCapabilities {
-		for _, capAction := range modCap.capability.Actions {
-			if capAction.Name == action.Name {
+		for i := range modCap.capability.Actions {
+			if modCap.capability.Actions[i].Supports(&action) {
 				moduleCapabilitiesStrs = append(moduleCapabilitiesStrs, strconv.Itoa(modCapIdx+1))
+				break
 			}
 		}
 	}
@@ -439,7 +472,11 @@ func (dpc *DataPathCSP) setSAIntfc(pathLength int) {
 }
 
 // Return a list of indexes of interfaces that match the input interface
+// A nil interface (e.g., the sink of a delete flow) is only matched by the nil interface
 func (dpc *DataPathCSP) getMatchingInterfaces(refIntfc *taxonomy.Interface) []string {
+	if refIntfc == nil {
+		return []string{zeroStr}
+	}
 	res := []string{}
 	for intfc, intfcIdx := range dpc.interfaceIdx {
 		if interfacesMatch(refIntfc, &intfc) {
@@ -465,6 +502,9 @@ func (dpc *DataPathCSP) modCapSupportsIntfc(pathLength int) {
 				modcapSupportsIntfcSrc = modcapSupportsIntfcSrc || modCap.virtualSource && interfacesMatch(apiIntfc, &intfc)
 				modcapSupportsIntfcSink = modcapSupportsIntfcSink || modCap.virtualSink && interfacesMatch(apiIntfc, &intfc)
 			}
+			// a module-capability without a source (sink) interface uses the nil interface as its source (sink)
+			modcapSupportsIntfcSrc = modcapSupportsIntfcSrc || !modCap.hasSource && intfcIdx == 0
+			modcapSupportsIntfcSink = modcapSupportsIntfcSink || !modCap.hasSink && intfcIdx == 0
 			if !modcapSupportsIntfcSrc {
 				preventAssignments(dpc.fzModel, []string{modCapVarname, srcIntfcVarname}, []int{modCapIdx + 1, intfcIdx}, pathLength)
 			}
@@ -478,7 +518,7 @@ func (dpc *DataPathCSP) modCapSupportsIntfc(pathLength int) {
 // If there are optimization goals set, defines appropriate variables and sets the CSP-solver optimization goal
 // Otherwise, just sets the CSP-solver goal as "satisfy"
 func (dpc *DataPathCSP) addOptimizationGoals(pathLength int) error {
-	const floatToIntRatio = 100.
+	dpc.goals = []goalInModel{}
 	goalVarnames := []string{}
 	weights := []string{}
 	for _, goal := range dpc.problemData.Configuration.OptimizationStrategy {
@@ -489,36 +529,64 @@ func (dpc *DataPathCSP) addOptimizationGoals(pathLength int) error {
 		if goalVarname == "" {
 			continue
 		}
-		floatWeight := 1.
-		if weight != "" {
-			floatWeight, err = strconv.ParseFloat(weight, 64) //nolint:revive // Ignore magic number 64
-			if err != nil {
-				return err
-			}
+		intWeight, err := getIntWeight(weight)
+		if err != nil {
+			return err
 		}
+		dpc.goals = append(dpc.goals, goalInModel{goal: goal, varname: goalVarname})
 		goalVarnames = append(goalVarnames, goalVarname)
-		weights = append(weights, strconv.Itoa(int(floatWeight*floatToIntRatio)))
+		weights = append(weights, intWeight)
 	}
+	setJointGoal(dpc.fzModel, goalVarnames, weights)
+	return nil
+}
 
+// If there are goals, sets the CSP-solver goal to minimize their weighted sum. Otherwise, just sets it to "satisfy"
+func setJointGoal(fzModel *FlatZincModel, goalVarnames, weights []string) {
 	if len(goalVarnames) == 0 { // No optimization goals. Just satisfy constraints
-		dpc.fzModel.SetSolveTarget(Satisfy, "")
-	} else {
-		dpc.fzModel.AddVariable(jointGoalVarname, IntType, true, true)
-		setVarAsWeightedSum(dpc.fzModel, jointGoalVarname, goalVarnames, weights)
-		dpc.fzModel.SetSolveTarget(Minimize, jointGoalVarname)
+		fzModel.SetSolveTarget(Satisfy, "")
+		return
 	}
-	return nil
+	fzModel.AddVariable(jointGoalVarname, IntType, true, true)
+	setVarAsWeightedSum(fzModel, jointGoalVarname, goalVarnames, weights)
+	fzModel.SetSolveTarget(Minimize, jointGoalVarname)
 }
 
-// Adds variables to calculate the value of a single optimization goal
-// Returns the variable containing the goal's value and its relative weight (as a string)
-func (dpc *DataPathCSP) addAnOptimizationGoal(goal adminconfig.AttributeOptimization, pathLen int) (string, string, error) {
+// Returns the weight of a goal (as a string), negated if the goal should be maximized
+func getGoalWeight(goal adminconfig.AttributeOptimization) string {
 	weight := goal.Weight
 	if goal.Directive == adminconfig.Maximize && weight != "" {
 		weight = "-" + weight
 	}
+	return weight
+}
+
+// Translates a goal weight (a float, given as a string) to an integer weight (as a string)
+func getIntWeight(weight string) (string, error) {
+	floatWeight := 1.
+	if weight != "" {
+		var err error
+		floatWeight, err = strconv.ParseFloat(weight, 64) //nolint:revive // Ignore magic number 64
+		if err != nil {
+			return "", err
+		}
+	}
+	return strconv.Itoa(int(floatWeight * floatToIntRatio)), nil
+}
 
+// Adds variables to calculate the value of a single optimization goal
+// Returns the variable containing the goal's value and its relative weight (as a string)
+func (dpc *DataPathCSP) addAnOptimizationGoal(goal adminconfig.AttributeOptimization, pathLen int) (string, string, error) {
+	weight := getGoalWeight(goal)
 	attribute := goal.Attribute
+	if isUsageCountAttribute(attribute) {
+		if dpc.partOfJointModel {
+			return "", "", nil
+		}
+		paths := []dataPathInModel{{dpc: dpc, pathLen: pathLen}}
+		return addUsageCountGoal(dpc.fzModel, attribute, paths, dpc.env), weight, nil
+	}
+
 	instanceTypes := dpc.env.AttributeManager.GetInstanceTypes(attribute)
 	if len(instanceTypes) == 0 {
 		return "", "", fmt.Errorf("no infrastructure data for attribute %s", attribute)
@@ -537,7 +605,7 @@ func (dpc *DataPathCSP) addAnOptimizationGoal(goal adminconfig.AttributeOptimiza
 	}
 
 	goalSumVarname := fmt.Sprintf("goal%sSum", sanitizedAttr)
-	dpc.fzModel.AddVariable(goalSumVarname, IntType, true, false)
+	dpc.fzModel.AddVariable(goalSumVarname, IntType, true, true)
 	setVarAsSimpleSumOfVarArray(dpc.fzModel, goalSumVarname, goalVarname)
 	return goalSumVarname, weight, nil
 }
@@ -782,11 +850,7 @@ func (dpc *DataPathCSP) getSolutionActionsAtPos(solverSolution CPSolution, pathP
 // Translates a solver's solution into a FybrikApplication Solution for a given data-path
 // Also returns the score of the solution (the smaller the better) if such exists, and NaN otherwise
 // TODO: better handle error messages
-func (dpc *DataPathCSP) decodeSolverSolution(solverSolutionStr string, pathLen int) (datapath.Solution, float64, error) {
-	solverSolution, err := dpc.fzModel.ReadBestSolution(solverSolutionStr)
-	if err != nil {
-		return datapath.Solution{}, math.NaN(), err
-	}
+func (dpc *DataPathCSP) decodeSolverSolution(solverSolution CPSolution, pathLen int) (datapath.Solution, float64, error) {
 	if len(solverSolution) == 0 {
 		return datapath.Solution{}, math.NaN(), nil // UNSAT
 	}
@@ -829,6 +893,7 @@ func (dpc *DataPathCSP) decodeSolverSolution(solverSolutionStr string, pathLen i
 
 	score := math.NaN()
 	if scoreStr, found := solverSolution[jointGoalVarname]; found {
+		var err error
 		score, err = strconv.ParseFloat(scoreStr[0], 64) //nolint:revive // Ignore magic number 64
 		if err != nil {
 			score = math.NaN()
@@ -838,14 +903,52 @@ func (dpc *DataPathCSP) decodeSolverSolution(solverSolutionStr string, pathLen i
 	return solution, score, nil
 }
 
+// Returns the values of the optimization goals in a solver's solution
+func (dpc *DataPathCSP) decodeGoalScores(solverSolution CPSolution) []datapath.GoalScore {
+	goalScores := []datapath.GoalScore{}
+	for _, goal := range dpc.goals {
+		value := math.NaN()
+		if valueStr, found := solverSolution[goal.varname]; found {
+			if parsedValue, err := strconv.ParseFloat(valueStr[0], 64); err == nil { //nolint:revive // Ignore magic number 64
+				value = parsedValue
+			}
+		}
+		goalScores = append(goalScores, datapath.GoalScore{Goal: goal.goal, Value: value})
+	}
+	return goalScores
+}
+
+// Adds a constraint forbidding the combination of module capabilities, clusters and storage accounts in the given
+// solver's solution, so that solving the model again yields a different data path
+func (dpc *DataPathCSP) excludeSolution(solverSolution CPSolution, pathLen int) {
+	dpc.excludedSolutions++
+	differsVarname := fmt.Sprintf("differsFromSolution%d", dpc.excludedSolutions)
+	decisionVarnames := []string{modCapVarname, clusterVarname, saVarname}
+	dpc.fzModel.AddVariableArray(differsVarname, BoolType, len(decisionVarnames)*pathLen, true, false)
+	differsIdx := 1
+	for _, varname := range decisionVarnames {
+		for pathPos := 1; pathPos <= pathLen; pathPos++ {
+			differsAtPos := varAtPos(differsVarname, differsIdx)
+			value := solverSolution[varname][pathPos-1]
+			dpc.fzModel.AddConstraint(IntNotEqConstraint, []string{varAtPos(varname, pathPos), value, differsAtPos},
+				GetDefinesVarAnnotation(differsAtPos))
+			differsIdx++
+		}
+	}
+	dpc.fzModel.AddConstraint(ArrBoolOrConstraint, []string{differsVarname, TrueValue})
+}
+
 // ----- helper functions -----
 
 func encodingComment(index int, encodedVal string) string {
 	return fmt.Sprintf("%d - %s", index, encodedVal)
 }
 
+// Actions with the same name and different properties, e.g., row filters with different predicates, get separate variables
 func getActionVarname(action taxonomy.Action) string {
-	return fmt.Sprintf(actionVarname, action.Name)
+	hash := fnv.New32a()
+	hash.Write([]byte(action.Encode()))
+	return fmt.Sprintf(actionVarname, action.Name, hash.Sum32())
 }
 
 func getAssetInterface(connection *datacatalog.GetAssetResponse) taxonomy.Interface {
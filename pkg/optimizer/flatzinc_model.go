@@ -36,6 +36,7 @@ const (
 	BoolLinLeConstraint  = "bool_lin_le"
 	BoolNotEqConstraint  = "bool_not"
 	ArrBoolOrConstraint  = "array_bool_or"
+	ArrBoolAndConstraint = "array_bool_and"
 	IntEqConstraint      = "int_eq_reif"
 	IntNotEqConstraint   = "int_ne_reif"
 	IntLeConstraint      = "int_le_reif"
@@ -284,6 +285,50 @@ func (fzw *FlatZincModel) SetSolveTarget(goal SolveGoal, expr string, annotation
 	fzw.SolveTarget = FlatZincSolveItem{goal, expr, annotations}
 }
 
+// Adds all params, variables and constraints of another model to this model.
+// All identifiers of the other model are prefixed by the given prefix, to avoid name collisions.
+// The solve item of the other model is ignored.
+func (fzw *FlatZincModel) AddSubModel(prefix string, subModel *FlatZincModel) {
+	rename := func(expr string) string {
+		return identifierRegexp.ReplaceAllStringFunc(expr, func(identifier string) string {
+			_, isParam := subModel.ParamMap[identifier]
+			_, isVar := subModel.VarMap[identifier]
+			if isParam || isVar {
+				return prefix + identifier
+			}
+			return identifier
+		})
+	}
+	renameAll := func(exprs []string) []string {
+		res := make([]string, len(exprs))
+		for i, expr := range exprs {
+			res[i] = rename(expr)
+		}
+		return res
+	}
+
+	if subModel.HeaderComments != "" {
+		for _, line := range strings.Split(strings.TrimSuffix(subModel.HeaderComments, "\n"), "\n") {
+			fzw.AddHeaderComment(prefix + ": " + strings.TrimPrefix(line, "% "))
+		}
+	}
+	for name, decl := range subModel.ParamMap {
+		if param, ok := decl.(*FlatZincParam); ok {
+			fzw.ParamMap[prefix+name] = &FlatZincParam{Name: prefix + name, Type: param.Type, Size: param.Size,
+				IsArray: param.IsArray, Assignment: rename(param.Assignment)}
+		}
+	}
+	for name, decl := range subModel.VarMap {
+		if variable, ok := decl.(*FlatZincVariable); ok {
+			fzw.VarMap[prefix+name] = &FlatZincVariable{Name: prefix + name, Type: variable.Type, Size: variable.Size,
+				IsArray: variable.IsArray, Assignment: rename(variable.Assignment), Annotations: renameAll(variable.Annotations)}
+		}
+	}
+	for _, constraint := range subModel.Constraints {
+		fzw.AddConstraint(constraint.Identifier, renameAll(constraint.Expressions), renameAll(constraint.Annotations)...)
+	}
+}
+
 func (fzw *FlatZincModel) Clear() {
 	fzw.ParamMap = map[string]Declares{}
 	fzw.VarMap = map[string]Declares{}
@@ -303,6 +348,14 @@ func (fzw *FlatZincModel) Dump() (string, error) {
 		}
 	}()
 
+	if _, err := file.WriteString(fzw.String()); err != nil {
+		return file.Name(), err
+	}
+	return file.Name(), nil
+}
+
+// returns the FlatZinc model using the FlatZinc syntax
+func (fzw *FlatZincModel) String() string {
 	fileContent := fzw.HeaderComments + "\n"
 	for _, fzParam := range mapValuesSortedByKey(fzw.ParamMap) {
 		fileContent += fzParam.Declaration()
@@ -318,11 +371,7 @@ func (fzw *FlatZincModel) Dump() (string, error) {
 		fileContent += constraint.constraintStatement()
 	}
 
-	fileContent += "\n" + fzw.SolveTarget.solveItemStatement()
-	if _, err := file.WriteString(fileContent); err != nil {
-		return file.Name(), err
-	}
-	return file.Name(), nil
+	return fileContent + "\n" + fzw.SolveTarget.solveItemStatement()
 }
 
 // Parses a single variable assignment line in a FlatZinc solution file. Returns the variable name and its value(s)
@@ -427,6 +476,8 @@ func (fzw *FlatZincModel) ReadBestSolutionFromFile(fileName string) (CPSolution,
 
 // helper functions
 
+var identifierRegexp = regexp.MustCompile("[A-Za-z][A-Za-z0-9_]*")
+
 func getFileContent(fileName string) (string, error) {
 	data, err := os.ReadFile(path.Clean(fileName))
 	if err != nil {
@@ -28,6 +28,26 @@ func TestWriteModel(t *testing.T) {
 	}
 }
 
+func TestAddSubModel(t *testing.T) {
+	subModel := NewFlatZincModel()
+	subModel.AddParamArray("costs", IntType, 2, "[1, 2]")
+	subModel.AddVariable("x", "1..2", false, true)
+	subModel.AddVariable("xCost", IntType, true, false)
+	subModel.AddConstraint(ArrIntElemConstraint, []string{"x", "costs", "xCost"}, GetDefinesVarAnnotation("xCost"))
+	jointModel := NewFlatZincModel()
+	jointModel.AddSubModel("m1_", subModel)
+	jointModel.AddSubModel("m2_", subModel)
+	for _, name := range []string{"m1_x", "m1_xCost", "m2_x", "m2_xCost"} {
+		if _, found := jointModel.VarMap[name]; !found {
+			t.Errorf("Expecting variable %s in joint model", name)
+		}
+	}
+	expected := []string{"m2_x", "m2_costs", "m2_xCost"}
+	if len(jointModel.Constraints) != 2 || !reflect.DeepEqual(jointModel.Constraints[1].Expressions, expected) {
+		t.Errorf("Expecting constraint expressions %v, got %v", expected, jointModel.Constraints)
+	}
+}
+
 var test1SolutionExpected = []CPSolution{
 	{
 		"Beamtime":   {"8"},
@@ -0,0 +1,148 @@
+// Copyright 2023 IBM Corp.
+// SPDX-License-Identifier: Apache-2.0
+
+package optimizer
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"fybrik.io/fybrik/pkg/datapath"
+)
+
+// JointDataPathCSP produces a single CSP for the data paths of several datasets, and decodes the solver's solutions.
+// The CSP of each dataset is built by its own DataPathCSP and added to the joint model with a per-dataset prefix.
+// The joint optimization goal is the sum of the per-dataset goals, plus goals which count the infrastructure
+// instances used by all data paths together (see usage_goals.go). This allows, e.g., colocating modules of
+// different datasets on the same cluster.
+type JointDataPathCSP struct {
+	dpcs    []*DataPathCSP
+	env     *datapath.Environment
+	fzModel *FlatZincModel
+}
+
+func NewJointDataPathCSP(problemData []datapath.DataInfo, env *datapath.Environment) *JointDataPathCSP {
+	jointCSP := JointDataPathCSP{env: env, fzModel: NewFlatZincModel()}
+	for idx := range problemData {
+		dpc := NewDataPathCSP(&problemData[idx], env)
+		dpc.partOfJointModel = true
+		jointCSP.dpcs = append(jointCSP.dpcs, dpc)
+	}
+	return &jointCSP
+}
+
+// The prefix of all variables belonging to the data path of the idx-th dataset
+func datasetPrefix(idx int) string {
+	return fmt.Sprintf("ds%d_", idx+1)
+}
+
+// Builds a FlatZinc CSP for all data paths, where the data path of the i-th dataset has length pathLengths[i]
+func (jdpc *JointDataPathCSP) BuildModel(pathLengths []int) (*FlatZincModel, error) {
+	if len(pathLengths) != len(jdpc.dpcs) {
+		return nil, fmt.Errorf("expecting %d path lengths, got %d", len(jdpc.dpcs), len(pathLengths))
+	}
+	jdpc.fzModel = NewFlatZincModel()
+	for idx, dpc := range jdpc.dpcs {
+		subModel, err := dpc.BuildModel(pathLengths[idx])
+		if err != nil {
+			return nil, err
+		}
+		jdpc.fzModel.AddSubModel(datasetPrefix(idx), subModel)
+	}
+	if err := jdpc.addOptimizationGoals(pathLengths); err != nil {
+		return nil, err
+	}
+	return jdpc.fzModel, nil
+}
+
+// Sums the goals of all data paths, and adds the usage-count goals required by any of the datasets.
+// A usage-count goal is weighted by the weight of largest magnitude given to it by the datasets' strategies.
+func (jdpc *JointDataPathCSP) addOptimizationGoals(pathLengths []int) error {
+	goalVarnames := []string{}
+	weights := []string{}
+	for idx := range jdpc.dpcs {
+		datasetGoal := datasetPrefix(idx) + jointGoalVarname
+		if _, found := jdpc.fzModel.VarMap[datasetGoal]; found {
+			goalVarnames = append(goalVarnames, datasetGoal)
+			weights = append(weights, oneStr)
+		}
+	}
+
+	usageWeights := map[string]int{}
+	usageAttributes := []string{}
+	for _, dpc := range jdpc.dpcs {
+		for _, goal := range dpc.problemData.Configuration.OptimizationStrategy {
+			if !isUsageCountAttribute(goal.Attribute) {
+				continue
+			}
+			intWeightStr, err := getIntWeight(getGoalWeight(goal))
+			if err != nil {
+				return err
+			}
+			intWeight, _ := strconv.Atoi(intWeightStr)
+			currWeight, found := usageWeights[goal.Attribute]
+			if !found {
+				usageAttributes = append(usageAttributes, goal.Attribute)
+			}
+			if !found || absInt(intWeight) > absInt(currWeight) {
+				usageWeights[goal.Attribute] = intWeight
+			}
+		}
+	}
+
+	paths := []dataPathInModel{}
+	for idx, dpc := range jdpc.dpcs {
+		paths = append(paths, dataPathInModel{dpc: dpc, prefix: datasetPrefix(idx), pathLen: pathLengths[idx]})
+	}
+	for _, attribute := range usageAttributes {
+		goalVarnames = append(goalVarnames, addUsageCountGoal(jdpc.fzModel, attribute, paths, jdpc.env))
+		weights = append(weights, strconv.Itoa(usageWeights[attribute]))
+	}
+
+	setJointGoal(jdpc.fzModel, goalVarnames, weights)
+	return nil
+}
+
+// Translates a solver's solution into a Solution per dataset.
+// Also returns the score of the joint solution (the smaller the better) if such exists, and NaN otherwise.
+// If the problem is UNSAT, all returned solutions are empty.
+func (jdpc *JointDataPathCSP) decodeSolverSolution(solverSolution CPSolution, pathLengths []int) ([]datapath.Solution, float64, error) {
+	solutions := make([]datapath.Solution, len(jdpc.dpcs))
+	if len(solverSolution) == 0 {
+		return solutions, math.NaN(), nil // UNSAT
+	}
+
+	for idx, dpc := range jdpc.dpcs {
+		prefix := datasetPrefix(idx)
+		subSolution := CPSolution{}
+		for varname, value := range solverSolution {
+			if strings.HasPrefix(varname, prefix) {
+				subSolution[strings.TrimPrefix(varname, prefix)] = value
+			}
+		}
+		solution, _, err := dpc.decodeSolverSolution(subSolution, pathLengths[idx])
+		if err != nil {
+			return nil, math.NaN(), err
+		}
+		solutions[idx] = solution
+	}
+
+	score := math.NaN()
+	if scoreStr, found := solverSolution[jointGoalVarname]; found {
+		var err error
+		score, err = strconv.ParseFloat(scoreStr[0], 64) //nolint:revive // Ignore magic number 64
+		if err != nil {
+			score = math.NaN()
+		}
+	}
+	return solutions, score, nil
+}
+
+func absInt(num int) int {
+	if num < 0 {
+		return -num
+	}
+	return num
+}
@@ -0,0 +1,88 @@
+// Copyright 2023 IBM Corp.
+// SPDX-License-Identifier: Apache-2.0
+
+package optimizer
+
+import (
+	"testing"
+	"time"
+
+	"fybrik.io/fybrik/pkg/adminconfig"
+	"fybrik.io/fybrik/pkg/datapath"
+	"fybrik.io/fybrik/pkg/infrastructure"
+)
+
+// The first dataset minimizes cluster costs, the second only minimizes the number of clusters used by all data paths
+func getJointDataInfo(env *datapath.Environment) []datapath.DataInfo {
+	costDataInfo := getDataInfo(env)
+	countDataInfo := getDataInfo(env)
+	countGoal := adminconfig.AttributeOptimization{Attribute: ClusterCountAttribute, Weight: "1.0", Directive: adminconfig.Minimize}
+	countDataInfo.Configuration.OptimizationStrategy = []adminconfig.AttributeOptimization{countGoal}
+	return []datapath.DataInfo{*costDataInfo, *countDataInfo}
+}
+
+func TestJointBuildModel(t *testing.T) {
+	env := getTestEnv()
+	jointCSP := NewJointDataPathCSP(getJointDataInfo(env), env)
+	fzModel, err := jointCSP.BuildModel([]int{2, 2})
+	if err != nil {
+		t.Fatalf("Failed building a joint CSP model: %s", err)
+	}
+	for _, varname := range []string{"ds1_" + modCapVarname, "ds2_" + modCapVarname, clusterUsedVarname, jointGoalVarname} {
+		if _, found := fzModel.VarMap[varname]; !found {
+			t.Errorf("Joint model is missing variable %s", varname)
+		}
+	}
+	if _, err := jointCSP.BuildModel([]int{2}); err == nil {
+		t.Error("Expecting an error when the number of path lengths does not match the number of datasets")
+	}
+}
+
+func TestJointOptimizerColocation(t *testing.T) {
+	env := getTestEnv()
+	opt := NewJointOptimizer(env, getJointDataInfo(env), NewCPSolver(), &testLog)
+	solutions, err := opt.Solve()
+	if err != nil {
+		t.Fatalf("Failed solving joint constraint problem: %v", err)
+	}
+	if len(solutions) != 2 {
+		t.Fatalf("Expecting 2 solutions, got %d", len(solutions))
+	}
+	for idx, solution := range solutions {
+		if len(solution.DataPath) == 0 {
+			t.Fatalf("Expecting a data path for dataset %d", idx)
+		}
+		for _, edge := range solution.DataPath {
+			if edge.Cluster != "cluster2" {
+				t.Errorf("Expecting modules of dataset %d to be colocated on cluster2, got %s", idx, edge.Cluster)
+			}
+		}
+	}
+}
+
+func TestModuleInstanceCountGoal(t *testing.T) {
+	env := getTestEnv()
+	dataInfo := getDataInfo(env)
+	countGoal := adminconfig.AttributeOptimization{Attribute: ModuleInstanceCountAttribute, Weight: "1.0", Directive: adminconfig.Minimize}
+	dataInfo.Configuration.OptimizationStrategy = []adminconfig.AttributeOptimization{countGoal}
+	dpCSP := NewDataPathCSP(dataInfo, env)
+	fzModel, err := dpCSP.BuildModel(2)
+	if err != nil {
+		t.Fatalf("Failed building a CSP model: %s", err)
+	}
+	solverSolution, err := NewCPSolver().Solve(fzModel, time.Time{})
+	if err != nil {
+		t.Fatalf("Failed solving constraint problem: %v", err)
+	}
+	solution, score, err := dpCSP.decodeSolverSolution(solverSolution, 2)
+	if err != nil {
+		t.Fatalf("Failed decoding solution: %v", err)
+	}
+	if len(solution.DataPath) != 2 {
+		t.Fatalf("Expecting a data path of length 2, got %d", len(solution.DataPath))
+	}
+	// Two different modules must be used (see getDataInfo), each deployed once
+	if score != 2*infrastructure.NormalizationFactor*floatToIntRatio {
+		t.Errorf("Expecting 2 module instances to be used, got score %v", score)
+	}
+}
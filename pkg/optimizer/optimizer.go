@@ -5,21 +5,20 @@
 	This package is for finding optimal data-path under constraints
 	Its main Optimizer class takes data-path and infrastructure metadata, restrictions and optimization goals.
 	Optimizer.Solve() returns a valid and optimal data path from a single DataSet to Workload (if such a path exists).
-	Note that currently only a single dataset is considered in a given optimization problem.
-	Also, more complex data-planes (e.g., DAG shaped) are not yet supported.
+	The JointOptimizer class solves the data paths of several datasets together, optimizing their joint quality.
+	More complex data-planes (e.g., DAG shaped) are not yet supported.
 
 	All relevant data gets translated into a Constraint Satisfaction Problem (CSP) in the FlatZinc format
 	(see https://www.minizinc.org/doc-latest/en/fzn-spec.html)
-	Any FlatZinc-supporting CSP solver can then be called to get an optimal solution.
+	The CSP is then solved by a Solver: either the built-in CPSolver, or any external FlatZinc-supporting CSP solver.
 */
 
 package optimizer
 
 import (
 	"math"
-	"os"
-	"os/exec"
-	"strings"
+	"sort"
+	"time"
 
 	"emperror.dev/errors"
 
@@ -37,47 +36,52 @@ type Optimizer struct {
 	dpc         *DataPathCSP
 	problemData *datapath.DataInfo
 	env         *datapath.Environment
-	solverPath  string
+	solver      Solver
 	log         *zerolog.Logger
+	// TimeLimit bounds the total time of all the models solved by a single call to Solve() or SolveTopK().
+	// A zero value means no time limit.
+	TimeLimit time.Duration
 }
 
-func NewOptimizer(env *datapath.Environment, problemData *datapath.DataInfo, solverPath string, log *zerolog.Logger) *Optimizer {
+func NewOptimizer(env *datapath.Environment, problemData *datapath.DataInfo, solver Solver, log *zerolog.Logger) *Optimizer {
 	opt := Optimizer{dpc: NewDataPathCSP(problemData, env), problemData: problemData,
-		env: env, solverPath: solverPath, log: log}
+		env: env, solver: solver, log: log, TimeLimit: environment.GetCSPTimeLimit()}
 	return &opt
 }
 
-func (opt *Optimizer) getSolution(pathLength int) (string, error) {
-	opt.log.Debug().Msgf("finding solution of length %d", pathLength)
-	modelFile, err := opt.dpc.BuildFzModel(pathLength)
-	if len(modelFile) > 0 {
-		defer os.Remove(modelFile)
-	}
-	if err != nil {
-		return "", errors.Wrap(err, "error building a model")
+// Returns the time at which a solve starting now should stop, or a zero time if there is no time limit
+func deadlineOf(timeLimit time.Duration) time.Time {
+	if timeLimit <= 0 {
+		return time.Time{}
 	}
+	return time.Now().Add(timeLimit)
+}
 
-	solverArgs := []string{modelFile}
-	additionalArgs := environment.GetCSPArgs()
-	if additionalArgs != "" {
-		solverArgs = append(solverArgs, strings.Split(additionalArgs, " ")...)
+func (opt *Optimizer) getSolution(pathLength int, deadline time.Time) (CPSolution, error) {
+	opt.log.Debug().Msgf("finding solution of length %d", pathLength)
+	fzModel, err := opt.dpc.BuildModel(pathLength)
+	if err != nil {
+		return nil, errors.Wrap(err, "error building a model")
 	}
-	opt.log.Debug().Msgf("Executing %s %v", opt.solverPath, solverArgs)
-	// #nosec G204 -- Avoid "Subprocess launched with variable" error
-	solverSolution, err := exec.Command(opt.solverPath, solverArgs...).Output()
+	solverSolution, err := opt.solver.Solve(fzModel, deadline)
 	if err != nil {
-		return "", errors.Wrapf(err, "error executing %s %s", opt.solverPath, modelFile)
+		return nil, errors.Wrap(err, "error solving the model")
 	}
-	return string(solverSolution), nil
+	return solverSolution, nil
 }
 
 // The main method to call for finding a legal and optimal data path
 // Attempts short data-paths first, and gradually increases data-path length.
+// All data-path lengths share the time limit of the optimizer.
 func (opt *Optimizer) Solve() (datapath.Solution, error) {
+	return opt.solveBefore(deadlineOf(opt.TimeLimit))
+}
+
+func (opt *Optimizer) solveBefore(deadline time.Time) (datapath.Solution, error) {
 	bestScore := math.NaN()
 	bestSolution := datapath.Solution{}
 	for pathLen := 1; pathLen <= MaxDataPathDepth; pathLen++ {
-		solverSolution, err := opt.getSolution(pathLen)
+		solverSolution, err := opt.getSolution(pathLen, deadline)
 		if err != nil {
 			return datapath.Solution{}, err
 		}
@@ -95,3 +99,105 @@ func (opt *Optimizer) Solve() (datapath.Solution, error) {
 	}
 	return bestSolution, nil
 }
+
+// SolveTopK returns up to k legal data paths with the best scores, sorted from best to worst.
+// Data paths differ in at least one module capability, cluster or storage account.
+// If no optimization goals are set, shorter data paths come first.
+func (opt *Optimizer) SolveTopK(k int) ([]datapath.ScoredSolution, error) {
+	deadline := deadlineOf(opt.TimeLimit)
+	solutions := []datapath.ScoredSolution{}
+	for pathLen := 1; pathLen <= MaxDataPathDepth; pathLen++ {
+		pathLenSolutions, err := opt.solveTopKOfLength(k, pathLen, deadline)
+		if err != nil {
+			return nil, err
+		}
+		solutions = append(solutions, pathLenSolutions...)
+	}
+	sort.SliceStable(solutions, func(i, j int) bool {
+		return solutions[i].Score < solutions[j].Score
+	})
+	if len(solutions) > k {
+		solutions = solutions[:k]
+	}
+	return solutions, nil
+}
+
+// Returns up to k legal data paths of the given length, by repeatedly solving the model
+// and excluding the data path found from the next solution
+func (opt *Optimizer) solveTopKOfLength(k, pathLength int, deadline time.Time) ([]datapath.ScoredSolution, error) {
+	opt.log.Debug().Msgf("finding %d best solutions of length %d", k, pathLength)
+	fzModel, err := opt.dpc.BuildModel(pathLength)
+	if err != nil {
+		return nil, errors.Wrap(err, "error building a model")
+	}
+	solutions := []datapath.ScoredSolution{}
+	for len(solutions) < k {
+		solverSolution, err := opt.solver.Solve(fzModel, deadline)
+		if err != nil {
+			return nil, errors.Wrap(err, "error solving the model")
+		}
+		solution, score, err := opt.dpc.decodeSolverSolution(solverSolution, pathLength)
+		if err != nil {
+			return nil, err
+		}
+		if len(solution.DataPath) == 0 { // no more solutions of this length
+			break
+		}
+		goals := opt.dpc.decodeGoalScores(solverSolution)
+		solutions = append(solutions, datapath.ScoredSolution{Solution: solution, Score: score, Goals: goals})
+		opt.dpc.excludeSolution(solverSolution, pathLength)
+	}
+	return solutions, nil
+}
+
+// JointOptimizer finds legal data paths for several datasets together, optimizing their joint quality.
+// Such joint optimization is needed for goals that depend on all data paths (e.g., the number of clusters used).
+type JointOptimizer struct {
+	jdpc        *JointDataPathCSP
+	problemData []datapath.DataInfo
+	env         *datapath.Environment
+	solver      Solver
+	log         *zerolog.Logger
+	// TimeLimit bounds the total time of a call to Solve(), including the optimization of each dataset on its own.
+	// A zero value means no time limit.
+	TimeLimit time.Duration
+}
+
+func NewJointOptimizer(env *datapath.Environment, problemData []datapath.DataInfo, solver Solver, log *zerolog.Logger) *JointOptimizer {
+	opt := JointOptimizer{jdpc: NewJointDataPathCSP(problemData, env), problemData: problemData,
+		env: env, solver: solver, log: log, TimeLimit: environment.GetCSPTimeLimit()}
+	return &opt
+}
+
+// The main method to call for finding legal and jointly-optimal data paths for all datasets.
+// The length of each data path is first fixed by optimizing each dataset on its own.
+// Then, all data paths (with their fixed lengths) are optimized together.
+// If any of the datasets has no legal data path, all returned solutions are empty.
+func (opt *JointOptimizer) Solve() ([]datapath.Solution, error) {
+	deadline := deadlineOf(opt.TimeLimit)
+	solutions := make([]datapath.Solution, len(opt.problemData))
+	pathLengths := make([]int, len(opt.problemData))
+	for idx := range opt.problemData {
+		solution, err := NewOptimizer(opt.env, &opt.problemData[idx], opt.solver, opt.log).solveBefore(deadline)
+		if err != nil {
+			return nil, err
+		}
+		if len(solution.DataPath) == 0 {
+			opt.log.Debug().Msgf("no data path found for dataset %s", opt.problemData[idx].Context.DataSetID)
+			return solutions, nil
+		}
+		pathLengths[idx] = len(solution.DataPath)
+	}
+
+	opt.log.Debug().Msgf("finding joint solution with path lengths %v", pathLengths)
+	fzModel, err := opt.jdpc.BuildModel(pathLengths)
+	if err != nil {
+		return nil, errors.Wrap(err, "error building a joint model")
+	}
+	solverSolution, err := opt.solver.Solve(fzModel, deadline)
+	if err != nil {
+		return nil, errors.Wrap(err, "error solving the joint model")
+	}
+	solutions, _, err = opt.jdpc.decodeSolverSolution(solverSolution, pathLengths)
+	return solutions, err
+}
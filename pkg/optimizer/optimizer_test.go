@@ -4,8 +4,8 @@
 package optimizer
 
 import (
-	"os"
 	"testing"
+	"time"
 
 	"fybrik.io/fybrik/pkg/logging"
 )
@@ -14,7 +14,7 @@ var testLog = logging.LogInit("Optimizer", "Test")
 
 func TestOptimizer(t *testing.T) {
 	env := getTestEnv()
-	opt := NewOptimizer(env, getDataInfo(env), os.Getenv("CSP_PATH"), &testLog)
+	opt := NewOptimizer(env, getDataInfo(env), NewSolverFromEnv(), &testLog)
 	solution, err := opt.Solve()
 	if err != nil {
 		t.Fatalf("Failed solving constraint problem: %v", err)
@@ -30,3 +30,85 @@ func TestOptimizer(t *testing.T) {
 		t.Log(edge)
 	}
 }
+
+func TestOptimizerTopK(t *testing.T) {
+	env := getTestEnv()
+	opt := NewOptimizer(env, getDataInfo(env), NewCPSolver(), &testLog)
+	solutions, err := opt.SolveTopK(3)
+	if err != nil {
+		t.Fatalf("Failed solving constraint problem: %v", err)
+	}
+	if len(solutions) != 3 {
+		t.Fatalf("Expecting 3 solutions, got %d", len(solutions))
+	}
+	for idx := range solutions {
+		if len(solutions[idx].Goals) != 1 || solutions[idx].Goals[0].Goal.Attribute != "ClusterCost" {
+			t.Errorf("Expecting a ClusterCost goal value in solution %d, got %v", idx, solutions[idx].Goals)
+		}
+		if idx > 0 && solutions[idx].Score < solutions[idx-1].Score {
+			t.Errorf("Solutions are not sorted by score: %f < %f", solutions[idx].Score, solutions[idx-1].Score)
+		}
+	}
+	for _, edge := range solutions[0].DataPath {
+		if edge.Cluster != "cluster2" {
+			t.Errorf("Expecting the best solution to only use the cheapest cluster, got %s", edge.Cluster)
+		}
+	}
+}
+
+// deadlineRecorder solves with the built-in solver and records the deadline of each model solved
+type deadlineRecorder struct {
+	CPSolver
+	deadlines []time.Time
+}
+
+func (slv *deadlineRecorder) Solve(fzModel *FlatZincModel, deadline time.Time) (CPSolution, error) {
+	slv.deadlines = append(slv.deadlines, deadline)
+	return slv.CPSolver.Solve(fzModel, deadline)
+}
+
+func checkSingleDeadline(t *testing.T, deadlines []time.Time, start time.Time, timeLimit time.Duration) {
+	if len(deadlines) < 2 {
+		t.Fatalf("Expecting several models to be solved, got %d", len(deadlines))
+	}
+	for _, deadline := range deadlines {
+		if !deadline.Equal(deadlines[0]) {
+			t.Errorf("Expecting all models to share the deadline %v, got %v", deadlines[0], deadline)
+		}
+	}
+	if deadlines[0].Before(start.Add(timeLimit)) || deadlines[0].After(time.Now().Add(timeLimit)) {
+		t.Errorf("Expecting a deadline %v after the start, got %v", timeLimit, deadlines[0].Sub(start))
+	}
+}
+
+func TestOptimizerTimeLimit(t *testing.T) {
+	env := getTestEnv()
+	solver := &deadlineRecorder{}
+	opt := NewOptimizer(env, getDataInfo(env), solver, &testLog)
+	opt.TimeLimit = time.Minute
+	start := time.Now()
+	if _, err := opt.SolveTopK(3); err != nil {
+		t.Fatalf("Failed solving constraint problem: %v", err)
+	}
+	checkSingleDeadline(t, solver.deadlines, start, opt.TimeLimit)
+
+	solver.deadlines = nil
+	jointOpt := NewJointOptimizer(env, getJointDataInfo(env), solver, &testLog)
+	jointOpt.TimeLimit = time.Minute
+	start = time.Now()
+	if _, err := jointOpt.Solve(); err != nil {
+		t.Fatalf("Failed solving joint constraint problem: %v", err)
+	}
+	checkSingleDeadline(t, solver.deadlines, start, jointOpt.TimeLimit)
+
+	solver.deadlines = nil
+	opt.TimeLimit = 0
+	if _, err := opt.Solve(); err != nil {
+		t.Fatalf("Failed solving constraint problem: %v", err)
+	}
+	for _, deadline := range solver.deadlines {
+		if !deadline.IsZero() {
+			t.Errorf("Expecting no deadline without a time limit, got %v", deadline)
+		}
+	}
+}
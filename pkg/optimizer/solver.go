@@ -0,0 +1,76 @@
+// Copyright 2023 IBM Corp.
+// SPDX-License-Identifier: Apache-2.0
+
+package optimizer
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"emperror.dev/errors"
+
+	"fybrik.io/fybrik/pkg/environment"
+)
+
+// Solver is the interface of a backend which finds the best solution to a constraint problem given as a FlatZincModel.
+// If there can be no solution to the constraint problem (UNSAT), Solve() returns an empty CPSolution.
+// If the solver could not decide whether a solution exists, an error is returned.
+// Solving is stopped at the given deadline. A zero deadline means no time limit.
+type Solver interface {
+	Solve(fzModel *FlatZincModel, deadline time.Time) (CPSolution, error)
+}
+
+// ExternalSolver dumps the model to a FlatZinc file and runs a FlatZinc-supporting solver executable on it
+type ExternalSolver struct {
+	Path string
+	Args []string
+}
+
+func NewExternalSolver(solverPath string, args ...string) *ExternalSolver {
+	return &ExternalSolver{Path: solverPath, Args: args}
+}
+
+// Solve kills the solver executable if it is still running at the deadline
+func (slv *ExternalSolver) Solve(fzModel *FlatZincModel, deadline time.Time) (CPSolution, error) {
+	modelFile, err := fzModel.Dump()
+	if len(modelFile) > 0 {
+		defer os.Remove(modelFile)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "error dumping the model")
+	}
+
+	ctx := context.Background()
+	if !deadline.IsZero() {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, deadline)
+		defer cancel()
+	}
+	solverArgs := append([]string{modelFile}, slv.Args...)
+	// #nosec G204 -- Avoid "Subprocess launched with variable" error
+	solverOutput, err := exec.CommandContext(ctx, slv.Path, solverArgs...).Output()
+	if ctx.Err() != nil {
+		return nil, errors.Wrapf(ctx.Err(), "time limit exceeded executing %s %s", slv.Path, modelFile)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "error executing %s %s", slv.Path, modelFile)
+	}
+	return fzModel.ReadBestSolution(string(solverOutput))
+}
+
+// NewSolverFromEnv returns the solver backend configured by the environment:
+// an ExternalSolver if a solver executable is specified in CSP_PATH, and the built-in CPSolver otherwise
+func NewSolverFromEnv() Solver {
+	cspPath := environment.GetCSPPath()
+	if cspPath == "" {
+		return NewCPSolver()
+	}
+	additionalArgs := []string{}
+	if args := environment.GetCSPArgs(); args != "" {
+		additionalArgs = strings.Split(args, " ")
+	}
+	return NewExternalSolver(cspPath, additionalArgs...)
+}
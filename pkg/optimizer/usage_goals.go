@@ -0,0 +1,137 @@
+// Copyright 2023 IBM Corp.
+// SPDX-License-Identifier: Apache-2.0
+
+// This file contains functions to add optimization goals which count the infrastructure instances used by one or more data paths
+
+package optimizer
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"fybrik.io/fybrik/pkg/datapath"
+	"fybrik.io/fybrik/pkg/infrastructure"
+)
+
+// Reserved optimization-goal attributes, which are not defined in the infrastructure attributes
+const (
+	ClusterCountAttribute        = "cluster-count"         // The number of clusters on which modules are deployed
+	ModuleInstanceCountAttribute = "module-instance-count" // The number of distinct (module x cluster) deployments
+
+	clusterUsedVarname        = "clusterUsed"        // Var at pos i is true iff some data path deploys a module on the i-th cluster
+	moduleInstanceUsedVarname = "moduleInstanceUsed" // Var at pos i is true iff some data path uses the i-th module instance
+)
+
+// A data path whose variables are part of a (possibly joint) model, under the given variable-name prefix
+type dataPathInModel struct {
+	dpc     *DataPathCSP
+	prefix  string
+	pathLen int
+}
+
+// Returns true if the given optimization attribute counts the usage of infrastructure instances
+func isUsageCountAttribute(attribute string) bool {
+	return attribute == ClusterCountAttribute || attribute == ModuleInstanceCountAttribute
+}
+
+// Adds variables to count the number of clusters or module instances used by the given data paths.
+// An instance that is used by more than one data path is only counted once.
+// Returns the variable holding the goal's value. Each used instance contributes NormalizationFactor to this value,
+// so that the goal is on the same scale as goals defined over normalized infrastructure attributes.
+func addUsageCountGoal(fzModel *FlatZincModel, attribute string, paths []dataPathInModel, env *datapath.Environment) string {
+	goalVarname := fmt.Sprintf("goal%s", sanitizeFznIdentifier(attribute))
+	if _, defined := fzModel.VarMap[goalVarname]; defined {
+		return goalVarname
+	}
+
+	var usedVarname string
+	var instanceIndicators [][]string
+	if attribute == ClusterCountAttribute {
+		usedVarname = clusterUsedVarname
+		instanceIndicators = clusterUsageIndicators(fzModel, paths, env)
+	} else {
+		usedVarname = moduleInstanceUsedVarname
+		instanceIndicators = moduleInstanceUsageIndicators(fzModel, paths, env)
+	}
+
+	fzModel.AddVariable(goalVarname, IntType, true, true)
+	fzModel.AddVariableArray(usedVarname, BoolType, len(instanceIndicators), true, false)
+	for idx, indicators := range instanceIndicators {
+		usedAtPos := varAtPos(usedVarname, idx+1)
+		annotation := GetDefinesVarAnnotation(usedAtPos)
+		fzModel.AddConstraint(ArrBoolOrConstraint, []string{fznCompoundLiteral(indicators, false), usedAtPos}, annotation)
+	}
+	weights := fznCompoundLiteral(arrayOfSameInt(infrastructure.NormalizationFactor, len(instanceIndicators)), false)
+	fzModel.AddConstraint(BoolLinEqConstraint, []string{weights, usedVarname, goalVarname}, GetDefinesVarAnnotation(goalVarname))
+	return goalVarname
+}
+
+// For each cluster, returns the indicators (over all paths and path positions) for a module being deployed on it
+func clusterUsageIndicators(fzModel *FlatZincModel, paths []dataPathInModel, env *datapath.Environment) [][]string {
+	res := [][]string{}
+	for clusterIdx := range env.Clusters {
+		indicators := []string{}
+		for _, path := range paths {
+			clusterInd := equalityIndicator(fzModel, path.prefix+clusterVarname, clusterIdx+1, path.pathLen, true)
+			indicators = append(indicators, arrayOfVarPositions(clusterInd, path.pathLen)...)
+		}
+		res = append(res, indicators)
+	}
+	return res
+}
+
+// For each module and each cluster, returns the indicators (over all paths and path positions) for the module
+// being deployed on the cluster. Module instances which cannot be used by any of the paths are omitted.
+func moduleInstanceUsageIndicators(fzModel *FlatZincModel, paths []dataPathInModel, env *datapath.Environment) [][]string {
+	moduleNames := []string{}
+	for moduleName := range env.Modules {
+		moduleNames = append(moduleNames, moduleName)
+	}
+	sort.Strings(moduleNames)
+
+	res := [][]string{}
+	for _, moduleName := range moduleNames {
+		for clusterIdx := range env.Clusters {
+			indicators := []string{}
+			for _, path := range paths {
+				instanceInd := moduleInstanceIndicator(fzModel, path, moduleName, clusterIdx+1)
+				if instanceInd != "" {
+					indicators = append(indicators, arrayOfVarPositions(instanceInd, path.pathLen)...)
+				}
+			}
+			if len(indicators) > 0 {
+				res = append(res, indicators)
+			}
+		}
+	}
+	return res
+}
+
+// Adds an indicator array whose elements are true iff the given module is deployed on the given cluster at each path pos.
+// Returns an empty string if the module has no allowed capabilities in the given path.
+func moduleInstanceIndicator(fzModel *FlatZincModel, path dataPathInModel, moduleName string, clusterVal int) string {
+	modCapIdxs := []string{}
+	for modCapIdx, modCap := range path.dpc.modulesCapabilities {
+		if modCap.module.Name == moduleName {
+			modCapIdxs = append(modCapIdxs, strconv.Itoa(modCapIdx+1))
+		}
+	}
+	if len(modCapIdxs) == 0 {
+		return ""
+	}
+
+	indicator := fmt.Sprintf("ind_%smoduleInstance_%s_%d", path.prefix, sanitizeFznIdentifier(moduleName), clusterVal)
+	if _, defined := fzModel.VarMap[indicator]; defined {
+		return indicator
+	}
+	moduleInd := setInIndicator(fzModel, path.prefix+modCapVarname, modCapIdxs, path.pathLen)
+	clusterInd := equalityIndicator(fzModel, path.prefix+clusterVarname, clusterVal, path.pathLen, true)
+	fzModel.AddVariableArray(indicator, BoolType, path.pathLen, true, false)
+	for pathPos := 1; pathPos <= path.pathLen; pathPos++ {
+		indicatorAtPos := varAtPos(indicator, pathPos)
+		arrayToAnd := fznCompoundLiteral([]string{varAtPos(moduleInd, pathPos), varAtPos(clusterInd, pathPos)}, false)
+		fzModel.AddConstraint(ArrBoolAndConstraint, []string{arrayToAnd, indicatorAtPos}, GetDefinesVarAnnotation(indicatorAtPos))
+	}
+	return indicator
+}
@@ -0,0 +1,173 @@
+// Copyright 2023 IBM Corp.
+// SPDX-License-Identifier: Apache-2.0
+
+package schedule
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"emperror.dev/errors"
+)
+
+// Schedule is a parsed Cron schedule with the standard five fields: minute, hour, day of month, month and day of week.
+// Each field is a bit set of the values it matches.
+type Schedule struct {
+	minute, hour, dom, month, dow uint64
+	// domStar and dowStar indicate that the day of month and the day of week are not restricted.
+	// If both are restricted, a day matches if it matches either of them, as in cron.
+	domStar, dowStar bool
+}
+
+type bounds struct {
+	min, max uint
+}
+
+var (
+	minutes  = bounds{0, 59}
+	hours    = bounds{0, 23}
+	days     = bounds{1, 31}
+	months   = bounds{1, 12}
+	weekdays = bounds{0, 7} // both 0 and 7 stand for Sunday
+)
+
+// predefined schedules
+var descriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// the maximal time span searched for the next activation, schedules such as "0 0 30 2 *" never activate
+const searchLimit = 5 * 366 * 24 * time.Hour
+
+// Parse parses a Cron schedule, e.g. "0 2 * * *" for every day at 2am.
+// Each field is either "*", a value, a range "a-b" or a list of them separated by commas,
+// optionally followed by a step "/n". The predefined schedules @yearly, @monthly, @weekly, @daily and @hourly
+// are supported as well.
+func Parse(spec string) (*Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if descriptor, found := descriptors[spec]; found {
+		spec = descriptor
+	}
+	fields := strings.Fields(spec)
+	if len(fields) != 5 { //nolint:revive // Ignore magic number
+		return nil, errors.Errorf("invalid schedule %q: expected 5 fields, found %d", spec, len(fields))
+	}
+	s := &Schedule{}
+	var err error
+	if s.minute, err = parseField(fields[0], minutes); err != nil {
+		return nil, errors.Wrapf(err, "invalid minute in schedule %q", spec)
+	}
+	if s.hour, err = parseField(fields[1], hours); err != nil {
+		return nil, errors.Wrapf(err, "invalid hour in schedule %q", spec)
+	}
+	if s.dom, err = parseField(fields[2], days); err != nil {
+		return nil, errors.Wrapf(err, "invalid day of month in schedule %q", spec)
+	}
+	if s.month, err = parseField(fields[3], months); err != nil {
+		return nil, errors.Wrapf(err, "invalid month in schedule %q", spec)
+	}
+	if s.dow, err = parseField(fields[4], weekdays); err != nil {
+		return nil, errors.Wrapf(err, "invalid day of week in schedule %q", spec)
+	}
+	// Sunday is either 0 or 7
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1
+	}
+	s.domStar = strings.HasPrefix(fields[2], "*")
+	s.dowStar = strings.HasPrefix(fields[4], "*")
+	return s, nil
+}
+
+// parseField returns the bit set of the values matched by a comma separated list of ranges
+func parseField(field string, b bounds) (uint64, error) {
+	var bits uint64
+	for _, expr := range strings.Split(field, ",") {
+		rangeExpr, stepExpr, hasStep := strings.Cut(expr, "/")
+		step := uint(1)
+		if hasStep {
+			value, err := strconv.ParseUint(stepExpr, 10, 8) //nolint:revive // Ignore magic numbers
+			if err != nil || value == 0 {
+				return 0, errors.Errorf("invalid step %q", stepExpr)
+			}
+			step = uint(value)
+		}
+		var low, high uint
+		switch {
+		case rangeExpr == "*":
+			low, high = b.min, b.max
+		case strings.Contains(rangeExpr, "-"):
+			lowExpr, highExpr, _ := strings.Cut(rangeExpr, "-")
+			var err error
+			if low, err = parseValue(lowExpr, b); err != nil {
+				return 0, err
+			}
+			if high, err = parseValue(highExpr, b); err != nil {
+				return 0, err
+			}
+			if low > high {
+				return 0, errors.Errorf("invalid range %q", rangeExpr)
+			}
+		default:
+			var err error
+			if low, err = parseValue(rangeExpr, b); err != nil {
+				return 0, err
+			}
+			high = low
+			// "a/n" stands for every n-th value starting with a
+			if hasStep {
+				high = b.max
+			}
+		}
+		for value := low; value <= high; value += step {
+			bits |= 1 << value
+		}
+	}
+	return bits, nil
+}
+
+func parseValue(expr string, b bounds) (uint, error) {
+	value, err := strconv.ParseUint(expr, 10, 8) //nolint:revive // Ignore magic numbers
+	if err != nil || uint(value) < b.min || uint(value) > b.max {
+		return 0, errors.Errorf("value %q is not in the range %d-%d", expr, b.min, b.max)
+	}
+	return uint(value), nil
+}
+
+// Next returns the first activation time of the schedule after the given time.
+// The schedule is evaluated in the location of the given time.
+// The zero time is returned if the schedule does not activate within the next five years.
+func (s *Schedule) Next(after time.Time) time.Time {
+	t := after.Truncate(time.Minute).Add(time.Minute)
+	limit := t.Add(searchLimit)
+	for t.Before(limit) {
+		switch {
+		case s.month&(1<<uint(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+		case !s.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+		case s.hour&(1<<uint(t.Hour())) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+		case s.minute&(1<<uint(t.Minute())) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+func (s *Schedule) dayMatches(t time.Time) bool {
+	domMatch := s.dom&(1<<uint(t.Day())) != 0
+	dowMatch := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domStar || s.dowStar {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
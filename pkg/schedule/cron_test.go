@@ -0,0 +1,50 @@
+// Copyright 2023 IBM Corp.
+// SPDX-License-Identifier: Apache-2.0
+
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/onsi/gomega"
+)
+
+func TestNext(t *testing.T) {
+	t.Parallel()
+	g := gomega.NewGomegaWithT(t)
+	// Sunday
+	start := time.Date(2023, time.January, 1, 10, 30, 0, 0, time.UTC)
+	tests := []struct {
+		spec string
+		next time.Time
+	}{
+		{"0 2 * * *", time.Date(2023, time.January, 2, 2, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2023, time.January, 2, 0, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2023, time.January, 1, 11, 0, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2023, time.January, 1, 10, 45, 0, 0, time.UTC)},
+		{"5/20 10 * * *", time.Date(2023, time.January, 1, 10, 45, 0, 0, time.UTC)},
+		{"0 9-17 * * 1-5", time.Date(2023, time.January, 2, 9, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2023, time.January, 8, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1,15 * *", time.Date(2023, time.January, 15, 0, 0, 0, 0, time.UTC)},
+		// either the day of month or the day of week matches
+		{"0 0 15 * 3", time.Date(2023, time.January, 4, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 0 30 2 *", time.Time{}},
+	}
+	for _, test := range tests {
+		s, err := Parse(test.spec)
+		g.Expect(err).NotTo(gomega.HaveOccurred(), test.spec)
+		g.Expect(s.Next(start)).To(gomega.Equal(test.next), test.spec)
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	t.Parallel()
+	g := gomega.NewGomegaWithT(t)
+	for _, spec := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "* * * 13 *", "* * * * 8",
+		"5-1 * * * *", "*/0 * * * *", "a * * * *", "@often"} {
+		_, err := Parse(spec)
+		g.Expect(err).To(gomega.HaveOccurred(), spec)
+	}
+}
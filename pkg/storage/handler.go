@@ -4,6 +4,7 @@
 package main
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -13,9 +14,14 @@ import (
 	"fybrik.io/fybrik/pkg/logging"
 	"fybrik.io/fybrik/pkg/model/storagemanager"
 	"fybrik.io/fybrik/pkg/storage/registrator"
+	"fybrik.io/fybrik/pkg/storage/registrator/agent"
 
 	// Registration of the implementation agents is done by adding blank imports which invoke init() method of each package
+	_ "fybrik.io/fybrik/pkg/storage/impl/azure"
+	_ "fybrik.io/fybrik/pkg/storage/impl/kafka"
 	_ "fybrik.io/fybrik/pkg/storage/impl/mysql"
+	_ "fybrik.io/fybrik/pkg/storage/impl/postgres"
+	_ "fybrik.io/fybrik/pkg/storage/impl/pvc"
 	_ "fybrik.io/fybrik/pkg/storage/impl/s3"
 )
 
@@ -87,6 +93,35 @@ func (r *Handler) deleteStorage(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"status": "OK"})
 }
 
+// lists the storage allocated in the selected storage account by invoking the specific implementation agent
+func (r *Handler) listStorage(c *gin.Context) {
+	// Parse request
+	var request storagemanager.ListStorageRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		r.Log.Info().Msg(err.Error())
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Error during ShouldBindJSON in listStorage"})
+		return
+	}
+	r.Log.Info().Msgf("listStorage request for %s", request.AccountType)
+	impl, err := registrator.GetAgent(request.AccountType)
+	if err != nil {
+		r.Log.Info().Msg(err.Error())
+		c.JSON(http.StatusNotImplemented, gin.H{"error": UnsupportedTypeError + string(request.AccountType)})
+		return
+	}
+	storage, err := impl.ListStorage(&request, r.Client)
+	if errors.Is(err, agent.ErrListNotSupported) {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		r.Log.Info().Msg(err.Error())
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, &storagemanager.ListStorageResponse{Storage: storage})
+}
+
 // return a list of supported connection types
 func (r *Handler) getSupportedStorageTypes(c *gin.Context) {
 	resp := &storagemanager.GetSupportedStorageTypesResponse{ConnectionTypes: registrator.GetRegisteredTypes()}
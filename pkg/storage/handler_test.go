@@ -7,6 +7,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/gin-gonic/gin"
@@ -38,8 +39,27 @@ func TestSupportedConnections(t *testing.T) {
 		response := &storagemanager.GetSupportedStorageTypesResponse{}
 		err := json.Unmarshal(w.Body.Bytes(), response)
 		g.Expect(err).To(gomega.BeNil())
-		g.Expect(response.ConnectionTypes).To(gomega.HaveLen(2))
+		g.Expect(response.ConnectionTypes).To(gomega.HaveLen(6))
 		g.Expect(response.ConnectionTypes).To(gomega.ContainElement(taxonomy.ConnectionType("s3")))
 		g.Expect(response.ConnectionTypes).To(gomega.ContainElement(taxonomy.ConnectionType("mysql")))
+		g.Expect(response.ConnectionTypes).To(gomega.ContainElement(taxonomy.ConnectionType("kafka")))
+		g.Expect(response.ConnectionTypes).To(gomega.ContainElement(taxonomy.ConnectionType("postgres")))
+		g.Expect(response.ConnectionTypes).To(gomega.ContainElement(taxonomy.ConnectionType("pvc")))
+		g.Expect(response.ConnectionTypes).To(gomega.ContainElement(taxonomy.ConnectionType("azure-blob")))
 	})
 }
+
+// test that listing is rejected for storage types whose allocated storage can not be identified
+func TestListStorageNotSupported(t *testing.T) {
+	t.Parallel()
+	client := fake.NewClientBuilder().WithScheme(runtime.NewScheme()).Build()
+	handler := NewHandler(client)
+
+	w := httptest.NewRecorder()
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(w)
+	body := `{"accountType": "mysql", "accountProperties": {"mysql": {"host": "localhost", "port": 3306}}}`
+	c.Request = httptest.NewRequest(http.MethodPost, "http://localhost/listStorage", strings.NewReader(body))
+	handler.listStorage(c)
+	assert.Equal(t, http.StatusNotImplemented, w.Code)
+}
@@ -0,0 +1,185 @@
+// Copyright 2023 IBM Corp.
+// SPDX-License-Identifier: Apache-2.0
+
+package azure
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"emperror.dev/errors"
+	"github.com/rs/zerolog"
+	kclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"fybrik.io/fybrik/pkg/logging"
+	"fybrik.io/fybrik/pkg/model/storagemanager"
+	"fybrik.io/fybrik/pkg/model/taxonomy"
+	"fybrik.io/fybrik/pkg/random"
+	"fybrik.io/fybrik/pkg/serde"
+	"fybrik.io/fybrik/pkg/storage/registrator"
+	"fybrik.io/fybrik/pkg/storage/registrator/agent"
+	"fybrik.io/fybrik/pkg/utils"
+)
+
+const (
+	azureAgent     = "azure-blob"
+	endpointKey    = "endpoint"
+	containerKey   = "container"
+	objectKey      = "object_key"
+	nameHashLength = 10
+	// container names are 3 to 63 characters long
+	maxContainerNameLen = 63
+)
+
+// metadataKeys are the names of the container metadata that hold the owner tags,
+// since metadata names must be valid C# identifiers
+var metadataKeys = map[string]string{
+	agent.OwnerNameKey:      "fybrik_app_name",
+	agent.OwnerNamespaceKey: "fybrik_app_namespace",
+	agent.OwnerUUIDKey:      "fybrik_app_uuid",
+	agent.PersistentKey:     "fybrik_persistent",
+}
+
+// invalidContainerChars are the characters that are replaced in generated container names
+var invalidContainerChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// Storage manager implementation for Azure Blob compatible stores
+type AzureImpl struct {
+	Name taxonomy.ConnectionType
+	Log  zerolog.Logger
+}
+
+// implementation of AgentInterface for Azure Blob compatible stores
+func NewAzureImpl() *AzureImpl {
+	return &AzureImpl{Name: azureAgent, Log: logging.LogInit(logging.CONNECTOR, "AzureBlobStorageManager")}
+}
+
+// register the implementation for Azure Blob compatible stores
+func init() {
+	azureImpl := NewAzureImpl()
+	if err := registrator.Register(azureImpl); err != nil {
+		azureImpl.Log.Error().Err(err).Send()
+	}
+}
+
+// return the supported connection type
+func (impl *AzureImpl) GetConnectionType() taxonomy.ConnectionType {
+	return impl.Name
+}
+
+// storage allocation
+// A container is created in the blob service of the account, and marked with its owner in its metadata.
+// The credentials of the account are taken from the account_name and account_key of the account secret.
+func (impl *AzureImpl) AllocateStorage(request *storagemanager.AllocateStorageRequest, client kclient.Client) (taxonomy.Connection, error) {
+	endpoint, err := agent.GetProperty(request.AccountProperties.Items, impl.Name, endpointKey)
+	if err != nil {
+		return taxonomy.Connection{}, err
+	}
+	blobClient, err := NewClient(endpoint, &request.Secret, client)
+	if err != nil {
+		return taxonomy.Connection{}, err
+	}
+	containerName := generateContainerName(&request.Opts)
+	metadata := map[string]string{}
+	for key, value := range agent.OwnerTags(&request.Opts) {
+		metadata[metadataKeys[key]] = value
+	}
+	exists, err := blobClient.createContainer(containerName, metadata)
+	if err != nil {
+		return taxonomy.Connection{}, errors.Wrapf(err, "could not create a container %s", containerName)
+	}
+	if exists {
+		impl.Log.Info().Msgf("Container %s already exists", containerName)
+	}
+	connection := taxonomy.Connection{
+		Name: impl.Name,
+		AdditionalProperties: serde.Properties{
+			Items: map[string]interface{}{
+				string(impl.Name): map[string]interface{}{
+					endpointKey:  endpoint,
+					containerKey: containerName,
+					objectKey:    request.Opts.DatasetProperties.Name + utils.Hash(request.Opts.AppDetails.UUID, nameHashLength),
+				},
+			},
+		},
+	}
+	return connection, nil
+}
+
+// storage deletion
+// The container is deleted together with its blobs, if it exists.
+func (impl *AzureImpl) DeleteStorage(request *storagemanager.DeleteStorageRequest, client kclient.Client) error {
+	endpoint, err := agent.GetProperty(request.Connection.AdditionalProperties.Items, impl.Name, endpointKey)
+	if err != nil {
+		return err
+	}
+	containerName, err := agent.GetProperty(request.Connection.AdditionalProperties.Items, impl.Name, containerKey)
+	if err != nil {
+		return err
+	}
+	blobClient, err := NewClient(endpoint, &request.Secret, client)
+	if err != nil {
+		return err
+	}
+	return blobClient.deleteContainer(containerName)
+}
+
+// list the containers allocated in the account, i.e., the containers whose metadata marks their owner
+func (impl *AzureImpl) ListStorage(request *storagemanager.ListStorageRequest, client kclient.Client) (
+	[]storagemanager.AllocatedStorage, error) {
+	endpoint, err := agent.GetProperty(request.AccountProperties.Items, impl.Name, endpointKey)
+	if err != nil {
+		return nil, err
+	}
+	blobClient, err := NewClient(endpoint, &request.Secret, client)
+	if err != nil {
+		return nil, err
+	}
+	containers, err := blobClient.listContainers()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not list the containers")
+	}
+	allocated := []storagemanager.AllocatedStorage{}
+	for i := range containers {
+		metadata := containers[i].metadata()
+		tags := map[string]string{}
+		for key, metadataKey := range metadataKeys {
+			if value, found := metadata[metadataKey]; found {
+				tags[key] = value
+			}
+		}
+		storage, found := agent.AllocatedStorageFromTags(containers[i].Name, tags)
+		if !found {
+			continue
+		}
+		storage.Connection = taxonomy.Connection{
+			Name: impl.Name,
+			AdditionalProperties: serde.Properties{
+				Items: map[string]interface{}{
+					string(impl.Name): map[string]interface{}{
+						endpointKey:  endpoint,
+						containerKey: containers[i].Name,
+					},
+				},
+			},
+		}
+		// the last modification time of a container is its creation time, unless its metadata or properties are changed
+		if modified, err := http.ParseTime(containers[i].Properties.LastModified); err == nil {
+			storage.CreationTime = modified.UTC().Format(time.RFC3339)
+		}
+		allocated = append(allocated, storage)
+	}
+	return allocated, nil
+}
+
+// generateContainerName returns a unique container name, that consists of lowercase letters, digits and single hyphens
+func generateContainerName(opts *storagemanager.Options) string {
+	suffix, _ := random.Hex(nameHashLength)
+	name := invalidContainerChars.ReplaceAllString(strings.ToLower(opts.AppDetails.Name+"-"+opts.AppDetails.Namespace), "-")
+	if maxLen := maxContainerNameLen - len(suffix) - 1; len(name) > maxLen {
+		name = name[:maxLen]
+	}
+	return strings.Trim(name, "-") + "-" + suffix
+}
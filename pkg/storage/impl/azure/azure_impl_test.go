@@ -0,0 +1,178 @@
+// Copyright 2023 IBM Corp.
+// SPDX-License-Identifier: Apache-2.0
+
+package azure
+
+import (
+	"encoding/base64"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"fybrik.io/fybrik/pkg/model/storagemanager"
+	"fybrik.io/fybrik/pkg/model/taxonomy"
+	"fybrik.io/fybrik/pkg/serde"
+	"fybrik.io/fybrik/pkg/storage/registrator/agent"
+)
+
+const (
+	emulatorAccount = "devstoreaccount1"
+	emulatorKey     = "Eby8vdM02xNOcqFlqUwJPLlmEtlCDXJ1OUzFT50uSRZ6IFsuFq2UVErCz4I6tq/K1SZFPTOtr/KBHBeksoGMGw=="
+)
+
+// emulator is a minimal in-memory blob service that serves the container operations like Azurite,
+// i.e., with path-style URLs that start with the account name
+type emulator struct {
+	mu         sync.Mutex
+	containers map[string]map[string]string
+	verifier   *blobClient
+}
+
+func newEmulator() *emulator {
+	key, _ := base64.StdEncoding.DecodeString(emulatorKey)
+	return &emulator{containers: map[string]map[string]string{}, verifier: &blobClient{account: emulatorAccount, key: key}}
+}
+
+func (e *emulator) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if r.Header.Get("Authorization") != "SharedKey "+emulatorAccount+":"+e.verifier.signature(r) {
+		w.Header().Set("x-ms-error-code", "AuthenticationFailed")
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	name := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, "/"+emulatorAccount), "/")
+	switch {
+	case r.Method == http.MethodGet && name == "" && r.URL.Query().Get("comp") == "list":
+		e.list(w)
+	case r.Method == http.MethodPut && r.URL.Query().Get("restype") == "container":
+		if _, exists := e.containers[name]; exists {
+			w.WriteHeader(http.StatusConflict)
+			_, _ = w.Write([]byte(`<?xml version="1.0" encoding="utf-8"?><Error><Code>ContainerAlreadyExists</Code>` +
+				`<Message>The specified container already exists.</Message></Error>`))
+			return
+		}
+		metadata := map[string]string{}
+		for header := range r.Header {
+			if lower := strings.ToLower(header); strings.HasPrefix(lower, metadataHeaderPrefix) {
+				metadata[strings.TrimPrefix(lower, metadataHeaderPrefix)] = r.Header.Get(header)
+			}
+		}
+		e.containers[name] = metadata
+		w.WriteHeader(http.StatusCreated)
+	case r.Method == http.MethodDelete && r.URL.Query().Get("restype") == "container":
+		if _, exists := e.containers[name]; !exists {
+			w.Header().Set("x-ms-error-code", containerNotFoundCode)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		delete(e.containers, name)
+		w.WriteHeader(http.StatusAccepted)
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+	}
+}
+
+// list writes the containers together with their metadata
+func (e *emulator) list(w http.ResponseWriter) {
+	var builder strings.Builder
+	builder.WriteString(`<?xml version="1.0" encoding="utf-8"?><EnumerationResults><Containers>`)
+	for name, metadata := range e.containers {
+		builder.WriteString("<Container><Name>" + name + "</Name><Properties><Last-Modified>" +
+			time.Now().UTC().Format(http.TimeFormat) + "</Last-Modified></Properties><Metadata>")
+		for key, value := range metadata {
+			builder.WriteString("<" + key + ">")
+			_ = xml.EscapeText(&builder, []byte(value))
+			builder.WriteString("</" + key + ">")
+		}
+		builder.WriteString("</Metadata></Container>")
+	}
+	builder.WriteString("</Containers><NextMarker /></EnumerationResults>")
+	_, _ = w.Write([]byte(builder.String()))
+}
+
+func newClient(g *gomega.WithT) kclient.Client {
+	scheme := runtime.NewScheme()
+	g.Expect(corev1.AddToScheme(scheme)).To(gomega.Succeed())
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "azure-credentials", Namespace: "fybrik-system"},
+		Data:       map[string][]byte{accountNameKey: []byte(emulatorAccount), accountKeyKey: []byte(emulatorKey)},
+	}
+	return fake.NewClientBuilder().WithScheme(scheme).WithObjects(secret).Build()
+}
+
+func TestAllocateContainer(t *testing.T) {
+	t.Parallel()
+	g := gomega.NewGomegaWithT(t)
+	service := newEmulator()
+	server := httptest.NewServer(service)
+	defer server.Close()
+	client := newClient(g)
+	impl := NewAzureImpl()
+
+	secretRef := taxonomy.SecretRef{Name: "azure-credentials", Namespace: "fybrik-system"}
+	properties := serde.Properties{Items: map[string]interface{}{
+		azureAgent: map[string]interface{}{endpointKey: server.URL + "/" + emulatorAccount},
+	}}
+	request := &storagemanager.AllocateStorageRequest{
+		AccountType:       azureAgent,
+		AccountProperties: taxonomy.StorageAccountProperties{Properties: properties},
+		Secret:            secretRef,
+		Opts: storagemanager.Options{
+			AppDetails:        storagemanager.ApplicationDetails{Name: "My.Notebook", Namespace: "default", UUID: "1234"},
+			DatasetProperties: storagemanager.DatasetDetails{Name: "transactions", Persistent: true},
+		},
+	}
+	connection, err := impl.AllocateStorage(request, client)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	containerName, err := agent.GetProperty(connection.AdditionalProperties.Items, azureAgent, containerKey)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(containerName).To(gomega.MatchRegexp("^my-notebook-default-[0-9a-f]+$"))
+	g.Expect(service.containers).To(gomega.HaveKey(containerName))
+
+	// the container is listed as allocated storage, other containers are not
+	service.containers["external"] = map[string]string{}
+	listRequest := &storagemanager.ListStorageRequest{AccountType: azureAgent, AccountProperties: request.AccountProperties,
+		Secret: secretRef}
+	allocated, err := impl.ListStorage(listRequest, client)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(allocated).To(gomega.HaveLen(1))
+	g.Expect(allocated[0].Name).To(gomega.Equal(containerName))
+	g.Expect(allocated[0].AppDetails.Name).To(gomega.Equal("My.Notebook"))
+	g.Expect(allocated[0].AppDetails.UUID).To(gomega.Equal("1234"))
+	g.Expect(allocated[0].Persistent).To(gomega.BeTrue())
+	g.Expect(allocated[0].CreationTime).NotTo(gomega.BeEmpty())
+
+	// deletion is idempotent
+	deleteRequest := &storagemanager.DeleteStorageRequest{Connection: connection, Secret: secretRef}
+	g.Expect(impl.DeleteStorage(deleteRequest, client)).To(gomega.Succeed())
+	g.Expect(service.containers).NotTo(gomega.HaveKey(containerName))
+	g.Expect(impl.DeleteStorage(deleteRequest, client)).To(gomega.Succeed())
+}
+
+func TestInvalidCredentials(t *testing.T) {
+	t.Parallel()
+	g := gomega.NewGomegaWithT(t)
+	server := httptest.NewServer(newEmulator())
+	defer server.Close()
+	client := newClient(g)
+
+	blobClient, err := NewClient(server.URL+"/"+emulatorAccount,
+		&taxonomy.SecretRef{Name: "azure-credentials", Namespace: "fybrik-system"}, client)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	blobClient.key = []byte("wrong")
+	_, err = blobClient.createContainer("container", nil)
+	g.Expect(err).To(gomega.HaveOccurred())
+	g.Expect(isServiceError(err, "AuthenticationFailed")).To(gomega.BeTrue())
+}
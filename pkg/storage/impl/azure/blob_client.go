@@ -0,0 +1,284 @@
+// Copyright 2023 IBM Corp.
+// SPDX-License-Identifier: Apache-2.0
+
+package azure
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"emperror.dev/errors"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	kclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"fybrik.io/fybrik/pkg/model/taxonomy"
+)
+
+const (
+	apiVersion            = "2021-08-06"
+	accountNameKey        = "account_name"
+	accountKeyKey         = "account_key"
+	metadataHeaderPrefix  = "x-ms-meta-"
+	containerExistsCode   = "ContainerAlreadyExists"
+	containerNotFoundCode = "ContainerNotFound"
+	requestTimeout        = 30 * time.Second
+)
+
+// blobClient is a client of the container operations of the Azure Blob service REST API.
+// It authorizes the requests with a shared key, thus it can be used with storage accounts
+// as well as with compatible stores such as the Azurite emulator.
+type blobClient struct {
+	endpoint   *url.URL
+	account    string
+	key        []byte
+	httpClient *http.Client
+}
+
+// container is a container listed by the blob service
+type container struct {
+	Name       string `xml:"Name"`
+	Properties struct {
+		LastModified string `xml:"Last-Modified"`
+	} `xml:"Properties"`
+	Metadata struct {
+		Items []struct {
+			XMLName xml.Name
+			Value   string `xml:",chardata"`
+		} `xml:",any"`
+	} `xml:"Metadata"`
+}
+
+// listContainersResult is the response of the list containers operation
+type listContainersResult struct {
+	Containers []container `xml:"Containers>Container"`
+	NextMarker string      `xml:"NextMarker"`
+}
+
+// serviceError is the error returned by the blob service
+type serviceError struct {
+	Status  string `xml:"-"`
+	Code    string `xml:"Code"`
+	Message string `xml:"Message"`
+}
+
+func (e *serviceError) Error() string {
+	return "blob service returned " + e.Status + ": " + e.Code + " " + e.Message
+}
+
+// NewClient returns a client of the blob service at the given endpoint, with the credentials of the given secret
+func NewClient(endpoint string, secretRef *taxonomy.SecretRef, client kclient.Client) (*blobClient, error) {
+	endpointURL, err := url.Parse(strings.TrimSuffix(endpoint, "/"))
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid endpoint %s", endpoint)
+	}
+	// Get credentials
+	secret := v1.Secret{}
+	if err := client.Get(context.Background(), types.NamespacedName{Name: secretRef.Name,
+		Namespace: secretRef.Namespace}, &secret); err != nil {
+		return nil, errors.Wrapf(err, "could not get a secret %s", secretRef.Name)
+	}
+	account, encodedKey := string(secret.Data[accountNameKey]), string(secret.Data[accountKeyKey])
+	if account == "" || encodedKey == "" {
+		return nil, errors.Errorf("could not retrieve credentials from the secret %s", secretRef.Name)
+	}
+	key, err := base64.StdEncoding.DecodeString(encodedKey)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid account key in the secret %s", secretRef.Name)
+	}
+	return &blobClient{
+		endpoint:   endpointURL,
+		account:    account,
+		key:        key,
+		httpClient: &http.Client{Timeout: requestTimeout},
+	}, nil
+}
+
+// createContainer creates a container with the given metadata, returns true if the container already exists
+func (c *blobClient) createContainer(name string, metadata map[string]string) (bool, error) {
+	header := http.Header{}
+	for key, value := range metadata {
+		header.Set(metadataHeaderPrefix+key, value)
+	}
+	resp, err := c.do(http.MethodPut, name, url.Values{"restype": []string{"container"}}, header)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusCreated {
+		return false, nil
+	}
+	if err := responseError(resp); !isServiceError(err, containerExistsCode) {
+		return false, err
+	}
+	return true, nil
+}
+
+// deleteContainer deletes a container together with its blobs, a missing container is ignored
+func (c *blobClient) deleteContainer(name string) error {
+	resp, err := c.do(http.MethodDelete, name, url.Values{"restype": []string{"container"}}, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusAccepted {
+		return nil
+	}
+	if err := responseError(resp); !isServiceError(err, containerNotFoundCode) {
+		return err
+	}
+	return nil
+}
+
+// listContainers returns all the containers of the account together with their metadata
+func (c *blobClient) listContainers() ([]container, error) {
+	containers := []container{}
+	marker := ""
+	for {
+		query := url.Values{"comp": []string{"list"}, "include": []string{"metadata"}}
+		if marker != "" {
+			query.Set("marker", marker)
+		}
+		resp, err := c.do(http.MethodGet, "", query, nil)
+		if err != nil {
+			return nil, err
+		}
+		result := &listContainersResult{}
+		if resp.StatusCode != http.StatusOK {
+			err = responseError(resp)
+		} else {
+			err = xml.NewDecoder(resp.Body).Decode(result)
+		}
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		containers = append(containers, result.Containers...)
+		if result.NextMarker == "" {
+			return containers, nil
+		}
+		marker = result.NextMarker
+	}
+}
+
+// metadata returns the metadata of a listed container
+func (c *container) metadata() map[string]string {
+	metadata := make(map[string]string, len(c.Metadata.Items))
+	for _, item := range c.Metadata.Items {
+		metadata[strings.ToLower(item.XMLName.Local)] = item.Value
+	}
+	return metadata
+}
+
+// do sends a signed request for the given container, or for the account if the container is empty
+func (c *blobClient) do(method, containerName string, query url.Values, header http.Header) (*http.Response, error) {
+	requestURL := *c.endpoint
+	if containerName != "" {
+		requestURL.Path += "/" + containerName
+	} else if requestURL.Path == "" {
+		requestURL.Path = "/"
+	}
+	requestURL.RawQuery = query.Encode()
+	req, err := http.NewRequestWithContext(context.Background(), method, requestURL.String(), http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	req.Header.Set("x-ms-date", time.Now().UTC().Format(http.TimeFormat))
+	req.Header.Set("x-ms-version", apiVersion)
+	req.Header.Set("Authorization", "SharedKey "+c.account+":"+c.signature(req))
+	return c.httpClient.Do(req)
+}
+
+// signature returns the shared key signature of a request
+// see https://learn.microsoft.com/en-us/rest/api/storageservices/authorize-with-shared-key
+func (c *blobClient) signature(req *http.Request) string {
+	contentLength := ""
+	if req.ContentLength > 0 {
+		contentLength = strconv.FormatInt(req.ContentLength, 10)
+	}
+	stringToSign := strings.Join([]string{
+		req.Method,
+		req.Header.Get("Content-Encoding"),
+		req.Header.Get("Content-Language"),
+		contentLength,
+		req.Header.Get("Content-MD5"),
+		req.Header.Get("Content-Type"),
+		"", // Date is given by x-ms-date
+		req.Header.Get("If-Modified-Since"),
+		req.Header.Get("If-Match"),
+		req.Header.Get("If-None-Match"),
+		req.Header.Get("If-Unmodified-Since"),
+		req.Header.Get("Range"),
+		canonicalizedHeaders(req.Header) + c.canonicalizedResource(req.URL),
+	}, "\n")
+	mac := hmac.New(sha256.New, c.key)
+	mac.Write([]byte(stringToSign))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// canonicalizedHeaders returns the x-ms- headers of a request in canonical form
+func canonicalizedHeaders(header http.Header) string {
+	names := []string{}
+	for name := range header {
+		if lower := strings.ToLower(name); strings.HasPrefix(lower, "x-ms-") {
+			names = append(names, lower)
+		}
+	}
+	sort.Strings(names)
+	var builder strings.Builder
+	for _, name := range names {
+		builder.WriteString(name + ":" + strings.TrimSpace(header.Get(name)) + "\n")
+	}
+	return builder.String()
+}
+
+// canonicalizedResource returns the resource of a request in canonical form
+func (c *blobClient) canonicalizedResource(requestURL *url.URL) string {
+	path := requestURL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	resource := "/" + c.account + path
+	query := requestURL.Query()
+	names := make([]string, 0, len(query))
+	for name := range query {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		values := query[name]
+		sort.Strings(values)
+		resource += "\n" + strings.ToLower(name) + ":" + strings.Join(values, ",")
+	}
+	return resource
+}
+
+// responseError returns the error reported in a response of the blob service
+func responseError(resp *http.Response) error {
+	svcErr := &serviceError{}
+	body, _ := io.ReadAll(resp.Body)
+	if xml.Unmarshal(body, svcErr) != nil || svcErr.Code == "" {
+		svcErr.Code = resp.Header.Get("x-ms-error-code")
+	}
+	svcErr.Status = resp.Status
+	return svcErr
+}
+
+// isServiceError returns true if the error has been returned by the blob service with the given code
+func isServiceError(err error, code string) bool {
+	var svcErr *serviceError
+	return errors.As(err, &svcErr) && svcErr.Code == code
+}
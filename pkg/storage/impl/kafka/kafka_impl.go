@@ -0,0 +1,212 @@
+// Copyright 2023 IBM Corp.
+// SPDX-License-Identifier: Apache-2.0
+
+package kafka
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"emperror.dev/errors"
+	"github.com/rs/zerolog"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	kclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"fybrik.io/fybrik/pkg/logging"
+	"fybrik.io/fybrik/pkg/model/storagemanager"
+	"fybrik.io/fybrik/pkg/model/taxonomy"
+	"fybrik.io/fybrik/pkg/random"
+	"fybrik.io/fybrik/pkg/serde"
+	"fybrik.io/fybrik/pkg/storage/registrator"
+	"fybrik.io/fybrik/pkg/storage/registrator/agent"
+	"fybrik.io/fybrik/pkg/utils"
+)
+
+const (
+	kafkaAgent         = "kafka"
+	bootstrapKey       = "bootstrap_servers"
+	topicKey           = "topic_name"
+	clusterKey         = "cluster"
+	partitionsKey      = "partitions"
+	replicasKey        = "replicas"
+	clusterLabel       = "strimzi.io/cluster"
+	randomSuffixLength = 5
+	defaultPartitions  = 1
+	defaultReplicas    = 1
+)
+
+// connectionKeys are the account properties that are passed to the modules in the connection of the allocated topic
+var connectionKeys = []string{"security_protocol", "sasl_mechanism", "schema_registry", "ssl_truststore",
+	"key_deserializer", "value_deserializer"}
+
+// kafkaTopicGVK is the kind of the topics managed by the Strimzi topic operator
+var kafkaTopicGVK = schema.GroupVersionKind{Group: "kafka.strimzi.io", Version: "v1beta2", Kind: "KafkaTopic"}
+
+// Storage manager implementation for Kafka
+type KafkaImpl struct {
+	Name taxonomy.ConnectionType
+	Log  zerolog.Logger
+}
+
+// implementation of AgentInterface for Kafka
+func NewKafkaImpl() *KafkaImpl {
+	return &KafkaImpl{Name: kafkaAgent, Log: logging.LogInit(logging.CONNECTOR, "KafkaStorageManager")}
+}
+
+// register the implementation for Kafka
+func init() {
+	kafkaImpl := NewKafkaImpl()
+	if err := registrator.Register(kafkaImpl); err != nil {
+		kafkaImpl.Log.Error().Err(err).Send()
+	}
+}
+
+// return the supported connection type
+func (impl *KafkaImpl) GetConnectionType() taxonomy.ConnectionType {
+	return impl.Name
+}
+
+// storage allocation
+// bootstrap servers and security settings are taken from the storage account, the topic name is generated.
+// If the account specifies a Strimzi cluster, a KafkaTopic resource is created in the namespace of the account secret,
+// otherwise the topic is expected to be created by the brokers upon the first write.
+func (impl *KafkaImpl) AllocateStorage(request *storagemanager.AllocateStorageRequest, client kclient.Client) (taxonomy.Connection, error) {
+	details := "could not allocate a Kafka topic"
+	bootstrapServers, err := agent.GetProperty(request.AccountProperties.Items, impl.Name, bootstrapKey)
+	if err != nil {
+		return taxonomy.Connection{}, errors.Wrap(err, details)
+	}
+	topic := generateTopicName(&request.Opts, &impl.Log)
+	var cluster string
+	if cluster, err = agent.GetProperty(request.AccountProperties.Items, impl.Name, clusterKey); err == nil {
+		if err = impl.createTopic(request, client, cluster, topic); err != nil {
+			return taxonomy.Connection{}, errors.Wrap(err, details)
+		}
+	}
+	properties := map[string]interface{}{
+		bootstrapKey: bootstrapServers,
+		topicKey:     topic,
+	}
+	for _, key := range connectionKeys {
+		if value, propErr := agent.GetProperty(request.AccountProperties.Items, impl.Name, key); propErr == nil {
+			properties[key] = value
+		}
+	}
+	connection := taxonomy.Connection{
+		Name: impl.Name,
+		AdditionalProperties: serde.Properties{
+			Items: map[string]interface{}{string(impl.Name): properties},
+		},
+	}
+	return connection, nil
+}
+
+// storage deletion
+// The KafkaTopic resource of the topic is deleted, if it exists.
+func (impl *KafkaImpl) DeleteStorage(request *storagemanager.DeleteStorageRequest, client kclient.Client) error {
+	topic, err := agent.GetProperty(request.Connection.AdditionalProperties.Items, impl.Name, topicKey)
+	if err != nil {
+		return errors.Wrap(err, "delete Kafka topic")
+	}
+	kafkaTopic := &unstructured.Unstructured{}
+	kafkaTopic.SetGroupVersionKind(kafkaTopicGVK)
+	kafkaTopic.SetName(topic)
+	kafkaTopic.SetNamespace(request.Secret.Namespace)
+	err = client.Delete(context.Background(), kafkaTopic)
+	if meta.IsNoMatchError(err) {
+		impl.Log.Info().Msg("KafkaTopic resources are not supported, topic " + topic + " is not deleted")
+		return nil
+	}
+	return kclient.IgnoreNotFound(err)
+}
+
+// list the topics allocated in the account
+// Only the KafkaTopic resources of the Strimzi cluster of the account that are marked with their owner are listed,
+// thus listing is not supported for accounts without a Strimzi cluster.
+func (impl *KafkaImpl) ListStorage(request *storagemanager.ListStorageRequest, client kclient.Client) (
+	[]storagemanager.AllocatedStorage, error) {
+	cluster, err := agent.GetProperty(request.AccountProperties.Items, impl.Name, clusterKey)
+	if err != nil {
+		return nil, agent.ErrListNotSupported
+	}
+	bootstrapServers, err := agent.GetProperty(request.AccountProperties.Items, impl.Name, bootstrapKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "list Kafka topics")
+	}
+	kafkaTopics := &unstructured.UnstructuredList{}
+	kafkaTopics.SetGroupVersionKind(kafkaTopicGVK.GroupVersion().WithKind(kafkaTopicGVK.Kind + "List"))
+	err = client.List(context.Background(), kafkaTopics, kclient.InNamespace(request.Secret.Namespace),
+		kclient.MatchingLabels{clusterLabel: cluster}, kclient.HasLabels{agent.OwnerUUIDKey})
+	if meta.IsNoMatchError(err) {
+		return nil, agent.ErrListNotSupported
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "list Kafka topics")
+	}
+	allocated := []storagemanager.AllocatedStorage{}
+	for i := range kafkaTopics.Items {
+		kafkaTopic := &kafkaTopics.Items[i]
+		storage, found := agent.AllocatedStorageFromTags(kafkaTopic.GetName(), kafkaTopic.GetAnnotations())
+		if !found {
+			continue
+		}
+		storage.Connection = taxonomy.Connection{
+			Name: impl.Name,
+			AdditionalProperties: serde.Properties{
+				Items: map[string]interface{}{string(impl.Name): map[string]interface{}{
+					bootstrapKey: bootstrapServers,
+					topicKey:     kafkaTopic.GetName(),
+				}},
+			},
+		}
+		storage.CreationTime = kafkaTopic.GetCreationTimestamp().UTC().Format(time.RFC3339)
+		allocated = append(allocated, storage)
+	}
+	return allocated, nil
+}
+
+// createTopic creates a KafkaTopic resource that is reconciled by the topic operator of the given Strimzi cluster
+func (impl *KafkaImpl) createTopic(request *storagemanager.AllocateStorageRequest, client kclient.Client,
+	cluster, topic string) error {
+	partitions := getIntProperty(request.AccountProperties.Items, impl.Name, partitionsKey, defaultPartitions)
+	replicas := getIntProperty(request.AccountProperties.Items, impl.Name, replicasKey, defaultReplicas)
+	kafkaTopic := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"topicName":  topic,
+			"partitions": int64(partitions),
+			"replicas":   int64(replicas),
+		},
+	}}
+	kafkaTopic.SetGroupVersionKind(kafkaTopicGVK)
+	kafkaTopic.SetName(topic)
+	kafkaTopic.SetNamespace(request.Secret.Namespace)
+	// the topic is marked with its owner, so that it can be collected if it becomes orphaned
+	kafkaTopic.SetLabels(map[string]string{clusterLabel: cluster, agent.OwnerUUIDKey: request.Opts.AppDetails.UUID})
+	kafkaTopic.SetAnnotations(agent.OwnerTags(&request.Opts))
+	impl.Log.Info().Msgf("Creating KafkaTopic %s/%s in cluster %s", kafkaTopic.GetNamespace(), topic, cluster)
+	return client.Create(context.Background(), kafkaTopic)
+}
+
+// getIntProperty returns an integer property of the account, or the default value if the property is not specified
+func getIntProperty(props map[string]interface{}, t taxonomy.ConnectionType, key string, defaultValue int) int {
+	value, err := agent.GetProperty(props, t, key)
+	if err != nil {
+		return defaultValue
+	}
+	intValue, err := strconv.Atoi(value)
+	if err != nil {
+		return defaultValue
+	}
+	return intValue
+}
+
+// generateTopicName returns a unique topic name that is a valid name of a Kubernetes resource as well
+func generateTopicName(opts *storagemanager.Options, log *zerolog.Logger) string {
+	suffix, _ := random.Hex(randomSuffixLength)
+	name := strings.ToLower(opts.AppDetails.Name + "-" + opts.AppDetails.Namespace + "-" + suffix)
+	return utils.K8sConformName(name, log)
+}
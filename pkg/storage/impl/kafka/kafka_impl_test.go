@@ -0,0 +1,109 @@
+// Copyright 2023 IBM Corp.
+// SPDX-License-Identifier: Apache-2.0
+
+package kafka
+
+import (
+	"context"
+	"testing"
+
+	"github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"fybrik.io/fybrik/pkg/model/storagemanager"
+	"fybrik.io/fybrik/pkg/model/taxonomy"
+	"fybrik.io/fybrik/pkg/serde"
+	"fybrik.io/fybrik/pkg/storage/registrator/agent"
+)
+
+func newRequest(properties map[string]interface{}) *storagemanager.AllocateStorageRequest {
+	return &storagemanager.AllocateStorageRequest{
+		AccountType: kafkaAgent,
+		AccountProperties: taxonomy.StorageAccountProperties{
+			Properties: serde.Properties{Items: map[string]interface{}{kafkaAgent: properties}},
+		},
+		Secret: taxonomy.SecretRef{Name: "kafka-credentials", Namespace: "fybrik-system"},
+		Opts: storagemanager.Options{
+			AppDetails:        storagemanager.ApplicationDetails{Name: "ingest", Namespace: "default", UUID: "1234"},
+			DatasetProperties: storagemanager.DatasetDetails{Name: "events"},
+		},
+	}
+}
+
+func TestAllocateTopic(t *testing.T) {
+	t.Parallel()
+	g := gomega.NewGomegaWithT(t)
+	client := fake.NewClientBuilder().WithScheme(runtime.NewScheme()).Build()
+	impl := NewKafkaImpl()
+
+	request := newRequest(map[string]interface{}{
+		bootstrapKey:        "kafka:9092",
+		"security_protocol": "SASL_SSL",
+		clusterKey:          "my-cluster",
+		partitionsKey:       3,
+	})
+	connection, err := impl.AllocateStorage(request, client)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(connection.Name).To(gomega.Equal(taxonomy.ConnectionType(kafkaAgent)))
+	topic, err := agent.GetProperty(connection.AdditionalProperties.Items, kafkaAgent, topicKey)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(topic).To(gomega.HavePrefix("ingest-default-"))
+	protocol, err := agent.GetProperty(connection.AdditionalProperties.Items, kafkaAgent, "security_protocol")
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(protocol).To(gomega.Equal("SASL_SSL"))
+	// the cluster is not a connection property
+	_, err = agent.GetProperty(connection.AdditionalProperties.Items, kafkaAgent, clusterKey)
+	g.Expect(err).To(gomega.HaveOccurred())
+
+	// a KafkaTopic is created for the Strimzi cluster
+	kafkaTopic := &unstructured.Unstructured{}
+	kafkaTopic.SetGroupVersionKind(kafkaTopicGVK)
+	key := types.NamespacedName{Name: topic, Namespace: "fybrik-system"}
+	g.Expect(client.Get(context.Background(), key, kafkaTopic)).To(gomega.Succeed())
+	g.Expect(kafkaTopic.GetLabels()).To(gomega.HaveKeyWithValue(clusterLabel, "my-cluster"))
+	partitions, _, _ := unstructured.NestedInt64(kafkaTopic.Object, "spec", "partitions")
+	g.Expect(partitions).To(gomega.Equal(int64(3)))
+
+	// the topic is listed as allocated storage of its owner
+	listRequest := &storagemanager.ListStorageRequest{AccountType: kafkaAgent, AccountProperties: request.AccountProperties,
+		Secret: request.Secret}
+	allocated, err := impl.ListStorage(listRequest, client)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(allocated).To(gomega.HaveLen(1))
+	g.Expect(allocated[0].Name).To(gomega.Equal(topic))
+	g.Expect(allocated[0].AppDetails).To(gomega.Equal(request.Opts.AppDetails))
+	listedTopic, err := agent.GetProperty(allocated[0].Connection.AdditionalProperties.Items, kafkaAgent, topicKey)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(listedTopic).To(gomega.Equal(topic))
+
+	// the KafkaTopic is removed upon deletion
+	deleteRequest := &storagemanager.DeleteStorageRequest{Connection: connection, Secret: request.Secret}
+	g.Expect(impl.DeleteStorage(deleteRequest, client)).To(gomega.Succeed())
+	g.Expect(client.Get(context.Background(), key, kafkaTopic)).NotTo(gomega.Succeed())
+	// deleting a topic twice succeeds
+	g.Expect(impl.DeleteStorage(deleteRequest, client)).To(gomega.Succeed())
+	allocated, err = impl.ListStorage(listRequest, client)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(allocated).To(gomega.BeEmpty())
+}
+
+func TestAllocateTopicWithoutBootstrapServers(t *testing.T) {
+	t.Parallel()
+	g := gomega.NewGomegaWithT(t)
+	client := fake.NewClientBuilder().WithScheme(runtime.NewScheme()).Build()
+	_, err := NewKafkaImpl().AllocateStorage(newRequest(map[string]interface{}{}), client)
+	g.Expect(err).To(gomega.HaveOccurred())
+}
+
+func TestListTopicsWithoutCluster(t *testing.T) {
+	t.Parallel()
+	g := gomega.NewGomegaWithT(t)
+	client := fake.NewClientBuilder().WithScheme(runtime.NewScheme()).Build()
+	request := newRequest(map[string]interface{}{bootstrapKey: "kafka:9092"})
+	_, err := NewKafkaImpl().ListStorage(&storagemanager.ListStorageRequest{AccountType: kafkaAgent,
+		AccountProperties: request.AccountProperties, Secret: request.Secret}, client)
+	g.Expect(err).To(gomega.MatchError(agent.ErrListNotSupported))
+}
@@ -165,3 +165,9 @@ func (impl *MySQLImpl) DeleteStorage(request *storagemanager.DeleteStorageReques
 	}
 	return nil
 }
+
+// listing allocated storage is not supported, since databases can not be marked with their owner
+func (impl *MySQLImpl) ListStorage(request *storagemanager.ListStorageRequest, client kclient.Client) (
+	[]storagemanager.AllocatedStorage, error) {
+	return nil, agent.ErrListNotSupported
+}
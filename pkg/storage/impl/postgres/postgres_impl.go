@@ -0,0 +1,302 @@
+// Copyright 2023 IBM Corp.
+// SPDX-License-Identifier: Apache-2.0
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"emperror.dev/errors"
+	"github.com/lib/pq"
+	"github.com/rs/zerolog"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	kclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"fybrik.io/fybrik/pkg/logging"
+	"fybrik.io/fybrik/pkg/model/storagemanager"
+	"fybrik.io/fybrik/pkg/model/taxonomy"
+	"fybrik.io/fybrik/pkg/random"
+	"fybrik.io/fybrik/pkg/serde"
+	"fybrik.io/fybrik/pkg/storage/registrator"
+	"fybrik.io/fybrik/pkg/storage/registrator/agent"
+)
+
+const (
+	hostKey            = "host"
+	portKey            = "port"
+	dbKey              = "database"
+	schemaKey          = "schema"
+	tableKey           = "table"
+	sslKey             = "ssl"
+	roleKey            = "role"
+	usernameKey        = "username"
+	passwordKey        = "password"
+	timeout            = time.Second * 5
+	postgresAgent      = "postgres"
+	randomSuffixLength = 5
+	maxIdentifierLen   = 63
+)
+
+// invalidIdentifierChars are the characters that are replaced in generated schema names
+var invalidIdentifierChars = regexp.MustCompile(`[^a-z0-9_]`)
+
+// connectFunc returns a connection to a PostgreSQL database
+type connectFunc func(host, port, database string, ssl bool, secretRef taxonomy.SecretRef, client kclient.Client) (*sql.DB, error)
+
+// Storage manager implementation for PostgreSQL
+type PostgresImpl struct {
+	Name    taxonomy.ConnectionType
+	Log     zerolog.Logger
+	connect connectFunc
+}
+
+// implementation of AgentInterface for PostgreSQL
+func NewPostgresImpl() *PostgresImpl {
+	return &PostgresImpl{Name: postgresAgent, Log: logging.LogInit(logging.CONNECTOR, "PostgresStorageManager"), connect: NewClient}
+}
+
+// register the implementation for PostgreSQL
+func init() {
+	postgresImpl := NewPostgresImpl()
+	if err := registrator.Register(postgresImpl); err != nil {
+		postgresImpl.Log.Error().Err(err).Send()
+	}
+}
+
+// return the supported connection type
+func (impl *PostgresImpl) GetConnectionType() taxonomy.ConnectionType {
+	return impl.Name
+}
+
+// dsn returns the connection string of a database
+func dsn(username, password, host, port, database string, ssl bool) string {
+	sslMode := "disable"
+	if ssl {
+		sslMode = "require"
+	}
+	connURL := url.URL{
+		Scheme:   postgresAgent,
+		User:     url.UserPassword(username, password),
+		Host:     net.JoinHostPort(host, port),
+		Path:     "/" + database,
+		RawQuery: url.Values{"sslmode": []string{sslMode}}.Encode(),
+	}
+	return connURL.String()
+}
+
+// returns a connection to PostgreSQL
+func NewClient(host, port, database string, ssl bool, secretRef taxonomy.SecretRef, client kclient.Client) (*sql.DB, error) {
+	// Get credentials
+	secret := v1.Secret{}
+	if err := client.Get(context.Background(), types.NamespacedName{Name: secretRef.Name,
+		Namespace: secretRef.Namespace}, &secret); err != nil {
+		return nil, errors.Wrapf(err, "could not get a secret %s", secretRef.Name)
+	}
+
+	username, password := string(secret.Data[usernameKey]), string(secret.Data[passwordKey])
+	if username == "" || password == "" {
+		return nil, errors.Errorf("could not retrieve credentials from the secret %s", secretRef.Name)
+	}
+
+	// connect to the server
+	return sql.Open(postgresAgent, dsn(username, password, host, port, database, ssl))
+}
+
+// database holds the properties of the database that storage is allocated in
+type database struct {
+	host string
+	port string
+	name string
+	ssl  bool
+}
+
+// getDatabase returns the database properties of an account or a connection
+func (impl *PostgresImpl) getDatabase(props map[string]interface{}) (*database, error) {
+	db := &database{}
+	var err error
+	if db.host, err = agent.GetProperty(props, impl.Name, hostKey); err != nil {
+		return nil, err
+	}
+	if db.port, err = agent.GetProperty(props, impl.Name, portKey); err != nil {
+		return nil, err
+	}
+	if db.name, err = agent.GetProperty(props, impl.Name, dbKey); err != nil {
+		return nil, err
+	}
+	if ssl, err := agent.GetProperty(props, impl.Name, sslKey); err == nil {
+		db.ssl, _ = strconv.ParseBool(ssl)
+	}
+	return db, nil
+}
+
+// properties returns the connection properties of a schema in the database
+func (db *database) properties(schema string) (map[string]interface{}, error) {
+	port, err := strconv.Atoi(db.port)
+	if err != nil {
+		return nil, err
+	}
+	props := map[string]interface{}{
+		hostKey:   db.host,
+		portKey:   port,
+		dbKey:     db.name,
+		schemaKey: schema,
+	}
+	if db.ssl {
+		props[sslKey] = true
+	}
+	return props, nil
+}
+
+// storage allocation
+// host, port and database are taken from the storage account, the schema name is generated,
+// and the table is taken from the DatasetProperties.Name.
+// The schema is marked with its owner in its comment. If the account specifies a role, e.g., the role of the modules,
+// the role is granted the usage of the schema and the creation of tables in it.
+func (impl *PostgresImpl) AllocateStorage(request *storagemanager.AllocateStorageRequest, client kclient.Client) (
+	taxonomy.Connection, error) {
+	details := "could not allocate PostgreSQL storage"
+	db, err := impl.getDatabase(request.AccountProperties.Items)
+	if err != nil {
+		return taxonomy.Connection{}, errors.Wrap(err, details)
+	}
+	schema := generateSchemaName(&request.Opts)
+	props, err := db.properties(schema)
+	if err != nil {
+		return taxonomy.Connection{}, errors.Wrap(err, details)
+	}
+	props[tableKey] = request.Opts.DatasetProperties.Name
+	owner, err := json.Marshal(agent.OwnerTags(&request.Opts))
+	if err != nil {
+		return taxonomy.Connection{}, errors.Wrap(err, details)
+	}
+	queries := []string{
+		"CREATE SCHEMA IF NOT EXISTS " + pq.QuoteIdentifier(schema),
+		"COMMENT ON SCHEMA " + pq.QuoteIdentifier(schema) + " IS " + pq.QuoteLiteral(string(owner)),
+	}
+	if role, roleErr := agent.GetProperty(request.AccountProperties.Items, impl.Name, roleKey); roleErr == nil {
+		queries = append(queries, "GRANT USAGE, CREATE ON SCHEMA "+pq.QuoteIdentifier(schema)+" TO "+pq.QuoteIdentifier(role))
+	}
+	if err = impl.execute(db, request.Secret, client, queries); err != nil {
+		return taxonomy.Connection{}, errors.Wrap(err, details)
+	}
+	connection := taxonomy.Connection{
+		Name: impl.Name,
+		AdditionalProperties: serde.Properties{
+			Items: map[string]interface{}{string(impl.Name): props},
+		},
+	}
+	return connection, nil
+}
+
+// storage deletion
+// The schema is dropped together with the tables in it.
+func (impl *PostgresImpl) DeleteStorage(request *storagemanager.DeleteStorageRequest, client kclient.Client) error {
+	details := "delete PostgreSQL storage"
+	db, err := impl.getDatabase(request.Connection.AdditionalProperties.Items)
+	if err != nil {
+		return errors.Wrap(err, details)
+	}
+	schema, err := agent.GetProperty(request.Connection.AdditionalProperties.Items, impl.Name, schemaKey)
+	if err != nil {
+		return errors.Wrap(err, details)
+	}
+	query := "DROP SCHEMA IF EXISTS " + pq.QuoteIdentifier(schema) + " CASCADE"
+	if err = impl.execute(db, request.Secret, client, []string{query}); err != nil {
+		return errors.Wrap(err, details)
+	}
+	return nil
+}
+
+// list the schemas allocated in the database of the account, i.e., the schemas whose comment marks their owner
+func (impl *PostgresImpl) ListStorage(request *storagemanager.ListStorageRequest, client kclient.Client) (
+	[]storagemanager.AllocatedStorage, error) {
+	details := "list PostgreSQL storage"
+	db, err := impl.getDatabase(request.AccountProperties.Items)
+	if err != nil {
+		return nil, errors.Wrap(err, details)
+	}
+	conn, err := impl.connect(db.host, db.port, db.name, db.ssl, request.Secret, client)
+	if err != nil {
+		return nil, errors.Wrap(err, details)
+	}
+	defer conn.Close()
+	ctx, cancelfunc := context.WithTimeout(context.Background(), timeout)
+	defer cancelfunc()
+	rows, err := conn.QueryContext(ctx, "SELECT nspname, obj_description(oid, 'pg_namespace') FROM pg_namespace "+
+		"WHERE obj_description(oid, 'pg_namespace') IS NOT NULL")
+	if err != nil {
+		return nil, errors.Wrap(err, details)
+	}
+	defer rows.Close()
+	allocated := []storagemanager.AllocatedStorage{}
+	for rows.Next() {
+		var schema, comment string
+		if err = rows.Scan(&schema, &comment); err != nil {
+			return nil, errors.Wrap(err, details)
+		}
+		tags := map[string]string{}
+		if json.Unmarshal([]byte(comment), &tags) != nil {
+			continue
+		}
+		storage, found := agent.AllocatedStorageFromTags(schema, tags)
+		if !found {
+			continue
+		}
+		props, err := db.properties(schema)
+		if err != nil {
+			return nil, errors.Wrap(err, details)
+		}
+		storage.Connection = taxonomy.Connection{
+			Name:                 impl.Name,
+			AdditionalProperties: serde.Properties{Items: map[string]interface{}{string(impl.Name): props}},
+		}
+		allocated = append(allocated, storage)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, errors.Wrap(err, details)
+	}
+	return allocated, nil
+}
+
+// execute runs the queries in a single transaction
+func (impl *PostgresImpl) execute(db *database, secretRef taxonomy.SecretRef, client kclient.Client, queries []string) error {
+	conn, err := impl.connect(db.host, db.port, db.name, db.ssl, secretRef, client)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	ctx, cancelfunc := context.WithTimeout(context.Background(), timeout)
+	defer cancelfunc()
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	for _, query := range queries {
+		impl.Log.Info().Msgf("Sending query %s", query)
+		if _, err = tx.ExecContext(ctx, query); err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// generateSchemaName returns a unique schema name that is a valid unquoted PostgreSQL identifier
+func generateSchemaName(opts *storagemanager.Options) string {
+	suffix, _ := random.Hex(randomSuffixLength)
+	name := strings.ToLower(opts.AppDetails.Name + "_" + opts.AppDetails.Namespace)
+	name = invalidIdentifierChars.ReplaceAllString(name, "_")
+	if maxLen := maxIdentifierLen - len(suffix) - 1; len(name) > maxLen {
+		name = name[:maxLen]
+	}
+	return name + "_" + suffix
+}
@@ -0,0 +1,120 @@
+// Copyright 2023 IBM Corp.
+// SPDX-License-Identifier: Apache-2.0
+
+package postgres
+
+import (
+	"database/sql"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/onsi/gomega"
+	kclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"fybrik.io/fybrik/pkg/model/storagemanager"
+	"fybrik.io/fybrik/pkg/model/taxonomy"
+	"fybrik.io/fybrik/pkg/serde"
+	"fybrik.io/fybrik/pkg/storage/registrator/agent"
+)
+
+// newMockImpl returns an agent whose connections are served by the given mock
+func newMockImpl(g *gomega.WithT) (*PostgresImpl, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	impl := NewPostgresImpl()
+	impl.connect = func(host, port, database string, ssl bool, secretRef taxonomy.SecretRef, client kclient.Client) (*sql.DB, error) {
+		return db, nil
+	}
+	return impl, mock
+}
+
+func accountProperties() serde.Properties {
+	return serde.Properties{Items: map[string]interface{}{
+		"postgres": map[string]interface{}{"host": "localhost", "port": 5432, "database": "warehouse", "role": "modules"},
+	}}
+}
+
+func TestAllocateSchema(t *testing.T) {
+	t.Parallel()
+	g := gomega.NewGomegaWithT(t)
+	impl, mock := newMockImpl(g)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`CREATE SCHEMA IF NOT EXISTS "my_notebook_default_`)).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta(`COMMENT ON SCHEMA "my_notebook_default_`) + `.*` + regexp.QuoteMeta(agent.OwnerUUIDKey)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta(`GRANT USAGE, CREATE ON SCHEMA "my_notebook_default_`) + `.*` + regexp.QuoteMeta(`TO "modules"`)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+	request := &storagemanager.AllocateStorageRequest{
+		AccountType:       "postgres",
+		AccountProperties: taxonomy.StorageAccountProperties{Properties: accountProperties()},
+		Opts: storagemanager.Options{
+			AppDetails:        storagemanager.ApplicationDetails{Name: "my-notebook", Namespace: "default", UUID: "123"},
+			DatasetProperties: storagemanager.DatasetDetails{Name: "transactions"},
+		},
+	}
+	connection, err := impl.AllocateStorage(request, nil)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(mock.ExpectationsWereMet()).To(gomega.Succeed())
+	schema, err := agent.GetProperty(connection.AdditionalProperties.Items, impl.Name, schemaKey)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(schema).To(gomega.HavePrefix("my_notebook_default_"))
+	table, err := agent.GetProperty(connection.AdditionalProperties.Items, impl.Name, tableKey)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(table).To(gomega.Equal("transactions"))
+
+	// deletion drops the schema if it exists
+	impl, mock = newMockImpl(g)
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`DROP SCHEMA IF EXISTS "` + schema + `" CASCADE`)).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+	g.Expect(impl.DeleteStorage(&storagemanager.DeleteStorageRequest{Connection: connection}, nil)).To(gomega.Succeed())
+	g.Expect(mock.ExpectationsWereMet()).To(gomega.Succeed())
+}
+
+func TestAllocateSchemaRollback(t *testing.T) {
+	t.Parallel()
+	g := gomega.NewGomegaWithT(t)
+	impl, mock := newMockImpl(g)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("CREATE SCHEMA").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("COMMENT ON SCHEMA").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("GRANT").WillReturnError(sql.ErrConnDone)
+	mock.ExpectRollback()
+	request := &storagemanager.AllocateStorageRequest{
+		AccountType:       "postgres",
+		AccountProperties: taxonomy.StorageAccountProperties{Properties: accountProperties()},
+	}
+	_, err := impl.AllocateStorage(request, nil)
+	g.Expect(err).To(gomega.HaveOccurred())
+	g.Expect(mock.ExpectationsWereMet()).To(gomega.Succeed())
+}
+
+func TestListSchemas(t *testing.T) {
+	t.Parallel()
+	g := gomega.NewGomegaWithT(t)
+	impl, mock := newMockImpl(g)
+
+	rows := sqlmock.NewRows([]string{"nspname", "obj_description"}).
+		AddRow("my_notebook_default_1234", `{"app.fybrik.io/app-name":"my-notebook","app.fybrik.io/app-namespace":"default",`+
+			`"app.fybrik.io/app-uuid":"123","app.fybrik.io/persistent":"true"}`).
+		AddRow("public", "standard public schema")
+	mock.ExpectQuery("SELECT nspname").WillReturnRows(rows)
+	request := &storagemanager.ListStorageRequest{
+		AccountType:       "postgres",
+		AccountProperties: taxonomy.StorageAccountProperties{Properties: accountProperties()},
+	}
+	allocated, err := impl.ListStorage(request, nil)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(mock.ExpectationsWereMet()).To(gomega.Succeed())
+	g.Expect(allocated).To(gomega.HaveLen(1))
+	g.Expect(allocated[0].Name).To(gomega.Equal("my_notebook_default_1234"))
+	g.Expect(allocated[0].AppDetails.UUID).To(gomega.Equal("123"))
+	g.Expect(allocated[0].Persistent).To(gomega.BeTrue())
+	database, err := agent.GetProperty(allocated[0].Connection.AdditionalProperties.Items, impl.Name, dbKey)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(database).To(gomega.Equal("warehouse"))
+}
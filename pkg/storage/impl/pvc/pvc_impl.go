@@ -0,0 +1,183 @@
+// Copyright 2023 IBM Corp.
+// SPDX-License-Identifier: Apache-2.0
+
+package pvc
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"emperror.dev/errors"
+	"github.com/rs/zerolog"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"fybrik.io/fybrik/pkg/environment"
+	"fybrik.io/fybrik/pkg/logging"
+	"fybrik.io/fybrik/pkg/model/storagemanager"
+	"fybrik.io/fybrik/pkg/model/taxonomy"
+	"fybrik.io/fybrik/pkg/random"
+	"fybrik.io/fybrik/pkg/serde"
+	"fybrik.io/fybrik/pkg/storage/registrator"
+	"fybrik.io/fybrik/pkg/storage/registrator/agent"
+	"fybrik.io/fybrik/pkg/utils"
+)
+
+const (
+	pvcAgent           = "pvc"
+	claimNameKey       = "claim_name"
+	namespaceKey       = "namespace"
+	pathKey            = "path"
+	storageClassKey    = "storage_class"
+	sizeKey            = "size"
+	accessModeKey      = "access_mode"
+	defaultSize        = "1Gi"
+	defaultAccessMode  = corev1.ReadWriteOnce
+	randomSuffixLength = 5
+)
+
+// Storage manager implementation for PersistentVolumeClaims
+type PVCImpl struct {
+	Name taxonomy.ConnectionType
+	Log  zerolog.Logger
+}
+
+// implementation of AgentInterface for PersistentVolumeClaims
+func NewPVCImpl() *PVCImpl {
+	return &PVCImpl{Name: pvcAgent, Log: logging.LogInit(logging.CONNECTOR, "PVCStorageManager")}
+}
+
+// register the implementation for PersistentVolumeClaims
+func init() {
+	pvcImpl := NewPVCImpl()
+	if err := registrator.Register(pvcImpl); err != nil {
+		pvcImpl.Log.Error().Err(err).Send()
+	}
+}
+
+// return the supported connection type
+func (impl *PVCImpl) GetConnectionType() taxonomy.ConnectionType {
+	return impl.Name
+}
+
+// storage allocation
+// A PersistentVolumeClaim is created in the namespace of the account, or in the modules namespace if the account
+// does not specify one, so that it can be mounted by the modules. The storage class, size and access mode of the claim
+// are taken from the account. The data of the dataset is stored in a path named after the dataset.
+func (impl *PVCImpl) AllocateStorage(request *storagemanager.AllocateStorageRequest, client kclient.Client) (taxonomy.Connection, error) {
+	details := "could not allocate a PersistentVolumeClaim"
+	namespace := impl.getNamespace(request.AccountProperties.Items)
+	size, err := resource.ParseQuantity(impl.getProperty(request.AccountProperties.Items, sizeKey, defaultSize))
+	if err != nil {
+		return taxonomy.Connection{}, errors.Wrap(err, details)
+	}
+	accessMode := corev1.PersistentVolumeAccessMode(impl.getProperty(request.AccountProperties.Items, accessModeKey, string(defaultAccessMode)))
+	claim := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      generateClaimName(&request.Opts, &impl.Log),
+			Namespace: namespace,
+			// the claim is marked with its owner, so that it can be collected if it becomes orphaned
+			Labels:      map[string]string{agent.OwnerUUIDKey: request.Opts.AppDetails.UUID},
+			Annotations: agent.OwnerTags(&request.Opts),
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes: []corev1.PersistentVolumeAccessMode{accessMode},
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceStorage: size},
+			},
+		},
+	}
+	if storageClass, err := agent.GetProperty(request.AccountProperties.Items, impl.Name, storageClassKey); err == nil {
+		claim.Spec.StorageClassName = &storageClass
+	}
+	impl.Log.Info().Msgf("Creating PersistentVolumeClaim %s/%s", namespace, claim.Name)
+	if err := client.Create(context.Background(), claim); err != nil && !apierrors.IsAlreadyExists(err) {
+		return taxonomy.Connection{}, errors.Wrap(err, details)
+	}
+	connection := taxonomy.Connection{
+		Name: impl.Name,
+		AdditionalProperties: serde.Properties{
+			Items: map[string]interface{}{
+				string(impl.Name): map[string]interface{}{
+					claimNameKey: claim.Name,
+					namespaceKey: namespace,
+					pathKey:      request.Opts.DatasetProperties.Name,
+				},
+			},
+		},
+	}
+	return connection, nil
+}
+
+// storage deletion
+// The PersistentVolumeClaim is deleted, if it exists. The volume is released according to the reclaim policy
+// of its storage class.
+func (impl *PVCImpl) DeleteStorage(request *storagemanager.DeleteStorageRequest, client kclient.Client) error {
+	details := "delete PersistentVolumeClaim"
+	name, err := agent.GetProperty(request.Connection.AdditionalProperties.Items, impl.Name, claimNameKey)
+	if err != nil {
+		return errors.Wrap(err, details)
+	}
+	namespace, err := agent.GetProperty(request.Connection.AdditionalProperties.Items, impl.Name, namespaceKey)
+	if err != nil {
+		return errors.Wrap(err, details)
+	}
+	claim := &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}
+	return kclient.IgnoreNotFound(client.Delete(context.Background(), claim))
+}
+
+// list the PersistentVolumeClaims allocated in the namespace of the account, i.e., the claims marked with their owner
+func (impl *PVCImpl) ListStorage(request *storagemanager.ListStorageRequest, client kclient.Client) (
+	[]storagemanager.AllocatedStorage, error) {
+	namespace := impl.getNamespace(request.AccountProperties.Items)
+	claims := &corev1.PersistentVolumeClaimList{}
+	if err := client.List(context.Background(), claims, kclient.InNamespace(namespace),
+		kclient.HasLabels{agent.OwnerUUIDKey}); err != nil {
+		return nil, errors.Wrap(err, "list PersistentVolumeClaims")
+	}
+	allocated := []storagemanager.AllocatedStorage{}
+	for i := range claims.Items {
+		claim := &claims.Items[i]
+		storage, found := agent.AllocatedStorageFromTags(claim.Name, claim.Annotations)
+		if !found {
+			continue
+		}
+		storage.Connection = taxonomy.Connection{
+			Name: impl.Name,
+			AdditionalProperties: serde.Properties{
+				Items: map[string]interface{}{string(impl.Name): map[string]interface{}{
+					claimNameKey: claim.Name,
+					namespaceKey: namespace,
+				}},
+			},
+		}
+		storage.CreationTime = claim.CreationTimestamp.UTC().Format(time.RFC3339)
+		allocated = append(allocated, storage)
+	}
+	return allocated, nil
+}
+
+// getNamespace returns the namespace of the claims allocated in the account
+func (impl *PVCImpl) getNamespace(props map[string]interface{}) string {
+	return impl.getProperty(props, namespaceKey, environment.GetDefaultModulesNamespace())
+}
+
+// getProperty returns a property of the account, or the default value if the property is not specified
+func (impl *PVCImpl) getProperty(props map[string]interface{}, key, defaultValue string) string {
+	value, err := agent.GetProperty(props, impl.Name, key)
+	if err != nil || value == "" {
+		return defaultValue
+	}
+	return value
+}
+
+// generateClaimName returns a unique claim name that is a valid name of a Kubernetes resource
+func generateClaimName(opts *storagemanager.Options, log *zerolog.Logger) string {
+	suffix, _ := random.Hex(randomSuffixLength)
+	name := strings.ToLower(opts.AppDetails.Name + "-" + opts.AppDetails.Namespace + "-" + suffix)
+	return utils.K8sConformName(name, log)
+}
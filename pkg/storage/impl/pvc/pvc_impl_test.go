@@ -0,0 +1,104 @@
+// Copyright 2023 IBM Corp.
+// SPDX-License-Identifier: Apache-2.0
+
+package pvc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"fybrik.io/fybrik/pkg/environment"
+	"fybrik.io/fybrik/pkg/model/storagemanager"
+	"fybrik.io/fybrik/pkg/model/taxonomy"
+	"fybrik.io/fybrik/pkg/serde"
+	"fybrik.io/fybrik/pkg/storage/registrator/agent"
+)
+
+func newRequest(properties map[string]interface{}) *storagemanager.AllocateStorageRequest {
+	return &storagemanager.AllocateStorageRequest{
+		AccountType: pvcAgent,
+		AccountProperties: taxonomy.StorageAccountProperties{
+			Properties: serde.Properties{Items: map[string]interface{}{pvcAgent: properties}},
+		},
+		Opts: storagemanager.Options{
+			AppDetails:        storagemanager.ApplicationDetails{Name: "notebook", Namespace: "default", UUID: "1234"},
+			DatasetProperties: storagemanager.DatasetDetails{Name: "transactions"},
+		},
+	}
+}
+
+func TestAllocateClaim(t *testing.T) {
+	t.Parallel()
+	g := gomega.NewGomegaWithT(t)
+	scheme := runtime.NewScheme()
+	g.Expect(corev1.AddToScheme(scheme)).To(gomega.Succeed())
+	client := fake.NewClientBuilder().WithScheme(scheme).Build()
+	impl := NewPVCImpl()
+
+	request := newRequest(map[string]interface{}{
+		namespaceKey:    "data",
+		storageClassKey: "standard",
+		sizeKey:         "10Gi",
+		accessModeKey:   "ReadWriteMany",
+	})
+	connection, err := impl.AllocateStorage(request, client)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(connection.Name).To(gomega.Equal(taxonomy.ConnectionType(pvcAgent)))
+	name, err := agent.GetProperty(connection.AdditionalProperties.Items, pvcAgent, claimNameKey)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(name).To(gomega.HavePrefix("notebook-default-"))
+	path, err := agent.GetProperty(connection.AdditionalProperties.Items, pvcAgent, pathKey)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(path).To(gomega.Equal("transactions"))
+
+	claim := &corev1.PersistentVolumeClaim{}
+	g.Expect(client.Get(context.Background(), types.NamespacedName{Name: name, Namespace: "data"}, claim)).To(gomega.Succeed())
+	g.Expect(*claim.Spec.StorageClassName).To(gomega.Equal("standard"))
+	g.Expect(claim.Spec.AccessModes).To(gomega.ConsistOf(corev1.ReadWriteMany))
+	g.Expect(claim.Spec.Resources.Requests.Storage().String()).To(gomega.Equal("10Gi"))
+	g.Expect(claim.Annotations).To(gomega.HaveKeyWithValue(agent.OwnerNameKey, "notebook"))
+
+	// the claim is listed as allocated storage
+	listRequest := &storagemanager.ListStorageRequest{AccountType: pvcAgent, AccountProperties: request.AccountProperties}
+	allocated, err := impl.ListStorage(listRequest, client)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(allocated).To(gomega.HaveLen(1))
+	g.Expect(allocated[0].Name).To(gomega.Equal(name))
+	g.Expect(allocated[0].AppDetails.UUID).To(gomega.Equal("1234"))
+
+	// deletion is idempotent
+	deleteRequest := &storagemanager.DeleteStorageRequest{Connection: connection}
+	g.Expect(impl.DeleteStorage(deleteRequest, client)).To(gomega.Succeed())
+	g.Expect(impl.DeleteStorage(deleteRequest, client)).To(gomega.Succeed())
+	allocated, err = impl.ListStorage(listRequest, client)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(allocated).To(gomega.BeEmpty())
+}
+
+func TestAllocateClaimDefaults(t *testing.T) {
+	t.Parallel()
+	g := gomega.NewGomegaWithT(t)
+	scheme := runtime.NewScheme()
+	g.Expect(corev1.AddToScheme(scheme)).To(gomega.Succeed())
+	client := fake.NewClientBuilder().WithScheme(scheme).Build()
+	impl := NewPVCImpl()
+
+	connection, err := impl.AllocateStorage(newRequest(map[string]interface{}{}), client)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	name, err := agent.GetProperty(connection.AdditionalProperties.Items, pvcAgent, claimNameKey)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	namespace, err := agent.GetProperty(connection.AdditionalProperties.Items, pvcAgent, namespaceKey)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(namespace).To(gomega.Equal(environment.GetDefaultModulesNamespace()))
+	claim := &corev1.PersistentVolumeClaim{}
+	g.Expect(client.Get(context.Background(), types.NamespacedName{Name: name, Namespace: namespace}, claim)).To(gomega.Succeed())
+	g.Expect(claim.Spec.StorageClassName).To(gomega.BeNil())
+	g.Expect(claim.Spec.AccessModes).To(gomega.ConsistOf(corev1.ReadWriteOnce))
+	g.Expect(claim.Spec.Resources.Requests.Storage().String()).To(gomega.Equal(defaultSize))
+}
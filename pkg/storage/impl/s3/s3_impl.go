@@ -6,10 +6,12 @@ package s3
 import (
 	"context"
 	"strings"
+	"time"
 
 	"emperror.dev/errors"
 	"github.com/minio/minio-go/v7"
 	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/minio/minio-go/v7/pkg/tags"
 	"github.com/rs/zerolog"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
@@ -30,6 +32,7 @@ const (
 	endpointKey    = "endpoint"
 	bucketKey      = "bucket"
 	objectKey      = "object_key"
+	noTagsError    = "NoSuchTagSet"
 )
 
 // s3 storage manager implementation
@@ -72,6 +75,14 @@ func (impl *S3Impl) AllocateStorage(request *storagemanager.AllocateStorageReque
 	if err = minioClient.MakeBucket(context.Background(), genBucketName, minio.MakeBucketOptions{}); err != nil {
 		return taxonomy.Connection{}, errors.Wrapf(err, "could not create a bucket %s", genBucketName)
 	}
+	// the bucket is tagged with its owner, so that it can be collected if it becomes orphaned
+	bucketTags, err := tags.NewTags(agent.OwnerTags(&request.Opts), false)
+	if err == nil {
+		err = minioClient.SetBucketTagging(context.Background(), genBucketName, bucketTags)
+	}
+	if err != nil {
+		impl.Log.Warn().Err(err).Msgf("could not tag the bucket %s, it will not be listed as allocated storage", genBucketName)
+	}
 	connection := taxonomy.Connection{
 		Name: impl.Name,
 		AdditionalProperties: serde.Properties{
@@ -116,6 +127,53 @@ func (impl *S3Impl) DeleteStorage(request *storagemanager.DeleteStorageRequest,
 	return minioClient.RemoveBucket(context.Background(), bucket)
 }
 
+// list the buckets allocated in the account, i.e., the buckets tagged with their owner
+func (impl *S3Impl) ListStorage(request *storagemanager.ListStorageRequest, client kclient.Client) (
+	[]storagemanager.AllocatedStorage, error) {
+	endpoint, err := agent.GetProperty(request.AccountProperties.Items, impl.Name, endpointKey)
+	if err != nil {
+		return nil, err
+	}
+	minioClient, err := NewClient(endpoint, &request.Secret, client)
+	if err != nil {
+		return nil, err
+	}
+	buckets, err := minioClient.ListBuckets(context.Background())
+	if err != nil {
+		return nil, errors.Wrap(err, "could not list the buckets")
+	}
+	allocated := []storagemanager.AllocatedStorage{}
+	for _, bucket := range buckets {
+		bucketTags, err := minioClient.GetBucketTagging(context.Background(), bucket.Name)
+		if err != nil {
+			if minio.ToErrorResponse(err).Code != noTagsError {
+				impl.Log.Warn().Err(err).Msgf("could not get the tags of the bucket %s", bucket.Name)
+			}
+			continue
+		}
+		storage, found := agent.AllocatedStorageFromTags(bucket.Name, bucketTags.ToMap())
+		if !found {
+			continue
+		}
+		storage.Connection = taxonomy.Connection{
+			Name: impl.Name,
+			AdditionalProperties: serde.Properties{
+				Items: map[string]interface{}{
+					string(impl.Name): map[string]interface{}{
+						endpointKey: endpoint,
+						bucketKey:   bucket.Name,
+					},
+				},
+			},
+		}
+		if !bucket.CreationDate.IsZero() {
+			storage.CreationTime = bucket.CreationDate.UTC().Format(time.RFC3339)
+		}
+		allocated = append(allocated, storage)
+	}
+	return allocated, nil
+}
+
 func generateBucketName(opts *storagemanager.Options) string {
 	suffix, _ := random.Hex(nameHashLength)
 	name := opts.AppDetails.Name + "-" + opts.AppDetails.Namespace + suffix
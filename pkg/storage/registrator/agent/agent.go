@@ -13,12 +13,25 @@ import (
 	"fybrik.io/fybrik/pkg/model/taxonomy"
 )
 
+// Tags or labels that mark allocated storage with the application it has been allocated for
+const (
+	OwnerNameKey      = "app.fybrik.io/app-name"
+	OwnerNamespaceKey = "app.fybrik.io/app-namespace"
+	OwnerUUIDKey      = "app.fybrik.io/app-uuid"
+	PersistentKey     = "app.fybrik.io/persistent"
+)
+
+// ErrListNotSupported is returned by agents that can not list the storage they have allocated
+var ErrListNotSupported = errors.New("listing allocated storage is not supported")
+
 // agent interface for managing storage for a supported connection type
 type AgentInterface interface {
 	// allocate storage
 	AllocateStorage(request *storagemanager.AllocateStorageRequest, client kclient.Client) (taxonomy.Connection, error)
 	// delete storage
 	DeleteStorage(request *storagemanager.DeleteStorageRequest, client kclient.Client) error
+	// list the storage allocated in an account, returns ErrListNotSupported if the allocated storage can not be identified
+	ListStorage(request *storagemanager.ListStorageRequest, client kclient.Client) ([]storagemanager.AllocatedStorage, error)
 	// return the supported connection type
 	GetConnectionType() taxonomy.ConnectionType
 }
@@ -40,3 +53,30 @@ func GetProperty(props map[string]interface{}, t taxonomy.ConnectionType, key st
 	}
 	return "", errors.New("undefined or missing property " + key)
 }
+
+// get the tags that mark storage allocated with the given options
+func OwnerTags(opts *storagemanager.Options) map[string]string {
+	tags := map[string]string{
+		OwnerNameKey:      opts.AppDetails.Name,
+		OwnerNamespaceKey: opts.AppDetails.Namespace,
+		OwnerUUIDKey:      opts.AppDetails.UUID,
+	}
+	if opts.DatasetProperties.Persistent {
+		tags[PersistentKey] = "true"
+	}
+	return tags
+}
+
+// get the details of allocated storage from its tags
+// returns false if the storage has not been allocated by the storage manager
+func AllocatedStorageFromTags(name string, tags map[string]string) (storagemanager.AllocatedStorage, bool) {
+	uuid, found := tags[OwnerUUIDKey]
+	if !found {
+		return storagemanager.AllocatedStorage{}, false
+	}
+	return storagemanager.AllocatedStorage{
+		Name:       name,
+		AppDetails: storagemanager.ApplicationDetails{Name: tags[OwnerNameKey], Namespace: tags[OwnerNamespaceKey], UUID: uuid},
+		Persistent: tags[PersistentKey] == "true",
+	}, true
+}